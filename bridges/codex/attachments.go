@@ -0,0 +1,163 @@
+package codex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+)
+
+const codexAttachmentMaxBytes = 50 * 1024 * 1024
+
+// codexAttachment is a Matrix upload saved to disk for a Codex turn.
+type codexAttachment struct {
+	Path     string
+	Name     string
+	MimeType string
+	IsImage  bool
+}
+
+func isCodexAttachmentMsgType(msgType event.MessageType) bool {
+	switch msgType {
+	case event.MsgImage, event.MsgFile, event.MsgAudio, event.MsgVideo:
+		return true
+	}
+	return false
+}
+
+// codexAttachmentCaption returns the caption of a media message, which is the
+// body when the message also carries a separate file name.
+func codexAttachmentCaption(content *event.MessageEventContent) string {
+	if content == nil || content.FileName == "" || content.Body == content.FileName {
+		return ""
+	}
+	return strings.TrimSpace(content.Body)
+}
+
+// codexAttachmentDir is the scratch directory that holds a room's uploads.
+// It lives outside the working directory so uploads never end up in the
+// user's repository unless Codex is asked to move them there.
+func (cc *CodexClient) codexAttachmentDir(portal *bridgev2.Portal) string {
+	return filepath.Join(os.TempDir(), "ai-bridge-codex-attachments", sanitizeCodexPathComponent(string(cc.UserLogin.ID)), sanitizeCodexPathComponent(string(portal.ID)))
+}
+
+// saveCodexAttachment downloads the media of a Matrix message into the room's
+// attachment directory.
+func (cc *CodexClient) saveCodexAttachment(ctx context.Context, portal *bridgev2.Portal, evt *event.Event, content *event.MessageEventContent) (*codexAttachment, error) {
+	if cc.UserLogin == nil || cc.UserLogin.Bridge == nil || cc.UserLogin.Bridge.Bot == nil {
+		return nil, errors.New("bridge is unavailable")
+	}
+	mediaURL := content.URL
+	if content.File != nil {
+		mediaURL = content.File.URL
+	}
+	if mediaURL == "" {
+		return nil, errors.New("message has no media")
+	}
+	if content.Info != nil && content.Info.Size > codexAttachmentMaxBytes {
+		return nil, fmt.Errorf("file is too large (max %d MB)", codexAttachmentMaxBytes/1024/1024)
+	}
+	data, err := cc.UserLogin.Bridge.Bot.DownloadMedia(ctx, id.ContentURIString(mediaURL), content.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download media: %w", err)
+	}
+	if len(data) > codexAttachmentMaxBytes {
+		return nil, fmt.Errorf("file is too large (max %d MB)", codexAttachmentMaxBytes/1024/1024)
+	}
+
+	mimeType := ""
+	if content.Info != nil {
+		mimeType = strings.TrimSpace(content.Info.MimeType)
+	}
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	name := codexAttachmentFileName(content, mimeType)
+	dir := cc.codexAttachmentDir(portal)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	// Prefix with the event ID so repeated uploads of e.g. "image.png" don't clash.
+	path := filepath.Join(dir, sanitizeCodexPathComponent(strings.TrimPrefix(evt.ID.String(), "$"))+"-"+name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+	return &codexAttachment{
+		Path:     path,
+		Name:     name,
+		MimeType: mimeType,
+		IsImage:  content.MsgType == event.MsgImage || strings.HasPrefix(mimeType, "image/"),
+	}, nil
+}
+
+func codexAttachmentFileName(content *event.MessageEventContent, mimeType string) string {
+	name := strings.TrimSpace(content.FileName)
+	if name == "" {
+		name = strings.TrimSpace(content.Body)
+	}
+	name = sanitizeCodexPathComponent(filepath.Base(name))
+	if name == "" || name == "." || name == "_" {
+		name = "attachment"
+	}
+	if filepath.Ext(name) == "" {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	return name
+}
+
+func sanitizeCodexPathComponent(raw string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(raw))
+}
+
+// buildCodexTurnInput converts a message body and its attachments into
+// turn/start input items. Images are sent as local images so the model can
+// see them; other files are referenced by path for Codex to open.
+func buildCodexTurnInput(body string, attachments []codexAttachment) []map[string]any {
+	var text strings.Builder
+	text.WriteString(strings.TrimSpace(body))
+	var images []map[string]any
+	for _, attachment := range attachments {
+		if attachment.IsImage {
+			images = append(images, map[string]any{"type": "localImage", "path": attachment.Path})
+			continue
+		}
+		if text.Len() > 0 {
+			text.WriteString("\n\n")
+		}
+		fmt.Fprintf(&text, "Attached file %s (%s) saved at %s", attachment.Name, attachment.MimeType, attachment.Path)
+	}
+	input := make([]map[string]any, 0, len(images)+1)
+	if text.Len() > 0 {
+		input = append(input, map[string]any{"type": "text", "text": text.String()})
+	}
+	return append(input, images...)
+}
+
+// codexAttachmentSummary describes an upload in the stored user message.
+func codexAttachmentSummary(body string, attachment *codexAttachment) string {
+	label := "file"
+	if attachment.IsImage {
+		label = "image"
+	}
+	summary := fmt.Sprintf("[%s: %s]", label, attachment.Name)
+	if body = strings.TrimSpace(body); body != "" {
+		summary = body + "\n" + summary
+	}
+	return summary
+}
@@ -0,0 +1,47 @@
+package codex
+
+import (
+	"strings"
+	"testing"
+
+	"maunium.net/go/mautrix/event"
+)
+
+func TestBuildCodexTurnInput(t *testing.T) {
+	input := buildCodexTurnInput("why is this broken?", []codexAttachment{
+		{Path: "/tmp/a/shot.png", Name: "shot.png", MimeType: "image/png", IsImage: true},
+		{Path: "/tmp/a/log.txt", Name: "log.txt", MimeType: "text/plain"},
+	})
+	if len(input) != 2 {
+		t.Fatalf("expected text and image items, got %#v", input)
+	}
+	text, _ := input[0]["text"].(string)
+	if input[0]["type"] != "text" || !strings.HasPrefix(text, "why is this broken?") || !strings.Contains(text, "/tmp/a/log.txt") {
+		t.Fatalf("unexpected text item %#v", input[0])
+	}
+	if input[1]["type"] != "localImage" || input[1]["path"] != "/tmp/a/shot.png" {
+		t.Fatalf("unexpected image item %#v", input[1])
+	}
+
+	imageOnly := buildCodexTurnInput("", []codexAttachment{{Path: "/tmp/a/shot.png", IsImage: true}})
+	if len(imageOnly) != 1 || imageOnly[0]["type"] != "localImage" {
+		t.Fatalf("expected only an image item without a caption, got %#v", imageOnly)
+	}
+}
+
+func TestCodexAttachmentFileName(t *testing.T) {
+	content := &event.MessageEventContent{MsgType: event.MsgFile, Body: "look at this", FileName: "../../etc/my report.pdf"}
+	if got := codexAttachmentFileName(content, "application/pdf"); got != "my_report.pdf" {
+		t.Fatalf("expected sanitized base name, got %q", got)
+	}
+	if got := codexAttachmentCaption(content); got != "look at this" {
+		t.Fatalf("expected caption, got %q", got)
+	}
+	noCaption := &event.MessageEventContent{MsgType: event.MsgImage, Body: "image.png"}
+	if got := codexAttachmentCaption(noCaption); got != "" {
+		t.Fatalf("expected no caption when body is the file name, got %q", got)
+	}
+	if got := codexAttachmentFileName(&event.MessageEventContent{}, "image/png"); !strings.HasPrefix(got, "attachment.") {
+		t.Fatalf("expected fallback name with extension, got %q", got)
+	}
+}
@@ -0,0 +1,362 @@
+package codex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/networkid"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/pkg/bridgeadapter"
+	"github.com/beeper/agentremote/pkg/connector/msgconv"
+	"github.com/beeper/agentremote/pkg/matrixevents"
+	"github.com/beeper/agentremote/pkg/shared/streamui"
+)
+
+var _ bridgev2.BackfillingNetworkAPI = (*CodexClient)(nil)
+
+// codexBackfillMessage is one Matrix message rendered from an imported turn.
+type codexBackfillMessage struct {
+	id     networkid.MessageID
+	fromMe bool
+	when   time.Time
+	part   *bridgev2.ConvertedMessagePart
+}
+
+// FetchMessages backfills imported threads with the turns that existed when
+// the thread was imported. Rooms started from Matrix have nothing to backfill.
+func (cc *CodexClient) FetchMessages(ctx context.Context, params bridgev2.FetchMessagesParams) (*bridgev2.FetchMessagesResponse, error) {
+	if params.Portal == nil || params.ThreadRoot != "" {
+		return nil, nil
+	}
+	meta := portalMeta(params.Portal)
+	if meta == nil || !meta.IsCodexRoom || !meta.CodexThreadImported || strings.TrimSpace(meta.CodexThreadID) == "" {
+		return nil, nil
+	}
+	thread, err := cc.readCodexThread(ctx, strings.TrimSpace(meta.CodexThreadID))
+	if err != nil {
+		return nil, err
+	}
+	turns := thread.Turns
+	if meta.CodexImportedTurns >= 0 && meta.CodexImportedTurns < len(turns) {
+		turns = turns[:meta.CodexImportedTurns]
+	}
+	messages := buildCodexThreadBackfill(thread, turns)
+
+	var batch []codexBackfillMessage
+	var cursor networkid.PaginationCursor
+	hasMore := false
+	if params.Forward {
+		start := 0
+		if params.AnchorMessage != nil {
+			// Anything that isn't a backfilled turn was bridged live after the
+			// import, so there is nothing newer to fill in.
+			start = len(messages)
+			for i, msg := range messages {
+				if msg.id == params.AnchorMessage.ID {
+					start = i + 1
+					break
+				}
+			}
+		}
+		end := len(messages)
+		if params.Count > 0 && start+params.Count < end {
+			end = start + params.Count
+			hasMore = true
+		}
+		if start < end {
+			batch = messages[start:end]
+		}
+	} else {
+		end := len(messages)
+		if idx, err := strconv.Atoi(string(params.Cursor)); err == nil && idx >= 0 && idx <= end {
+			end = idx
+		}
+		start := 0
+		if params.Count > 0 && end-params.Count > 0 {
+			start = end - params.Count
+		}
+		if start < end {
+			batch = messages[start:end]
+		}
+		hasMore = start > 0
+		if hasMore {
+			cursor = networkid.PaginationCursor(strconv.Itoa(start))
+		}
+	}
+
+	humanSender := bridgev2.EventSender{IsFromMe: true, Sender: humanUserID(cc.UserLogin.ID), SenderLogin: cc.UserLogin.ID}
+	out := make([]*bridgev2.BackfillMessage, 0, len(batch))
+	for _, msg := range batch {
+		sender := cc.senderForPortal()
+		if msg.fromMe {
+			sender = humanSender
+		}
+		out = append(out, &bridgev2.BackfillMessage{
+			ConvertedMessage: &bridgev2.ConvertedMessage{Parts: []*bridgev2.ConvertedMessagePart{msg.part}},
+			Sender:           sender,
+			ID:               msg.id,
+			TxnID:            networkid.TransactionID(msg.id),
+			Timestamp:        msg.when,
+			StreamOrder:      msg.when.UnixMilli(),
+		})
+	}
+	return &bridgev2.FetchMessagesResponse{
+		Messages:                out,
+		Cursor:                  cursor,
+		HasMore:                 hasMore,
+		Forward:                 params.Forward,
+		AggressiveDeduplication: true,
+		ApproxTotalCount:        len(messages),
+	}, nil
+}
+
+// buildCodexThreadBackfill renders each turn as the user's prompt followed by
+// the assistant's reply. Codex doesn't timestamp turns, so they are spread
+// evenly between the thread's creation and last update.
+func buildCodexThreadBackfill(thread *codexThread, turns []codexTurn) []codexBackfillMessage {
+	start, end := thread.createdTime(), thread.updatedTime()
+	if start.IsZero() {
+		end = time.Now()
+		start = end.Add(-time.Duration(len(turns)) * time.Minute)
+	}
+	step := time.Duration(0)
+	if len(turns) > 1 && end.After(start) {
+		step = end.Sub(start) / time.Duration(len(turns)-1)
+	}
+	var out []codexBackfillMessage
+	for i, turn := range turns {
+		turnID := strings.TrimSpace(turn.ID)
+		if turnID == "" {
+			turnID = strconv.Itoa(i)
+		}
+		when := start.Add(step * time.Duration(i))
+		// Keep the reply after the prompt even when turns share a timestamp.
+		when = when.Add(time.Duration(i*2) * time.Millisecond)
+		idPrefix := fmt.Sprintf("codex:%s:%s", thread.ID, turnID)
+		userText, snapshot := buildCodexTurnCanonical(turnID, turn)
+		if userText != "" {
+			out = append(out, codexBackfillMessage{
+				id:     networkid.MessageID(idPrefix + ":user"),
+				fromMe: true,
+				when:   when,
+				part: &bridgev2.ConvertedMessagePart{
+					ID:         networkid.PartID("0"),
+					Type:       event.EventMessage,
+					Content:    &event.MessageEventContent{MsgType: event.MsgText, Body: userText},
+					DBMetadata: &MessageMetadata{BaseMessageMetadata: bridgeadapter.BaseMessageMetadata{Role: "user", Body: userText}},
+				},
+			})
+		}
+		if snapshot == nil {
+			continue
+		}
+		out = append(out, codexBackfillMessage{
+			id:   networkid.MessageID(idPrefix + ":assistant"),
+			when: when.Add(time.Millisecond),
+			part: &bridgev2.ConvertedMessagePart{
+				ID:      networkid.PartID("0"),
+				Type:    event.EventMessage,
+				Content: &event.MessageEventContent{MsgType: event.MsgText, Body: snapshot.Body},
+				Extra: map[string]any{
+					matrixevents.BeeperAIKey: snapshot.CanonicalUIMessage,
+					"m.mentions":             map[string]any{},
+				},
+				DBMetadata: &MessageMetadata{BaseMessageMetadata: *snapshot},
+			},
+		})
+	}
+	return out
+}
+
+// buildCodexTurnCanonical replays a completed turn's items through the
+// streamui recorder, producing the same canonical UI message a live turn
+// would have stored. It returns the user's prompt text separately.
+func buildCodexTurnCanonical(turnID string, turn codexTurn) (string, *bridgeadapter.BaseMessageMetadata) {
+	state := streamui.UIState{TurnID: turnID}
+	streamui.ApplyChunk(&state, map[string]any{
+		"type":            "start",
+		"messageId":       turnID,
+		"messageMetadata": msgconv.BuildUIMessageMetadata(msgconv.UIMessageMetadataParams{TurnID: turnID}),
+	})
+
+	var userText, visible, reasoning strings.Builder
+	var toolCalls []ToolCallMetadata
+	hasAssistantParts := false
+	for i, raw := range turn.Items {
+		var probe struct {
+			Type string `json:"type"`
+			ID   string `json:"id"`
+		}
+		_ = json.Unmarshal(raw, &probe)
+		itemID := strings.TrimSpace(probe.ID)
+		if itemID == "" {
+			itemID = fmt.Sprintf("%s-item-%d", turnID, i)
+		}
+		switch probe.Type {
+		case "userMessage":
+			appendBackfillParagraph(&userText, codexUserMessageText(raw))
+		case "agentMessage":
+			var it struct {
+				Text string `json:"text"`
+			}
+			_ = json.Unmarshal(raw, &it)
+			if strings.TrimSpace(it.Text) == "" {
+				continue
+			}
+			streamui.ApplyChunk(&state, map[string]any{"type": "text-start", "id": itemID})
+			streamui.ApplyChunk(&state, map[string]any{"type": "text-delta", "id": itemID, "delta": it.Text})
+			streamui.ApplyChunk(&state, map[string]any{"type": "text-end", "id": itemID})
+			appendBackfillParagraph(&visible, it.Text)
+			hasAssistantParts = true
+		case "reasoning":
+			var it struct {
+				Summary []string `json:"summary"`
+				Content []string `json:"content"`
+			}
+			_ = json.Unmarshal(raw, &it)
+			text := strings.TrimSpace(strings.Join(it.Summary, "\n"))
+			if text == "" {
+				text = strings.TrimSpace(strings.Join(it.Content, "\n"))
+			}
+			if text == "" {
+				continue
+			}
+			streamui.ApplyChunk(&state, map[string]any{"type": "reasoning-start", "id": itemID})
+			streamui.ApplyChunk(&state, map[string]any{"type": "reasoning-delta", "id": itemID, "delta": text})
+			streamui.ApplyChunk(&state, map[string]any{"type": "reasoning-end", "id": itemID})
+			appendBackfillParagraph(&reasoning, text)
+			hasAssistantParts = true
+		default:
+			toolName, ok := codexBackfillToolName(probe.Type, raw)
+			if !ok {
+				continue
+			}
+			var it map[string]any
+			_ = json.Unmarshal(raw, &it)
+			streamui.ApplyChunk(&state, map[string]any{
+				"type":             "tool-input-available",
+				"toolCallId":       itemID,
+				"toolName":         toolName,
+				"title":            streamui.ToolDisplayTitle(toolName),
+				"input":            it,
+				"providerExecuted": true,
+			})
+			tc := newProviderToolCall(itemID, toolName, it)
+			status, _ := it["status"].(string)
+			switch strings.TrimSpace(status) {
+			case "declined":
+				streamui.ApplyChunk(&state, map[string]any{"type": "tool-output-denied", "toolCallId": itemID})
+				tc.ResultStatus = string(matrixevents.ResultStatusDenied)
+				tc.ErrorMessage = "Denied by user"
+			case "failed":
+				errText := "tool failed"
+				if errObj, ok := it["error"].(map[string]any); ok {
+					if msg, ok := errObj["message"].(string); ok && strings.TrimSpace(msg) != "" {
+						errText = strings.TrimSpace(msg)
+					}
+				}
+				streamui.ApplyChunk(&state, map[string]any{"type": "tool-output-error", "toolCallId": itemID, "errorText": errText, "providerExecuted": true})
+				tc.ResultStatus = string(matrixevents.ResultStatusError)
+				tc.ErrorMessage = errText
+			default:
+				streamui.ApplyChunk(&state, map[string]any{"type": "tool-output-available", "toolCallId": itemID, "output": it, "providerExecuted": true})
+			}
+			toolCalls = append(toolCalls, tc)
+			hasAssistantParts = true
+		}
+	}
+	if !hasAssistantParts {
+		return strings.TrimSpace(userText.String()), nil
+	}
+
+	finishReason := strings.TrimSpace(turn.Status)
+	if finishReason == "" {
+		finishReason = "completed"
+	}
+	if turn.Error != nil && strings.TrimSpace(turn.Error.Message) != "" {
+		streamui.ApplyChunk(&state, map[string]any{"type": "error", "errorText": strings.TrimSpace(turn.Error.Message)})
+	}
+	streamui.ApplyChunk(&state, map[string]any{
+		"type":            "finish",
+		"finishReason":    finishReason,
+		"messageMetadata": msgconv.BuildUIMessageMetadata(msgconv.UIMessageMetadataParams{TurnID: turnID, FinishReason: finishReason}),
+	})
+
+	body := strings.TrimSpace(visible.String())
+	if body == "" {
+		body = "..."
+	}
+	return strings.TrimSpace(userText.String()), &bridgeadapter.BaseMessageMetadata{
+		Role:               "assistant",
+		Body:               body,
+		FinishReason:       finishReason,
+		TurnID:             turnID,
+		CanonicalSchema:    "ai-sdk-ui-message-v1",
+		CanonicalUIMessage: streamui.SnapshotCanonicalUIMessage(&state),
+		ThinkingContent:    strings.TrimSpace(reasoning.String()),
+		ToolCalls:          toolCalls,
+	}
+}
+
+// codexBackfillToolName maps tool-like items to the tool names used while
+// streaming, see handleItemStarted.
+func codexBackfillToolName(itemType string, raw json.RawMessage) (string, bool) {
+	switch itemType {
+	case "commandExecution", "fileChange", "collabToolCall", "webSearch", "imageView", "plan", "contextCompaction":
+		return itemType, true
+	case "mcpToolCall":
+		var it struct {
+			Tool string `json:"tool"`
+		}
+		_ = json.Unmarshal(raw, &it)
+		if strings.TrimSpace(it.Tool) == "" {
+			return "mcpToolCall", true
+		}
+		return strings.TrimSpace(it.Tool), true
+	case "enteredReviewMode", "exitedReviewMode":
+		return "review", true
+	}
+	return "", false
+}
+
+func codexUserMessageText(raw json.RawMessage) string {
+	var it struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+			Path string `json:"path"`
+		} `json:"content"`
+	}
+	_ = json.Unmarshal(raw, &it)
+	var text strings.Builder
+	for _, input := range it.Content {
+		switch input.Type {
+		case "text":
+			appendBackfillParagraph(&text, input.Text)
+		case "image", "localImage":
+			label := "[image]"
+			if path := strings.TrimSpace(input.Path); path != "" {
+				label = fmt.Sprintf("[image: %s]", path)
+			}
+			appendBackfillParagraph(&text, label)
+		}
+	}
+	return text.String()
+}
+
+func appendBackfillParagraph(sb *strings.Builder, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString(text)
+}
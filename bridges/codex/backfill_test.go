@@ -0,0 +1,81 @@
+package codex
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildCodexThreadBackfill(t *testing.T) {
+	thread := &codexThread{ID: "thr_1", CreatedAt: 1_700_000_000, UpdatedAt: 1_700_000_600}
+	turns := []codexTurn{{
+		ID:     "turn_1",
+		Status: "completed",
+		Items: []json.RawMessage{
+			json.RawMessage(`{"type":"userMessage","id":"u1","content":[{"type":"text","text":"fix the tests"}]}`),
+			json.RawMessage(`{"type":"reasoning","id":"r1","summary":["looking at failures"]}`),
+			json.RawMessage(`{"type":"commandExecution","id":"c1","command":"go test ./...","status":"completed"}`),
+			json.RawMessage(`{"type":"mcpToolCall","id":"m1","tool":"search","status":"failed","error":{"message":"boom"}}`),
+			json.RawMessage(`{"type":"agentMessage","id":"a1","text":"All green now."}`),
+		},
+	}, {
+		ID:    "turn_2",
+		Items: []json.RawMessage{json.RawMessage(`{"type":"userMessage","id":"u2","content":[{"type":"localImage","path":"/tmp/x.png"}]}`)},
+	}}
+
+	messages := buildCodexThreadBackfill(thread, turns)
+	if len(messages) != 3 {
+		t.Fatalf("expected user, assistant, user messages, got %d", len(messages))
+	}
+	if messages[0].id != "codex:thr_1:turn_1:user" || !messages[0].fromMe || messages[0].part.Content.Body != "fix the tests" {
+		t.Fatalf("unexpected user message %#v", messages[0])
+	}
+	if !messages[1].when.After(messages[0].when) || !messages[2].when.After(messages[1].when) {
+		t.Fatal("expected backfilled messages in order")
+	}
+
+	assistant := messages[1]
+	meta, ok := assistant.part.DBMetadata.(*MessageMetadata)
+	if !ok || meta.Role != "assistant" || meta.Body != "All green now." || meta.ThinkingContent != "looking at failures" {
+		t.Fatalf("unexpected assistant metadata %#v", assistant.part.DBMetadata)
+	}
+	if len(meta.ToolCalls) != 2 || meta.ToolCalls[1].ToolName != "search" || meta.ToolCalls[1].ErrorMessage != "boom" {
+		t.Fatalf("unexpected tool calls %#v", meta.ToolCalls)
+	}
+	parts, _ := meta.CanonicalUIMessage["parts"].([]any)
+	if len(parts) != 4 {
+		t.Fatalf("expected reasoning, two tools and text parts, got %#v", meta.CanonicalUIMessage)
+	}
+	if messages[2].part.Content.Body != "[image: /tmp/x.png]" {
+		t.Fatalf("unexpected image prompt %q", messages[2].part.Content.Body)
+	}
+}
+
+func TestParseCodexThreadIdentifier(t *testing.T) {
+	if id, ok := parseCodexThreadIdentifier(" codex:thread:thr_1 "); !ok || id != "thr_1" {
+		t.Fatalf("expected thread id, got %q (%v)", id, ok)
+	}
+	if _, ok := parseCodexThreadIdentifier("codex"); ok {
+		t.Fatal("expected plain codex identifier not to be a thread")
+	}
+	if _, ok := parseCodexThreadIdentifier("codex:thread:"); ok {
+		t.Fatal("expected empty thread id to be rejected")
+	}
+}
+
+func TestCodexThreadContactIsDistinctPerThread(t *testing.T) {
+	first := codexThreadContact("thr_1", "Fix the tests")
+	second := codexThreadContact("thr_2", "Add a README")
+	if first.UserID == second.UserID || first.UserID == codexGhostID {
+		t.Fatalf("expected distinct per-thread user IDs, got %q and %q", first.UserID, second.UserID)
+	}
+	if first.Ghost != nil {
+		t.Fatal("expected thread contacts not to carry the shared Codex ghost")
+	}
+	if first.UserInfo == nil || first.UserInfo.Name == nil || *first.UserInfo.Name != "Fix the tests" {
+		t.Fatalf("expected the thread title as contact name, got %#v", first.UserInfo)
+	}
+	threadID, ok := parseCodexThreadIdentifier(string(first.UserID))
+	if !ok || threadID != "thr_1" {
+		t.Fatalf("expected the contact ID to resolve back to the thread, got %q (%v)", threadID, ok)
+	}
+}
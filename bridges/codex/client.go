@@ -59,10 +59,11 @@ type codexActiveTurn struct {
 }
 
 type codexPendingMessage struct {
-	event  *event.Event
-	portal *bridgev2.Portal
-	meta   *PortalMetadata
-	body   string
+	event       *event.Event
+	portal      *bridgev2.Portal
+	meta        *PortalMetadata
+	body        string
+	attachments []codexAttachment
 }
 
 type codexPendingQueue []*codexPendingMessage
@@ -172,6 +173,8 @@ func (cc *CodexClient) Connect(ctx context.Context) {
 		return
 	}
 
+	cc.approvalFlow.RestorePending(cc.backgroundContext(ctx))
+
 	// Best-effort account/read.
 	readCtx, cancel := context.WithTimeout(cc.backgroundContext(ctx), 10*time.Second)
 	defer cancel()
@@ -368,10 +371,28 @@ func (cc *CodexClient) ResolveIdentifier(ctx context.Context, identifier string,
 	if cc == nil || cc.UserLogin == nil || cc.UserLogin.Bridge == nil {
 		return nil, errors.New("login unavailable")
 	}
-	if !isCodexIdentifier(identifier) {
+	threadID, isThread := parseCodexThreadIdentifier(identifier)
+	if !isThread && !isCodexIdentifier(identifier) {
 		return nil, fmt.Errorf("unknown identifier: %s", identifier)
 	}
 
+	if isThread {
+		title := "Codex thread"
+		var chat *bridgev2.CreateChatResponse
+		if createChat {
+			var err error
+			if chat, err = cc.importCodexThread(ctx, threadID); err != nil {
+				return nil, fmt.Errorf("failed to import Codex thread: %w", err)
+			}
+			if chat.Portal != nil && chat.Portal.Name != "" {
+				title = chat.Portal.Name
+			}
+		}
+		resp := codexThreadContact(threadID, title)
+		resp.Chat = chat
+		return resp, nil
+	}
+
 	ghost, err := cc.UserLogin.Bridge.GetGhostByID(ctx, codexGhostID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Codex ghost: %w", err)
@@ -410,7 +431,14 @@ func (cc *CodexClient) GetContactList(ctx context.Context) ([]*bridgev2.ResolveI
 	if err != nil {
 		return nil, err
 	}
-	return []*bridgev2.ResolveIdentifierResponse{resp}, nil
+	contacts := []*bridgev2.ResolveIdentifierResponse{resp}
+	threads, err := cc.codexThreadContacts(ctx)
+	if err != nil {
+		// The default chat stays usable even if Codex can't list its threads.
+		cc.loggerForContext(ctx).Warn().Err(err).Msg("Failed to list Codex threads")
+		return contacts, nil
+	}
+	return append(contacts, threads...), nil
 }
 
 func codexPortalTitle(portal *bridgev2.Portal) string {
@@ -444,9 +472,10 @@ func (cc *CodexClient) HandleMatrixMessage(ctx context.Context, msg *bridgev2.Ma
 		return &bridgev2.MatrixMessageResponse{Pending: false}, nil
 	}
 
-	// Only text messages.
-	switch msg.Content.MsgType {
-	case event.MsgText, event.MsgNotice, event.MsgEmote:
+	isAttachment := isCodexAttachmentMsgType(msg.Content.MsgType)
+	switch {
+	case msg.Content.MsgType == event.MsgText, msg.Content.MsgType == event.MsgNotice, msg.Content.MsgType == event.MsgEmote:
+	case isAttachment:
 	default:
 		return nil, bridgeadapter.UnsupportedMessageStatus(fmt.Errorf("%s messages are not supported", msg.Content.MsgType))
 	}
@@ -454,10 +483,16 @@ func (cc *CodexClient) HandleMatrixMessage(ctx context.Context, msg *bridgev2.Ma
 		return &bridgev2.MatrixMessageResponse{Pending: false}, nil
 	}
 	body := strings.TrimSpace(msg.Content.Body)
-	if body == "" {
+	if isAttachment {
+		body = codexAttachmentCaption(msg.Content)
+	} else if body == "" {
 		return &bridgev2.MatrixMessageResponse{Pending: false}, nil
 	}
 
+	if meta.AwaitingCwdSetup && isAttachment {
+		cc.sendSystemNotice(ctx, portal, "Send the working directory path for this room first.")
+		return &bridgev2.MatrixMessageResponse{Pending: false}, nil
+	}
 	if meta.AwaitingCwdSetup {
 		path, err := resolveCodexWorkingDirectory(strings.TrimSpace(msg.Content.Body))
 		if err != nil {
@@ -501,6 +536,17 @@ func (cc *CodexClient) HandleMatrixMessage(ctx context.Context, msg *bridgev2.Ma
 		return nil, errors.New("portal has no room id")
 	}
 
+	var attachments []codexAttachment
+	storedBody := body
+	if isAttachment {
+		attachment, err := cc.saveCodexAttachment(ctx, portal, msg.Event, msg.Content)
+		if err != nil {
+			return nil, messageSendStatusError(err, "Failed to save attachment: "+err.Error(), "")
+		}
+		attachments = append(attachments, *attachment)
+		storedBody = codexAttachmentSummary(body, attachment)
+	}
+
 	// Save user message immediately; we return Pending=true.
 	userMsg := &database.Message{
 		ID:        bridgeadapter.MatrixMessageID(msg.Event.ID),
@@ -509,7 +555,7 @@ func (cc *CodexClient) HandleMatrixMessage(ctx context.Context, msg *bridgev2.Ma
 		SenderID:  humanUserID(cc.UserLogin.ID),
 		Timestamp: bridgeadapter.MatrixEventTimestamp(msg.Event),
 		Metadata: &MessageMetadata{
-			BaseMessageMetadata: bridgeadapter.BaseMessageMetadata{Role: "user", Body: body},
+			BaseMessageMetadata: bridgeadapter.BaseMessageMetadata{Role: "user", Body: storedBody},
 		},
 	}
 	if msg.InputTransactionID != "" {
@@ -525,10 +571,11 @@ func (cc *CodexClient) HandleMatrixMessage(ctx context.Context, msg *bridgev2.Ma
 	if !cc.acquireRoomIfQueueEmpty(roomID) {
 		cc.sendPendingStatus(ctx, portal, msg.Event, "Queued — waiting for current turn to finish...")
 		cc.queuePendingCodex(roomID, &codexPendingMessage{
-			event:  msg.Event,
-			portal: portal,
-			meta:   meta,
-			body:   body,
+			event:       msg.Event,
+			portal:      portal,
+			meta:        meta,
+			body:        body,
+			attachments: attachments,
 		})
 		return &bridgev2.MatrixMessageResponse{
 			DB:      userMsg,
@@ -541,7 +588,7 @@ func (cc *CodexClient) HandleMatrixMessage(ctx context.Context, msg *bridgev2.Ma
 	go func() {
 		func() {
 			defer cc.releaseRoom(roomID)
-			cc.runTurn(cc.backgroundContext(ctx), portal, meta, msg.Event, body)
+			cc.runTurn(cc.backgroundContext(ctx), portal, meta, msg.Event, body, attachments)
 		}()
 		cc.processPendingCodex(roomID)
 	}()
@@ -552,14 +599,30 @@ func (cc *CodexClient) HandleMatrixMessage(ctx context.Context, msg *bridgev2.Ma
 	}, nil
 }
 
-func (cc *CodexClient) runTurn(ctx context.Context, portal *bridgev2.Portal, meta *PortalMetadata, sourceEvent *event.Event, body string) {
+func (cc *CodexClient) runTurn(ctx context.Context, portal *bridgev2.Portal, meta *PortalMetadata, sourceEvent *event.Event, body string, attachments []codexAttachment) {
+	params := map[string]any{
+		"threadId":       strings.TrimSpace(meta.CodexThreadID),
+		"input":          buildCodexTurnInput(body, attachments),
+		"cwd":            strings.TrimSpace(meta.CodexCwd),
+		"model":          cc.codexModel(meta),
+		"approvalPolicy": codexApprovalPolicy(meta),
+		"sandboxPolicy":  cc.buildSandboxPolicy(meta),
+	}
+	if effort := strings.TrimSpace(meta.CodexReasoningEffort); effort != "" {
+		params["effort"] = effort
+	}
+	cc.streamTurn(ctx, portal, meta, sourceEvent, "turn/start", params)
+}
+
+// streamTurn starts a turn with the given method (turn/start or review/start)
+// and streams its notifications into the room until it completes.
+func (cc *CodexClient) streamTurn(ctx context.Context, portal *bridgev2.Portal, meta *PortalMetadata, sourceEvent *event.Event, method string, params map[string]any) {
 	log := cc.loggerForContext(ctx)
 	state := newStreamingState(ctx, meta, sourceEvent.ID, sourceEvent.Sender.String(), portal.MXID)
 	state.startedAtMs = time.Now().UnixMilli()
 
-	model := cc.connector.Config.Codex.DefaultModel
+	model := cc.codexModel(meta)
 	threadID := strings.TrimSpace(meta.CodexThreadID)
-	cwd := strings.TrimSpace(meta.CodexCwd)
 
 	// Post placeholder timeline message immediately to get an event id for streaming.
 	state.initialEventID = cc.sendInitialStreamMessage(ctx, portal, state, "...", state.turnID)
@@ -570,11 +633,6 @@ func (cc *CodexClient) runTurn(ctx context.Context, portal *bridgev2.Portal, met
 	cc.emitUIStart(ctx, portal, state, model)
 	cc.uiEmitter(state).EmitUIStepStart(ctx, portal)
 
-	approvalPolicy := "untrusted"
-	if lvl, _ := stringutil.NormalizeElevatedLevel(meta.ElevatedLevel); lvl == "full" {
-		approvalPolicy = "never"
-	}
-
 	// Start turn.
 	var turnStart struct {
 		Turn struct {
@@ -584,15 +642,7 @@ func (cc *CodexClient) runTurn(ctx context.Context, portal *bridgev2.Portal, met
 	}
 	turnStartCtx, cancelTurnStart := context.WithTimeout(ctx, 60*time.Second)
 	defer cancelTurnStart()
-	err := cc.rpc.Call(turnStartCtx, "turn/start", map[string]any{
-		"threadId": threadID,
-		"input": []map[string]any{
-			{"type": "text", "text": body},
-		},
-		"cwd":            cwd,
-		"approvalPolicy": approvalPolicy,
-		"sandboxPolicy":  cc.buildSandboxPolicy(cwd),
-	}, &turnStart)
+	err := cc.rpc.Call(turnStartCtx, method, params, &turnStart)
 	if err != nil {
 		cc.uiEmitter(state).EmitUIError(ctx, portal, err.Error())
 		cc.emitUIFinish(ctx, portal, state, model, "failed")
@@ -652,8 +702,10 @@ done:
 	log.Debug().Str("status", finishStatus).Str("thread", threadID).Str("turn", turnID).Msg("Codex turn finished")
 	state.completedAtMs = time.Now().UnixMilli()
 	// If we observed turn-level diff updates, finalize them as a dedicated tool output.
-	if diff := strings.TrimSpace(state.codexLatestDiff); diff != "" {
+	diff := strings.TrimSpace(state.codexLatestDiff)
+	if diff != "" {
 		diffToolID := fmt.Sprintf("diff-%s", turnID)
+		stats := summarizeCodexDiff(diff)
 		cc.ensureUIToolInputStart(ctx, portal, state, diffToolID, "diff", true, map[string]any{"turnId": turnID})
 		cc.uiEmitter(state).EmitUIToolOutputAvailable(ctx, portal, diffToolID, diff, true, false)
 		state.toolCalls = append(state.toolCalls, ToolCallMetadata{
@@ -661,12 +713,20 @@ done:
 			ToolName:      "diff",
 			ToolType:      string(matrixevents.ToolTypeProvider),
 			Input:         map[string]any{"turnId": turnID},
-			Output:        map[string]any{"diff": diff},
+			Output:        map[string]any{"diff": diff, "files": codexDiffStatsOutput(stats)},
 			Status:        string(matrixevents.ToolStatusCompleted),
 			ResultStatus:  string(matrixevents.ResultStatusSuccess),
 			StartedAtMs:   state.startedAtMs,
 			CompletedAtMs: state.completedAtMs,
 		})
+		if summary := formatCodexDiffSummary(stats); summary != "" {
+			if state.accumulated.Len() > 0 {
+				summary = "\n\n" + summary
+			}
+			state.accumulated.WriteString(summary)
+			state.visibleAccumulated.WriteString(summary)
+			cc.uiEmitter(state).EmitUITextDelta(ctx, portal, summary)
+		}
 	}
 	if completedErr != "" {
 		cc.uiEmitter(state).EmitUIError(ctx, portal, completedErr)
@@ -674,6 +734,11 @@ done:
 	cc.emitUIFinish(ctx, portal, state, model, finishStatus)
 	cc.sendFinalAssistantTurn(ctx, portal, state, model, finishStatus)
 	cc.saveAssistantMessage(ctx, portal, state, model, finishStatus)
+	if diff != "" {
+		if err := cc.publishCodexTurnDiff(ctx, portal, meta, turnID, diff); err != nil {
+			log.Warn().Err(err).Str("turn", turnID).Msg("Failed to publish Codex turn diff")
+		}
+	}
 }
 
 func (cc *CodexClient) appendCodexToolOutput(state *streamingState, toolCallID, delta string) string {
@@ -1557,14 +1622,6 @@ func resolveCodexWorkingDirectory(raw string) (string, error) {
 	return filepath.Clean(path), nil
 }
 
-func (cc *CodexClient) buildSandboxPolicy(cwd string) map[string]any {
-	return map[string]any{
-		"type":          "workspaceWrite",
-		"writableRoots": []string{cwd},
-		"networkAccess": cc.codexNetworkAccess(),
-	}
-}
-
 func (cc *CodexClient) ensureCodexThread(ctx context.Context, portal *bridgev2.Portal, meta *PortalMetadata) error {
 	if meta == nil || portal == nil {
 		return errors.New("missing portal/meta")
@@ -1584,7 +1641,6 @@ func (cc *CodexClient) ensureCodexThread(ctx context.Context, portal *bridgev2.P
 	if err := cc.ensureRPC(ctx); err != nil {
 		return err
 	}
-	model := cc.connector.Config.Codex.DefaultModel
 	var resp struct {
 		Thread struct {
 			ID string `json:"id"`
@@ -1593,10 +1649,10 @@ func (cc *CodexClient) ensureCodexThread(ctx context.Context, portal *bridgev2.P
 	callCtx, cancelCall := context.WithTimeout(ctx, 60*time.Second)
 	defer cancelCall()
 	err := cc.rpc.Call(callCtx, "thread/start", map[string]any{
-		"model":          model,
+		"model":          cc.codexModel(meta),
 		"cwd":            meta.CodexCwd,
-		"approvalPolicy": "untrusted",
-		"sandboxPolicy":  cc.buildSandboxPolicy(meta.CodexCwd),
+		"approvalPolicy": codexApprovalPolicy(meta),
+		"sandboxPolicy":  cc.buildSandboxPolicy(meta),
 	}, &resp)
 	if err != nil {
 		return err
@@ -1640,10 +1696,10 @@ func (cc *CodexClient) ensureCodexThreadLoaded(ctx context.Context, portal *brid
 	defer cancelCall()
 	err := cc.rpc.Call(callCtx, "thread/resume", map[string]any{
 		"threadId":       threadID,
-		"model":          cc.connector.Config.Codex.DefaultModel,
+		"model":          cc.codexModel(meta),
 		"cwd":            meta.CodexCwd,
-		"approvalPolicy": "untrusted",
-		"sandboxPolicy":  cc.buildSandboxPolicy(meta.CodexCwd),
+		"approvalPolicy": codexApprovalPolicy(meta),
+		"sandboxPolicy":  cc.buildSandboxPolicy(meta),
 	}, &resp)
 	if err != nil {
 		// If the stored thread can't be resumed (missing/corrupt), fall back to a fresh thread.
@@ -1693,17 +1749,22 @@ func (cc *CodexClient) HandleMatrixDeleteChat(ctx context.Context, msg *bridgev2
 		cancel()
 	}
 
-	if tid != "" {
+	// Imported threads belong to the user's Codex home and project, so only
+	// forget them here.
+	if tid != "" && !meta.CodexThreadImported {
 		callCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 		_ = cc.rpc.Call(callCtx, "thread/archive", map[string]any{"threadId": tid}, &struct{}{})
 		cancel()
+	}
+	if tid != "" {
 		cc.loadedMu.Lock()
 		delete(cc.loadedThreads, tid)
 		cc.loadedMu.Unlock()
 	}
-	if cwd := strings.TrimSpace(meta.CodexCwd); cwd != "" {
+	if cwd := strings.TrimSpace(meta.CodexCwd); cwd != "" && !meta.CodexThreadImported {
 		_ = os.RemoveAll(cwd)
 	}
+	_ = os.RemoveAll(cc.codexAttachmentDir(msg.Portal))
 	meta.CodexThreadID = ""
 	meta.CodexCwd = ""
 	_ = msg.Portal.Save(ctx)
@@ -1846,7 +1907,7 @@ func (cc *CodexClient) processPendingCodex(roomID id.RoomID) {
 	go func() {
 		func() {
 			defer cc.releaseRoom(roomID)
-			cc.runTurn(ctx, pm.portal, meta, pm.event, pm.body)
+			cc.runTurn(ctx, pm.portal, meta, pm.event, pm.body, pm.attachments)
 		}()
 		cc.processPendingCodex(roomID)
 	}()
@@ -0,0 +1,290 @@
+package codex
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"maunium.net/go/mautrix/bridgev2/commands"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/pkg/connector/commandregistry"
+)
+
+// HelpSectionCodex is the help section for Codex room commands.
+var HelpSectionCodex = commands.HelpSection{
+	Name:  "Codex",
+	Order: 30,
+}
+
+var codexCommandRegistry = commandregistry.NewRegistry()
+
+func registerCodexCommand(def commandregistry.Definition) *commands.FullHandler {
+	return commandregistry.RegisterForPortalLogin[*CodexClient](codexCommandRegistry, HelpSectionCodex, def)
+}
+
+func requireCodexRoom(ce *commands.Event) (*CodexClient, *PortalMetadata, bool) {
+	client, _ := commandregistry.PortalLoginClient[*CodexClient](ce)
+	meta := portalMeta(ce.Portal)
+	if client == nil || meta == nil || !meta.IsCodexRoom {
+		commandregistry.ReplyFailure(ce, "That command only works in Codex rooms.", event.MessageStatusUnsupported)
+		return nil, nil, false
+	}
+	return client, meta, true
+}
+
+// updateCodexSetting applies a room setting command: without arguments it
+// shows the current value, "default" clears it, and anything else is
+// normalized and saved.
+func updateCodexSetting(ce *commands.Event, label string, current string, normalize func(string) (string, bool), set func(*PortalMetadata, string), usage string) {
+	_, meta, ok := requireCodexRoom(ce)
+	if !ok {
+		return
+	}
+	if len(ce.Args) == 0 {
+		ce.Reply("%s: `%s`\n\nUsage: `%s`", label, current, usage)
+		return
+	}
+	value := ""
+	if raw := strings.TrimSpace(ce.RawArgs); !strings.EqualFold(raw, "default") {
+		normalized, valid := normalize(raw)
+		if !valid {
+			commandregistry.ReplyFailure(ce, fmt.Sprintf("Unknown %s: %s\n\nUsage: `%s`", strings.ToLower(label), raw, usage), event.MessageStatusUnsupported)
+			return
+		}
+		value = normalized
+	}
+	set(meta, value)
+	if err := ce.Portal.Save(ce.Ctx); err != nil {
+		commandregistry.ReplyFailure(ce, "Failed to save room settings: "+err.Error(), event.MessageStatusGenericError)
+		return
+	}
+	if value == "" {
+		ce.Reply("%s reset to the default. It applies from the next turn.", label)
+		return
+	}
+	ce.Reply("%s set to `%s`. It applies from the next turn.", label, value)
+}
+
+var _ = registerCodexCommand(commandregistry.Definition{
+	Name:        "model",
+	Description: "Show or change the model of this Codex thread",
+	Args:        "[model|default]",
+	Handler: func(ce *commands.Event) {
+		client, meta, ok := requireCodexRoom(ce)
+		if !ok {
+			return
+		}
+		models, listErr := client.listCodexModels(ce.Ctx)
+		if len(ce.Args) == 0 {
+			reply := fmt.Sprintf("Model: `%s`", client.codexModel(meta))
+			if len(models) > 0 {
+				reply += "\n\nAvailable: " + strings.Join(models, ", ")
+			}
+			ce.Reply("%s", reply)
+			return
+		}
+		updateCodexSetting(ce, "Model", client.codexModel(meta), func(raw string) (string, bool) {
+			// Only validate when Codex could tell us what's available.
+			return raw, listErr != nil || len(models) == 0 || slices.Contains(models, raw)
+		}, func(meta *PortalMetadata, value string) {
+			meta.CodexModel = value
+		}, "!ai model [model|default]")
+	},
+})
+
+var _ = registerCodexCommand(commandregistry.Definition{
+	Name:        "effort",
+	Description: "Show or change the reasoning effort of this Codex thread",
+	Args:        "[minimal|low|medium|high|xhigh|default]",
+	Handler: func(ce *commands.Event) {
+		meta := portalMeta(ce.Portal)
+		current := "default"
+		if meta != nil && meta.CodexReasoningEffort != "" {
+			current = meta.CodexReasoningEffort
+		}
+		updateCodexSetting(ce, "Reasoning effort", current, normalizeCodexReasoningEffort, func(meta *PortalMetadata, value string) {
+			meta.CodexReasoningEffort = value
+		}, "!ai effort [minimal|low|medium|high|xhigh|default]")
+	},
+})
+
+var _ = registerCodexCommand(commandregistry.Definition{
+	Name:        "sandbox",
+	Description: "Show or change what Codex may touch on disk and network",
+	Args:        "[read-only|workspace-write|danger-full-access|default]",
+	Handler: func(ce *commands.Event) {
+		updateCodexSetting(ce, "Sandbox", codexSandboxMode(portalMeta(ce.Portal)), normalizeCodexSandboxMode, func(meta *PortalMetadata, value string) {
+			meta.CodexSandboxMode = value
+		}, "!ai sandbox [read-only|workspace-write|danger-full-access|default]")
+	},
+})
+
+var _ = registerCodexCommand(commandregistry.Definition{
+	Name:        "approvals",
+	Description: "Show or change when Codex asks before running commands",
+	Args:        "[untrusted|on-failure|on-request|never|default]",
+	Handler: func(ce *commands.Event) {
+		updateCodexSetting(ce, "Approval policy", codexApprovalPolicy(portalMeta(ce.Portal)), normalizeCodexApprovalPolicy, func(meta *PortalMetadata, value string) {
+			meta.CodexApprovalPolicy = value
+		}, "!ai approvals [untrusted|on-failure|on-request|never|default]")
+	},
+})
+
+var _ = registerCodexCommand(commandregistry.Definition{
+	Name:        "review",
+	Description: "Ask Codex to review changes in the working directory",
+	Args:        "[base <branch>|commit <sha>|<instructions>]",
+	Handler:     fnCodexReview,
+})
+
+func fnCodexReview(ce *commands.Event) {
+	client, meta, ok := requireCodexRoom(ce)
+	if !ok {
+		return
+	}
+	target, err := parseCodexReviewTarget(ce.Args)
+	if err != nil {
+		commandregistry.ReplyFailure(ce, err.Error()+"\n\nUsage: `!ai review [base <branch>|commit <sha>|<instructions>]`", event.MessageStatusUnsupported)
+		return
+	}
+	if meta.AwaitingCwdSetup || strings.TrimSpace(meta.CodexCwd) == "" {
+		commandregistry.ReplyFailure(ce, "Set a working directory for this room first.", event.MessageStatusUnsupported)
+		return
+	}
+	ctx := client.backgroundContext(ce.Ctx)
+	if err := client.ensureRPC(ctx); err != nil {
+		commandregistry.ReplyFailure(ce, "Codex isn't available. Sign in again.", event.MessageStatusGenericError)
+		return
+	}
+	if err := client.ensureCodexThread(ctx, ce.Portal, meta); err != nil {
+		commandregistry.ReplyFailure(ce, "Codex thread unavailable: "+err.Error(), event.MessageStatusGenericError)
+		return
+	}
+	roomID := ce.Portal.MXID
+	if !client.acquireRoomIfQueueEmpty(roomID) {
+		commandregistry.ReplyFailure(ce, "Codex is busy in this room. Try again when the current turn finishes.", event.MessageStatusGenericError)
+		return
+	}
+	params := map[string]any{
+		"threadId": strings.TrimSpace(meta.CodexThreadID),
+		"target":   target,
+		"delivery": "inline",
+	}
+	sourceEvent := &event.Event{ID: ce.EventID, Sender: ce.User.MXID, RoomID: roomID, Type: event.EventMessage}
+	portal := ce.Portal
+	go func() {
+		func() {
+			defer client.releaseRoom(roomID)
+			client.streamTurn(ctx, portal, meta, sourceEvent, "review/start", params)
+		}()
+		client.processPendingCodex(roomID)
+	}()
+}
+
+var _ = registerCodexCommand(commandregistry.Definition{
+	Name:        "revert",
+	Description: "Undo the changes of a turn by applying its diff in reverse",
+	Args:        "[turn ID]",
+	Handler:     fnCodexRevert,
+})
+
+func fnCodexRevert(ce *commands.Event) {
+	client, meta, ok := requireCodexRoom(ce)
+	if !ok {
+		return
+	}
+	turnID := strings.TrimSpace(ce.RawArgs)
+	if turnID == "" {
+		turnID = strings.TrimSpace(meta.CodexLastDiffTurnID)
+	}
+	if turnID == "" {
+		commandregistry.ReplyFailure(ce, "There are no Codex changes to revert in this room.", event.MessageStatusUnsupported)
+		return
+	}
+	patchPath := client.codexPatchPath(ce.Portal, turnID)
+	if _, err := os.Stat(patchPath); err != nil {
+		commandregistry.ReplyFailure(ce, fmt.Sprintf("No saved diff for turn %s.", turnID), event.MessageStatusUnsupported)
+		return
+	}
+	if meta.AwaitingCwdSetup || strings.TrimSpace(meta.CodexCwd) == "" {
+		commandregistry.ReplyFailure(ce, "Set a working directory for this room first.", event.MessageStatusUnsupported)
+		return
+	}
+	ctx := client.backgroundContext(ce.Ctx)
+	if err := client.ensureRPC(ctx); err != nil {
+		commandregistry.ReplyFailure(ce, "Codex isn't available. Sign in again.", event.MessageStatusGenericError)
+		return
+	}
+	roomID := ce.Portal.MXID
+	if !client.acquireRoomIfQueueEmpty(roomID) {
+		commandregistry.ReplyFailure(ce, "Codex is busy in this room. Try again when the current turn finishes.", event.MessageStatusGenericError)
+		return
+	}
+	go func() {
+		func() {
+			defer client.releaseRoom(roomID)
+			result, err := client.revertCodexPatch(ctx, meta, patchPath)
+			// The command's message status is already sent, so the outcome
+			// is only reported as a reply.
+			if err != nil {
+				ce.Reply("Couldn't revert turn %s: %v", turnID, err)
+				return
+			}
+			ce.Reply("%s", formatCodexRevertResult(turnID, result))
+		}()
+		client.processPendingCodex(roomID)
+	}()
+}
+
+// parseCodexReviewTarget maps review command arguments to a review/start target.
+func parseCodexReviewTarget(args []string) (map[string]any, error) {
+	if len(args) == 0 {
+		return map[string]any{"type": "uncommittedChanges"}, nil
+	}
+	switch strings.ToLower(args[0]) {
+	case "base", "branch":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("review base needs exactly one branch name")
+		}
+		return map[string]any{"type": "baseBranch", "branch": args[1]}, nil
+	case "commit":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("review commit needs exactly one commit SHA")
+		}
+		return map[string]any{"type": "commit", "sha": args[1]}, nil
+	}
+	return map[string]any{"type": "custom", "instructions": strings.Join(args, " ")}, nil
+}
+
+// listCodexModels returns the model IDs the Codex app-server offers.
+func (cc *CodexClient) listCodexModels(ctx context.Context) ([]string, error) {
+	if err := cc.ensureRPC(cc.backgroundContext(ctx)); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Data []struct {
+			ID    string `json:"id"`
+			Model string `json:"model"`
+		} `json:"data"`
+	}
+	callCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	if err := cc.rpc.Call(callCtx, "model/list", map[string]any{}, &resp); err != nil {
+		return nil, err
+	}
+	models := make([]string, 0, len(resp.Data))
+	for _, entry := range resp.Data {
+		model := strings.TrimSpace(entry.Model)
+		if model == "" {
+			model = strings.TrimSpace(entry.ID)
+		}
+		if model != "" && !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
@@ -13,8 +13,19 @@ func humanUserID(loginID networkid.UserLoginID) networkid.UserID {
 
 // Minimal room capabilities for codex bridge rooms.
 var aiBaseCaps = &event.RoomFeatures{
-	ID:                  aiCapabilityID,
-	MaxTextLength:       100000,
+	ID:            aiCapabilityID + "+attachments",
+	MaxTextLength: 100000,
+	File: event.FileFeatureMap{
+		event.MsgImage: codexFileFeatures(map[string]event.CapabilitySupportLevel{
+			"image/png":  event.CapLevelFullySupported,
+			"image/jpeg": event.CapLevelFullySupported,
+			"image/webp": event.CapLevelFullySupported,
+			"image/gif":  event.CapLevelFullySupported,
+		}),
+		event.MsgFile:  codexFileFeatures(map[string]event.CapabilitySupportLevel{"*/*": event.CapLevelFullySupported}),
+		event.MsgAudio: codexFileFeatures(map[string]event.CapabilitySupportLevel{"*/*": event.CapLevelFullySupported}),
+		event.MsgVideo: codexFileFeatures(map[string]event.CapabilitySupportLevel{"*/*": event.CapLevelFullySupported}),
+	},
 	Reply:               event.CapLevelFullySupported,
 	Thread:              event.CapLevelFullySupported,
 	Edit:                event.CapLevelFullySupported,
@@ -23,3 +34,12 @@ var aiBaseCaps = &event.RoomFeatures{
 	TypingNotifications: true,
 	DeleteChat:          true,
 }
+
+func codexFileFeatures(mimeTypes map[string]event.CapabilitySupportLevel) *event.FileFeatures {
+	return &event.FileFeatures{
+		MimeTypes:        mimeTypes,
+		Caption:          event.CapLevelFullySupported,
+		MaxCaptionLength: 100000,
+		MaxSize:          codexAttachmentMaxBytes,
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"go.mau.fi/util/dbutil"
 
 	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/commands"
 	"maunium.net/go/mautrix/bridgev2/database"
 	"maunium.net/go/mautrix/bridgev2/networkid"
 
@@ -66,6 +67,11 @@ func (cc *CodexConnector) Start(ctx context.Context) error {
 
 	cc.applyRuntimeDefaults()
 	bridgeadapter.PrimeUserLoginCache(ctx, cc.br)
+	if proc, ok := cc.br.Commands.(*commands.Processor); ok {
+		codexCommandRegistry.AddTo(proc)
+	} else {
+		cc.br.Log.Warn().Type("commands_type", cc.br.Commands).Msg("Failed to register Codex commands: command processor type assertion failed")
+	}
 	cc.autoProvisionExistingCodex(ctx)
 
 	return nil
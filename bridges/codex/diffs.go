@@ -0,0 +1,244 @@
+package codex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/networkid"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/pkg/bridgeadapter"
+)
+
+const (
+	codexPatchMimeType = "text/x-diff"
+	// codexDiffSummaryMaxFiles caps the per-file lines in the final message;
+	// the full list is always in the uploaded patch.
+	codexDiffSummaryMaxFiles = 20
+)
+
+// codexDiffFileStat summarizes the changes of one file in a unified diff.
+type codexDiffFileStat struct {
+	Path    string
+	Status  string // "added", "deleted", "renamed" or "modified"
+	Added   int
+	Removed int
+}
+
+// summarizeCodexDiff parses a unified diff (as produced by git diff) into
+// per-file line counts.
+func summarizeCodexDiff(diff string) []codexDiffFileStat {
+	var stats []codexDiffFileStat
+	var current *codexDiffFileStat
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			stats = append(stats, codexDiffFileStat{Path: codexDiffGitPath(line), Status: "modified"})
+			current = &stats[len(stats)-1]
+			inHunk = false
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk && strings.HasPrefix(line, "new file mode"):
+			current.Status = "added"
+		case !inHunk && strings.HasPrefix(line, "deleted file mode"):
+			current.Status = "deleted"
+		case !inHunk && strings.HasPrefix(line, "rename to "):
+			current.Status = "renamed"
+			current.Path = strings.TrimSpace(strings.TrimPrefix(line, "rename to "))
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			if path := codexDiffHeaderPath(line); path != "" {
+				current.Path = path
+			}
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			if path := codexDiffHeaderPath(line); path != "" && current.Status == "deleted" {
+				current.Path = path
+			}
+		case inHunk && strings.HasPrefix(line, "+"):
+			current.Added++
+		case inHunk && strings.HasPrefix(line, "-"):
+			current.Removed++
+		}
+	}
+	return stats
+}
+
+func codexDiffGitPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if idx := strings.LastIndex(rest, " b/"); idx >= 0 {
+		return rest[idx+len(" b/"):]
+	}
+	return strings.TrimPrefix(rest, "a/")
+}
+
+func codexDiffHeaderPath(line string) string {
+	path := strings.TrimSpace(line[4:])
+	if path == "/dev/null" {
+		return ""
+	}
+	if tab := strings.IndexByte(path, '\t'); tab >= 0 {
+		path = path[:tab]
+	}
+	for _, prefix := range []string{"a/", "b/"} {
+		if trimmed, ok := strings.CutPrefix(path, prefix); ok {
+			return trimmed
+		}
+	}
+	return path
+}
+
+// formatCodexDiffSummary renders the stats as a short markdown list.
+func formatCodexDiffSummary(stats []codexDiffFileStat) string {
+	if len(stats) == 0 {
+		return ""
+	}
+	added, removed := 0, 0
+	for _, stat := range stats {
+		added += stat.Added
+		removed += stat.Removed
+	}
+	noun := "files"
+	if len(stats) == 1 {
+		noun = "file"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Changed %d %s** (+%d −%d)", len(stats), noun, added, removed)
+	for i, stat := range stats {
+		if i == codexDiffSummaryMaxFiles {
+			fmt.Fprintf(&sb, "\n- …and %d more", len(stats)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n- `%s` +%d −%d", stat.Path, stat.Added, stat.Removed)
+		if stat.Status != "modified" {
+			fmt.Fprintf(&sb, " (%s)", stat.Status)
+		}
+	}
+	return sb.String()
+}
+
+func codexDiffStatsOutput(stats []codexDiffFileStat) []map[string]any {
+	out := make([]map[string]any, 0, len(stats))
+	for _, stat := range stats {
+		out = append(out, map[string]any{
+			"path":    stat.Path,
+			"status":  stat.Status,
+			"added":   stat.Added,
+			"removed": stat.Removed,
+		})
+	}
+	return out
+}
+
+// codexPatchPath is where a turn's final diff is kept for !ai revert.
+func (cc *CodexClient) codexPatchPath(portal *bridgev2.Portal, turnID string) string {
+	return filepath.Join(cc.codexAttachmentDir(portal), "diff-"+sanitizeCodexPathComponent(turnID)+".patch")
+}
+
+// publishCodexTurnDiff saves the final diff of a turn for reverting and
+// uploads it to the room as a .patch file.
+func (cc *CodexClient) publishCodexTurnDiff(ctx context.Context, portal *bridgev2.Portal, meta *PortalMetadata, turnID, diff string) error {
+	if portal == nil || portal.MXID == "" {
+		return errors.New("portal has no room")
+	}
+	data := []byte(diff)
+	if !strings.HasSuffix(diff, "\n") {
+		data = append(data, '\n')
+	}
+	path := cc.codexPatchPath(portal, turnID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	if meta != nil {
+		meta.CodexLastDiffTurnID = turnID
+		if err := portal.Save(ctx); err != nil {
+			return err
+		}
+	}
+
+	intent, err := cc.getCodexIntentForPortal(ctx, portal, bridgev2.RemoteEventMessage)
+	if err != nil {
+		return err
+	}
+	fileName := "codex-" + sanitizeCodexPathComponent(turnID) + ".patch"
+	uri, file, err := intent.UploadMedia(ctx, portal.MXID, data, fileName, codexPatchMimeType)
+	if err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	content := &event.MessageEventContent{
+		MsgType:  event.MsgFile,
+		Body:     fileName,
+		FileName: fileName,
+		Info:     &event.FileInfo{MimeType: codexPatchMimeType, Size: len(data)},
+	}
+	if file != nil {
+		content.File = file
+	} else {
+		content.URL = uri
+	}
+	_, _, err = cc.sendViaPortal(ctx, portal, &bridgev2.ConvertedMessage{
+		Parts: []*bridgev2.ConvertedMessagePart{{
+			ID:      networkid.PartID("0"),
+			Type:    event.EventMessage,
+			Content: content,
+			Extra:   map[string]any{"m.mentions": map[string]any{}},
+			DBMetadata: &MessageMetadata{
+				BaseMessageMetadata: bridgeadapter.BaseMessageMetadata{Role: "assistant", TurnID: turnID},
+				ExcludeFromHistory:  true,
+			},
+		}},
+	}, "")
+	return err
+}
+
+// codexRevertTimeout bounds the git apply run by !ai revert.
+const codexRevertTimeout = time.Minute
+
+// codexExecResult is the command/exec response.
+type codexExecResult struct {
+	ExitCode int    `json:"exitCode"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+// revertCodexPatch runs git apply -R on a saved turn diff through Codex's
+// command/exec, in the room's working directory and sandbox. git apply is
+// all-or-nothing, so a failed revert leaves the files as they were.
+func (cc *CodexClient) revertCodexPatch(ctx context.Context, meta *PortalMetadata, patchPath string) (codexExecResult, error) {
+	var result codexExecResult
+	callCtx, cancel := context.WithTimeout(ctx, codexRevertTimeout+10*time.Second)
+	defer cancel()
+	err := cc.rpc.Call(callCtx, "command/exec", map[string]any{
+		"command":       []string{"git", "apply", "-R", patchPath},
+		"cwd":           meta.CodexCwd,
+		"timeoutMs":     codexRevertTimeout.Milliseconds(),
+		"sandboxPolicy": cc.buildSandboxPolicy(meta),
+	}, &result)
+	return result, err
+}
+
+// formatCodexRevertResult describes the outcome of revertCodexPatch.
+func formatCodexRevertResult(turnID string, result codexExecResult) string {
+	if result.ExitCode == 0 {
+		return fmt.Sprintf("Reverted the changes of turn %s.", turnID)
+	}
+	msg := fmt.Sprintf("Couldn't revert turn %s: `git apply -R` exited with code %d, so no files were changed.", turnID, result.ExitCode)
+	output := strings.TrimSpace(strings.TrimSpace(result.Stderr) + "\n" + strings.TrimSpace(result.Stdout))
+	if output != "" {
+		if len(output) > 2000 {
+			output = output[:2000] + "\n…"
+		}
+		msg += "\n\n```\n" + output + "\n```"
+	}
+	return msg
+}
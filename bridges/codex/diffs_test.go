@@ -0,0 +1,67 @@
+package codex
+
+import (
+	"strings"
+	"testing"
+)
+
+const testCodexDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+-// old
++// new
++// extra
+diff --git a/docs/new.md b/docs/new.md
+new file mode 100644
+--- /dev/null
++++ b/docs/new.md
+@@ -0,0 +1,2 @@
++# Title
++--- not a header
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+
+func TestSummarizeCodexDiff(t *testing.T) {
+	stats := summarizeCodexDiff(testCodexDiff)
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 files, got %#v", stats)
+	}
+	if stats[0] != (codexDiffFileStat{Path: "main.go", Status: "modified", Added: 2, Removed: 1}) {
+		t.Fatalf("unexpected main.go stat %#v", stats[0])
+	}
+	if stats[1] != (codexDiffFileStat{Path: "docs/new.md", Status: "added", Added: 2}) {
+		t.Fatalf("unexpected new file stat %#v", stats[1])
+	}
+	if stats[2] != (codexDiffFileStat{Path: "gone.txt", Status: "deleted", Removed: 1}) {
+		t.Fatalf("unexpected deleted file stat %#v", stats[2])
+	}
+
+	summary := formatCodexDiffSummary(stats)
+	if !strings.HasPrefix(summary, "**Changed 3 files** (+4 −2)") {
+		t.Fatalf("unexpected summary header %q", summary)
+	}
+	if !strings.Contains(summary, "`docs/new.md` +2 −0 (added)") {
+		t.Fatalf("expected per-file line for new file, got %q", summary)
+	}
+	if formatCodexDiffSummary(nil) != "" {
+		t.Fatal("expected empty summary without files")
+	}
+}
+
+func TestFormatCodexRevertResult(t *testing.T) {
+	if got := formatCodexRevertResult("turn-1", codexExecResult{}); got != "Reverted the changes of turn turn-1." {
+		t.Fatalf("unexpected success message %q", got)
+	}
+	got := formatCodexRevertResult("turn-1", codexExecResult{ExitCode: 1, Stderr: "error: patch failed: main.go:1\n"})
+	if !strings.Contains(got, "exited with code 1, so no files were changed") || !strings.Contains(got, "error: patch failed: main.go:1") {
+		t.Fatalf("expected exit code and git output, got %q", got)
+	}
+}
@@ -18,3 +18,13 @@ func isCodexIdentifier(identifier string) bool {
 		return false
 	}
 }
+
+// codexThreadIdentifierPrefix marks identifiers that refer to an existing
+// thread in the Codex home rather than the default Codex chat.
+const codexThreadIdentifierPrefix = "codex:thread:"
+
+func parseCodexThreadIdentifier(identifier string) (string, bool) {
+	threadID, ok := strings.CutPrefix(strings.TrimSpace(identifier), codexThreadIdentifierPrefix)
+	threadID = strings.TrimSpace(threadID)
+	return threadID, ok && threadID != ""
+}
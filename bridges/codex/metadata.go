@@ -29,6 +29,24 @@ type PortalMetadata struct {
 	CodexCwd         string `json:"codex_cwd,omitempty"`
 	ElevatedLevel    string `json:"elevated_level,omitempty"`
 	AwaitingCwdSetup bool   `json:"awaiting_cwd_setup,omitempty"`
+
+	// Per-room thread settings, sent on thread/start and turn/start.
+	// Empty values use the bridge defaults.
+	CodexModel           string `json:"codex_model,omitempty"`
+	CodexReasoningEffort string `json:"codex_reasoning_effort,omitempty"`
+	CodexSandboxMode     string `json:"codex_sandbox_mode,omitempty"`
+	CodexApprovalPolicy  string `json:"codex_approval_policy,omitempty"`
+
+	// Set for rooms created from a thread that already existed in the Codex
+	// home. Such threads aren't archived and their cwd isn't removed when the
+	// chat is deleted. CodexImportedTurns bounds backfill to the turns that
+	// existed at import time; later turns are bridged live.
+	CodexThreadImported bool `json:"codex_thread_imported,omitempty"`
+	CodexImportedTurns  int  `json:"codex_imported_turns,omitempty"`
+
+	// CodexLastDiffTurnID is the latest turn whose diff was published, which
+	// !ai revert undoes by default.
+	CodexLastDiffTurnID string `json:"codex_last_diff_turn_id,omitempty"`
 }
 
 type MessageMetadata struct {
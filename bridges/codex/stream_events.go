@@ -12,3 +12,11 @@ func defaultCodexChatPortalKey(loginID networkid.UserLoginID) networkid.PortalKe
 		Receiver: loginID,
 	}
 }
+
+// codexThreadPortalKey is the portal of a thread imported from the Codex home.
+func codexThreadPortalKey(loginID networkid.UserLoginID, threadID string) networkid.PortalKey {
+	return networkid.PortalKey{
+		ID:       networkid.PortalID(fmt.Sprintf("codex:%s:thread:%s", loginID, threadID)),
+		Receiver: loginID,
+	}
+}
@@ -0,0 +1,235 @@
+package codex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/database"
+	"maunium.net/go/mautrix/bridgev2/networkid"
+
+	"github.com/beeper/agentremote/pkg/bridgeadapter"
+)
+
+const (
+	codexThreadListPageSize = 50
+	codexThreadListMax      = 200
+	codexThreadTitleMaxLen  = 80
+)
+
+// codexThread is a thread as returned by thread/list and thread/read.
+type codexThread struct {
+	ID        string      `json:"id"`
+	Preview   string      `json:"preview"`
+	Cwd       string      `json:"cwd"`
+	CreatedAt int64       `json:"createdAt"`
+	UpdatedAt int64       `json:"updatedAt"`
+	Turns     []codexTurn `json:"turns"`
+}
+
+type codexTurn struct {
+	ID     string            `json:"id"`
+	Status string            `json:"status"`
+	Items  []json.RawMessage `json:"items"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (t codexThread) createdTime() time.Time {
+	if t.CreatedAt <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(t.CreatedAt, 0)
+}
+
+func (t codexThread) updatedTime() time.Time {
+	if t.UpdatedAt <= 0 {
+		return t.createdTime()
+	}
+	return time.Unix(t.UpdatedAt, 0)
+}
+
+// title derives a room name from the thread's first prompt.
+func (t codexThread) title() string {
+	title := strings.Join(strings.Fields(t.Preview), " ")
+	if title == "" {
+		return "Codex thread " + t.ID
+	}
+	if runes := []rune(title); len(runes) > codexThreadTitleMaxLen {
+		title = strings.TrimSpace(string(runes[:codexThreadTitleMaxLen-1])) + "…"
+	}
+	return title
+}
+
+// listCodexThreads returns the threads in the Codex home, newest first.
+func (cc *CodexClient) listCodexThreads(ctx context.Context) ([]codexThread, error) {
+	if err := cc.ensureRPC(cc.backgroundContext(ctx)); err != nil {
+		return nil, err
+	}
+	var threads []codexThread
+	cursor := ""
+	for len(threads) < codexThreadListMax {
+		params := map[string]any{"limit": codexThreadListPageSize}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var resp struct {
+			Data       []codexThread `json:"data"`
+			NextCursor string        `json:"nextCursor"`
+		}
+		callCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err := cc.rpc.Call(callCtx, "thread/list", params, &resp)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, thread := range resp.Data {
+			if strings.TrimSpace(thread.ID) != "" {
+				threads = append(threads, thread)
+			}
+		}
+		cursor = strings.TrimSpace(resp.NextCursor)
+		if cursor == "" || len(resp.Data) == 0 {
+			break
+		}
+	}
+	return threads, nil
+}
+
+// readCodexThread loads a thread together with its turns.
+func (cc *CodexClient) readCodexThread(ctx context.Context, threadID string) (*codexThread, error) {
+	if err := cc.ensureRPC(cc.backgroundContext(ctx)); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Thread codexThread `json:"thread"`
+	}
+	callCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	if err := cc.rpc.Call(callCtx, "thread/read", map[string]any{
+		"threadId":     threadID,
+		"includeTurns": true,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(resp.Thread.ID) == "" {
+		return nil, fmt.Errorf("thread %s not found", threadID)
+	}
+	return &resp.Thread, nil
+}
+
+// bridgedCodexThreadIDs returns the threads that already have a room, so
+// they aren't offered for import again.
+func (cc *CodexClient) bridgedCodexThreadIDs(ctx context.Context) map[string]struct{} {
+	out := make(map[string]struct{})
+	if cc.UserLogin == nil || cc.UserLogin.Bridge == nil || cc.UserLogin.Bridge.DB == nil {
+		return out
+	}
+	ups, err := cc.UserLogin.Bridge.DB.UserPortal.GetAllForLogin(ctx, cc.UserLogin.UserLogin)
+	if err != nil {
+		return out
+	}
+	for _, up := range ups {
+		if up == nil {
+			continue
+		}
+		portal, err := cc.UserLogin.Bridge.GetExistingPortalByKey(ctx, up.Portal)
+		if err != nil || portal == nil {
+			continue
+		}
+		if meta, ok := portal.Metadata.(*PortalMetadata); ok && meta != nil && strings.TrimSpace(meta.CodexThreadID) != "" {
+			out[strings.TrimSpace(meta.CodexThreadID)] = struct{}{}
+		}
+	}
+	return out
+}
+
+// codexThreadContacts lists importable threads as contacts. Each thread gets
+// its own user ID, so picking it resolves to codexThreadIdentifierPrefix+threadID
+// and imports the thread instead of opening the default chat.
+func (cc *CodexClient) codexThreadContacts(ctx context.Context) ([]*bridgev2.ResolveIdentifierResponse, error) {
+	threads, err := cc.listCodexThreads(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bridged := cc.bridgedCodexThreadIDs(ctx)
+	out := make([]*bridgev2.ResolveIdentifierResponse, 0, len(threads))
+	for _, thread := range threads {
+		if _, ok := bridged[thread.ID]; ok {
+			continue
+		}
+		out = append(out, codexThreadContact(thread.ID, thread.title()))
+	}
+	return out, nil
+}
+
+// codexThreadContact describes a thread without a ghost: bridgev2 would
+// otherwise replace the thread's name and identifier with the shared Codex
+// ghost's, and sync the thread title onto that ghost.
+func codexThreadContact(threadID, title string) *bridgev2.ResolveIdentifierResponse {
+	identifier := codexThreadIdentifierPrefix + threadID
+	return &bridgev2.ResolveIdentifierResponse{
+		UserID:   networkid.UserID(identifier),
+		UserInfo: bridgeadapter.BuildBotUserInfo(title, identifier),
+	}
+}
+
+// importCodexThread returns the portal of an existing Codex thread, creating
+// the room on first use. Room creation triggers a forward backfill of the
+// thread's earlier turns via FetchMessages.
+func (cc *CodexClient) importCodexThread(ctx context.Context, threadID string) (*bridgev2.CreateChatResponse, error) {
+	thread, err := cc.readCodexThread(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Codex thread: %w", err)
+	}
+	portal, err := cc.UserLogin.Bridge.GetPortalByKey(ctx, codexThreadPortalKey(cc.UserLogin.ID, thread.ID))
+	if err != nil {
+		return nil, err
+	}
+	if portal == nil {
+		return nil, errors.New("codex thread portal unavailable")
+	}
+	meta := portalMeta(portal)
+	if portal.MXID == "" {
+		meta.IsCodexRoom = true
+		meta.CodexThreadImported = true
+		meta.CodexThreadID = thread.ID
+		meta.CodexImportedTurns = len(thread.Turns)
+		meta.Title = thread.title()
+		if cwd := strings.TrimSpace(thread.Cwd); cwd != "" {
+			if _, statErr := os.Stat(cwd); statErr == nil {
+				meta.CodexCwd = cwd
+			}
+		}
+		meta.AwaitingCwdSetup = strings.TrimSpace(meta.CodexCwd) == ""
+		portal.RoomType = database.RoomTypeDM
+		portal.OtherUserID = codexGhostID
+		portal.Name = meta.Title
+		portal.NameSet = true
+		if err := portal.Save(ctx); err != nil {
+			return nil, err
+		}
+	}
+	info := cc.composeCodexChatInfo(meta.Title)
+	info.CanBackfill = true
+	if portal.MXID == "" {
+		if err := portal.CreateMatrixRoom(ctx, cc.UserLogin, info); err != nil {
+			return nil, err
+		}
+		bridgeadapter.SendAIRoomInfo(ctx, portal, bridgeadapter.AIRoomKindAgent)
+		if meta.AwaitingCwdSetup {
+			cc.sendSystemNotice(ctx, portal, "The thread's working directory isn't available on this machine. Send an absolute path or `~/...` to continue it here.")
+		}
+	}
+	return &bridgev2.CreateChatResponse{
+		PortalKey:  portal.PortalKey,
+		PortalInfo: info,
+		Portal:     portal,
+	}, nil
+}
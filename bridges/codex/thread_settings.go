@@ -0,0 +1,104 @@
+package codex
+
+import (
+	"strings"
+
+	"github.com/beeper/agentremote/pkg/shared/stringutil"
+)
+
+// Sandbox modes, named like the Codex CLI's --sandbox flag.
+const (
+	codexSandboxReadOnly       = "read-only"
+	codexSandboxWorkspaceWrite = "workspace-write"
+	codexSandboxFullAccess     = "danger-full-access"
+)
+
+var codexReasoningEfforts = []string{"minimal", "low", "medium", "high", "xhigh"}
+
+var codexApprovalPolicies = []string{"untrusted", "on-failure", "on-request", "never"}
+
+// codexModel is the model the room's thread runs on.
+func (cc *CodexClient) codexModel(meta *PortalMetadata) string {
+	if meta != nil && strings.TrimSpace(meta.CodexModel) != "" {
+		return strings.TrimSpace(meta.CodexModel)
+	}
+	if cc.connector == nil || cc.connector.Config.Codex == nil {
+		return ""
+	}
+	return cc.connector.Config.Codex.DefaultModel
+}
+
+// codexApprovalPolicy returns the room's approval policy. Without an explicit
+// policy, full elevation skips approvals and everything else asks for them.
+func codexApprovalPolicy(meta *PortalMetadata) string {
+	if meta == nil {
+		return "untrusted"
+	}
+	if policy := strings.TrimSpace(meta.CodexApprovalPolicy); policy != "" {
+		return policy
+	}
+	if lvl, _ := stringutil.NormalizeElevatedLevel(meta.ElevatedLevel); lvl == "full" {
+		return "never"
+	}
+	return "untrusted"
+}
+
+func codexSandboxMode(meta *PortalMetadata) string {
+	if meta != nil && strings.TrimSpace(meta.CodexSandboxMode) != "" {
+		return strings.TrimSpace(meta.CodexSandboxMode)
+	}
+	return codexSandboxWorkspaceWrite
+}
+
+func (cc *CodexClient) buildSandboxPolicy(meta *PortalMetadata) map[string]any {
+	switch codexSandboxMode(meta) {
+	case codexSandboxReadOnly:
+		return map[string]any{"type": "readOnly"}
+	case codexSandboxFullAccess:
+		return map[string]any{"type": "dangerFullAccess"}
+	}
+	cwd := ""
+	if meta != nil {
+		cwd = strings.TrimSpace(meta.CodexCwd)
+	}
+	return map[string]any{
+		"type":          "workspaceWrite",
+		"writableRoots": []string{cwd},
+		"networkAccess": cc.codexNetworkAccess(),
+	}
+}
+
+func normalizeCodexSandboxMode(raw string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "read-only", "readonly", "read":
+		return codexSandboxReadOnly, true
+	case "workspace-write", "workspace", "write":
+		return codexSandboxWorkspaceWrite, true
+	case "danger-full-access", "full-access", "full":
+		return codexSandboxFullAccess, true
+	}
+	return "", false
+}
+
+func normalizeCodexReasoningEffort(raw string) (string, bool) {
+	effort := strings.ToLower(strings.TrimSpace(raw))
+	for _, candidate := range codexReasoningEfforts {
+		if effort == candidate {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func normalizeCodexApprovalPolicy(raw string) (string, bool) {
+	policy := strings.ToLower(strings.TrimSpace(raw))
+	if policy == "ask" {
+		policy = "untrusted"
+	}
+	for _, candidate := range codexApprovalPolicies {
+		if policy == candidate {
+			return candidate, true
+		}
+	}
+	return "", false
+}
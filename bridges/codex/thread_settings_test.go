@@ -0,0 +1,61 @@
+package codex
+
+import "testing"
+
+func TestCodexApprovalPolicy(t *testing.T) {
+	if got := codexApprovalPolicy(&PortalMetadata{}); got != "untrusted" {
+		t.Fatalf("expected untrusted by default, got %q", got)
+	}
+	if got := codexApprovalPolicy(&PortalMetadata{ElevatedLevel: "full"}); got != "never" {
+		t.Fatalf("expected never for full elevation, got %q", got)
+	}
+	if got := codexApprovalPolicy(&PortalMetadata{ElevatedLevel: "full", CodexApprovalPolicy: "on-request"}); got != "on-request" {
+		t.Fatalf("expected explicit policy to win, got %q", got)
+	}
+	if policy, ok := normalizeCodexApprovalPolicy(" Ask "); !ok || policy != "untrusted" {
+		t.Fatalf("expected ask alias to map to untrusted, got %q (%v)", policy, ok)
+	}
+	if _, ok := normalizeCodexApprovalPolicy("sometimes"); ok {
+		t.Fatal("expected unknown policy to be rejected")
+	}
+}
+
+func TestCodexBuildSandboxPolicy(t *testing.T) {
+	cc := &CodexClient{connector: &CodexConnector{}}
+	policy := cc.buildSandboxPolicy(&PortalMetadata{CodexCwd: "/work"})
+	if policy["type"] != "workspaceWrite" {
+		t.Fatalf("expected workspaceWrite by default, got %#v", policy)
+	}
+	if roots, _ := policy["writableRoots"].([]string); len(roots) != 1 || roots[0] != "/work" {
+		t.Fatalf("expected cwd as writable root, got %#v", policy)
+	}
+	mode, ok := normalizeCodexSandboxMode("readonly")
+	if !ok {
+		t.Fatal("expected readonly alias")
+	}
+	if policy := cc.buildSandboxPolicy(&PortalMetadata{CodexSandboxMode: mode}); policy["type"] != "readOnly" {
+		t.Fatalf("expected readOnly, got %#v", policy)
+	}
+	mode, _ = normalizeCodexSandboxMode("full")
+	if policy := cc.buildSandboxPolicy(&PortalMetadata{CodexSandboxMode: mode}); policy["type"] != "dangerFullAccess" {
+		t.Fatalf("expected dangerFullAccess, got %#v", policy)
+	}
+}
+
+func TestParseCodexReviewTarget(t *testing.T) {
+	target, err := parseCodexReviewTarget(nil)
+	if err != nil || target["type"] != "uncommittedChanges" {
+		t.Fatalf("expected uncommitted changes by default, got %#v (%v)", target, err)
+	}
+	target, err = parseCodexReviewTarget([]string{"base", "main"})
+	if err != nil || target["type"] != "baseBranch" || target["branch"] != "main" {
+		t.Fatalf("unexpected base target %#v (%v)", target, err)
+	}
+	if _, err := parseCodexReviewTarget([]string{"commit"}); err == nil {
+		t.Fatal("expected error for commit without sha")
+	}
+	target, err = parseCodexReviewTarget([]string{"check", "error", "handling"})
+	if err != nil || target["type"] != "custom" || target["instructions"] != "check error handling" {
+		t.Fatalf("unexpected custom target %#v (%v)", target, err)
+	}
+}
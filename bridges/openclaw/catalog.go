@@ -96,8 +96,7 @@ func (oc *OpenClawClient) enrichPortalMetadata(ctx context.Context, meta *Portal
 	if models, err := oc.loadModelCatalog(ctx, false); err == nil && len(models) > 0 {
 		meta.OpenClawKnownModelCount = len(models)
 	}
-	agentID := stringsTrimDefault(meta.OpenClawAgentID, meta.OpenClawDMTargetAgentID)
-	if catalog, err := oc.loadToolsCatalog(ctx, agentID, false); err == nil && catalog != nil {
+	if catalog, err := oc.loadToolsCatalog(ctx, openClawPortalAgentID(meta), false); err == nil && catalog != nil {
 		meta.OpenClawToolCount, meta.OpenClawToolProfile = summarizeToolsCatalog(*catalog)
 	}
 	if preview := strings.TrimSpace(meta.OpenClawLastMessagePreview); meta.OpenClawPreviewSnippet == "" && preview != "" {
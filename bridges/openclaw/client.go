@@ -158,6 +158,9 @@ func (oc *OpenClawClient) Connect(ctx context.Context) {
 			}
 			oc.connectMu.Unlock()
 		}()
+		if oc.manager != nil {
+			oc.manager.approvalFlow.RestorePending(runCtx)
+		}
 		oc.connectLoop(runCtx)
 	}()
 }
@@ -540,6 +543,9 @@ func (oc *OpenClawClient) topicForPortal(meta *PortalMetadata) string {
 	appendPart(summarizeOpenClawOrigin(meta.OpenClawOrigin, meta.OpenClawChannel))
 	appendPart(meta.ModelProvider)
 	appendPart(meta.Model)
+	if thinking := strings.TrimSpace(meta.ThinkingLevel); thinking != "" {
+		appendPart("Thinking: " + thinking)
+	}
 	if preview := stringsTrimDefault(meta.OpenClawPreviewSnippet, meta.OpenClawLastMessagePreview); strings.TrimSpace(preview) != "" {
 		appendPart("Recent: " + strings.TrimSpace(preview))
 	}
@@ -553,6 +559,9 @@ func (oc *OpenClawClient) topicForPortal(meta *PortalMetadata) string {
 		}
 		appendPart(toolSummary)
 	}
+	if overrides := formatOpenClawToolOverrides(meta.OpenClawToolsAllow, meta.OpenClawToolsDeny); overrides != "" {
+		appendPart("Tool overrides: " + overrides)
+	}
 	if meta.OpenClawKnownModelCount > 0 {
 		appendPart(fmt.Sprintf("Models: %d", meta.OpenClawKnownModelCount))
 	}
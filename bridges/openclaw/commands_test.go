@@ -45,6 +45,53 @@ func TestParseOpenClawControlCommand(t *testing.T) {
 			want:   &openClawControlCommand{Action: "reasoning", Clear: true},
 			wantOK: true,
 		},
+		{
+			name:   "model list",
+			body:   "/model",
+			want:   &openClawControlCommand{Action: "model"},
+			wantOK: true,
+		},
+		{
+			name:   "model value",
+			body:   "/model openai/gpt-5",
+			want:   &openClawControlCommand{Action: "model", Value: "openai/gpt-5"},
+			wantOK: true,
+		},
+		{
+			name:   "model clear",
+			body:   "/model Default",
+			want:   &openClawControlCommand{Action: "model", Clear: true},
+			wantOK: true,
+		},
+		{
+			name:   "tools list",
+			body:   "/tools",
+			want:   &openClawControlCommand{Action: "tools"},
+			wantOK: true,
+		},
+		{
+			name:   "tool enable",
+			body:   "/tools enable web_search",
+			want:   &openClawControlCommand{Action: "tool_enable", Value: "web_search"},
+			wantOK: true,
+		},
+		{
+			name:   "tool disable",
+			body:   "/tools deny exec",
+			want:   &openClawControlCommand{Action: "tool_disable", Value: "exec"},
+			wantOK: true,
+		},
+		{
+			name:   "tools reset",
+			body:   "/tools reset",
+			want:   &openClawControlCommand{Action: "tools", Clear: true},
+			wantOK: true,
+		},
+		{
+			name:   "tools unknown subcommand",
+			body:   "/tools everything please",
+			wantOK: false,
+		},
 		{
 			name:   "non command",
 			body:   "hello",
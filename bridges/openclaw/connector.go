@@ -6,11 +6,13 @@ import (
 	"sync"
 
 	"go.mau.fi/util/configupgrade"
+	"go.mau.fi/util/dbutil"
 	"go.mau.fi/util/ptr"
 	"maunium.net/go/mautrix/bridgev2"
 	"maunium.net/go/mautrix/bridgev2/database"
 	"maunium.net/go/mautrix/bridgev2/networkid"
 
+	"github.com/beeper/agentremote/pkg/aidb"
 	"github.com/beeper/agentremote/pkg/bridgeadapter"
 )
 
@@ -22,6 +24,7 @@ var (
 type OpenClawConnector struct {
 	bridgeadapter.BaseConnectorMethods
 	br     *bridgev2.Bridge
+	db     *dbutil.Database
 	Config Config
 
 	clientsMu sync.Mutex
@@ -39,7 +42,11 @@ func (oc *OpenClawConnector) Init(bridge *bridgev2.Bridge) {
 	bridgeadapter.EnsureClientMap(&oc.clientsMu, &oc.clients)
 }
 
-func (oc *OpenClawConnector) Start(_ context.Context) error {
+func (oc *OpenClawConnector) Start(ctx context.Context) error {
+	// The shared AI bridge schema holds pending approvals.
+	if err := aidb.Upgrade(ctx, oc.bridgeDB(), "openclaw_bridge", "openclaw bridge database not initialized"); err != nil {
+		return err
+	}
 	if oc.Config.Bridge.CommandPrefix == "" {
 		oc.Config.Bridge.CommandPrefix = "!openclaw"
 	}
@@ -49,6 +56,16 @@ func (oc *OpenClawConnector) Start(_ context.Context) error {
 	return nil
 }
 
+func (oc *OpenClawConnector) bridgeDB() *dbutil.Database {
+	if oc.db == nil && oc.br != nil && oc.br.DB != nil {
+		oc.db = aidb.NewChild(
+			oc.br.DB.Database,
+			dbutil.ZeroLogger(oc.br.Log.With().Str("db_section", "openclaw_bridge").Logger()),
+		)
+	}
+	return oc.db
+}
+
 func (oc *OpenClawConnector) Stop(_ context.Context) {
 	bridgeadapter.StopClients(&oc.clientsMu, &oc.clients)
 }
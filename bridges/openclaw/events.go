@@ -147,6 +147,7 @@ func (evt *OpenClawSessionResyncEvent) GetChatInfo(ctx context.Context, portal *
 	}
 	roomType := openClawRoomType(meta)
 	evt.client.maybeRefreshPortalCapabilities(ctx, portal, &previous)
+	evt.client.maybeSendRoomSettings(ctx, portal, &previous)
 	return &bridgev2.ChatInfo{
 		Type:        ptr.Ptr(roomType),
 		Name:        ptr.Ptr(title),
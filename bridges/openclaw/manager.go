@@ -37,6 +37,7 @@ type openClawManager struct {
 	approvalFlow       *bridgeadapter.ApprovalFlow[*openClawPendingApprovalData]
 	waiting            map[string]struct{}
 	started            map[string]struct{}
+	stopped            map[string]struct{}
 	resyncing          map[string]time.Time
 	lastEmittedUserMsg map[string]networkid.MessageID
 
@@ -60,6 +61,7 @@ func newOpenClawManager(client *OpenClawClient) *openClawManager {
 		sessions:           make(map[string]gatewaySessionRow),
 		waiting:            make(map[string]struct{}),
 		started:            make(map[string]struct{}),
+		stopped:            make(map[string]struct{}),
 		resyncing:          make(map[string]time.Time),
 		lastEmittedUserMsg: make(map[string]networkid.MessageID),
 	}
@@ -137,6 +139,7 @@ func (m *openClawManager) Start(ctx context.Context) (bool, error) {
 		}
 		m.cancel = nil
 		m.started = make(map[string]struct{})
+		m.stopped = make(map[string]struct{})
 		m.resyncing = make(map[string]time.Time)
 		m.mu.Unlock()
 	}()
@@ -173,6 +176,7 @@ func (m *openClawManager) Stop() {
 	m.cancel = nil
 	m.gateway = nil
 	m.started = make(map[string]struct{})
+	m.stopped = make(map[string]struct{})
 	m.resyncing = make(map[string]time.Time)
 	m.mu.Unlock()
 	if cancel != nil {
@@ -436,15 +440,51 @@ func parseOpenClawControlCommand(body string, msgType event.MessageType, evtType
 			return nil, false
 		}
 		value := strings.ToLower(strings.TrimSpace(rest))
-		if value == "inherit" || value == "default" || value == "-" {
+		if isOpenClawClearValue(value) {
 			return &openClawControlCommand{Action: cmd, Clear: true}, true
 		}
 		return &openClawControlCommand{Action: cmd, Value: value}, true
+	case "model":
+		if rest == "" {
+			return &openClawControlCommand{Action: "model"}, true
+		}
+		if isOpenClawClearValue(strings.ToLower(rest)) {
+			return &openClawControlCommand{Action: "model", Clear: true}, true
+		}
+		return &openClawControlCommand{Action: "model", Value: rest}, true
+	case "tools":
+		if rest == "" {
+			return &openClawControlCommand{Action: "tools"}, true
+		}
+		args := strings.Fields(rest)
+		switch strings.ToLower(args[0]) {
+		case "enable", "allow":
+			if len(args) != 2 {
+				return nil, false
+			}
+			return &openClawControlCommand{Action: "tool_enable", Value: args[1]}, true
+		case "disable", "deny":
+			if len(args) != 2 {
+				return nil, false
+			}
+			return &openClawControlCommand{Action: "tool_disable", Value: args[1]}, true
+		case "reset", "default", "inherit":
+			if len(args) != 1 {
+				return nil, false
+			}
+			return &openClawControlCommand{Action: "tools", Clear: true}, true
+		default:
+			return nil, false
+		}
 	default:
 		return nil, false
 	}
 }
 
+func isOpenClawClearValue(value string) bool {
+	return value == "inherit" || value == "default" || value == "-"
+}
+
 func (m *openClawManager) applySessionPatch(ctx context.Context, portal *bridgev2.Portal, gateway *gatewayWSClient, sessionKey, apiKey, displayName string, command *openClawControlCommand) error {
 	value := any(nil)
 	notice := "OpenClaw " + displayName + " cleared."
@@ -459,6 +499,109 @@ func (m *openClawManager) applySessionPatch(ctx context.Context, portal *bridgev
 	return nil
 }
 
+func (m *openClawManager) applyModelCommand(ctx context.Context, portal *bridgev2.Portal, gateway *gatewayWSClient, sessionKey string, command *openClawControlCommand) error {
+	meta := portalMeta(portal)
+	if command.Clear {
+		if err := gateway.PatchSession(ctx, sessionKey, map[string]any{"model": nil}); err != nil {
+			return err
+		}
+		meta.ModelProvider, meta.Model = "", ""
+		m.client.sendSystemNoticeViaPortal(ctx, portal, "OpenClaw model reset to the gateway default.")
+		return nil
+	}
+	models, err := m.client.loadModelCatalog(ctx, false)
+	if err != nil {
+		return err
+	}
+	model, ok := findOpenClawModel(models, command.Value)
+	if !ok {
+		m.client.sendSystemNoticeViaPortal(ctx, portal, "Unknown OpenClaw model "+command.Value+". Send /model to list the available models.")
+		return nil
+	}
+	ref := openClawModelRef(model.Provider, model.ID)
+	if err = gateway.PatchSession(ctx, sessionKey, map[string]any{"model": ref}); err != nil {
+		return err
+	}
+	meta.ModelProvider, meta.Model = strings.TrimSpace(model.Provider), strings.TrimSpace(model.ID)
+	m.client.sendSystemNoticeViaPortal(ctx, portal, "OpenClaw model set to "+ref+".")
+	return nil
+}
+
+// applyToolsCommand changes the session's tool overrides. sessions.patch has
+// no field for per-session tool lists, so the bridge keeps the overrides and
+// enforces them itself: approval requests for enabled tools are allowed and
+// for disabled tools denied, and runs that call a disabled tool are stopped.
+// Tools outside the agent's profile can't be enabled this way.
+func (m *openClawManager) applyToolsCommand(ctx context.Context, portal *bridgev2.Portal, command *openClawControlCommand) error {
+	meta := portalMeta(portal)
+	var allow, deny []string
+	notice := "OpenClaw tool overrides cleared."
+	if !command.Clear {
+		catalog, err := m.client.loadToolsCatalog(ctx, openClawPortalAgentID(meta), false)
+		if err != nil {
+			return err
+		}
+		tool, ok := findOpenClawTool(catalog, command.Value)
+		if !ok {
+			m.client.sendSystemNoticeViaPortal(ctx, portal, "Unknown OpenClaw tool "+command.Value+". Send /tools to list the available tools.")
+			return nil
+		}
+		enable := command.Action == "tool_enable"
+		allow, deny = applyOpenClawToolOverride(meta.OpenClawToolsAllow, meta.OpenClawToolsDeny, tool.ID, enable)
+		notice = "OpenClaw tool " + tool.ID + " disabled; the bridge denies its approvals and stops runs that call it."
+		if enable {
+			notice = "OpenClaw tool " + tool.ID + " enabled; the bridge approves its calls in this session."
+		}
+	}
+	meta.OpenClawToolsAllow, meta.OpenClawToolsDeny = allow, deny
+	m.client.sendSystemNoticeViaPortal(ctx, portal, notice)
+	return nil
+}
+
+// openClawOverrideApproval is the approval decision the session's tool
+// overrides make for toolName, or "" when the user has to decide.
+func openClawOverrideApproval(meta *PortalMetadata, toolName string) string {
+	switch {
+	case meta == nil:
+		return ""
+	case containsOpenClawTool(meta.OpenClawToolsDeny, toolName):
+		return "deny"
+	case containsOpenClawTool(meta.OpenClawToolsAllow, toolName):
+		return "allow-once"
+	default:
+		return ""
+	}
+}
+
+// stopDisabledToolRun aborts a run once it calls a tool disabled for its
+// session. The gateway has already started the call by the time the bridge
+// sees it, so this stops the run rather than the call itself.
+func (m *openClawManager) stopDisabledToolRun(ctx context.Context, portal *bridgev2.Portal, meta *PortalMetadata, sessionKey, turnID, runID, toolName string) {
+	if !containsOpenClawTool(meta.OpenClawToolsDeny, toolName) {
+		return
+	}
+	m.mu.Lock()
+	if _, stopped := m.stopped[turnID]; stopped {
+		m.mu.Unlock()
+		return
+	}
+	m.stopped[turnID] = struct{}{}
+	m.mu.Unlock()
+	gateway, err := m.requireGateway()
+	if err == nil {
+		err = gateway.AbortRun(ctx, sessionKey, runID)
+	}
+	if err != nil {
+		m.client.Log().Warn().Err(err).Str("session_key", sessionKey).Str("tool_name", toolName).Msg("Failed to stop OpenClaw run calling a disabled tool")
+		return
+	}
+	m.client.sendSystemNoticeViaPortal(ctx, portal, "Stopped the OpenClaw run: tool "+toolName+" is disabled for this session. Send /tools enable "+toolName+" or /tools reset to allow it.")
+}
+
+func openClawPortalAgentID(meta *PortalMetadata) string {
+	return stringsTrimDefault(meta.OpenClawAgentID, meta.OpenClawDMTargetAgentID)
+}
+
 func (m *openClawManager) handleControlCommand(ctx context.Context, msg *bridgev2.MatrixMessage, gateway *gatewayWSClient, body string) (bool, error) {
 	if msg == nil || msg.Portal == nil || gateway == nil {
 		return false, nil
@@ -468,6 +611,7 @@ func (m *openClawManager) handleControlCommand(ctx context.Context, msg *bridgev
 		return false, nil
 	}
 	meta := portalMeta(msg.Portal)
+	previous := *meta
 	sessionKey := strings.TrimSpace(meta.OpenClawSessionKey)
 	if sessionKey == "" {
 		m.client.sendSystemNoticeViaPortal(ctx, msg.Portal, "OpenClaw session key is unavailable for this room.")
@@ -487,6 +631,7 @@ func (m *openClawManager) handleControlCommand(ctx context.Context, msg *bridgev
 		if err := m.applySessionPatch(ctx, msg.Portal, gateway, sessionKey, "thinkingLevel", "thinking level", command); err != nil {
 			return true, err
 		}
+		meta.ThinkingLevel = command.Value
 	case "verbose":
 		if err := m.applySessionPatch(ctx, msg.Portal, gateway, sessionKey, "verboseLevel", "verbose level", command); err != nil {
 			return true, err
@@ -495,9 +640,39 @@ func (m *openClawManager) handleControlCommand(ctx context.Context, msg *bridgev
 		if err := m.applySessionPatch(ctx, msg.Portal, gateway, sessionKey, "reasoningLevel", "reasoning level", command); err != nil {
 			return true, err
 		}
+	case "model":
+		if command.Value == "" && !command.Clear {
+			models, err := m.client.loadModelCatalog(ctx, true)
+			if err != nil {
+				return true, err
+			}
+			m.client.sendSystemNoticeViaPortal(ctx, msg.Portal, formatOpenClawModelsList(models, meta))
+			return true, nil
+		}
+		if err := m.applyModelCommand(ctx, msg.Portal, gateway, sessionKey, command); err != nil {
+			return true, err
+		}
+	case "tools", "tool_enable", "tool_disable":
+		if command.Action == "tools" && !command.Clear {
+			catalog, err := m.client.loadToolsCatalog(ctx, openClawPortalAgentID(meta), true)
+			if err != nil {
+				return true, err
+			}
+			m.client.sendSystemNoticeViaPortal(ctx, msg.Portal, formatOpenClawToolsList(catalog, meta))
+			return true, nil
+		}
+		if err := m.applyToolsCommand(ctx, msg.Portal, command); err != nil {
+			return true, err
+		}
 	default:
 		return false, nil
 	}
+	if settings := roomSettingsForPortal(&previous); !settings.equal(roomSettingsForPortal(meta)) {
+		if err := msg.Portal.Save(ctx); err != nil {
+			m.client.Log().Warn().Err(err).Str("session_key", sessionKey).Msg("Failed to save OpenClaw portal after control command")
+		}
+		m.client.sendRoomSettings(ctx, msg.Portal)
+	}
 	if err := m.syncSessions(ctx); err != nil {
 		m.client.Log().Debug().Err(err).Str("session_key", sessionKey).Msg("Failed to refresh OpenClaw sessions after control command")
 	}
@@ -989,6 +1164,16 @@ func (m *openClawManager) handleApprovalRequest(ctx context.Context, payload gat
 		}
 		turnID = strings.TrimSpace(data.TurnID)
 	}
+	if decision := openClawOverrideApproval(portalMeta(portal), toolName); decision != "" {
+		gateway, err := m.requireGateway()
+		if err == nil {
+			err = gateway.ResolveApproval(ctx, payload.ID, decision)
+		}
+		if err == nil {
+			return
+		}
+		m.client.Log().Warn().Err(err).Str("approval_id", payload.ID).Str("tool_name", toolName).Msg("Failed to apply OpenClaw tool override to approval")
+	}
 	m.client.sendApprovalRequestFallbackEvent(
 		ctx,
 		portal,
@@ -1285,6 +1470,7 @@ func (m *openClawManager) handleAgentEvent(ctx context.Context, payload gatewayA
 		toolCallID := stringsTrimDefault(stringValue(payload.Data["toolCallId"]), stringsTrimDefault(stringValue(payload.Data["toolUseId"]), stringValue(payload.Data["id"])))
 		toolName := stringsTrimDefault(stringValue(payload.Data["toolName"]), stringsTrimDefault(stringValue(payload.Data["name"]), "tool"))
 		if toolCallID != "" {
+			m.stopDisabledToolRun(ctx, portal, meta, payload.SessionKey, turnID, payload.RunID, toolName)
 			if input, ok := payload.Data["input"]; ok {
 				m.client.EmitStreamPart(ctx, portal, turnID, agentID, payload.SessionKey, map[string]any{
 					"timestamp":        eventTS.UnixMilli(),
@@ -1686,6 +1872,7 @@ func (m *openClawManager) clearStartedTurn(turnID string) {
 	}
 	m.mu.Lock()
 	delete(m.started, turnID)
+	delete(m.stopped, turnID)
 	m.mu.Unlock()
 }
 
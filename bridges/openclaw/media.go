@@ -36,7 +36,7 @@ func (oc *OpenClawClient) buildOpenClawAttachmentContent(ctx context.Context, po
 	if source == nil {
 		return nil, errors.New("unsupported attachment source")
 	}
-	data, mimeType, err := downloadOpenClawAttachment(ctx, source, openClawMaxMediaMB)
+	data, mimeType, err := downloadOpenClawAttachment(ctx, source, openClawMaxMediaMB, loginMetadata(oc.UserLogin).GatewayURL)
 	if err != nil {
 		return nil, err
 	}
@@ -262,7 +262,7 @@ func openClawAttachmentFilename(source *openClawAttachmentSource) string {
 	return base
 }
 
-func downloadOpenClawAttachment(ctx context.Context, source *openClawAttachmentSource, maxSizeMB int) ([]byte, string, error) {
+func downloadOpenClawAttachment(ctx context.Context, source *openClawAttachmentSource, maxSizeMB int, gatewayURL string) ([]byte, string, error) {
 	if source == nil {
 		return nil, "", errors.New("missing attachment source")
 	}
@@ -281,7 +281,7 @@ func downloadOpenClawAttachment(ctx context.Context, source *openClawAttachmentS
 		}
 		return data, mimeType, nil
 	case "url":
-		return downloadOpenClawAttachmentURL(ctx, source.URL, source.MimeType, maxBytes, maxSizeMB)
+		return downloadOpenClawAttachmentURL(ctx, source.URL, source.MimeType, maxBytes, gatewayURL)
 	default:
 		return nil, "", fmt.Errorf("unsupported attachment source kind %q", source.Kind)
 	}
@@ -310,7 +310,10 @@ func decodeOpenClawDataOrBase64(raw, fallbackMime string) ([]byte, string, error
 	return decoded, mimeType, nil
 }
 
-func downloadOpenClawAttachmentURL(ctx context.Context, rawURL, fallbackMime string, maxBytes int64, _ int) ([]byte, string, error) {
+// downloadOpenClawAttachmentURL fetches an attachment through the safe
+// download client. The gateway's own host is allowed even when it is on
+// localhost or the LAN, since it serves the attachments it sends.
+func downloadOpenClawAttachmentURL(ctx context.Context, rawURL, fallbackMime string, maxBytes int64, gatewayURL string) ([]byte, string, error) {
 	rawURL = strings.TrimSpace(rawURL)
 	if rawURL == "" {
 		return nil, "", errors.New("missing attachment URL")
@@ -318,7 +321,11 @@ func downloadOpenClawAttachmentURL(ctx context.Context, rawURL, fallbackMime str
 	if strings.HasPrefix(rawURL, "file://") || strings.HasPrefix(rawURL, "/") {
 		return nil, "", errors.New("local file access is not permitted")
 	}
-	return media.DownloadURL(ctx, rawURL, fallbackMime, maxBytes)
+	var allowed []string
+	if parsed, err := url.Parse(strings.TrimSpace(gatewayURL)); err == nil && parsed.Hostname() != "" {
+		allowed = append(allowed, parsed.Hostname())
+	}
+	return media.DownloadURLWithClient(ctx, media.DownloadClientFor(allowed...), rawURL, fallbackMime, maxBytes)
 }
 
 func messageTypeForMIME(mimeType string) event.MessageType {
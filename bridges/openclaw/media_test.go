@@ -2,6 +2,8 @@ package openclaw
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -318,14 +320,30 @@ func TestOpenClawAttachmentSourceFromNestedAssetSource(t *testing.T) {
 }
 
 func TestDownloadOpenClawAttachmentURLRejectsLocalFiles(t *testing.T) {
-	if _, _, err := downloadOpenClawAttachmentURL(context.Background(), "file:///tmp/test.txt", "", 1024, 1); err == nil {
+	if _, _, err := downloadOpenClawAttachmentURL(context.Background(), "file:///tmp/test.txt", "", 1024, ""); err == nil {
 		t.Fatal("expected local file URL to be rejected")
 	}
-	if _, _, err := downloadOpenClawAttachmentURL(context.Background(), "/tmp/test.txt", "", 1024, 1); err == nil {
+	if _, _, err := downloadOpenClawAttachmentURL(context.Background(), "/tmp/test.txt", "", 1024, ""); err == nil {
 		t.Fatal("expected absolute path to be rejected")
 	}
 }
 
+func TestDownloadOpenClawAttachmentURLAllowsGatewayHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+
+	if _, _, err := downloadOpenClawAttachmentURL(context.Background(), srv.URL, "", 1024, "wss://gateway.example.com"); err == nil {
+		t.Fatal("expected loopback attachment from another host to be rejected")
+	}
+	data, mimeType, err := downloadOpenClawAttachmentURL(context.Background(), srv.URL, "", 1024, "ws://127.0.0.1:18789")
+	if err != nil || string(data) != "hello" || mimeType != "text/plain" {
+		t.Fatalf("expected download from the gateway host, got %q %q (err %v)", data, mimeType, err)
+	}
+}
+
 func TestTopicForPortal(t *testing.T) {
 	oc := &OpenClawClient{}
 	topic := oc.topicForPortal(&PortalMetadata{
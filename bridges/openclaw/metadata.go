@@ -66,6 +66,8 @@ type PortalMetadata struct {
 	OpenClawDefaultAgentID       string         `json:"openclaw_default_agent_id,omitempty"`
 	OpenClawToolProfile          string         `json:"openclaw_tool_profile,omitempty"`
 	OpenClawToolCount            int            `json:"openclaw_tool_count,omitempty"`
+	OpenClawToolsAllow           []string       `json:"openclaw_tools_allow,omitempty"`
+	OpenClawToolsDeny            []string       `json:"openclaw_tools_deny,omitempty"`
 	OpenClawKnownModelCount      int            `json:"openclaw_known_model_count,omitempty"`
 	OpenClawLastPreviewAt        int64          `json:"openclaw_last_preview_at,omitempty"`
 	HistoryMode                  string         `json:"history_mode,omitempty"`
@@ -0,0 +1,209 @@
+package openclaw
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"maunium.net/go/mautrix/bridgev2"
+
+	"github.com/beeper/agentremote/pkg/matrixevents"
+)
+
+// openClawRoomSettings is the room settings state published for OpenClaw
+// rooms so clients can show the session's model, thinking level and tool
+// overrides.
+type openClawRoomSettings struct {
+	Model         string   `json:"model,omitempty"`
+	ThinkingLevel string   `json:"thinking_level,omitempty"`
+	ToolsEnabled  []string `json:"tools_enabled,omitempty"`
+	ToolsDisabled []string `json:"tools_disabled,omitempty"`
+}
+
+func roomSettingsForPortal(meta *PortalMetadata) openClawRoomSettings {
+	if meta == nil {
+		return openClawRoomSettings{}
+	}
+	return openClawRoomSettings{
+		Model:         openClawModelRef(meta.ModelProvider, meta.Model),
+		ThinkingLevel: strings.TrimSpace(meta.ThinkingLevel),
+		ToolsEnabled:  meta.OpenClawToolsAllow,
+		ToolsDisabled: meta.OpenClawToolsDeny,
+	}
+}
+
+func (s openClawRoomSettings) equal(other openClawRoomSettings) bool {
+	return s.Model == other.Model &&
+		s.ThinkingLevel == other.ThinkingLevel &&
+		slices.Equal(s.ToolsEnabled, other.ToolsEnabled) &&
+		slices.Equal(s.ToolsDisabled, other.ToolsDisabled)
+}
+
+func (oc *OpenClawClient) sendRoomSettings(ctx context.Context, portal *bridgev2.Portal) bool {
+	if portal == nil || portal.MXID == "" {
+		return false
+	}
+	//lint:ignore SA1019 bridgev2 currently exposes room-meta sending via portal internals
+	return portal.Internal().SendRoomMeta(
+		ctx,
+		nil,
+		time.Now(),
+		matrixevents.RoomSettingsEventType,
+		"",
+		roomSettingsForPortal(portalMeta(portal)),
+		true,
+		nil,
+	)
+}
+
+// maybeSendRoomSettings republishes the room settings when a resync changed
+// them.
+func (oc *OpenClawClient) maybeSendRoomSettings(ctx context.Context, portal *bridgev2.Portal, previous *PortalMetadata) {
+	if oc == nil || portal == nil || portal.MXID == "" {
+		return
+	}
+	if roomSettingsForPortal(previous).equal(roomSettingsForPortal(portalMeta(portal))) {
+		return
+	}
+	oc.sendRoomSettings(ctx, portal)
+}
+
+func openClawModelRef(provider, model string) string {
+	provider, model = strings.TrimSpace(provider), strings.TrimSpace(model)
+	if provider == "" || model == "" || strings.HasPrefix(strings.ToLower(model), strings.ToLower(provider)+"/") {
+		return model
+	}
+	return provider + "/" + model
+}
+
+// findOpenClawModel resolves "provider/model", or a model ID or name that only
+// one provider offers.
+func findOpenClawModel(models []gatewayModelChoice, query string) (gatewayModelChoice, bool) {
+	query = strings.TrimSpace(query)
+	for _, model := range models {
+		if strings.EqualFold(openClawModelRef(model.Provider, model.ID), query) {
+			return model, true
+		}
+	}
+	var match *gatewayModelChoice
+	for i := range models {
+		if !gatewayModelMatches(models[i], query) {
+			continue
+		}
+		if match != nil {
+			return gatewayModelChoice{}, false
+		}
+		match = &models[i]
+	}
+	if match == nil {
+		return gatewayModelChoice{}, false
+	}
+	return *match, true
+}
+
+func findOpenClawTool(catalog *gatewayToolsCatalogResponse, name string) (gatewayToolCatalogEntry, bool) {
+	if catalog == nil {
+		return gatewayToolCatalogEntry{}, false
+	}
+	for _, group := range catalog.Groups {
+		for _, tool := range group.Tools {
+			if strings.EqualFold(strings.TrimSpace(tool.ID), name) || strings.EqualFold(strings.TrimSpace(tool.Label), name) {
+				return tool, true
+			}
+		}
+	}
+	return gatewayToolCatalogEntry{}, false
+}
+
+func containsOpenClawTool(list []string, tool string) bool {
+	tool = strings.TrimSpace(tool)
+	return tool != "" && slices.ContainsFunc(list, func(existing string) bool { return strings.EqualFold(existing, tool) })
+}
+
+// applyOpenClawToolOverride enables or disables tool on top of the current
+// allow and deny lists. A tool is only ever in one of them.
+func applyOpenClawToolOverride(allow, deny []string, tool string, enable bool) ([]string, []string) {
+	remove := func(list []string) []string {
+		return slices.DeleteFunc(slices.Clone(list), func(existing string) bool { return strings.EqualFold(existing, tool) })
+	}
+	allow, deny = remove(allow), remove(deny)
+	if enable {
+		allow = append(allow, tool)
+	} else {
+		deny = append(deny, tool)
+	}
+	slices.Sort(allow)
+	slices.Sort(deny)
+	return allow, deny
+}
+
+func formatOpenClawToolsList(catalog *gatewayToolsCatalogResponse, meta *PortalMetadata) string {
+	var sb strings.Builder
+	count, profile := 0, ""
+	if catalog != nil {
+		count, profile = summarizeToolsCatalog(*catalog)
+	}
+	if profile != "" {
+		fmt.Fprintf(&sb, "OpenClaw tools (%d, profile %s):", count, profile)
+	} else {
+		fmt.Fprintf(&sb, "OpenClaw tools (%d):", count)
+	}
+	if catalog != nil {
+		for _, group := range catalog.Groups {
+			if len(group.Tools) == 0 {
+				continue
+			}
+			label := stringsTrimDefault(group.Label, group.ID)
+			if label != "" {
+				fmt.Fprintf(&sb, "\n\n%s", label)
+			}
+			for _, tool := range group.Tools {
+				id := strings.TrimSpace(tool.ID)
+				fmt.Fprintf(&sb, "\n- %s", id)
+				switch {
+				case meta != nil && containsOpenClawTool(meta.OpenClawToolsAllow, id):
+					sb.WriteString(" (enabled)")
+				case meta != nil && containsOpenClawTool(meta.OpenClawToolsDeny, id):
+					sb.WriteString(" (disabled)")
+				case tool.Optional:
+					sb.WriteString(" (optional)")
+				}
+				if description := strings.TrimSpace(tool.Description); description != "" {
+					fmt.Fprintf(&sb, ": %s", description)
+				}
+			}
+		}
+	}
+	sb.WriteString("\n\nUse /tools enable <tool>, /tools disable <tool> or /tools reset.")
+	return sb.String()
+}
+
+func formatOpenClawModelsList(models []gatewayModelChoice, meta *PortalMetadata) string {
+	var sb strings.Builder
+	current := "gateway default"
+	if meta != nil {
+		current = stringsTrimDefault(openClawModelRef(meta.ModelProvider, meta.Model), current)
+	}
+	fmt.Fprintf(&sb, "OpenClaw model: %s\n\nAvailable models:", current)
+	for _, model := range models {
+		fmt.Fprintf(&sb, "\n- %s", openClawModelRef(model.Provider, model.ID))
+		if name := strings.TrimSpace(model.Name); name != "" && !strings.EqualFold(name, model.ID) {
+			fmt.Fprintf(&sb, " (%s)", name)
+		}
+	}
+	sb.WriteString("\n\nUse /model <provider/model> or /model default.")
+	return sb.String()
+}
+
+func formatOpenClawToolOverrides(allow, deny []string) string {
+	parts := make([]string, 0, len(allow)+len(deny))
+	for _, tool := range allow {
+		parts = append(parts, "+"+tool)
+	}
+	for _, tool := range deny {
+		parts = append(parts, "-"+tool)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,106 @@
+package openclaw
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFindOpenClawModel(t *testing.T) {
+	models := []gatewayModelChoice{
+		{ID: "gpt-5", Name: "GPT-5", Provider: "openai"},
+		{ID: "gpt-5", Name: "GPT-5", Provider: "azure"},
+		{ID: "claude-sonnet-4", Name: "Claude Sonnet 4", Provider: "anthropic"},
+	}
+	if model, ok := findOpenClawModel(models, "azure/gpt-5"); !ok || model.Provider != "azure" {
+		t.Fatalf("expected azure model, got %#v %v", model, ok)
+	}
+	if model, ok := findOpenClawModel(models, "Claude Sonnet 4"); !ok || model.ID != "claude-sonnet-4" {
+		t.Fatalf("expected lookup by name, got %#v %v", model, ok)
+	}
+	if _, ok := findOpenClawModel(models, "gpt-5"); ok {
+		t.Fatal("expected model offered by two providers to be ambiguous")
+	}
+	if _, ok := findOpenClawModel(models, "missing"); ok {
+		t.Fatal("expected unknown model to fail")
+	}
+}
+
+func TestApplyOpenClawToolOverride(t *testing.T) {
+	allow, deny := applyOpenClawToolOverride(nil, []string{"exec", "web_search"}, "web_search", true)
+	if !slices.Equal(allow, []string{"web_search"}) || !slices.Equal(deny, []string{"exec"}) {
+		t.Fatalf("unexpected overrides after enable: %v %v", allow, deny)
+	}
+	allow, deny = applyOpenClawToolOverride(allow, deny, "web_search", false)
+	if len(allow) != 0 || !slices.Equal(deny, []string{"exec", "web_search"}) {
+		t.Fatalf("unexpected overrides after disable: %v %v", allow, deny)
+	}
+	if got := formatOpenClawToolOverrides([]string{"browser"}, deny); got != "+browser, -exec, -web_search" {
+		t.Fatalf("unexpected override summary %q", got)
+	}
+}
+
+func TestOpenClawOverrideApproval(t *testing.T) {
+	meta := &PortalMetadata{OpenClawToolsAllow: []string{"web_search"}, OpenClawToolsDeny: []string{"exec"}}
+	if got := openClawOverrideApproval(meta, "EXEC"); got != "deny" {
+		t.Fatalf("expected disabled tool approvals to be denied, got %q", got)
+	}
+	if got := openClawOverrideApproval(meta, "web_search"); got != "allow-once" {
+		t.Fatalf("expected enabled tool approvals to be allowed, got %q", got)
+	}
+	if got := openClawOverrideApproval(meta, "browser"); got != "" {
+		t.Fatalf("expected tools without overrides to ask, got %q", got)
+	}
+	if got := openClawOverrideApproval(&PortalMetadata{}, "exec"); got != "" {
+		t.Fatalf("expected no decision without overrides, got %q", got)
+	}
+}
+
+func TestOpenClawRoomSettings(t *testing.T) {
+	meta := &PortalMetadata{
+		ModelProvider:     "openai",
+		Model:             "gpt-5",
+		ThinkingLevel:     "high",
+		OpenClawToolsDeny: []string{"exec"},
+	}
+	settings := roomSettingsForPortal(meta)
+	if settings.Model != "openai/gpt-5" || settings.ThinkingLevel != "high" || !slices.Equal(settings.ToolsDisabled, []string{"exec"}) {
+		t.Fatalf("unexpected room settings %#v", settings)
+	}
+	if !settings.equal(roomSettingsForPortal(meta)) {
+		t.Fatal("expected identical settings to be equal")
+	}
+	if settings.equal(roomSettingsForPortal(&PortalMetadata{ModelProvider: "openai", Model: "gpt-5"})) {
+		t.Fatal("expected changed settings to differ")
+	}
+	if got := openClawModelRef("openrouter", "openrouter/auto"); got != "openrouter/auto" {
+		t.Fatalf("expected provider prefix not to repeat, got %q", got)
+	}
+
+	topic := (&OpenClawClient{}).topicForPortal(meta)
+	if !strings.Contains(topic, "Thinking: high") || !strings.Contains(topic, "Tool overrides: -exec") {
+		t.Fatalf("expected settings in topic, got %q", topic)
+	}
+}
+
+func TestFormatOpenClawToolsList(t *testing.T) {
+	catalog := &gatewayToolsCatalogResponse{
+		Profiles: []gatewayToolCatalogProfile{{ID: "coding"}},
+		Groups: []gatewayToolCatalogGroup{{
+			Label: "Web",
+			Tools: []gatewayToolCatalogEntry{
+				{ID: "web_search", Description: "Search the web"},
+				{ID: "browser", Optional: true},
+			},
+		}},
+	}
+	list := formatOpenClawToolsList(catalog, &PortalMetadata{OpenClawToolsDeny: []string{"web_search"}})
+	for _, want := range []string{"OpenClaw tools (2, profile coding):", "- web_search (disabled): Search the web", "- browser (optional)"} {
+		if !strings.Contains(list, want) {
+			t.Fatalf("expected %q in tools list, got %q", want, list)
+		}
+	}
+	if tool, ok := findOpenClawTool(catalog, "BROWSER"); !ok || tool.ID != "browser" {
+		t.Fatalf("expected case-insensitive tool lookup, got %#v %v", tool, ok)
+	}
+}
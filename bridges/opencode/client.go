@@ -92,6 +92,7 @@ func (oc *OpenCodeClient) Connect(ctx context.Context) {
 	oc.UserLogin.BridgeState.Send(status.BridgeState{StateEvent: status.StateConnected, Message: "Connected"})
 	if oc.bridge != nil {
 		go func() {
+			oc.bridge.RestorePendingApprovals(oc.BackgroundContext(ctx))
 			if err := oc.bridge.RestoreConnections(oc.BackgroundContext(ctx)); err != nil {
 				oc.UserLogin.Log.Warn().Err(err).Msg("Failed to restore OpenCode connections")
 			}
@@ -0,0 +1,275 @@
+package opencode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"maunium.net/go/mautrix/bridgev2/commands"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/bridges/opencode/opencodebridge"
+	"github.com/beeper/agentremote/pkg/connector/commandregistry"
+)
+
+// HelpSectionOpenCode is the help section for OpenCode room commands.
+var HelpSectionOpenCode = commands.HelpSection{
+	Name:  "OpenCode",
+	Order: 30,
+}
+
+var openCodeCommandRegistry = commandregistry.NewRegistry()
+
+func registerOpenCodeCommand(def commandregistry.Definition) *commands.FullHandler {
+	return commandregistry.RegisterForPortalLogin[*OpenCodeClient](openCodeCommandRegistry, HelpSectionOpenCode, def)
+}
+
+func requireOpenCodeRoom(ce *commands.Event) (*OpenCodeClient, bool) {
+	client, _ := commandregistry.PortalLoginClient[*OpenCodeClient](ce)
+	meta := portalMeta(ce.Portal)
+	if client == nil || client.bridge == nil || !meta.IsOpenCodeRoom || meta.OpenCodeSessionID == "" {
+		commandregistry.ReplyFailure(ce, "That command only works in OpenCode session rooms.", event.MessageStatusUnsupported)
+		return nil, false
+	}
+	return client, true
+}
+
+// commandMessageID picks the OpenCode message a command targets: an explicit
+// message ID argument, or the message the command replies to.
+func commandMessageID(ce *commands.Event, client *OpenCodeClient) (string, error) {
+	if raw := strings.TrimSpace(ce.RawArgs); raw != "" {
+		return raw, nil
+	}
+	if ce.ReplyTo == "" {
+		return "", nil
+	}
+	return client.bridge.ResolveMessageID(ce.Ctx, ce.Portal, ce.ReplyTo)
+}
+
+var _ = registerOpenCodeCommand(commandregistry.Definition{
+	Name:        "fork",
+	Description: "Fork this session into a new room, up to the replied-to message or the given message ID",
+	Args:        "[message ID]",
+	Handler: func(ce *commands.Event) {
+		client, ok := requireOpenCodeRoom(ce)
+		if !ok {
+			return
+		}
+		messageID, err := commandMessageID(ce, client)
+		if err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to find the message to fork at: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		forked, err := client.bridge.ForkSessionChat(ce.Ctx, ce.Portal, messageID)
+		if err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to fork session: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		ce.Reply("Forked into %s", forked.MXID.URI().MatrixToURL())
+	},
+})
+
+var _ = registerOpenCodeCommand(commandregistry.Definition{
+	Name:        "revert",
+	Description: "Revert the session to before the replied-to message, the given message ID or the last prompt",
+	Args:        "[message ID]",
+	Handler: func(ce *commands.Event) {
+		client, ok := requireOpenCodeRoom(ce)
+		if !ok {
+			return
+		}
+		messageID, err := commandMessageID(ce, client)
+		if err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to find the message to revert to: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		count, err := client.bridge.RevertSession(ce.Ctx, ce.Portal, messageID)
+		if err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to revert session: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		ce.Reply("Reverted %d %s and their file changes. Send a message to continue from here, or use `$cmdprefix unrevert` to undo.", count, pluralMessages(count))
+	},
+})
+
+var _ = registerOpenCodeCommand(commandregistry.Definition{
+	Name:        "unrevert",
+	Description: "Undo the last revert in this session",
+	Handler: func(ce *commands.Event) {
+		client, ok := requireOpenCodeRoom(ce)
+		if !ok {
+			return
+		}
+		count, err := client.bridge.UnrevertSession(ce.Ctx, ce.Portal)
+		if err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to unrevert session: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		ce.Reply("Restored %d %s.", count, pluralMessages(count))
+	},
+})
+
+var _ = registerOpenCodeCommand(commandregistry.Definition{
+	Name:        "share",
+	Description: "Share this session and post its public URL",
+	Handler: func(ce *commands.Event) {
+		client, ok := requireOpenCodeRoom(ce)
+		if !ok {
+			return
+		}
+		shareURL, err := client.bridge.ShareSession(ce.Ctx, ce.Portal)
+		if err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to share session: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		ce.Reply("Session shared: %s", shareURL)
+	},
+})
+
+var _ = registerOpenCodeCommand(commandregistry.Definition{
+	Name:        "unshare",
+	Description: "Stop sharing this session",
+	Handler: func(ce *commands.Event) {
+		client, ok := requireOpenCodeRoom(ce)
+		if !ok {
+			return
+		}
+		if err := client.bridge.UnshareSession(ce.Ctx, ce.Portal); err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to unshare session: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		ce.Reply("Session is no longer shared.")
+	},
+})
+
+var _ = registerOpenCodeCommand(commandregistry.Definition{
+	Name:        "summarize",
+	Description: "Summarize the session history to free up context",
+	Handler: func(ce *commands.Event) {
+		client, ok := requireOpenCodeRoom(ce)
+		if !ok {
+			return
+		}
+		ce.Reply("Summarizing session…")
+		// Compaction runs a whole model turn, so don't hold up the command
+		// processor. Failures can only be reported as a reply by then.
+		ctx := ce.Log.WithContext(client.BackgroundContext(context.Background()))
+		portal := ce.Portal
+		go func() {
+			if err := client.bridge.SummarizeSession(ctx, portal); err != nil {
+				ce.Reply("Failed to summarize session: %s", err.Error())
+			}
+		}()
+	},
+})
+
+// commandListMax caps the choices listed by the model and agent commands.
+const commandListMax = 40
+
+var _ = registerOpenCodeCommand(commandregistry.Definition{
+	Name:        "model",
+	Description: "Show or change the model of this room's prompts",
+	Args:        "[provider/model|default]",
+	Handler: func(ce *commands.Event) {
+		client, ok := requireOpenCodeRoom(ce)
+		if !ok {
+			return
+		}
+		if len(ce.Args) == 0 {
+			current, _ := opencodebridge.RoomModelLabel(client.PortalMeta(ce.Portal))
+			models, err := client.bridge.ListModels(ce.Ctx, ce.Portal)
+			if err != nil {
+				ce.Reply("Model: `%s`\n\nFailed to list models: %s", current, err.Error())
+				return
+			}
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "Model: `%s`\n\nAvailable models:", current)
+			for i, model := range models {
+				if i == commandListMax {
+					fmt.Fprintf(&sb, "\n- …and %d more", len(models)-i)
+					break
+				}
+				fmt.Fprintf(&sb, "\n- `%s`", model.Ref())
+				if model.Name != model.ModelID {
+					fmt.Fprintf(&sb, " (%s)", model.Name)
+				}
+				if model.Default {
+					sb.WriteString(" – provider default")
+				}
+			}
+			sb.WriteString("\n\nUsage: `$cmdprefix model <provider/model|default>`")
+			ce.Reply("%s", sb.String())
+			return
+		}
+		ref := strings.TrimSpace(ce.RawArgs)
+		if strings.EqualFold(ref, "default") {
+			ref = ""
+		}
+		model, err := client.bridge.SetRoomModel(ce.Ctx, ce.Portal, ref)
+		if err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to set model: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		if model == "" {
+			ce.Reply("Model reset to the server default. It applies from the next prompt.")
+			return
+		}
+		ce.Reply("Model set to `%s`. It applies from the next prompt.", model)
+	},
+})
+
+var _ = registerOpenCodeCommand(commandregistry.Definition{
+	Name:        "agent",
+	Description: "Show or change the OpenCode agent of this room's prompts",
+	Args:        "[name|default]",
+	Handler: func(ce *commands.Event) {
+		client, ok := requireOpenCodeRoom(ce)
+		if !ok {
+			return
+		}
+		if len(ce.Args) == 0 {
+			_, current := opencodebridge.RoomModelLabel(client.PortalMeta(ce.Portal))
+			agents, err := client.bridge.ListAgents(ce.Ctx, ce.Portal)
+			if err != nil {
+				ce.Reply("Agent: `%s`\n\nFailed to list agents: %s", current, err.Error())
+				return
+			}
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "Agent: `%s`\n\nAvailable agents:", current)
+			for i, agent := range agents {
+				if i == commandListMax {
+					fmt.Fprintf(&sb, "\n- …and %d more", len(agents)-i)
+					break
+				}
+				fmt.Fprintf(&sb, "\n- `%s`", agent.Name)
+				if agent.Description != "" {
+					fmt.Fprintf(&sb, " – %s", agent.Description)
+				}
+			}
+			sb.WriteString("\n\nUsage: `$cmdprefix agent <name|default>`")
+			ce.Reply("%s", sb.String())
+			return
+		}
+		name := strings.TrimSpace(ce.RawArgs)
+		if strings.EqualFold(name, "default") {
+			name = ""
+		}
+		agent, err := client.bridge.SetRoomAgent(ce.Ctx, ce.Portal, name)
+		if err != nil {
+			commandregistry.ReplyFailure(ce, "Failed to set agent: "+err.Error(), event.MessageStatusGenericError)
+			return
+		}
+		if agent == "" {
+			ce.Reply("Agent reset to the server default. It applies from the next prompt.")
+			return
+		}
+		ce.Reply("Agent set to `%s`. It applies from the next prompt.", agent)
+	},
+})
+
+func pluralMessages(count int) string {
+	if count == 1 {
+		return "message"
+	}
+	return "messages"
+}
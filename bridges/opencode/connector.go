@@ -7,11 +7,14 @@ import (
 	"sync"
 
 	"go.mau.fi/util/configupgrade"
+	"go.mau.fi/util/dbutil"
 	"go.mau.fi/util/ptr"
 	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/commands"
 	"maunium.net/go/mautrix/bridgev2/database"
 	"maunium.net/go/mautrix/bridgev2/networkid"
 
+	"github.com/beeper/agentremote/pkg/aidb"
 	"github.com/beeper/agentremote/pkg/bridgeadapter"
 )
 
@@ -23,6 +26,7 @@ var (
 type OpenCodeConnector struct {
 	bridgeadapter.BaseConnectorMethods
 	br     *bridgev2.Bridge
+	db     *dbutil.Database
 	Config Config
 
 	clientsMu sync.Mutex
@@ -40,16 +44,38 @@ func (oc *OpenCodeConnector) Init(bridge *bridgev2.Bridge) {
 	bridgeadapter.EnsureClientMap(&oc.clientsMu, &oc.clients)
 }
 
-func (oc *OpenCodeConnector) Start(_ context.Context) error {
+func (oc *OpenCodeConnector) Start(ctx context.Context) error {
+	// The shared AI bridge schema holds pending approvals.
+	if err := aidb.Upgrade(ctx, oc.bridgeDB(), "opencode_bridge", "opencode bridge database not initialized"); err != nil {
+		return err
+	}
 	if oc.Config.Bridge.CommandPrefix == "" {
 		oc.Config.Bridge.CommandPrefix = "!opencode"
 	}
 	if oc.Config.OpenCode.Enabled == nil {
 		oc.Config.OpenCode.Enabled = ptr.Ptr(true)
 	}
+	if proc, ok := oc.br.Commands.(*commands.Processor); ok {
+		openCodeCommandRegistry.AddTo(proc)
+	} else {
+		oc.br.Log.Warn().Type("commands_type", oc.br.Commands).Msg("Failed to register OpenCode commands: command processor type assertion failed")
+	}
+	if !oc.registerRoomSettingsHandler() {
+		oc.br.Log.Warn().Msg("Failed to register OpenCode room settings handler: Matrix connector type assertion failed")
+	}
 	return nil
 }
 
+func (oc *OpenCodeConnector) bridgeDB() *dbutil.Database {
+	if oc.db == nil && oc.br != nil && oc.br.DB != nil {
+		oc.db = aidb.NewChild(
+			oc.br.DB.Database,
+			dbutil.ZeroLogger(oc.br.Log.With().Str("db_section", "opencode_bridge").Logger()),
+		)
+	}
+	return oc.db
+}
+
 func (oc *OpenCodeConnector) Stop(_ context.Context) {
 	bridgeadapter.StopClients(&oc.clientsMu, &oc.clients)
 }
@@ -3,10 +3,12 @@ package opencode
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
+	"maunium.net/go/mautrix"
 	"maunium.net/go/mautrix/bridgev2"
 	"maunium.net/go/mautrix/bridgev2/networkid"
 	"maunium.net/go/mautrix/event"
@@ -318,6 +320,60 @@ func (oc *OpenCodeClient) SetRoomName(_ context.Context, _ *bridgev2.Portal, _ s
 	return nil
 }
 
+// RoomTopic returns the room's current topic, which may have been changed in
+// Matrix since the portal was saved.
+func (oc *OpenCodeClient) RoomTopic(ctx context.Context, portal *bridgev2.Portal) (string, error) {
+	if portal == nil || portal.MXID == "" {
+		return "", errors.New("portal has no Matrix room ID")
+	}
+	stateConn, ok := oc.UserLogin.Bridge.Matrix.(bridgev2.MatrixConnectorWithArbitraryRoomState)
+	if !ok {
+		return portal.Topic, nil
+	}
+	evt, err := stateConn.GetStateEvent(ctx, portal.MXID, event.StateTopic, "")
+	if errors.Is(err, mautrix.MNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get room topic: %w", err)
+	}
+	if evt == nil {
+		return "", nil
+	}
+	return evt.Content.AsTopic().Topic, nil
+}
+
+func (oc *OpenCodeClient) SetRoomTopic(ctx context.Context, portal *bridgev2.Portal, topic string) error {
+	if portal == nil || portal.MXID == "" {
+		return errors.New("portal has no Matrix room ID")
+	}
+	if portal.Topic == topic {
+		return nil
+	}
+	_, err := oc.UserLogin.Bridge.Bot.SendState(ctx, portal.MXID, event.StateTopic, "", &event.Content{
+		Parsed: &event.TopicEventContent{Topic: topic},
+	}, time.Time{})
+	if err != nil {
+		return fmt.Errorf("failed to set room topic: %w", err)
+	}
+	portal.Topic = topic
+	return portal.Save(ctx)
+}
+
+// SendRoomSettings writes the room settings state event from the bridge bot.
+// The settings handler ignores it since only the room owner's events apply.
+func (oc *OpenCodeClient) SendRoomSettings(ctx context.Context, portal *bridgev2.Portal, settings opencodebridge.RoomSettings) error {
+	if portal == nil || portal.MXID == "" {
+		return errors.New("portal has no Matrix room ID")
+	}
+	_, err := oc.UserLogin.Bridge.Bot.SendState(ctx, portal.MXID, matrixevents.RoomSettingsEventType, "", &event.Content{
+		Parsed: &settings,
+	}, time.Time{})
+	if err != nil {
+		return fmt.Errorf("failed to send room settings: %w", err)
+	}
+	return nil
+}
+
 func (oc *OpenCodeClient) SenderForOpenCode(instanceID string, fromMe bool) bridgev2.EventSender {
 	if fromMe {
 		return bridgev2.EventSender{Sender: humanUserID(oc.UserLogin.ID), SenderLogin: oc.UserLogin.ID, IsFromMe: true}
@@ -360,6 +416,10 @@ func (oc *OpenCodeClient) PortalMeta(portal *bridgev2.Portal) *opencodebridge.Po
 		AgentID:        meta.AgentID,
 		VerboseLevel:   meta.VerboseLevel,
 		AwaitingPath:   meta.OpenCodeAwaitingPath,
+		ProviderID:     meta.OpenCodeProviderID,
+		ModelID:        meta.OpenCodeModelID,
+		Agent:          meta.OpenCodeAgent,
+		SettingsTopic:  meta.OpenCodeSettingsTopic,
 	}
 }
 
@@ -378,6 +438,10 @@ func (oc *OpenCodeClient) SetPortalMeta(portal *bridgev2.Portal, meta *opencodeb
 	existing.AgentID = meta.AgentID
 	existing.VerboseLevel = meta.VerboseLevel
 	existing.OpenCodeAwaitingPath = meta.AwaitingPath
+	existing.OpenCodeProviderID = meta.ProviderID
+	existing.OpenCodeModelID = meta.ModelID
+	existing.OpenCodeAgent = meta.Agent
+	existing.OpenCodeSettingsTopic = meta.SettingsTopic
 	portal.Metadata = existing
 }
 
@@ -25,6 +25,11 @@ type PortalMetadata struct {
 	OpenCodeAwaitingPath bool   `json:"opencode_awaiting_path,omitempty"`
 	AgentID              string `json:"agent_id,omitempty"`
 	VerboseLevel         string `json:"verbose_level,omitempty"`
+	OpenCodeProviderID   string `json:"opencode_provider_id,omitempty"`
+	OpenCodeModelID      string `json:"opencode_model_id,omitempty"`
+	OpenCodeAgent        string `json:"opencode_agent,omitempty"`
+	// OpenCodeSettingsTopic is the topic last set from the room settings.
+	OpenCodeSettingsTopic string `json:"opencode_settings_topic,omitempty"`
 }
 
 type MessageMetadata = opencodebridge.MessageMetadata
@@ -118,6 +118,32 @@ func (c *Client) do(req *http.Request, out any) error {
 	return decoder.Decode(out)
 }
 
+// ListProviders returns the providers and models configured on the server.
+func (c *Client) ListProviders(ctx context.Context) (*ProviderList, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/config/providers", nil)
+	if err != nil {
+		return nil, err
+	}
+	var providers ProviderList
+	if err := c.do(req, &providers); err != nil {
+		return nil, err
+	}
+	return &providers, nil
+}
+
+// ListAgents returns the agents configured on the server.
+func (c *Client) ListAgents(ctx context.Context) ([]Agent, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/agent", nil)
+	if err != nil {
+		return nil, err
+	}
+	var agents []Agent
+	if err := c.do(req, &agents); err != nil {
+		return nil, err
+	}
+	return agents, nil
+}
+
 // ListSessions returns all sessions from the server.
 func (c *Client) ListSessions(ctx context.Context) ([]Session, error) {
 	req, err := c.newRequest(ctx, http.MethodGet, "/session", nil)
@@ -151,6 +177,22 @@ func (c *Client) CreateSession(ctx context.Context, title, directory string) (*S
 	return &session, nil
 }
 
+// GetSession fetches a single session.
+func (c *Client) GetSession(ctx context.Context, sessionID string) (*Session, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, errors.New("session id is required")
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, "/session/"+url.PathEscape(sessionID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := c.do(req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
 // DeleteSession deletes an OpenCode session.
 func (c *Client) DeleteSession(ctx context.Context, sessionID string) error {
 	if strings.TrimSpace(sessionID) == "" {
@@ -221,9 +263,93 @@ func (c *Client) ListMessages(ctx context.Context, sessionID string, limit int)
 	return messages, nil
 }
 
+// ForkSession creates a new session with the history of sessionID up to (but
+// not including) messageID. An empty messageID copies the whole session.
+func (c *Client) ForkSession(ctx context.Context, sessionID, messageID string) (*Session, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, errors.New("session id is required")
+	}
+	payload := map[string]any{}
+	if strings.TrimSpace(messageID) != "" {
+		payload["messageID"] = strings.TrimSpace(messageID)
+	}
+	return c.sessionAction(ctx, http.MethodPost, sessionID, "fork", payload)
+}
+
+// RevertSession reverts a session to before messageID (or partID within it),
+// undoing the file changes made since.
+func (c *Client) RevertSession(ctx context.Context, sessionID, messageID, partID string) (*Session, error) {
+	if strings.TrimSpace(sessionID) == "" || strings.TrimSpace(messageID) == "" {
+		return nil, errors.New("session id and message id are required")
+	}
+	payload := map[string]any{"messageID": strings.TrimSpace(messageID)}
+	if strings.TrimSpace(partID) != "" {
+		payload["partID"] = strings.TrimSpace(partID)
+	}
+	return c.sessionAction(ctx, http.MethodPost, sessionID, "revert", payload)
+}
+
+// UnrevertSession restores the messages hidden by the last revert.
+func (c *Client) UnrevertSession(ctx context.Context, sessionID string) (*Session, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, errors.New("session id is required")
+	}
+	return c.sessionAction(ctx, http.MethodPost, sessionID, "unrevert", nil)
+}
+
+// ShareSession publishes a session. The share URL is in Session.Share.
+func (c *Client) ShareSession(ctx context.Context, sessionID string) (*Session, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, errors.New("session id is required")
+	}
+	return c.sessionAction(ctx, http.MethodPost, sessionID, "share", nil)
+}
+
+// UnshareSession removes the public share of a session.
+func (c *Client) UnshareSession(ctx context.Context, sessionID string) (*Session, error) {
+	if strings.TrimSpace(sessionID) == "" {
+		return nil, errors.New("session id is required")
+	}
+	return c.sessionAction(ctx, http.MethodDelete, sessionID, "share", nil)
+}
+
+// SummarizeSession compacts a session's history into a summary using the
+// given model.
+func (c *Client) SummarizeSession(ctx context.Context, sessionID, providerID, modelID string) error {
+	if strings.TrimSpace(sessionID) == "" {
+		return errors.New("session id is required")
+	}
+	if strings.TrimSpace(providerID) == "" || strings.TrimSpace(modelID) == "" {
+		return errors.New("provider id and model id are required")
+	}
+	payload := map[string]any{
+		"providerID": strings.TrimSpace(providerID),
+		"modelID":    strings.TrimSpace(modelID),
+	}
+	path := fmt.Sprintf("/session/%s/summarize", url.PathEscape(sessionID))
+	req, err := c.newRequest(ctx, http.MethodPost, path, payload)
+	if err != nil {
+		return err
+	}
+	return c.do(req, nil)
+}
+
+func (c *Client) sessionAction(ctx context.Context, method, sessionID, action string, payload any) (*Session, error) {
+	path := fmt.Sprintf("/session/%s/%s", url.PathEscape(sessionID), action)
+	req, err := c.newRequest(ctx, method, path, payload)
+	if err != nil {
+		return nil, err
+	}
+	var session Session
+	if err := c.do(req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
 // SendMessageAsync sends a message to a session asynchronously. The server
 // returns 204 immediately; the assistant response is delivered via SSE.
-func (c *Client) SendMessageAsync(ctx context.Context, sessionID, messageID string, parts []PartInput) error {
+func (c *Client) SendMessageAsync(ctx context.Context, sessionID, messageID string, parts []PartInput, opts PromptOptions) error {
 	if strings.TrimSpace(sessionID) == "" {
 		return errors.New("session id is required")
 	}
@@ -236,6 +362,12 @@ func (c *Client) SendMessageAsync(ctx context.Context, sessionID, messageID stri
 	if strings.TrimSpace(messageID) != "" {
 		payload["messageID"] = strings.TrimSpace(messageID)
 	}
+	if opts.Model != nil && opts.Model.ProviderID != "" && opts.Model.ModelID != "" {
+		payload["model"] = opts.Model
+	}
+	if strings.TrimSpace(opts.Agent) != "" {
+		payload["agent"] = strings.TrimSpace(opts.Agent)
+	}
 	path := fmt.Sprintf("/session/%s/prompt_async", url.PathEscape(sessionID))
 	req, err := c.newRequest(ctx, http.MethodPost, path, payload)
 	if err != nil {
@@ -27,14 +27,31 @@ func (t *Timestamp) UnmarshalJSON(data []byte) error {
 
 // Session represents an OpenCode session summary.
 type Session struct {
-	ID        string      `json:"id"`
-	Slug      string      `json:"slug"`
-	ProjectID string      `json:"projectID"`
-	Directory string      `json:"directory"`
-	ParentID  string      `json:"parentID,omitempty"`
-	Title     string      `json:"title"`
-	Version   string      `json:"version"`
-	Time      SessionTime `json:"time"`
+	ID        string         `json:"id"`
+	Slug      string         `json:"slug"`
+	ProjectID string         `json:"projectID"`
+	Directory string         `json:"directory"`
+	ParentID  string         `json:"parentID,omitempty"`
+	Title     string         `json:"title"`
+	Version   string         `json:"version"`
+	Time      SessionTime    `json:"time"`
+	Share     *SessionShare  `json:"share,omitempty"`
+	Revert    *SessionRevert `json:"revert,omitempty"`
+}
+
+// SessionShare holds the public URL of a shared session.
+type SessionShare struct {
+	URL string `json:"url"`
+}
+
+// SessionRevert marks the point a session has been reverted to. Messages from
+// MessageID onwards are hidden until the session is unreverted, and are
+// dropped once a new prompt is sent.
+type SessionRevert struct {
+	MessageID string `json:"messageID"`
+	PartID    string `json:"partID,omitempty"`
+	Snapshot  string `json:"snapshot,omitempty"`
+	Diff      string `json:"diff,omitempty"`
 }
 
 // SessionTime holds session timing metadata.
@@ -161,6 +178,41 @@ type PartInput struct {
 	Command     string    `json:"command,omitempty"`
 }
 
+// PromptOptions overrides the session defaults for a single prompt.
+type PromptOptions struct {
+	Model *ModelRef
+	Agent string
+}
+
+// Provider is a model provider configured on the server.
+type Provider struct {
+	ID     string                   `json:"id"`
+	Name   string                   `json:"name"`
+	Models map[string]ProviderModel `json:"models"`
+}
+
+// ProviderModel is a model offered by a provider.
+type ProviderModel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ProviderList is the server's provider catalog. Default maps provider IDs to
+// their default model ID.
+type ProviderList struct {
+	Providers []Provider        `json:"providers"`
+	Default   map[string]string `json:"default"`
+}
+
+// Agent is an agent configured on the server.
+type Agent struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Mode        string `json:"mode,omitempty"` // "primary", "subagent" or "all"
+	BuiltIn     bool   `json:"builtIn,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
+}
+
 // MessageWithParts bundles a message info block with its parts.
 type MessageWithParts struct {
 	Info  Message `json:"info"`
@@ -8,6 +8,7 @@ import (
 	"maunium.net/go/mautrix/bridgev2/networkid"
 	"maunium.net/go/mautrix/event"
 
+	"github.com/beeper/agentremote/bridges/opencode/opencode"
 	"github.com/beeper/agentremote/pkg/bridgeadapter"
 )
 
@@ -22,6 +23,9 @@ type Host interface {
 	FinishOpenCodeStream(turnID string)
 	DownloadAndEncodeMedia(ctx context.Context, mediaURL string, file *event.EncryptedFileInfo, maxMB int) (string, string, error)
 	SetRoomName(ctx context.Context, portal *bridgev2.Portal, name string) error
+	RoomTopic(ctx context.Context, portal *bridgev2.Portal) (string, error)
+	SetRoomTopic(ctx context.Context, portal *bridgev2.Portal, topic string) error
+	SendRoomSettings(ctx context.Context, portal *bridgev2.Portal, settings RoomSettings) error
 	SenderForOpenCode(instanceID string, fromMe bool) bridgev2.EventSender
 	CleanupPortal(ctx context.Context, portal *bridgev2.Portal, reason string)
 	PortalMeta(portal *bridgev2.Portal) *PortalMeta
@@ -47,6 +51,25 @@ type PortalMeta struct {
 	AgentID        string
 	VerboseLevel   string
 	AwaitingPath   bool
+	// ProviderID and ModelID pick the model of the room's prompts, and Agent
+	// the OpenCode agent. Empty values use the server defaults.
+	ProviderID string
+	ModelID    string
+	Agent      string
+	// SettingsTopic is the topic the bridge last set from the settings. The
+	// topic is only updated while the room still shows it.
+	SettingsTopic string
+}
+
+func (meta *PortalMeta) promptOptions() opencode.PromptOptions {
+	if meta == nil {
+		return opencode.PromptOptions{}
+	}
+	opts := opencode.PromptOptions{Agent: meta.Agent}
+	if meta.ProviderID != "" && meta.ModelID != "" {
+		opts.Model = &opencode.ModelRef{ProviderID: meta.ProviderID, ModelID: meta.ModelID}
+	}
+	return opts
 }
 
 // OpenCodeInstance stores connection details for an OpenCode server.
@@ -96,6 +119,15 @@ func (b *Bridge) ApprovalHandler() bridgeadapter.ApprovalReactionHandler {
 	return b.manager.approvalFlow
 }
 
+// RestorePendingApprovals brings back the permission prompts that were still
+// unanswered when the bridge stopped.
+func (b *Bridge) RestorePendingApprovals(ctx context.Context) {
+	if b == nil || b.manager == nil {
+		return
+	}
+	b.manager.approvalFlow.RestorePending(ctx)
+}
+
 func (b *Bridge) RestoreConnections(ctx context.Context) error {
 	if b == nil || b.manager == nil {
 		return nil
@@ -44,6 +44,7 @@ type queuedUserMessage struct {
 	sessionID string
 	eventID   id.EventID
 	parts     []opencode.PartInput
+	opts      opencode.PromptOptions
 }
 
 type openCodeSessionQueue struct {
@@ -420,7 +420,7 @@ func (m *OpenCodeManager) requireConnectedInstance(instanceID string) (*openCode
 	return inst, nil
 }
 
-func (m *OpenCodeManager) SendMessage(ctx context.Context, instanceID, sessionID string, parts []opencode.PartInput, eventID id.EventID) error {
+func (m *OpenCodeManager) SendMessage(ctx context.Context, instanceID, sessionID string, parts []opencode.PartInput, opts opencode.PromptOptions, eventID id.EventID) error {
 	inst, err := m.requireConnectedInstance(instanceID)
 	if err != nil {
 		return err
@@ -442,6 +442,7 @@ func (m *OpenCodeManager) SendMessage(ctx context.Context, instanceID, sessionID
 		sessionID: sessionID,
 		eventID:   eventID,
 		parts:     parts,
+		opts:      opts,
 	}
 	toSend := inst.enqueueMessage(sessionID, item)
 	if toSend == nil {
@@ -455,7 +456,7 @@ func (m *OpenCodeManager) sendQueuedMessage(ctx context.Context, inst *openCodeI
 		return nil
 	}
 	msgID := opencodeMessageIDForEvent(item.eventID)
-	if err := inst.client.SendMessageAsync(ctx, item.sessionID, msgID, item.parts); err != nil {
+	if err := inst.client.SendMessageAsync(ctx, item.sessionID, msgID, item.parts, item.opts); err != nil {
 		inst.requeueMessageFront(item.sessionID, item)
 		inst.releaseActiveSession(item.sessionID)
 		if opencode.IsAuthError(err) {
@@ -543,6 +544,157 @@ func (m *OpenCodeManager) UpdateSessionTitle(ctx context.Context, instanceID, se
 	})
 }
 
+func (m *OpenCodeManager) ForkSession(ctx context.Context, instanceID, sessionID, messageID string) (*opencode.Session, error) {
+	return m.runSessionMutation(ctx, instanceID, "fork session", func(inst *openCodeInstance) (*opencode.Session, error) {
+		return inst.client.ForkSession(ctx, sessionID, messageID)
+	})
+}
+
+func (m *OpenCodeManager) ShareSession(ctx context.Context, instanceID, sessionID string) (*opencode.Session, error) {
+	return m.runSessionMutation(ctx, instanceID, "share session", func(inst *openCodeInstance) (*opencode.Session, error) {
+		return inst.client.ShareSession(ctx, sessionID)
+	})
+}
+
+func (m *OpenCodeManager) UnshareSession(ctx context.Context, instanceID, sessionID string) (*opencode.Session, error) {
+	return m.runSessionMutation(ctx, instanceID, "unshare session", func(inst *openCodeInstance) (*opencode.Session, error) {
+		return inst.client.UnshareSession(ctx, sessionID)
+	})
+}
+
+// RevertSession reverts a session to before messageID, or to before the last
+// prompt when messageID is empty, and removes the reverted replies from the
+// room. It returns the updated session and the reverted messages.
+func (m *OpenCodeManager) RevertSession(ctx context.Context, instanceID, sessionID, messageID string) (*opencode.Session, []opencode.MessageWithParts, error) {
+	inst, err := m.requireConnectedInstance(instanceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	messages, err := inst.client.ListMessages(ctx, sessionID, 0)
+	if err != nil {
+		if opencode.IsAuthError(err) {
+			m.setConnected(inst, false)
+		}
+		return nil, nil, fmt.Errorf("list messages: %w", err)
+	}
+	if messageID == "" {
+		messageID = lastOpenCodeUserMessageID(messages)
+		if messageID == "" {
+			return nil, nil, errors.New("nothing to revert")
+		}
+	}
+	reverted := openCodeMessagesFrom(messages, messageID)
+	if len(reverted) == 0 {
+		return nil, nil, fmt.Errorf("message %s not found in session", messageID)
+	}
+	session, err := m.runSessionMutation(ctx, instanceID, "revert session", func(inst *openCodeInstance) (*opencode.Session, error) {
+		return inst.client.RevertSession(ctx, sessionID, messageID, "")
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, msg := range reverted {
+		if msg.Info.Role != "user" {
+			m.handleMessageRemoved(ctx, inst, sessionID, msg.Info.ID)
+		}
+	}
+	return session, reverted, nil
+}
+
+// UnrevertSession undoes the last revert of a session and posts the restored
+// replies to the room again. It returns the restored messages.
+func (m *OpenCodeManager) UnrevertSession(ctx context.Context, instanceID, sessionID string) ([]opencode.MessageWithParts, error) {
+	inst, err := m.requireConnectedInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := inst.client.GetSession(ctx, sessionID)
+	if err != nil {
+		if opencode.IsAuthError(err) {
+			m.setConnected(inst, false)
+		}
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	if current.Revert == nil || current.Revert.MessageID == "" {
+		return nil, errors.New("session isn't reverted")
+	}
+	if _, err = m.runSessionMutation(ctx, instanceID, "unrevert session", func(inst *openCodeInstance) (*opencode.Session, error) {
+		return inst.client.UnrevertSession(ctx, sessionID)
+	}); err != nil {
+		return nil, err
+	}
+	messages, err := inst.client.ListMessages(ctx, sessionID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list messages: %w", err)
+	}
+	restored := openCodeMessagesFrom(messages, current.Revert.MessageID)
+	inst.upsertMessages(sessionID, restored)
+	if portal := m.bridge.findOpenCodePortal(ctx, instanceID, sessionID); portal != nil {
+		m.bridge.restoreOpenCodeMessages(ctx, portal, instanceID, restored)
+	}
+	return restored, nil
+}
+
+// SummarizeSession compacts a session with the given model, or with the model
+// of its latest reply when model is nil. The summary arrives as a regular
+// assistant message over the event stream.
+func (m *OpenCodeManager) SummarizeSession(ctx context.Context, instanceID, sessionID string, model *opencode.ModelRef) error {
+	inst, err := m.requireConnectedInstance(instanceID)
+	if err != nil {
+		return err
+	}
+	if model == nil {
+		messages, listErr := inst.client.ListMessages(ctx, sessionID, 0)
+		if listErr != nil {
+			err = listErr
+		} else if providerID, modelID := lastOpenCodeModel(messages); providerID != "" && modelID != "" {
+			model = &opencode.ModelRef{ProviderID: providerID, ModelID: modelID}
+		} else {
+			return errors.New("the session has no replies to take the model from yet")
+		}
+	}
+	if err == nil {
+		err = inst.client.SummarizeSession(ctx, sessionID, model.ProviderID, model.ModelID)
+	}
+	if err != nil {
+		if opencode.IsAuthError(err) {
+			m.setConnected(inst, false)
+		}
+		return fmt.Errorf("summarize session: %w", err)
+	}
+	return nil
+}
+
+func (m *OpenCodeManager) ListProviders(ctx context.Context, instanceID string) (*opencode.ProviderList, error) {
+	inst, err := m.requireConnectedInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	providers, err := inst.client.ListProviders(ctx)
+	if err != nil {
+		if opencode.IsAuthError(err) {
+			m.setConnected(inst, false)
+		}
+		return nil, fmt.Errorf("list providers: %w", err)
+	}
+	return providers, nil
+}
+
+func (m *OpenCodeManager) ListAgents(ctx context.Context, instanceID string) ([]opencode.Agent, error) {
+	inst, err := m.requireConnectedInstance(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	agents, err := inst.client.ListAgents(ctx)
+	if err != nil {
+		if opencode.IsAuthError(err) {
+			m.setConnected(inst, false)
+		}
+		return nil, fmt.Errorf("list agents: %w", err)
+	}
+	return agents, nil
+}
+
 func (m *OpenCodeManager) syncSessions(ctx context.Context, inst *openCodeInstance, sessions []opencode.Session) (int, error) {
 	count := 0
 	for _, session := range sessions {
@@ -28,7 +28,13 @@ func (b *Bridge) buildOpenCodeFileContent(ctx context.Context, portal *bridgev2.
 	if fileURL == "" {
 		return nil, errors.New("missing file URL")
 	}
-	data, mimeType, err := downloadOpenCodeFile(ctx, fileURL, part.Mime, openCodeMaxMediaMB)
+	var serverURL string
+	if meta := b.portalMeta(portal); meta != nil && b.manager != nil {
+		if inst := b.manager.getInstance(meta.InstanceID); inst != nil {
+			serverURL = inst.cfg.URL
+		}
+	}
+	data, mimeType, err := downloadOpenCodeFile(ctx, fileURL, part.Mime, openCodeMaxMediaMB, serverURL)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +75,10 @@ func (b *Bridge) buildOpenCodeFileContent(ctx context.Context, portal *bridgev2.
 	return content, nil
 }
 
-func downloadOpenCodeFile(ctx context.Context, fileURL, fallbackMime string, maxSizeMB int) ([]byte, string, error) {
+// downloadOpenCodeFile loads a file part. HTTP URLs go through the safe
+// download client, which may also reach the host of serverURL since OpenCode
+// usually serves files from localhost.
+func downloadOpenCodeFile(ctx context.Context, fileURL, fallbackMime string, maxSizeMB int, serverURL string) ([]byte, string, error) {
 	fileURL = strings.TrimSpace(fileURL)
 	if fileURL == "" {
 		return nil, "", errors.New("missing file URL")
@@ -122,7 +131,11 @@ func downloadOpenCodeFile(ctx context.Context, fileURL, fallbackMime string, max
 		return data, mimeType, nil
 	}
 
-	return media.DownloadURL(ctx, fileURL, fallbackMime, maxBytes)
+	var allowed []string
+	if parsed, err := url.Parse(serverURL); err == nil && parsed.Hostname() != "" {
+		allowed = append(allowed, parsed.Hostname())
+	}
+	return media.DownloadURLWithClient(ctx, media.DownloadClientFor(allowed...), fileURL, fallbackMime, maxBytes)
 }
 
 func filenameFromOpenCodeURL(raw string) string {
@@ -56,7 +56,7 @@ func (b *Bridge) HandleMatrixMessage(ctx context.Context, msg *bridgev2.MatrixMe
 
 	runCtx := b.host.BackgroundContext(ctx)
 	go func() {
-		if err := b.manager.SendMessage(runCtx, meta.InstanceID, meta.SessionID, parts, msg.Event.ID); err != nil {
+		if err := b.manager.SendMessage(runCtx, meta.InstanceID, meta.SessionID, parts, meta.promptOptions(), msg.Event.ID); err != nil {
 			b.host.SendSystemNotice(runCtx, portal, "OpenCode send failed: "+err.Error())
 			return
 		}
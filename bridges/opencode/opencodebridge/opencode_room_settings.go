@@ -0,0 +1,279 @@
+package opencodebridge
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"maunium.net/go/mautrix/bridgev2"
+
+	"github.com/beeper/agentremote/bridges/opencode/opencode"
+)
+
+// ModelOption is a model that can be picked for a room.
+type ModelOption struct {
+	ProviderID string
+	ModelID    string
+	Name       string
+	// Default is set for the provider's default model.
+	Default bool
+}
+
+// Ref returns the "provider/model" form used in commands and room settings.
+func (o ModelOption) Ref() string {
+	return o.ProviderID + "/" + o.ModelID
+}
+
+// RoomSettings is the content of the room settings state event for OpenCode
+// rooms. Empty values reset to the server defaults.
+type RoomSettings struct {
+	Model string `json:"model,omitempty"`
+	Agent string `json:"agent,omitempty"`
+}
+
+// ListModels returns the models offered by the room's OpenCode server.
+func (b *Bridge) ListModels(ctx context.Context, portal *bridgev2.Portal) ([]ModelOption, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return nil, err
+	}
+	providers, err := b.manager.ListProviders(ctx, meta.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+	return openCodeModelOptions(providers), nil
+}
+
+// ListAgents returns the agents of the room's OpenCode server that can drive
+// a session, skipping subagents and hidden agents.
+func (b *Bridge) ListAgents(ctx context.Context, portal *bridgev2.Portal) ([]opencode.Agent, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return nil, err
+	}
+	agents, err := b.manager.ListAgents(ctx, meta.InstanceID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(agents, func(agent opencode.Agent) bool {
+		return agent.Hidden || agent.Mode == "subagent" || strings.TrimSpace(agent.Name) == ""
+	}), nil
+}
+
+// SetRoomModel sets the model of the room's prompts. An empty ref resets it
+// to the server default. It returns the normalized "provider/model" ref.
+func (b *Bridge) SetRoomModel(ctx context.Context, portal *bridgev2.Portal, ref string) (string, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return "", err
+	}
+	option, err := b.resolveRoomModel(ctx, portal, ref)
+	if err != nil {
+		return "", err
+	}
+	meta.ProviderID, meta.ModelID = option.ProviderID, option.ModelID
+	if err = b.saveRoomSettings(ctx, portal, meta, true); err != nil {
+		return "", err
+	}
+	if option.ModelID == "" {
+		return "", nil
+	}
+	return option.Ref(), nil
+}
+
+// SetRoomAgent sets the OpenCode agent of the room's prompts. An empty name
+// resets it to the server default.
+func (b *Bridge) SetRoomAgent(ctx context.Context, portal *bridgev2.Portal, name string) (string, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return "", err
+	}
+	agentName, err := b.resolveRoomAgent(ctx, portal, name)
+	if err != nil {
+		return "", err
+	}
+	meta.Agent = agentName
+	if err = b.saveRoomSettings(ctx, portal, meta, true); err != nil {
+		return "", err
+	}
+	return agentName, nil
+}
+
+// ApplyRoomSettings applies a room settings state event. Both values are
+// validated before either is saved.
+func (b *Bridge) ApplyRoomSettings(ctx context.Context, portal *bridgev2.Portal, settings RoomSettings) error {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return err
+	}
+	option, err := b.resolveRoomModel(ctx, portal, settings.Model)
+	if err != nil {
+		return err
+	}
+	agentName, err := b.resolveRoomAgent(ctx, portal, settings.Agent)
+	if err != nil {
+		return err
+	}
+	if meta.ProviderID == option.ProviderID && meta.ModelID == option.ModelID && meta.Agent == agentName {
+		return nil
+	}
+	meta.ProviderID, meta.ModelID, meta.Agent = option.ProviderID, option.ModelID, agentName
+	return b.saveRoomSettings(ctx, portal, meta, false)
+}
+
+func (b *Bridge) resolveRoomModel(ctx context.Context, portal *bridgev2.Portal, ref string) (ModelOption, error) {
+	if ref = strings.TrimSpace(ref); ref == "" {
+		return ModelOption{}, nil
+	}
+	models, err := b.ListModels(ctx, portal)
+	if err != nil {
+		return ModelOption{}, err
+	}
+	option, ok := findOpenCodeModel(models, ref)
+	if !ok {
+		return ModelOption{}, fmt.Errorf("unknown model %s", ref)
+	}
+	return option, nil
+}
+
+func (b *Bridge) resolveRoomAgent(ctx context.Context, portal *bridgev2.Portal, name string) (string, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		return "", nil
+	}
+	agents, err := b.ListAgents(ctx, portal)
+	if err != nil {
+		return "", err
+	}
+	idx := slices.IndexFunc(agents, func(agent opencode.Agent) bool { return strings.EqualFold(agent.Name, name) })
+	if idx < 0 {
+		return "", fmt.Errorf("unknown agent %s", name)
+	}
+	return agents[idx].Name, nil
+}
+
+// saveRoomSettings saves the room's model and agent. publish also writes the
+// room settings state event, for changes that didn't come from one.
+func (b *Bridge) saveRoomSettings(ctx context.Context, portal *bridgev2.Portal, meta *PortalMeta, publish bool) error {
+	if portal.MXID != "" {
+		b.updateRoomSettingsTopic(ctx, portal, meta)
+	}
+	b.host.SetPortalMeta(portal, meta)
+	if err := b.host.SavePortal(ctx, portal); err != nil {
+		return err
+	}
+	if publish && portal.MXID != "" {
+		if err := b.host.SendRoomSettings(ctx, portal, roomSettingsForMeta(meta)); err != nil {
+			b.host.Log().Warn().Err(err).Msg("Failed to send OpenCode room settings")
+		}
+	}
+	return nil
+}
+
+// updateRoomSettingsTopic shows the room's settings in its topic while the
+// bridge owns the topic.
+func (b *Bridge) updateRoomSettingsTopic(ctx context.Context, portal *bridgev2.Portal, meta *PortalMeta) {
+	current, err := b.host.RoomTopic(ctx, portal)
+	if err != nil {
+		b.host.Log().Warn().Err(err).Msg("Failed to get OpenCode room topic")
+		return
+	}
+	topic, ok := openCodeSettingsTopicUpdate(current, meta)
+	if !ok {
+		return
+	}
+	if topic != current {
+		if err = b.host.SetRoomTopic(ctx, portal, topic); err != nil {
+			b.host.Log().Warn().Err(err).Msg("Failed to update OpenCode room topic")
+			return
+		}
+	}
+	meta.SettingsTopic = topic
+}
+
+// openCodeSettingsTopicUpdate returns the topic for meta's settings, or false
+// when the room's current topic was set by someone else. The bridge owns the
+// topic while it is empty or still the one the bridge last wrote.
+func openCodeSettingsTopicUpdate(current string, meta *PortalMeta) (string, bool) {
+	if current != "" && current != meta.SettingsTopic {
+		return "", false
+	}
+	return openCodeRoomTopic(meta), true
+}
+
+func roomSettingsForMeta(meta *PortalMeta) RoomSettings {
+	settings := RoomSettings{Agent: meta.Agent}
+	if meta.ProviderID != "" && meta.ModelID != "" {
+		settings.Model = meta.ProviderID + "/" + meta.ModelID
+	}
+	return settings
+}
+
+// RoomModelLabel describes the room's model and agent for replies and topics.
+func RoomModelLabel(meta *PortalMeta) (model, agent string) {
+	model, agent = "server default", "server default"
+	if meta != nil && meta.ProviderID != "" && meta.ModelID != "" {
+		model = meta.ProviderID + "/" + meta.ModelID
+	}
+	if meta != nil && meta.Agent != "" {
+		agent = meta.Agent
+	}
+	return model, agent
+}
+
+func openCodeRoomTopic(meta *PortalMeta) string {
+	if meta == nil || (meta.ModelID == "" && meta.Agent == "") {
+		return ""
+	}
+	model, agent := RoomModelLabel(meta)
+	return fmt.Sprintf("Model: %s · Agent: %s", model, agent)
+}
+
+// openCodeModelOptions flattens the provider catalog, sorted by provider and
+// model ID.
+func openCodeModelOptions(providers *opencode.ProviderList) []ModelOption {
+	if providers == nil {
+		return nil
+	}
+	var out []ModelOption
+	for _, provider := range providers.Providers {
+		for key, model := range provider.Models {
+			modelID := cmp.Or(strings.TrimSpace(model.ID), key)
+			out = append(out, ModelOption{
+				ProviderID: provider.ID,
+				ModelID:    modelID,
+				Name:       cmp.Or(strings.TrimSpace(model.Name), modelID),
+				Default:    providers.Default[provider.ID] == modelID,
+			})
+		}
+	}
+	slices.SortFunc(out, func(a, b ModelOption) int {
+		return cmp.Or(cmp.Compare(a.ProviderID, b.ProviderID), cmp.Compare(a.ModelID, b.ModelID))
+	})
+	return out
+}
+
+// findOpenCodeModel resolves "provider/model", or a bare model ID that only
+// one provider offers. Model IDs may contain slashes themselves.
+func findOpenCodeModel(models []ModelOption, ref string) (ModelOption, bool) {
+	ref = strings.TrimSpace(ref)
+	for _, option := range models {
+		if strings.EqualFold(option.Ref(), ref) {
+			return option, true
+		}
+	}
+	var match *ModelOption
+	for i, option := range models {
+		if strings.EqualFold(option.ModelID, ref) {
+			if match != nil {
+				return ModelOption{}, false
+			}
+			match = &models[i]
+		}
+	}
+	if match == nil {
+		return ModelOption{}, false
+	}
+	return *match, true
+}
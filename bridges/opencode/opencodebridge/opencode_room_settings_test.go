@@ -0,0 +1,98 @@
+package opencodebridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/beeper/agentremote/bridges/opencode/opencode"
+)
+
+func TestOpenCodeModelOptions(t *testing.T) {
+	models := openCodeModelOptions(&opencode.ProviderList{
+		Providers: []opencode.Provider{{
+			ID: "openrouter",
+			Models: map[string]opencode.ProviderModel{
+				"anthropic/claude-sonnet": {ID: "anthropic/claude-sonnet", Name: "Claude Sonnet"},
+			},
+		}, {
+			ID: "anthropic",
+			Models: map[string]opencode.ProviderModel{
+				"claude-sonnet": {ID: "claude-sonnet"},
+				"claude-haiku":  {},
+			},
+		}},
+		Default: map[string]string{"anthropic": "claude-sonnet"},
+	})
+	if len(models) != 3 || models[0].Ref() != "anthropic/claude-haiku" || models[2].Ref() != "openrouter/anthropic/claude-sonnet" {
+		t.Fatalf("unexpected models %#v", models)
+	}
+	if !models[1].Default || models[1].Name != "claude-sonnet" {
+		t.Fatalf("expected provider default with ID as name, got %#v", models[1])
+	}
+
+	if got, ok := findOpenCodeModel(models, "OpenRouter/anthropic/claude-sonnet"); !ok || got.ProviderID != "openrouter" {
+		t.Fatalf("expected full ref match, got %#v (%v)", got, ok)
+	}
+	if got, ok := findOpenCodeModel(models, "claude-haiku"); !ok || got.ProviderID != "anthropic" {
+		t.Fatalf("expected unique bare model match, got %#v (%v)", got, ok)
+	}
+	if _, ok := findOpenCodeModel(models, "gpt-5"); ok {
+		t.Fatal("expected unknown model to be rejected")
+	}
+}
+
+func TestOpenCodeRoomPromptOptions(t *testing.T) {
+	meta := &PortalMeta{ProviderID: "anthropic", ModelID: "claude-sonnet", Agent: "plan"}
+	opts := meta.promptOptions()
+	if opts.Model == nil || opts.Model.ProviderID != "anthropic" || opts.Model.ModelID != "claude-sonnet" || opts.Agent != "plan" {
+		t.Fatalf("unexpected prompt options %#v", opts)
+	}
+	if topic := openCodeRoomTopic(meta); topic != "Model: anthropic/claude-sonnet · Agent: plan" {
+		t.Fatalf("unexpected topic %q", topic)
+	}
+	if opts := (&PortalMeta{}).promptOptions(); opts.Model != nil || opts.Agent != "" {
+		t.Fatalf("expected server defaults, got %#v", opts)
+	}
+	if topic := openCodeRoomTopic(&PortalMeta{}); topic != "" {
+		t.Fatalf("expected no topic for defaults, got %q", topic)
+	}
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	client, err := opencode.NewClient(server.URL, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	parts := []opencode.PartInput{{Type: "text", Text: "hi"}}
+	if err = client.SendMessageAsync(context.Background(), "ses_1", "", parts, opts); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	model, _ := body["model"].(map[string]any)
+	if model["providerID"] != "anthropic" || model["modelID"] != "claude-sonnet" || body["agent"] != "plan" {
+		t.Fatalf("expected model and agent in prompt, got %#v", body)
+	}
+}
+
+func TestOpenCodeSettingsTopicUpdate(t *testing.T) {
+	meta := &PortalMeta{ProviderID: "anthropic", ModelID: "claude-sonnet"}
+	if topic, ok := openCodeSettingsTopicUpdate("", meta); !ok || topic != "Model: anthropic/claude-sonnet · Agent: server default" {
+		t.Fatalf("expected the bridge to take an empty topic, got %q (%v)", topic, ok)
+	}
+	if _, ok := openCodeSettingsTopicUpdate("Fixing the login flow", meta); ok {
+		t.Fatal("expected a topic set by someone else to be left alone")
+	}
+	reset := &PortalMeta{SettingsTopic: "Model: anthropic/claude-haiku · Agent: server default"}
+	if topic, ok := openCodeSettingsTopicUpdate(reset.SettingsTopic, reset); !ok || topic != "" {
+		t.Fatalf("expected a reset to clear the bridge's own topic, got %q (%v)", topic, ok)
+	}
+	if settings := roomSettingsForMeta(&PortalMeta{ProviderID: "anthropic", ModelID: "claude-sonnet", Agent: "plan"}); settings != (RoomSettings{Model: "anthropic/claude-sonnet", Agent: "plan"}) {
+		t.Fatalf("unexpected room settings %#v", settings)
+	}
+}
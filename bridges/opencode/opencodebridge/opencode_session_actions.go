@@ -0,0 +1,208 @@
+package opencodebridge
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/simplevent"
+	"maunium.net/go/mautrix/id"
+
+	"github.com/beeper/agentremote/bridges/opencode/opencode"
+)
+
+var errNotSessionRoom = bridgeError("not an OpenCode session room")
+
+func (b *Bridge) requireSessionRoom(portal *bridgev2.Portal) (*PortalMeta, error) {
+	if b == nil || b.manager == nil {
+		return nil, ErrUnavailable
+	}
+	meta := b.portalMeta(portal)
+	if meta == nil || !meta.IsOpenCodeRoom || meta.InstanceID == "" || meta.SessionID == "" {
+		return nil, errNotSessionRoom
+	}
+	return meta, nil
+}
+
+// ForkSessionChat forks the room's session at messageID (or copies all of it
+// when messageID is empty) and returns the portal of the new session.
+func (b *Bridge) ForkSessionChat(ctx context.Context, portal *bridgev2.Portal, messageID string) (*bridgev2.Portal, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return nil, err
+	}
+	session, err := b.manager.ForkSession(ctx, meta.InstanceID, meta.SessionID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	inst := b.manager.getInstance(meta.InstanceID)
+	if err = b.ensureOpenCodeSessionPortalWithRoom(ctx, inst, *session, true); err != nil {
+		return nil, err
+	}
+	forked := b.findOpenCodePortal(ctx, meta.InstanceID, session.ID)
+	if forked == nil || forked.MXID == "" {
+		return nil, errors.New("failed to create OpenCode portal")
+	}
+	if forkedMeta := b.portalMeta(forked); meta.ModelID != "" || meta.Agent != "" {
+		forkedMeta.ProviderID, forkedMeta.ModelID, forkedMeta.Agent = meta.ProviderID, meta.ModelID, meta.Agent
+		if err = b.saveRoomSettings(ctx, forked, forkedMeta, true); err != nil {
+			return nil, err
+		}
+	}
+	return forked, nil
+}
+
+// RevertSession reverts the room's session and returns the number of reverted
+// messages.
+func (b *Bridge) RevertSession(ctx context.Context, portal *bridgev2.Portal, messageID string) (int, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return 0, err
+	}
+	_, reverted, err := b.manager.RevertSession(ctx, meta.InstanceID, meta.SessionID, messageID)
+	return len(reverted), err
+}
+
+// UnrevertSession undoes the last revert in the room and returns the number of
+// restored messages.
+func (b *Bridge) UnrevertSession(ctx context.Context, portal *bridgev2.Portal) (int, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := b.manager.UnrevertSession(ctx, meta.InstanceID, meta.SessionID)
+	return len(restored), err
+}
+
+// ShareSession shares the room's session and returns its public URL.
+func (b *Bridge) ShareSession(ctx context.Context, portal *bridgev2.Portal) (string, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return "", err
+	}
+	session, err := b.manager.ShareSession(ctx, meta.InstanceID, meta.SessionID)
+	if err != nil {
+		return "", err
+	}
+	if session.Share == nil || strings.TrimSpace(session.Share.URL) == "" {
+		return "", errors.New("OpenCode didn't return a share URL")
+	}
+	return strings.TrimSpace(session.Share.URL), nil
+}
+
+func (b *Bridge) UnshareSession(ctx context.Context, portal *bridgev2.Portal) error {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return err
+	}
+	_, err = b.manager.UnshareSession(ctx, meta.InstanceID, meta.SessionID)
+	return err
+}
+
+func (b *Bridge) SummarizeSession(ctx context.Context, portal *bridgev2.Portal) error {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return err
+	}
+	return b.manager.SummarizeSession(ctx, meta.InstanceID, meta.SessionID, meta.promptOptions().Model)
+}
+
+// ResolveMessageID maps a bridged Matrix event in the room to the OpenCode
+// message it belongs to.
+func (b *Bridge) ResolveMessageID(ctx context.Context, portal *bridgev2.Portal, eventID id.EventID) (string, error) {
+	meta, err := b.requireSessionRoom(portal)
+	if err != nil {
+		return "", err
+	}
+	login := b.host.Login()
+	if login == nil || login.Bridge == nil || login.Bridge.DB == nil {
+		return "", errors.New("login unavailable")
+	}
+	msg, err := login.Bridge.DB.Message.GetPartByMXID(ctx, eventID)
+	if err != nil {
+		return "", err
+	}
+	if msg == nil || msg.Room != portal.PortalKey {
+		return "", errors.New("that message isn't an OpenCode message in this room")
+	}
+	if messageID, ok := parseOpenCodeMessageID(msg.ID); ok {
+		return messageID, nil
+	}
+	if partID, ok := parseOpenCodePartID(msg.ID); ok {
+		if inst := b.manager.getInstance(meta.InstanceID); inst != nil {
+			for _, cached := range inst.listCachedMessages(meta.SessionID) {
+				if _, found := findOpenCodePart(cached.Parts, partID); found {
+					return cached.Info.ID, nil
+				}
+			}
+		}
+	}
+	return "", errors.New("that message isn't an OpenCode message in this room")
+}
+
+// restoreOpenCodeMessages posts previously removed assistant messages again,
+// converted the same way as backfill.
+func (b *Bridge) restoreOpenCodeMessages(ctx context.Context, portal *bridgev2.Portal, instanceID string, messages []opencode.MessageWithParts) {
+	entries := make([]backfillMessageEntry, 0, len(messages))
+	for _, msg := range messages {
+		entries = append(entries, backfillMessageEntry{msg: msg, when: openCodeMessageTime(msg)})
+	}
+	slices.SortStableFunc(entries, func(a, b backfillMessageEntry) int {
+		if c := a.when.Compare(b.when); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.msg.Info.ID, b.msg.Info.ID)
+	})
+	converted, err := b.convertOpenCodeBackfill(ctx, portal, instanceID, entries)
+	if err != nil {
+		b.host.Log().Warn().Err(err).Msg("Failed to convert restored OpenCode messages")
+		return
+	}
+	for _, msg := range converted {
+		b.queueRemoteEvent(&simplevent.PreConvertedMessage{
+			EventMeta: simplevent.EventMeta{
+				Type:      bridgev2.RemoteEventMessage,
+				PortalKey: portal.PortalKey,
+				Sender:    msg.Sender,
+				Timestamp: msg.Timestamp,
+			},
+			Data:          msg.ConvertedMessage,
+			ID:            msg.ID,
+			TransactionID: msg.TxnID,
+		})
+	}
+}
+
+// openCodeMessagesFrom returns messageID and everything after it.
+func openCodeMessagesFrom(messages []opencode.MessageWithParts, messageID string) []opencode.MessageWithParts {
+	for i, msg := range messages {
+		if msg.Info.ID == messageID {
+			return messages[i:]
+		}
+	}
+	return nil
+}
+
+func lastOpenCodeUserMessageID(messages []opencode.MessageWithParts) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Info.Role == "user" {
+			return messages[i].Info.ID
+		}
+	}
+	return ""
+}
+
+// lastOpenCodeModel returns the provider and model of the latest message that
+// names both.
+func lastOpenCodeModel(messages []opencode.MessageWithParts) (providerID, modelID string) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		info := messages[i].Info
+		if info.ProviderID != "" && info.ModelID != "" {
+			return info.ProviderID, info.ModelID
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,105 @@
+package opencodebridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/beeper/agentremote/bridges/opencode/opencode"
+)
+
+func TestOpenCodeRevertHelpers(t *testing.T) {
+	messages := []opencode.MessageWithParts{
+		{Info: opencode.Message{ID: "m1", Role: "user"}},
+		{Info: opencode.Message{ID: "m2", Role: "assistant", ProviderID: "anthropic", ModelID: "claude-sonnet"}},
+		{Info: opencode.Message{ID: "m3", Role: "user"}},
+		{Info: opencode.Message{ID: "m4", Role: "assistant"}},
+	}
+	if got := lastOpenCodeUserMessageID(messages); got != "m3" {
+		t.Fatalf("expected last prompt m3, got %q", got)
+	}
+	if got := openCodeMessagesFrom(messages, "m3"); len(got) != 2 || got[0].Info.ID != "m3" || got[1].Info.ID != "m4" {
+		t.Fatalf("unexpected reverted messages %#v", got)
+	}
+	if got := openCodeMessagesFrom(messages, "missing"); got != nil {
+		t.Fatalf("expected no messages for unknown ID, got %#v", got)
+	}
+	if provider, model := lastOpenCodeModel(messages); provider != "anthropic" || model != "claude-sonnet" {
+		t.Fatalf("unexpected model %s/%s", provider, model)
+	}
+	if got := lastOpenCodeUserMessageID(nil); got != "" {
+		t.Fatalf("expected no prompt, got %q", got)
+	}
+}
+
+func TestOpenCodeSessionActionRequests(t *testing.T) {
+	type request struct {
+		method string
+		path   string
+		body   map[string]any
+	}
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{method: r.Method, path: r.URL.Path}
+		_ = json.NewDecoder(r.Body).Decode(&req.body)
+		requests = append(requests, req)
+		if r.URL.Path == "/session/ses_1/summarize" {
+			_, _ = w.Write([]byte("true"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"ses_2","share":{"url":"https://opncd.ai/s/abc"},"revert":{"messageID":"m3"}}`))
+	}))
+	defer server.Close()
+
+	client, err := opencode.NewClient(server.URL, "", "")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	if session, err := client.ForkSession(ctx, "ses_1", "m2"); err != nil || session.ID != "ses_2" {
+		t.Fatalf("fork failed: %v %#v", err, session)
+	}
+	if session, err := client.RevertSession(ctx, "ses_1", "m3", ""); err != nil || session.Revert == nil || session.Revert.MessageID != "m3" {
+		t.Fatalf("revert failed: %v %#v", err, session)
+	}
+	if _, err := client.UnrevertSession(ctx, "ses_1"); err != nil {
+		t.Fatalf("unrevert failed: %v", err)
+	}
+	if session, err := client.ShareSession(ctx, "ses_1"); err != nil || session.Share == nil || session.Share.URL != "https://opncd.ai/s/abc" {
+		t.Fatalf("share failed: %v %#v", err, session)
+	}
+	if _, err := client.UnshareSession(ctx, "ses_1"); err != nil {
+		t.Fatalf("unshare failed: %v", err)
+	}
+	if err := client.SummarizeSession(ctx, "ses_1", "anthropic", "claude-sonnet"); err != nil {
+		t.Fatalf("summarize failed: %v", err)
+	}
+	if _, err := client.RevertSession(ctx, "ses_1", "", ""); err == nil {
+		t.Fatal("expected revert without message ID to fail")
+	}
+
+	expected := []request{
+		{method: http.MethodPost, path: "/session/ses_1/fork", body: map[string]any{"messageID": "m2"}},
+		{method: http.MethodPost, path: "/session/ses_1/revert", body: map[string]any{"messageID": "m3"}},
+		{method: http.MethodPost, path: "/session/ses_1/unrevert"},
+		{method: http.MethodPost, path: "/session/ses_1/share"},
+		{method: http.MethodDelete, path: "/session/ses_1/share"},
+		{method: http.MethodPost, path: "/session/ses_1/summarize", body: map[string]any{"providerID": "anthropic", "modelID": "claude-sonnet"}},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("expected %d requests, got %#v", len(expected), requests)
+	}
+	for i, want := range expected {
+		got := requests[i]
+		if got.method != want.method || got.path != want.path || len(got.body) != len(want.body) {
+			t.Fatalf("request %d: expected %#v, got %#v", i, want, got)
+		}
+		for key, value := range want.body {
+			if got.body[key] != value {
+				t.Fatalf("request %d: expected %s=%v, got %#v", i, key, value, got.body)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+
+	"maunium.net/go/mautrix/bridgev2/matrix"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/bridges/opencode/opencodebridge"
+	"github.com/beeper/agentremote/pkg/matrixevents"
+)
+
+// registerRoomSettingsHandler listens for room settings state events, which
+// bridgev2 doesn't forward to network connectors.
+func (oc *OpenCodeConnector) registerRoomSettingsHandler() bool {
+	matrixConnector, ok := oc.br.Matrix.(*matrix.Connector)
+	if !ok || matrixConnector == nil {
+		return false
+	}
+	matrixConnector.EventProcessor.On(matrixevents.RoomSettingsEventType, oc.handleRoomSettingsEvent)
+	return true
+}
+
+// handleRoomSettingsEvent applies the model and agent of an OpenCode room's
+// settings state event. Only the room's owner can change them.
+func (oc *OpenCodeConnector) handleRoomSettingsEvent(ctx context.Context, evt *event.Event) {
+	if evt == nil || evt.StateKey == nil || *evt.StateKey != "" {
+		return
+	}
+	portal, err := oc.br.GetPortalByMXID(ctx, evt.RoomID)
+	if err != nil || portal == nil || !portalMeta(portal).IsOpenCodeRoom {
+		return
+	}
+	login := oc.br.GetCachedUserLoginByID(portal.Receiver)
+	if login == nil || login.UserMXID != evt.Sender {
+		return
+	}
+	client, ok := login.Client.(*OpenCodeClient)
+	if !ok || client.bridge == nil {
+		return
+	}
+	var settings opencodebridge.RoomSettings
+	if err = json.Unmarshal(evt.Content.VeryRaw, &settings); err != nil {
+		client.sendSystemNoticeViaPortal(ctx, portal, "Invalid room settings: "+err.Error())
+		return
+	}
+	if err = client.bridge.ApplyRoomSettings(ctx, portal, settings); err != nil {
+		client.sendSystemNoticeViaPortal(ctx, portal, "Failed to apply room settings: "+err.Error())
+	}
+}
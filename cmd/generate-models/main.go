@@ -7,9 +7,11 @@ import (
 	"go/format"
 	"io"
 	"maps"
+	"math"
 	"net/http"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -136,11 +138,12 @@ type OpenRouterArchitecture struct {
 	InstructType     string   `json:"instruct_type"`
 }
 
-// OpenRouterPricing contains model pricing information
+// OpenRouterPricing contains model pricing information (USD per token, as strings)
 type OpenRouterPricing struct {
-	Prompt     string `json:"prompt"`
-	Completion string `json:"completion"`
-	WebSearch  string `json:"web_search"`
+	Prompt         string `json:"prompt"`
+	Completion     string `json:"completion"`
+	WebSearch      string `json:"web_search"`
+	InputCacheRead string `json:"input_cache_read"`
 }
 
 // OpenRouterTopProvider contains top provider information
@@ -178,6 +181,14 @@ type ModelCapabilities struct {
 	PDF             bool
 	ContextWindow   int
 	MaxOutputTokens int
+	Pricing         *ModelPricing
+}
+
+// ModelPricing mirrors connector.ModelPricing (USD per million tokens)
+type ModelPricing struct {
+	Input       float64 `json:"input"`
+	Output      float64 `json:"output"`
+	CachedInput float64 `json:"cached_input,omitempty"`
 }
 
 func main() {
@@ -288,9 +299,48 @@ func detectCapabilities(modelID string, apiModel OpenRouterModel, hasAPIData boo
 	caps.ContextWindow = apiModel.ContextLength
 	caps.MaxOutputTokens = apiModel.TopProvider.MaxCompletionTokens
 
+	caps.Pricing = parsePricing(apiModel.Pricing)
+
 	return caps
 }
 
+// parsePricing converts OpenRouter's per-token prices to USD per million tokens.
+// Returns nil when the model has no usable prompt/completion price.
+func parsePricing(p OpenRouterPricing) *ModelPricing {
+	input, okInput := parsePerTokenPrice(p.Prompt)
+	output, okOutput := parsePerTokenPrice(p.Completion)
+	if !okInput || !okOutput {
+		return nil
+	}
+	cached, _ := parsePerTokenPrice(p.InputCacheRead)
+	return &ModelPricing{Input: input, Output: output, CachedInput: cached}
+}
+
+func parsePerTokenPrice(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	perToken, err := strconv.ParseFloat(value, 64)
+	if err != nil || perToken < 0 {
+		return 0, false
+	}
+	// Round away float noise from the string conversion (e.g. 0.000003 * 1e6).
+	return math.Round(perToken*1e6*1e6) / 1e6, true
+}
+
+// pricingGo returns the Go code representation of model pricing
+func pricingGo(pricing *ModelPricing) string {
+	if pricing == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("&ModelPricing{Input: %s, Output: %s, CachedInput: %s}",
+		strconv.FormatFloat(pricing.Input, 'f', -1, 64),
+		strconv.FormatFloat(pricing.Output, 'f', -1, 64),
+		strconv.FormatFloat(pricing.CachedInput, 'f', -1, 64),
+	)
+}
+
 // availableToolsGo returns the Go code representation of available tools
 func availableToolsGo(caps ModelCapabilities) string {
 	if caps.WebSearch && caps.ToolCalling {
@@ -368,6 +418,7 @@ var ModelManifest = struct {
 			ContextWindow:       %d,
 			MaxOutputTokens:     %d,
 			AvailableTools:      %s,
+			Pricing:             %s,
 		},
 `,
 			modelID,
@@ -385,6 +436,7 @@ var ModelManifest = struct {
 			caps.ContextWindow,
 			caps.MaxOutputTokens,
 			availableToolsGo(caps),
+			pricingGo(caps.Pricing),
 		))
 	}
 
@@ -413,22 +465,23 @@ var ModelManifest = struct {
 
 // JSONModelInfo mirrors the connector.ModelInfo struct for JSON output
 type JSONModelInfo struct {
-	ID                  string   `json:"id"`
-	Name                string   `json:"name"`
-	Provider            string   `json:"provider"`
-	API                 string   `json:"api,omitempty"`
-	Description         string   `json:"description,omitempty"`
-	SupportsVision      bool     `json:"supports_vision"`
-	SupportsToolCalling bool     `json:"supports_tool_calling"`
-	SupportsReasoning   bool     `json:"supports_reasoning"`
-	SupportsWebSearch   bool     `json:"supports_web_search"`
-	SupportsImageGen    bool     `json:"supports_image_gen,omitempty"`
-	SupportsAudio       bool     `json:"supports_audio,omitempty"`
-	SupportsVideo       bool     `json:"supports_video,omitempty"`
-	SupportsPDF         bool     `json:"supports_pdf,omitempty"`
-	ContextWindow       int      `json:"context_window,omitempty"`
-	MaxOutputTokens     int      `json:"max_output_tokens,omitempty"`
-	AvailableTools      []string `json:"available_tools,omitempty"`
+	ID                  string        `json:"id"`
+	Name                string        `json:"name"`
+	Provider            string        `json:"provider"`
+	API                 string        `json:"api,omitempty"`
+	Description         string        `json:"description,omitempty"`
+	SupportsVision      bool          `json:"supports_vision"`
+	SupportsToolCalling bool          `json:"supports_tool_calling"`
+	SupportsReasoning   bool          `json:"supports_reasoning"`
+	SupportsWebSearch   bool          `json:"supports_web_search"`
+	SupportsImageGen    bool          `json:"supports_image_gen,omitempty"`
+	SupportsAudio       bool          `json:"supports_audio,omitempty"`
+	SupportsVideo       bool          `json:"supports_video,omitempty"`
+	SupportsPDF         bool          `json:"supports_pdf,omitempty"`
+	ContextWindow       int           `json:"context_window,omitempty"`
+	MaxOutputTokens     int           `json:"max_output_tokens,omitempty"`
+	AvailableTools      []string      `json:"available_tools,omitempty"`
+	Pricing             *ModelPricing `json:"pricing,omitempty"`
 }
 
 // JSONManifest is the full manifest structure for JSON output
@@ -468,6 +521,7 @@ func generateJSONFile(apiModels map[string]OpenRouterModel, outputPath string) e
 			ContextWindow:       caps.ContextWindow,
 			MaxOutputTokens:     caps.MaxOutputTokens,
 			AvailableTools:      availableToolsJSON(caps),
+			Pricing:             caps.Pricing,
 		})
 	}
 
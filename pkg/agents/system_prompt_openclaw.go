@@ -192,6 +192,7 @@ func BuildSystemPrompt(params SystemPromptParams) string {
 		"write":            "Create or overwrite files",
 		"edit":             "Make precise edits to files",
 		"apply_patch":      "Apply multi-file patches",
+		"file_history":     "List, show, or restore earlier versions of a file",
 		"exec":             "Run shell commands (pty available for TTY-required CLIs)",
 		"process":          "Manage background exec sessions",
 		"web_search":       "Search the web (best available provider)",
@@ -210,6 +211,7 @@ func BuildSystemPrompt(params SystemPromptParams) string {
 		"session_status":   "Show a !ai status-equivalent status card (usage + time + Reasoning/Verbose/Elevated); use for model-use questions (📊 session_status); optional per-session model override",
 		"image":            "Analyze an image with the configured image model",
 		"beeper_docs":      "Search Beeper docs (help.beeper.com, developers.beeper.com)",
+		"mcp_resource":     "List, read, or attach resources published by connected MCP servers",
 	}
 
 	toolOrder := []string{
@@ -217,6 +219,7 @@ func BuildSystemPrompt(params SystemPromptParams) string {
 		"write",
 		"edit",
 		"apply_patch",
+		"file_history",
 		"exec",
 		"process",
 		"web_search",
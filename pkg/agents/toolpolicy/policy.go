@@ -76,8 +76,8 @@ var ToolGroups = map[string][]string{
 		"image",
 	},
 	// ai-bridge extras (keep separate so group:openclaw stays portable with OpenClaw configs).
-	GroupAIBridge: {"gravatar_fetch", "gravatar_set", "beeper_docs", "beeper_send_feedback", "image_generate", "tts", "calculator"},
-	GroupFS:       {"read", "write", "edit", "apply_patch"},
+	GroupAIBridge: {"gravatar_fetch", "gravatar_set", "beeper_docs", "beeper_send_feedback", "image_generate", "tts", "calculator", "mcp_resource"},
+	GroupFS:       {"read", "write", "edit", "apply_patch", "file_history"},
 }
 
 var ownerOnlyToolNames = map[string]struct{}{
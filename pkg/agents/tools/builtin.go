@@ -22,6 +22,7 @@ const (
 	GroupCalc      = toolpolicy.GroupCalc
 	GroupBuilder   = toolpolicy.GroupBuilder
 	GroupMessaging = toolpolicy.GroupMessaging
+	GroupRuntime   = toolpolicy.GroupRuntime
 	GroupSessions  = toolpolicy.GroupSessions
 	GroupMemory    = toolpolicy.GroupMemory
 	GroupWeb       = toolpolicy.GroupWeb
@@ -49,10 +50,14 @@ func BuiltinTools() []*Tool {
 		GravatarSetTool,
 		BeeperDocsTool,
 		BeeperSendFeedbackTool,
+		MCPResourceTool,
 		ReadTool,
 		ApplyPatchTool,
 		WriteTool,
 		EditTool,
+		FileHistoryTool,
+		ExecTool,
+		ProcessTool,
 	}
 	return tools
 }
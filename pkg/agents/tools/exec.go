@@ -0,0 +1,15 @@
+package tools
+
+import "github.com/beeper/agentremote/pkg/shared/toolspec"
+
+// ExecTool runs shell commands in the agent's sandboxed working directory.
+var ExecTool = newRuntimeTool(toolspec.ExecName, toolspec.ExecDescription, "Exec", toolspec.ExecSchema())
+
+// ProcessTool manages background sessions started by exec.
+var ProcessTool = newRuntimeTool(toolspec.ProcessName, toolspec.ProcessDescription, "Process", toolspec.ProcessSchema())
+
+func newRuntimeTool(name, description, title string, schema map[string]any) *Tool {
+	tool := newConnectorOnlyTool(name, description, title, schema)
+	tool.Group = GroupRuntime
+	return tool
+}
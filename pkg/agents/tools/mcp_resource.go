@@ -0,0 +1,11 @@
+package tools
+
+import "github.com/beeper/agentremote/pkg/shared/toolspec"
+
+// MCPResourceTool lists, reads and attaches resources from connected MCP servers.
+var MCPResourceTool = newConnectorOnlyTool(
+	toolspec.MCPResourceName,
+	toolspec.MCPResourceDescription,
+	"MCP Resource",
+	toolspec.MCPResourceSchema(),
+)
@@ -54,4 +54,10 @@ var (
 		title:       "Edit",
 		inputSchema: toolspec.EditSchema(),
 	})
+	FileHistoryTool = newUnavailableBuiltinTool(unavailableBuiltinToolSpec{
+		name:        toolspec.FileHistoryName,
+		description: toolspec.FileHistoryDescription,
+		title:       "File History",
+		inputSchema: toolspec.FileHistorySchema(),
+	})
 )
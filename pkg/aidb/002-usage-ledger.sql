@@ -0,0 +1,18 @@
+-- v1 -> v2: add usage ledger
+CREATE TABLE IF NOT EXISTS ai_usage_ledger (
+  bridge_id TEXT NOT NULL,
+  login_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL DEFAULT '',
+  room_id TEXT NOT NULL DEFAULT '',
+  model_id TEXT NOT NULL,
+  turn_id TEXT NOT NULL DEFAULT '',
+  prompt_tokens INTEGER NOT NULL DEFAULT 0,
+  completion_tokens INTEGER NOT NULL DEFAULT 0,
+  reasoning_tokens INTEGER NOT NULL DEFAULT 0,
+  cached_tokens INTEGER NOT NULL DEFAULT 0,
+  cost_micros INTEGER NOT NULL DEFAULT 0,
+  created_at_ms INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_ledger_login_time
+  ON ai_usage_ledger(bridge_id, login_id, created_at_ms);
@@ -0,0 +1,26 @@
+-- v2 -> v3: add cron job run history
+CREATE TABLE IF NOT EXISTS ai_cron_job_runs (
+  bridge_id TEXT NOT NULL,
+  login_id TEXT NOT NULL,
+  job_id TEXT NOT NULL,
+  run_id TEXT NOT NULL,
+  run_key TEXT NOT NULL DEFAULT '',
+  trigger_kind TEXT NOT NULL DEFAULT '',
+  started_at_ms INTEGER NOT NULL,
+  finished_at_ms INTEGER NOT NULL DEFAULT 0,
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  status TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT '',
+  room_id TEXT NOT NULL DEFAULT '',
+  delivered_room_id TEXT NOT NULL DEFAULT '',
+  delivered_event_id TEXT NOT NULL DEFAULT '',
+  model_id TEXT NOT NULL DEFAULT '',
+  prompt_tokens INTEGER NOT NULL DEFAULT 0,
+  completion_tokens INTEGER NOT NULL DEFAULT 0,
+  reasoning_tokens INTEGER NOT NULL DEFAULT 0,
+  output TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (bridge_id, login_id, job_id, run_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_cron_job_runs_job_time
+  ON ai_cron_job_runs(bridge_id, login_id, job_id, started_at_ms);
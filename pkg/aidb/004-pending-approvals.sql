@@ -0,0 +1,18 @@
+-- v3 -> v4: persist pending tool approvals across restarts
+CREATE TABLE IF NOT EXISTS ai_pending_approvals (
+  bridge_id TEXT NOT NULL,
+  login_id TEXT NOT NULL,
+  approval_id TEXT NOT NULL,
+  room_id TEXT NOT NULL DEFAULT '',
+  owner_mxid TEXT NOT NULL DEFAULT '',
+  prompt_event_id TEXT NOT NULL DEFAULT '',
+  prompt_message_id TEXT NOT NULL DEFAULT '',
+  tool_call_id TEXT NOT NULL DEFAULT '',
+  tool_name TEXT NOT NULL DEFAULT '',
+  turn_id TEXT NOT NULL DEFAULT '',
+  options_json TEXT NOT NULL DEFAULT '',
+  data_json TEXT NOT NULL DEFAULT '',
+  expires_at_ms INTEGER NOT NULL DEFAULT 0,
+  created_at_ms INTEGER NOT NULL,
+  PRIMARY KEY (bridge_id, login_id, approval_id)
+);
@@ -0,0 +1,14 @@
+-- v4 -> v5: keep previous versions of agent workspace files
+CREATE TABLE IF NOT EXISTS ai_memory_file_revisions (
+  bridge_id TEXT NOT NULL,
+  login_id TEXT NOT NULL,
+  agent_id TEXT NOT NULL,
+  path TEXT NOT NULL,
+  version INTEGER NOT NULL,
+  content TEXT NOT NULL,
+  hash TEXT NOT NULL,
+  author TEXT NOT NULL DEFAULT '',
+  tool_call_id TEXT NOT NULL DEFAULT '',
+  created_at INTEGER NOT NULL,
+  PRIMARY KEY (bridge_id, login_id, agent_id, path, version)
+);
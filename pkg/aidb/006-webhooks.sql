@@ -0,0 +1,44 @@
+-- v5 -> v6: inbound webhooks that trigger agent turns
+CREATE TABLE IF NOT EXISTS ai_webhooks (
+  bridge_id TEXT NOT NULL,
+  login_id TEXT NOT NULL,
+  hook_id TEXT NOT NULL,
+  name TEXT NOT NULL DEFAULT '',
+  secret TEXT NOT NULL,
+  prompt_template TEXT NOT NULL DEFAULT '',
+  agent_id TEXT NOT NULL DEFAULT '',
+  room_id TEXT NOT NULL DEFAULT '',
+  wrap_untrusted INTEGER NOT NULL DEFAULT 1,
+  enabled INTEGER NOT NULL DEFAULT 1,
+  created_at_ms INTEGER NOT NULL,
+  updated_at_ms INTEGER NOT NULL,
+  PRIMARY KEY (bridge_id, login_id, hook_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_webhooks_hook
+  ON ai_webhooks(bridge_id, hook_id);
+
+CREATE TABLE IF NOT EXISTS ai_webhook_deliveries (
+  bridge_id TEXT NOT NULL,
+  login_id TEXT NOT NULL,
+  hook_id TEXT NOT NULL,
+  delivery_id TEXT NOT NULL,
+  replay_of TEXT NOT NULL DEFAULT '',
+  received_at_ms INTEGER NOT NULL,
+  event_type TEXT NOT NULL DEFAULT '',
+  content_type TEXT NOT NULL DEFAULT '',
+  payload TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT '',
+  error TEXT NOT NULL DEFAULT '',
+  room_id TEXT NOT NULL DEFAULT '',
+  event_id TEXT NOT NULL DEFAULT '',
+  external_id TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (bridge_id, login_id, hook_id, delivery_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_webhook_deliveries_hook_time
+  ON ai_webhook_deliveries(bridge_id, login_id, hook_id, received_at_ms);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ai_webhook_deliveries_external
+  ON ai_webhook_deliveries(bridge_id, login_id, hook_id, external_id)
+  WHERE external_id <> '';
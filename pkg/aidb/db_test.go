@@ -30,7 +30,7 @@ func TestNewChildNilBase(t *testing.T) {
 	}
 }
 
-func TestUpgradeV1Fresh(t *testing.T) {
+func TestUpgradeFresh(t *testing.T) {
 	ctx := context.Background()
 	parentDB := setupTestDB(t)
 	bridgeDB := NewChild(parentDB, dbutil.NoopLogger)
@@ -46,8 +46,8 @@ func TestUpgradeV1Fresh(t *testing.T) {
 	if err := bridgeDB.QueryRow(ctx, "SELECT version FROM "+VersionTable).Scan(&version); err != nil {
 		t.Fatalf("read %s failed: %v", VersionTable, err)
 	}
-	if version != 1 {
-		t.Fatalf("expected %s=1, got %d", VersionTable, version)
+	if version != 6 {
+		t.Fatalf("expected %s=6, got %d", VersionTable, version)
 	}
 
 	for _, table := range []string{
@@ -63,6 +63,12 @@ func TestUpgradeV1Fresh(t *testing.T) {
 		"ai_managed_heartbeat_run_keys",
 		"ai_system_events",
 		"ai_sessions",
+		"ai_usage_ledger",
+		"ai_cron_job_runs",
+		"ai_pending_approvals",
+		"ai_memory_file_revisions",
+		"ai_webhooks",
+		"ai_webhook_deliveries",
 	} {
 		exists, err := bridgeDB.TableExists(ctx, table)
 		if err != nil {
@@ -92,7 +98,7 @@ func TestNewChildUpgrade(t *testing.T) {
 	if err := bridgeDB.QueryRow(ctx, "SELECT version FROM "+VersionTable).Scan(&version); err != nil {
 		t.Fatalf("read %s failed: %v", VersionTable, err)
 	}
-	if version != 1 {
-		t.Fatalf("expected %s=1, got %d", VersionTable, version)
+	if version != 6 {
+		t.Fatalf("expected %s=6, got %d", VersionTable, version)
 	}
 }
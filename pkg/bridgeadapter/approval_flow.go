@@ -2,6 +2,7 @@ package bridgeadapter
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 	"sync"
 	"time"
@@ -69,6 +70,9 @@ type ApprovalFlow[D any] struct {
 	promptsByApproval map[string]*ApprovalPromptRegistration
 	promptsByEventID  map[id.EventID]string
 
+	// persisted holds the approvals that have a row in the bridge DB.
+	persisted map[string]struct{}
+
 	login           func() *bridgev2.UserLogin
 	sender          func(portal *bridgev2.Portal) bridgev2.EventSender
 	backgroundCtx   func(ctx context.Context) context.Context
@@ -91,6 +95,7 @@ func NewApprovalFlow[D any](cfg ApprovalFlowConfig[D]) *ApprovalFlow[D] {
 		pending:           make(map[string]*Pending[D]),
 		promptsByApproval: make(map[string]*ApprovalPromptRegistration),
 		promptsByEventID:  make(map[id.EventID]string),
+		persisted:         make(map[string]struct{}),
 		login:             cfg.Login,
 		sender:            cfg.Sender,
 		backgroundCtx:     cfg.BackgroundContext,
@@ -147,12 +152,18 @@ func (f *ApprovalFlow[D]) Get(approvalID string) *Pending[D] {
 // Returns false if the approval is not found.
 func (f *ApprovalFlow[D]) SetData(approvalID string, updater func(D) D) bool {
 	f.mu.Lock()
-	defer f.mu.Unlock()
 	p := f.pending[approvalID]
 	if p == nil {
+		f.mu.Unlock()
 		return false
 	}
 	p.Data = updater(p.Data)
+	data := p.Data
+	_, persisted := f.persisted[approvalID]
+	f.mu.Unlock()
+	if persisted {
+		f.updatePersistedData(approvalID, data)
+	}
 	return true
 }
 
@@ -165,7 +176,12 @@ func (f *ApprovalFlow[D]) Drop(approvalID string) {
 	f.mu.Lock()
 	delete(f.pending, approvalID)
 	f.dropPromptLocked(approvalID)
+	_, persisted := f.persisted[approvalID]
+	delete(f.persisted, approvalID)
 	f.mu.Unlock()
+	if persisted {
+		f.deletePersisted(approvalID)
+	}
 }
 
 // FindByData iterates pending approvals and returns the id of the first one
@@ -443,9 +459,205 @@ func (f *ApprovalFlow[D]) SendPrompt(ctx context.Context, portal *bridgev2.Porta
 	f.bindPromptEventLocked(strings.TrimSpace(params.ApprovalID), eventID)
 	f.mu.Unlock()
 
+	f.persistPrompt(ctx, login, strings.TrimSpace(params.ApprovalID), msgID)
 	f.sendPrefillReactions(ctx, portal, login, msgID, prompt.Options)
 }
 
+// ---------------------------------------------------------------------------
+// Persistence
+// ---------------------------------------------------------------------------
+
+// persistPrompt stores a sent prompt and its pending data in the bridge DB so
+// that RestorePending can pick it up after a restart.
+func (f *ApprovalFlow[D]) persistPrompt(ctx context.Context, login *bridgev2.UserLogin, approvalID string, msgID networkid.MessageID) {
+	scope := approvalStoreScopeForLogin(login)
+	if scope == nil || approvalID == "" || msgID == "" {
+		return
+	}
+	f.mu.Lock()
+	reg := f.promptsByApproval[approvalID]
+	p := f.pending[approvalID]
+	if reg == nil {
+		// Resolved while the prompt was being sent.
+		f.mu.Unlock()
+		return
+	}
+	approval := persistedApproval{
+		ApprovalID:      approvalID,
+		RoomID:          reg.RoomID,
+		OwnerMXID:       reg.OwnerMXID,
+		PromptEventID:   reg.PromptEventID,
+		PromptMessageID: msgID,
+		ToolCallID:      reg.ToolCallID,
+		ToolName:        reg.ToolName,
+		TurnID:          reg.TurnID,
+		Options:         reg.Options,
+		ExpiresAt:       reg.ExpiresAt,
+	}
+	var data D
+	if p != nil {
+		data = p.Data
+		approval.ExpiresAt = p.ExpiresAt
+	}
+	f.persisted[approvalID] = struct{}{}
+	f.mu.Unlock()
+
+	log := login.Log.With().Str("approval_id", approvalID).Logger()
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to encode pending approval data")
+	} else {
+		approval.Data = string(encoded)
+	}
+	ctx = f.detachedContext(ctx)
+	if err = scope.save(ctx, approval); err != nil {
+		log.Warn().Err(err).Msg("Failed to persist pending approval")
+		return
+	}
+	f.mu.Lock()
+	_, stillPending := f.persisted[approvalID]
+	f.mu.Unlock()
+	if !stillPending {
+		// Dropped while saving, so Drop's delete may have run first.
+		if err = scope.delete(ctx, approvalID); err != nil {
+			log.Warn().Err(err).Msg("Failed to delete persisted approval")
+		}
+	}
+}
+
+func (f *ApprovalFlow[D]) updatePersistedData(approvalID string, data D) {
+	login := f.login()
+	scope := approvalStoreScopeForLogin(login)
+	if scope == nil {
+		return
+	}
+	encoded, err := json.Marshal(data)
+	if err == nil {
+		err = scope.updateData(f.detachedContext(context.Background()), approvalID, string(encoded))
+	}
+	if err != nil {
+		login.Log.Warn().Err(err).Str("approval_id", approvalID).Msg("Failed to update persisted approval data")
+	}
+}
+
+func (f *ApprovalFlow[D]) deletePersisted(approvalID string) {
+	login := f.login()
+	scope := approvalStoreScopeForLogin(login)
+	if scope == nil {
+		return
+	}
+	if err := scope.delete(f.detachedContext(context.Background()), approvalID); err != nil {
+		login.Log.Warn().Err(err).Str("approval_id", approvalID).Msg("Failed to delete persisted approval")
+	}
+}
+
+// RestorePending loads the approvals that were still waiting for a decision
+// when the bridge last stopped. Callback-based flows (DeliverDecision set) get
+// their unexpired approvals back, so a late reaction still resolves them.
+// Everything else can no longer be answered: the prompt is edited to say it
+// expired and the approval is removed.
+func (f *ApprovalFlow[D]) RestorePending(ctx context.Context) {
+	if f == nil {
+		return
+	}
+	login := f.login()
+	scope := approvalStoreScopeForLogin(login)
+	if scope == nil {
+		return
+	}
+	approvals, err := scope.list(ctx)
+	if err != nil {
+		login.Log.Warn().Err(err).Msg("Failed to load persisted approvals")
+		return
+	}
+	now := time.Now()
+	for _, approval := range approvals {
+		f.mu.Lock()
+		_, live := f.pending[approval.ApprovalID]
+		f.mu.Unlock()
+		if live {
+			continue
+		}
+		if f.deliverDecision != nil && now.Before(approval.ExpiresAt) && f.rehydrate(approval) {
+			continue
+		}
+		f.expirePersisted(ctx, login, scope, approval)
+	}
+}
+
+func (f *ApprovalFlow[D]) rehydrate(approval persistedApproval) bool {
+	var data D
+	if approval.Data != "" {
+		if err := json.Unmarshal([]byte(approval.Data), &data); err != nil {
+			return false
+		}
+	}
+	restored := &Pending[D]{
+		ExpiresAt: approval.ExpiresAt,
+		Data:      data,
+		ch:        make(chan ApprovalDecisionPayload, 1),
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending[approval.ApprovalID] = restored
+	// Nothing waits on restored approvals, so expire them here or the entry,
+	// its row and the prompt would linger until the next restart.
+	time.AfterFunc(time.Until(approval.ExpiresAt), func() {
+		f.expireRestored(approval, restored)
+	})
+	f.registerPromptLocked(ApprovalPromptRegistration{
+		ApprovalID:    approval.ApprovalID,
+		RoomID:        approval.RoomID,
+		OwnerMXID:     approval.OwnerMXID,
+		ToolCallID:    approval.ToolCallID,
+		ToolName:      approval.ToolName,
+		TurnID:        approval.TurnID,
+		ExpiresAt:     approval.ExpiresAt,
+		Options:       approval.Options,
+		PromptEventID: approval.PromptEventID,
+	})
+	f.persisted[approval.ApprovalID] = struct{}{}
+	return true
+}
+
+// expireRestored edits the prompt of a restored approval that ran out
+// unanswered and drops it. It does nothing once the approval was answered.
+func (f *ApprovalFlow[D]) expireRestored(approval persistedApproval, restored *Pending[D]) {
+	f.mu.Lock()
+	current := f.pending[approval.ApprovalID]
+	f.mu.Unlock()
+	if current != restored {
+		return
+	}
+	login := f.login()
+	if scope := approvalStoreScopeForLogin(login); scope != nil {
+		f.expirePersisted(f.detachedContext(context.Background()), login, scope, approval)
+	}
+	f.Drop(approval.ApprovalID)
+}
+
+func (f *ApprovalFlow[D]) expirePersisted(ctx context.Context, login *bridgev2.UserLogin, scope *approvalStoreScope, approval persistedApproval) {
+	log := login.Log.With().Str("approval_id", approval.ApprovalID).Logger()
+	if approval.RoomID != "" && approval.PromptMessageID != "" {
+		portal, err := login.Bridge.GetPortalByMXID(ctx, approval.RoomID)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to get portal of expired approval")
+		} else if portal != nil {
+			login.QueueRemoteEvent(&RemoteEdit{
+				Portal:        portal.PortalKey,
+				Sender:        f.senderOrEmpty(portal),
+				TargetMessage: approval.PromptMessageID,
+				Timestamp:     time.Now(),
+				LogKey:        f.logKey,
+				PreBuilt:      buildApprovalExpiredEdit(approval),
+			})
+		}
+	}
+	if err := scope.delete(ctx, approval.ApprovalID); err != nil {
+		log.Warn().Err(err).Msg("Failed to delete expired approval")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Reaction handling (satisfies ApprovalReactionHandler)
 // ---------------------------------------------------------------------------
@@ -561,6 +773,14 @@ func (f *ApprovalFlow[D]) redactPromptReactions(msg *bridgev2.MatrixReaction, ke
 	}()
 }
 
+func (f *ApprovalFlow[D]) detachedContext(ctx context.Context) context.Context {
+	ctx = context.WithoutCancel(ctx)
+	if f.backgroundCtx != nil {
+		ctx = f.backgroundCtx(ctx)
+	}
+	return ctx
+}
+
 func (f *ApprovalFlow[D]) senderOrEmpty(portal *bridgev2.Portal) bridgev2.EventSender {
 	if f.sender != nil {
 		return f.sender(portal)
@@ -0,0 +1,189 @@
+package bridgeadapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mau.fi/util/dbutil"
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/networkid"
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+
+	"github.com/beeper/agentremote/pkg/aidb"
+	"github.com/beeper/agentremote/pkg/matrixevents"
+)
+
+// persistedApproval is an approval prompt stored in the bridge DB while it
+// waits for a decision, so that it outlives the bridge process.
+type persistedApproval struct {
+	ApprovalID      string
+	RoomID          id.RoomID
+	OwnerMXID       id.UserID
+	PromptEventID   id.EventID
+	PromptMessageID networkid.MessageID
+	ToolCallID      string
+	ToolName        string
+	TurnID          string
+	Options         []ApprovalOption
+	Data            string
+	ExpiresAt       time.Time
+}
+
+type approvalStoreScope struct {
+	db       *dbutil.Database
+	bridgeID string
+	loginID  string
+}
+
+func approvalStoreScopeForLogin(login *bridgev2.UserLogin) *approvalStoreScope {
+	if login == nil || login.Bridge == nil || login.Bridge.DB == nil || login.Bridge.DB.Database == nil {
+		return nil
+	}
+	return &approvalStoreScope{
+		db:       aidb.NewChild(login.Bridge.DB.Database, dbutil.NoopLogger),
+		bridgeID: string(login.Bridge.DB.BridgeID),
+		loginID:  string(login.ID),
+	}
+}
+
+func (s *approvalStoreScope) save(ctx context.Context, approval persistedApproval) error {
+	options, err := json.Marshal(approval.Options)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx, `
+		INSERT INTO ai_pending_approvals (
+			bridge_id, login_id, approval_id, room_id, owner_mxid, prompt_event_id, prompt_message_id,
+			tool_call_id, tool_name, turn_id, options_json, data_json, expires_at_ms, created_at_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (bridge_id, login_id, approval_id) DO UPDATE SET
+			room_id=excluded.room_id,
+			owner_mxid=excluded.owner_mxid,
+			prompt_event_id=excluded.prompt_event_id,
+			prompt_message_id=excluded.prompt_message_id,
+			tool_call_id=excluded.tool_call_id,
+			tool_name=excluded.tool_name,
+			turn_id=excluded.turn_id,
+			options_json=excluded.options_json,
+			data_json=excluded.data_json,
+			expires_at_ms=excluded.expires_at_ms
+	`,
+		s.bridgeID, s.loginID, approval.ApprovalID, approval.RoomID.String(), approval.OwnerMXID.String(),
+		approval.PromptEventID.String(), string(approval.PromptMessageID), approval.ToolCallID, approval.ToolName,
+		approval.TurnID, string(options), approval.Data, approval.ExpiresAt.UnixMilli(), time.Now().UnixMilli(),
+	)
+	return err
+}
+
+func (s *approvalStoreScope) updateData(ctx context.Context, approvalID, data string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE ai_pending_approvals SET data_json=$4
+		WHERE bridge_id=$1 AND login_id=$2 AND approval_id=$3
+	`, s.bridgeID, s.loginID, approvalID, data)
+	return err
+}
+
+func (s *approvalStoreScope) delete(ctx context.Context, approvalID string) error {
+	_, err := s.db.Exec(ctx, `
+		DELETE FROM ai_pending_approvals
+		WHERE bridge_id=$1 AND login_id=$2 AND approval_id=$3
+	`, s.bridgeID, s.loginID, approvalID)
+	return err
+}
+
+func (s *approvalStoreScope) list(ctx context.Context) ([]persistedApproval, error) {
+	rows, err := s.db.Query(ctx, `
+		SELECT
+			approval_id, room_id, owner_mxid, prompt_event_id, prompt_message_id,
+			tool_call_id, tool_name, turn_id, options_json, data_json, expires_at_ms
+		FROM ai_pending_approvals
+		WHERE bridge_id=$1 AND login_id=$2
+		ORDER BY created_at_ms, approval_id
+	`, s.bridgeID, s.loginID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var approvals []persistedApproval
+	for rows.Next() {
+		var (
+			approval                              persistedApproval
+			roomID, ownerMXID, eventID, messageID string
+			options                               string
+			expiresAtMs                           int64
+		)
+		if err := rows.Scan(
+			&approval.ApprovalID, &roomID, &ownerMXID, &eventID, &messageID,
+			&approval.ToolCallID, &approval.ToolName, &approval.TurnID, &options, &approval.Data, &expiresAtMs,
+		); err != nil {
+			return nil, err
+		}
+		approval.RoomID = id.RoomID(roomID)
+		approval.OwnerMXID = id.UserID(ownerMXID)
+		approval.PromptEventID = id.EventID(eventID)
+		approval.PromptMessageID = networkid.MessageID(messageID)
+		if options != "" {
+			if err := json.Unmarshal([]byte(options), &approval.Options); err != nil {
+				return nil, fmt.Errorf("decode options of approval %s: %w", approval.ApprovalID, err)
+			}
+		}
+		if expiresAtMs > 0 {
+			approval.ExpiresAt = time.UnixMilli(expiresAtMs)
+		}
+		approvals = append(approvals, approval)
+	}
+	return approvals, rows.Err()
+}
+
+// buildApprovalExpiredEdit replaces an approval prompt that can no longer be
+// answered with a notice saying so.
+func buildApprovalExpiredEdit(approval persistedApproval) *bridgev2.ConvertedEdit {
+	toolName := strings.TrimSpace(approval.ToolName)
+	if toolName == "" {
+		toolName = "tool"
+	}
+	toolCallID := strings.TrimSpace(approval.ToolCallID)
+	if toolCallID == "" {
+		toolCallID = approval.ApprovalID
+	}
+	body := fmt.Sprintf("Approval request for %s expired before it was answered.", toolName)
+	metadata := map[string]any{"approvalId": approval.ApprovalID}
+	if approval.TurnID != "" {
+		metadata["turn_id"] = approval.TurnID
+	}
+	return &bridgev2.ConvertedEdit{
+		ModifiedParts: []*bridgev2.ConvertedEditPart{{
+			Type:    event.EventMessage,
+			Content: &event.MessageEventContent{MsgType: event.MsgNotice, Body: body},
+			Extra: map[string]any{
+				matrixevents.BeeperAIKey: map[string]any{
+					"id":       approval.ApprovalID,
+					"role":     "assistant",
+					"metadata": metadata,
+					"parts": []map[string]any{{
+						"type":       "dynamic-tool",
+						"toolName":   toolName,
+						"toolCallId": toolCallID,
+						"state":      "output-denied",
+						"approval": map[string]any{
+							"id":       approval.ApprovalID,
+							"approved": false,
+							"reason":   RejectReasonExpired,
+						},
+					}},
+				},
+				ApprovalDecisionKey: map[string]any{
+					"kind":       RejectReasonExpired,
+					"approvalId": approval.ApprovalID,
+					"toolCallId": toolCallID,
+					"toolName":   toolName,
+				},
+			},
+		}},
+	}
+}
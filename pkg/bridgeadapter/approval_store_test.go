@@ -0,0 +1,162 @@
+package bridgeadapter
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.mau.fi/util/dbutil"
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+
+	"github.com/beeper/agentremote/pkg/aidb"
+)
+
+func setupApprovalStore(t *testing.T) *approvalStoreScope {
+	t.Helper()
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	raw.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = raw.Close() })
+	base, err := dbutil.NewWithDB(raw, "sqlite3")
+	if err != nil {
+		t.Fatalf("wrap db: %v", err)
+	}
+	db := aidb.NewChild(base, dbutil.NoopLogger)
+	if err = aidb.Upgrade(context.Background(), db, "test", ""); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	return &approvalStoreScope{db: db, bridgeID: "bridge", loginID: "login"}
+}
+
+func TestApprovalStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	scope := setupApprovalStore(t)
+	expires := time.UnixMilli(time.Now().Add(time.Minute).UnixMilli())
+	approval := persistedApproval{
+		ApprovalID:      "approval-1",
+		RoomID:          "!room:example.com",
+		OwnerMXID:       "@owner:example.com",
+		PromptEventID:   "$prompt",
+		PromptMessageID: "opencode:prompt",
+		ToolCallID:      "call-1",
+		ToolName:        "bash",
+		Options:         DefaultApprovalOptions(),
+		Data:            `{"SessionID":"ses_1"}`,
+		ExpiresAt:       expires,
+	}
+	if err := scope.save(ctx, approval); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	if err := scope.updateData(ctx, "approval-1", `{"SessionID":"ses_2"}`); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	approvals, err := scope.list(ctx)
+	if err != nil || len(approvals) != 1 {
+		t.Fatalf("expected one approval, got %#v (%v)", approvals, err)
+	}
+	got := approvals[0]
+	if got.PromptMessageID != approval.PromptMessageID || got.OwnerMXID != approval.OwnerMXID || !got.ExpiresAt.Equal(expires) {
+		t.Fatalf("unexpected approval %#v", got)
+	}
+	if got.Data != `{"SessionID":"ses_2"}` || len(got.Options) != len(approval.Options) {
+		t.Fatalf("unexpected data or options %#v", got)
+	}
+	if err = scope.delete(ctx, "approval-1"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if approvals, err = scope.list(ctx); err != nil || len(approvals) != 0 {
+		t.Fatalf("expected no approvals after delete, got %#v (%v)", approvals, err)
+	}
+}
+
+type testApprovalData struct {
+	RoomID    id.RoomID
+	SessionID string
+}
+
+func TestApprovalFlowRehydrate(t *testing.T) {
+	flow := NewApprovalFlow(ApprovalFlowConfig[*testApprovalData]{
+		DeliverDecision: func(context.Context, *bridgev2.Portal, *Pending[*testApprovalData], ApprovalDecisionPayload) error {
+			return nil
+		},
+	})
+	ok := flow.rehydrate(persistedApproval{
+		ApprovalID:    "approval-1",
+		RoomID:        "!room:example.com",
+		OwnerMXID:     "@owner:example.com",
+		PromptEventID: "$prompt",
+		Options:       DefaultApprovalOptions(),
+		Data:          `{"RoomID":"!room:example.com","SessionID":"ses_1"}`,
+		ExpiresAt:     time.Now().Add(time.Minute),
+	})
+	if !ok {
+		t.Fatal("expected approval to be rehydrated")
+	}
+	pending := flow.Get("approval-1")
+	if pending == nil || pending.Data == nil || pending.Data.SessionID != "ses_1" {
+		t.Fatalf("unexpected rehydrated data %#v", pending)
+	}
+	match := flow.matchReaction("$prompt", "@owner:example.com", "✅", time.Now())
+	if !match.ShouldResolve || !match.Decision.Approved {
+		t.Fatalf("expected reaction to rehydrated prompt to resolve, got %#v", match)
+	}
+	if flow.rehydrate(persistedApproval{ApprovalID: "approval-2", Data: "{not json"}) {
+		t.Fatal("expected undecodable data to be rejected")
+	}
+}
+
+func TestApprovalFlowRehydratedApprovalExpires(t *testing.T) {
+	flow := NewApprovalFlow(ApprovalFlowConfig[*testApprovalData]{
+		Login: func() *bridgev2.UserLogin { return nil },
+		DeliverDecision: func(context.Context, *bridgev2.Portal, *Pending[*testApprovalData], ApprovalDecisionPayload) error {
+			return nil
+		},
+	})
+	if !flow.rehydrate(persistedApproval{
+		ApprovalID:    "approval-1",
+		RoomID:        "!room:example.com",
+		OwnerMXID:     "@owner:example.com",
+		PromptEventID: "$prompt",
+		Options:       DefaultApprovalOptions(),
+		ExpiresAt:     time.Now().Add(20 * time.Millisecond),
+	}) {
+		t.Fatal("expected approval to be rehydrated")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for flow.Get("approval-1") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the restored approval to be dropped when it expires")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if match := flow.matchReaction("$prompt", "@owner:example.com", "✅", time.Now()); match.KnownPrompt {
+		t.Fatalf("expected the expired prompt to be forgotten, got %#v", match)
+	}
+	flow.mu.Lock()
+	_, persisted := flow.persisted["approval-1"]
+	flow.mu.Unlock()
+	if persisted {
+		t.Fatal("expected the expired approval to be removed from the persisted set")
+	}
+}
+
+func TestBuildApprovalExpiredEdit(t *testing.T) {
+	edit := buildApprovalExpiredEdit(persistedApproval{ApprovalID: "approval-1", ToolName: "bash"})
+	if len(edit.ModifiedParts) != 1 {
+		t.Fatalf("expected one modified part, got %#v", edit)
+	}
+	part := edit.ModifiedParts[0]
+	if part.Type != event.EventMessage || part.Content.Body != "Approval request for bash expired before it was answered." {
+		t.Fatalf("unexpected edit content %#v", part.Content)
+	}
+	decision, _ := part.Extra[ApprovalDecisionKey].(map[string]any)
+	if decision["kind"] != RejectReasonExpired || decision["toolCallId"] != "approval-1" {
+		t.Fatalf("unexpected decision metadata %#v", decision)
+	}
+}
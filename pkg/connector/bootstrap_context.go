@@ -26,7 +26,7 @@ func (oc *AIClient) buildBootstrapContextFiles(ctx context.Context, agentID stri
 		string(oc.UserLogin.Bridge.DB.BridgeID),
 		string(oc.UserLogin.ID),
 		agentID,
-	)
+	).WithAuthor(textfs.Author{Kind: textfs.AuthorSystem})
 
 	skipBootstrap := false
 	if oc.connector != nil && oc.connector.Config.Agents != nil && oc.connector.Config.Agents.Defaults != nil {
@@ -327,12 +327,32 @@ type AIClient struct {
 	modelCatalogLoaded bool
 	modelCatalogCache  []ModelCatalogEntry
 
-	// MCP tool cache
-	mcpToolsMu        sync.Mutex
-	mcpTools          []ToolDefinition
-	mcpToolSet        map[string]struct{}
-	mcpToolServer     map[string]string
-	mcpToolsFetchedAt time.Time
+	// MCP tool, resource and prompt caches
+	mcpToolsMu            sync.Mutex
+	mcpTools              []ToolDefinition
+	mcpToolSet            map[string]struct{}
+	mcpToolServer         map[string]string
+	mcpToolsFetchedAt     time.Time
+	mcpResources          []mcpResourceEntry
+	mcpResourcesFetchedAt time.Time
+	mcpResourceContents   map[MCPResourceRef]mcpResourceContent
+	mcpPrompts            []mcpPromptEntry
+	mcpPromptsFetchedAt   time.Time
+
+	// Long-lived MCP sessions receiving change notifications, per server
+	mcpWatchesMu sync.Mutex
+	mcpWatches   map[string]*mcpResourceWatch
+
+	// Rooms already told about a usage budget downgrade, per budget window
+	usageBudgetMu      sync.Mutex
+	usageBudgetNotices map[string]struct{}
+
+	// MCP elicitation requests waiting for the user's reply, per room
+	mcpElicitationsMu sync.Mutex
+	mcpElicitations   map[id.RoomID]*pendingMCPElicitation
+
+	// Commands started by the exec tool
+	execSessions execSessionManager
 
 	// Tool approvals (e.g. OpenAI MCP approval requests)
 	approvalFlow *bridgeadapter.ApprovalFlow[*pendingToolApprovalData]
@@ -437,7 +457,8 @@ func newAIClient(login *bridgev2.UserLogin, connector *OpenAIConnector, apiKey s
 	}, log)
 
 	// Initialize provider based on login metadata
-	// All providers use the OpenAI SDK with different base URLs
+	// Most providers use the OpenAI SDK with different base URLs; native providers
+	// only set oc.provider and are streamed through streamProviderMessages.
 	switch meta.Provider {
 	case ProviderBeeper:
 		beeperBaseURL := connector.resolveBeeperBaseURL(meta)
@@ -488,6 +509,43 @@ func newAIClient(login *bridgev2.UserLogin, connector *OpenAIConnector, apiKey s
 		}
 		oc.provider = provider
 		oc.api = provider.Client()
+
+	case ProviderAnthropic:
+		anthropicURL := connector.resolveAnthropicBaseURL()
+		log.Info().
+			Str("provider", meta.Provider).
+			Str("anthropic_url", anthropicURL).
+			Msg("Initializing AI provider endpoint")
+		provider, err := NewAnthropicProvider(key, anthropicURL, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Anthropic provider: %w", err)
+		}
+		oc.provider = provider
+
+	case ProviderGemini:
+		geminiURL := connector.resolveGeminiBaseURL()
+		log.Info().
+			Str("provider", meta.Provider).
+			Str("gemini_url", geminiURL).
+			Msg("Initializing AI provider endpoint")
+		provider, err := NewGeminiProvider(key, geminiURL, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gemini provider: %w", err)
+		}
+		oc.provider = provider
+
+	case ProviderLocal:
+		localURL := connector.resolveLocalBaseURL()
+		log.Info().
+			Str("provider", meta.Provider).
+			Str("local_url", localURL).
+			Msg("Initializing AI provider endpoint")
+		provider, err := NewLocalProvider(key, localURL, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create local provider: %w", err)
+		}
+		oc.provider = provider
+		oc.api = provider.Client()
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", meta.Provider)
 	}
@@ -994,6 +1052,7 @@ func (oc *AIClient) Connect(ctx context.Context) {
 	})
 
 	restoreSystemEventsFromDB(oc)
+	oc.approvalFlow.RestorePending(oc.backgroundContext(ctx))
 
 	if oc.scheduler != nil {
 		oc.scheduler.Start(ctx)
@@ -1015,6 +1074,11 @@ func (oc *AIClient) Disconnect() {
 	oc.loggedIn.Store(false)
 
 	oc.stopLifecycleIntegrations()
+	oc.execSessions.KillAll()
+	oc.closeMCPResourceWatches()
+	if oc.scheduler != nil {
+		oc.scheduler.Stop()
+	}
 	// Stop all login-scoped integration workers for this login.
 	if oc.UserLogin != nil && oc.UserLogin.Bridge != nil && oc.UserLogin.Bridge.DB != nil {
 		bridgeID := string(oc.UserLogin.Bridge.DB.BridgeID)
@@ -1341,6 +1405,29 @@ func (oc *AIClient) defaultModelForProvider() string {
 			return providers.Beeper.DefaultModel
 		}
 		return DefaultModelBeeper
+	case ProviderAnthropic:
+		if providers.Anthropic.DefaultModel != "" {
+			return providers.Anthropic.DefaultModel
+		}
+		return DefaultModelAnthropic
+	case ProviderGemini:
+		if providers.Gemini.DefaultModel != "" {
+			return providers.Gemini.DefaultModel
+		}
+		return DefaultModelGemini
+	case ProviderLocal:
+		if providers.Local.DefaultModel != "" {
+			return providers.Local.DefaultModel
+		}
+		// There is no well-known default; use the first discovered model.
+		if loginMeta.ModelCache != nil {
+			for _, model := range loginMeta.ModelCache.Models {
+				if model.Provider == ProviderLocal {
+					return model.ID
+				}
+			}
+		}
+		return ""
 	default:
 		return DefaultModelOpenRouter
 	}
@@ -1759,6 +1846,9 @@ func (oc *AIClient) listAvailableModels(ctx context.Context, forceRefresh bool)
 
 	oc.loggerForContext(ctx).Debug().Msg("Loading derived model catalog")
 	allModels := oc.loadModelCatalogModels(ctx)
+	if meta.Provider == ProviderLocal {
+		allModels = oc.discoverLocalModels(ctx, allModels)
+	}
 
 	// Update cache
 	if meta.ModelCache == nil {
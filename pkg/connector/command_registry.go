@@ -21,10 +21,15 @@ var aiCommandRegistry = commandregistry.NewRegistry()
 var moduleCommandRegisterMu sync.Mutex
 var moduleCommandsRegistered = map[string]struct{}{}
 var allowedUserCommandNames = map[string]struct{}{
-	"new":    {},
-	"reset":  {},
-	"status": {},
-	"stop":   {},
+	"approvals": {},
+	"cron":      {},
+	"history":   {},
+	"new":       {},
+	"prompt":    {},
+	"reset":     {},
+	"status":    {},
+	"stop":      {},
+	"usage":     {},
 }
 
 func isUserFacingCommand(name string) bool {
@@ -0,0 +1,56 @@
+package commandregistry
+
+import (
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/commands"
+	"maunium.net/go/mautrix/event"
+)
+
+// RegisterForPortalLogin registers def under section as a command that only
+// runs in portals owned by one of the sender's logins with a client of type C.
+func RegisterForPortalLogin[C bridgev2.NetworkAPI](r *Registry, section commands.HelpSection, def Definition) *commands.FullHandler {
+	def.Section = section
+	def.RequiresPortal = true
+	def.RequiresLogin = true
+	def.HasLogin = func(ce *commands.Event) bool {
+		_, ok := PortalLoginClient[C](ce)
+		return ok
+	}
+	return r.Register(def)
+}
+
+// PortalLoginClient returns the client of the login that owns the command's
+// portal, as long as that login belongs to the sender and its client is a C.
+func PortalLoginClient[C bridgev2.NetworkAPI](ce *commands.Event) (C, bool) {
+	var zero C
+	if ce == nil || ce.User == nil || ce.Portal == nil || ce.Portal.Receiver == "" || ce.Bridge == nil {
+		return zero, false
+	}
+	login := ce.Bridge.GetCachedUserLoginByID(ce.Portal.Receiver)
+	if login == nil || login.UserMXID != ce.User.MXID {
+		return zero, false
+	}
+	client, ok := login.Client.(C)
+	return client, ok
+}
+
+// ReplyFailure marks the command as failed and replies with message.
+func ReplyFailure(ce *commands.Event, message string, reason event.MessageStatusReason) {
+	if ce.MessageStatus != nil {
+		ce.MessageStatus.Status = event.MessageStatusFail
+		ce.MessageStatus.ErrorReason = reason
+		ce.MessageStatus.Message = message
+		ce.MessageStatus.IsCertain = true
+	}
+	ce.Reply("%s", message)
+}
+
+// AddTo adds all handlers of the registry to proc.
+func (r *Registry) AddTo(proc *commands.Processor) {
+	handlers := r.All()
+	commandHandlers := make([]commands.CommandHandler, 0, len(handlers))
+	for _, handler := range handlers {
+		commandHandlers = append(commandHandlers, handler)
+	}
+	proc.AddHandlers(commandHandlers...)
+}
@@ -0,0 +1,116 @@
+package connector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"maunium.net/go/mautrix/bridgev2/commands"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/pkg/connector/commandregistry"
+)
+
+const approvalsUsage = "Usage: `!ai approvals [allow|deny <tool> [<argument> [glob|regex|prefix|domain] <pattern>] | remove <id>]`. " +
+	"Name MCP tools as `mcp:<server>:<tool>`. In globs `*` stays within a path segment and `**` spans segments; " +
+	"allow regexes must match the whole value; " +
+	"prefer prefix for file paths and domain for URLs."
+
+var _ = registerAICommand(commandregistry.Definition{
+	Name:          "approvals",
+	Description:   "List or change the rules that allow or deny tool calls by their arguments",
+	Args:          "[allow|deny|remove] [tool] [argument] [pattern]",
+	Section:       HelpSectionAI,
+	RequiresLogin: true,
+	Handler:       fnApprovals,
+})
+
+func fnApprovals(ce *commands.Event) {
+	client := getAIClient(ce)
+	if client == nil {
+		markCommandFailure(ce, "Couldn't load AI settings. Try again.", event.MessageStatusGenericError)
+		ce.Reply("Couldn't load AI settings. Try again.")
+		return
+	}
+	if len(ce.Args) == 0 {
+		ce.Reply("%s", formatToolArgumentRules(client.toolArgumentRules()))
+		return
+	}
+	switch strings.ToLower(ce.Args[0]) {
+	case "list":
+		ce.Reply("%s", formatToolArgumentRules(client.toolArgumentRules()))
+	case "remove", "delete", "rm":
+		if len(ce.Args) != 2 {
+			markCommandFailure(ce, "Missing rule ID.", event.MessageStatusUnsupported)
+			ce.Reply("%s", approvalsUsage)
+			return
+		}
+		if err := client.removeToolArgumentRule(ce.Ctx, ce.Args[1]); err != nil {
+			markCommandFailure(ce, "Couldn't remove rule: "+err.Error(), event.MessageStatusGenericError)
+			ce.Reply("Couldn't remove rule: %s", err.Error())
+			return
+		}
+		ce.Reply("Removed rule `%s`.", ce.Args[1])
+	case ToolArgumentRuleAllow, ToolArgumentRuleDeny:
+		rule, err := parseToolArgumentRuleArgs(ce.Args)
+		if err == nil {
+			rule, err = client.addToolArgumentRule(ce.Ctx, rule)
+		}
+		if err != nil {
+			markCommandFailure(ce, "Couldn't add rule: "+err.Error(), event.MessageStatusGenericError)
+			ce.Reply("Couldn't add rule: %s\n\n%s", err.Error(), approvalsUsage)
+			return
+		}
+		ce.Reply("Added rule %s", formatToolArgumentRule(rule))
+	default:
+		markCommandFailure(ce, "Unknown approvals option: "+ce.Args[0], event.MessageStatusUnsupported)
+		ce.Reply("%s", approvalsUsage)
+	}
+}
+
+// parseToolArgumentRuleArgs parses `allow|deny <tool> [<argument> [match] <pattern>]`.
+// The pattern is everything after the argument and match, so regexes may
+// contain spaces.
+func parseToolArgumentRuleArgs(args []string) (ToolArgumentRule, error) {
+	if len(args) < 2 {
+		return ToolArgumentRule{}, errors.New("missing tool name")
+	}
+	rule := ToolArgumentRule{Effect: args[0], ToolName: args[1]}
+	if spec, ok := strings.CutPrefix(args[1], "mcp:"); ok {
+		rule.ToolKind = string(ToolApprovalKindMCP)
+		rule.ToolName = spec
+		if server, tool, found := strings.Cut(spec, ":"); found {
+			rule.ServerLabel, rule.ToolName = server, tool
+		}
+	} else {
+		rule.ToolKind = string(ToolApprovalKindBuiltin)
+	}
+	rest := args[2:]
+	if len(rest) == 0 {
+		return normalizeToolArgumentRule(rule)
+	}
+	rule.Argument, rest = rest[0], rest[1:]
+	if len(rest) > 1 {
+		switch strings.ToLower(rest[0]) {
+		case ToolArgumentMatchGlob, ToolArgumentMatchRegex, ToolArgumentMatchPrefix, ToolArgumentMatchDomain:
+			rule.Match, rest = rest[0], rest[1:]
+		}
+	}
+	if len(rest) == 0 {
+		return ToolArgumentRule{}, fmt.Errorf("missing pattern for argument %s", rule.Argument)
+	}
+	rule.Pattern = strings.Join(rest, " ")
+	return normalizeToolArgumentRule(rule)
+}
+
+func formatToolArgumentRules(rules []ToolArgumentRule) string {
+	if len(rules) == 0 {
+		return "No tool argument rules.\n\n" + approvalsUsage
+	}
+	var sb strings.Builder
+	sb.WriteString("Tool argument rules (deny rules win):")
+	for _, rule := range rules {
+		fmt.Fprintf(&sb, "\n- %s", formatToolArgumentRule(rule))
+	}
+	return sb.String()
+}
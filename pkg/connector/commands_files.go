@@ -0,0 +1,81 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"maunium.net/go/mautrix/bridgev2/commands"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/pkg/connector/commandregistry"
+	"github.com/beeper/agentremote/pkg/textfs"
+)
+
+const historyUsage = "Usage: `!ai history <path> [restore <version>]`"
+
+var _ = registerAICommand(commandregistry.Definition{
+	Name:           "history",
+	Description:    "List earlier versions of an agent file or restore one",
+	Args:           "<path> [restore <version>]",
+	Section:        HelpSectionAI,
+	RequiresPortal: true,
+	RequiresLogin:  true,
+	Handler:        fnHistory,
+})
+
+func fnHistory(ce *commands.Event) {
+	client, _, ok := requireClientMeta(ce)
+	if !ok {
+		return
+	}
+	if len(ce.Args) != 1 && (len(ce.Args) != 3 || !strings.EqualFold(ce.Args[1], "restore")) {
+		markCommandFailure(ce, "Invalid history arguments.", event.MessageStatusUnsupported)
+		ce.Reply("%s", historyUsage)
+		return
+	}
+	store, err := textFSStoreForPortal(client, ce.Portal)
+	if err != nil {
+		markCommandFailure(ce, err.Error(), event.MessageStatusGenericError)
+		ce.Reply("Couldn't open agent files: %s", err.Error())
+		return
+	}
+	path := ce.Args[0]
+
+	if len(ce.Args) == 3 {
+		version, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(ce.Args[2]), "v"))
+		if err != nil {
+			markCommandFailure(ce, "Invalid version: "+ce.Args[2], event.MessageStatusUnsupported)
+			ce.Reply("%s", historyUsage)
+			return
+		}
+		entry, err := store.WithAuthor(textfs.Author{Kind: textfs.AuthorUser}).Revert(ce.Ctx, path, version)
+		if err != nil {
+			markCommandFailure(ce, "Couldn't restore file: "+err.Error(), event.MessageStatusGenericError)
+			ce.Reply("Couldn't restore `%s` to version %d: %s", path, version, err.Error())
+			return
+		}
+		go func(ctx context.Context, path string) {
+			ctx, cancel := context.WithTimeout(ctx, textFSPostWriteTimeout)
+			defer cancel()
+			notifyIntegrationFileChanged(ctx, path)
+			maybeRefreshAgentIdentity(ctx, path)
+		}(WithBridgeToolContext(context.Background(), &BridgeToolContext{Client: client, Portal: ce.Portal, Meta: portalMeta(ce.Portal)}), entry.Path)
+		ce.Reply("Restored `%s` to version %d.", entry.Path, version)
+		return
+	}
+
+	revisions, err := store.History(ce.Ctx, path)
+	if err != nil {
+		markCommandFailure(ce, "Couldn't load file history: "+err.Error(), event.MessageStatusGenericError)
+		ce.Reply("Couldn't load history of `%s`: %s", path, err.Error())
+		return
+	}
+	current, _, err := store.Read(ce.Ctx, path)
+	if err != nil {
+		markCommandFailure(ce, "Couldn't read file: "+err.Error(), event.MessageStatusGenericError)
+		ce.Reply("Couldn't read `%s`: %s", path, err.Error())
+		return
+	}
+	ce.Reply("%s", formatFileRevisions(path, summarizeFileRevisions(revisions, current)))
+}
@@ -0,0 +1,169 @@
+package connector
+
+import (
+	"cmp"
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/commands"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/pkg/connector/commandregistry"
+)
+
+var _ = registerAICommand(commandregistry.Definition{
+	Name:           "prompt",
+	Description:    "List MCP prompts or run one in this chat",
+	Args:           "[name] [key=value...]",
+	Section:        HelpSectionAI,
+	RequiresPortal: true,
+	RequiresLogin:  true,
+	Handler:        fnMCPPrompt,
+})
+
+func fnMCPPrompt(ce *commands.Event) {
+	client, meta, ok := requireClientMeta(ce)
+	if !ok {
+		return
+	}
+	if !client.isMCPConfigured() {
+		markCommandFailure(ce, "No MCP servers connected.", event.MessageStatusUnsupported)
+		ce.Reply("No MCP servers connected. Add one with %s", mcpAddUsage(client.isMCPStdioEnabled()))
+		return
+	}
+	entries, err := client.mcpPromptEntries(ce.Ctx)
+	if err != nil {
+		markCommandFailure(ce, "Couldn't list MCP prompts: "+err.Error(), event.MessageStatusGenericError)
+		ce.Reply("Couldn't list MCP prompts: %s", err.Error())
+		return
+	}
+	if len(ce.Args) == 0 {
+		ce.Reply("%s", formatMCPPromptList(entries))
+		return
+	}
+
+	prompt, err := findMCPPrompt(entries, ce.Args[0])
+	if err != nil {
+		markCommandFailure(ce, err.Error(), event.MessageStatusUnsupported)
+		ce.Reply("%s", err.Error())
+		return
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(ce.RawArgs), ce.Args[0]))
+	runMCPPrompt(ce, client, meta, prompt, ce.Args[1:], rest)
+}
+
+// runMCPPrompt renders prompt with the given arguments and sends the result
+// into the chat as a user turn.
+func runMCPPrompt(ce *commands.Event, client *AIClient, meta *PortalMetadata, prompt mcpPromptEntry, tokens []string, rawRest string) {
+	args, err := parseMCPPromptArgs(prompt, tokens, rawRest)
+	if err != nil {
+		markCommandFailure(ce, err.Error(), event.MessageStatusGenericError)
+		ce.Reply("Prompt `%s`: %s", prompt.Name, err.Error())
+		return
+	}
+	result, err := client.getMCPPrompt(ce.Ctx, prompt, args)
+	if err != nil {
+		markCommandFailure(ce, err.Error(), event.MessageStatusGenericError)
+		ce.Reply("%s", err.Error())
+		return
+	}
+	body := renderMCPPromptMessages(result)
+	if body == "" {
+		markCommandFailure(ce, "Prompt rendered no text.", event.MessageStatusGenericError)
+		ce.Reply("Prompt `%s` rendered no text.", prompt.Name)
+		return
+	}
+	if _, _, err := client.dispatchInternalMessage(ce.Ctx, ce.Portal, meta, body, "mcp-prompt", false); err != nil {
+		markCommandFailure(ce, "Couldn't send prompt: "+err.Error(), event.MessageStatusGenericError)
+		ce.Reply("Couldn't send prompt: %s", err.Error())
+	}
+}
+
+// mcpPromptCommandName is the room command that runs entry: the prompt name,
+// or "server:name" when several servers publish it. It is empty when the name
+// can't be typed as a command or would shadow an AI command; such prompts stay
+// reachable through !ai prompt.
+func mcpPromptCommandName(entry mcpPromptEntry, entries []mcpPromptEntry) string {
+	name := strings.ToLower(entry.Name)
+	if name == "" || strings.ContainsFunc(name, func(r rune) bool { return r == '/' || r <= ' ' }) {
+		return ""
+	}
+	for _, other := range entries {
+		if other.Server != entry.Server && strings.EqualFold(other.Name, entry.Name) {
+			return entry.Server + ":" + name
+		}
+	}
+	if aiCommandRegistry.Get(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// mcpPromptCommands registers one command per discovered MCP prompt. Prompts
+// differ per login, so these live in their own registry and are typed as
+// "/<name> [key=value...]" in the chat rather than through !ai.
+func mcpPromptCommands(entries []mcpPromptEntry) *commandregistry.Registry {
+	registry := commandregistry.NewRegistry()
+	for _, entry := range entries {
+		name := mcpPromptCommandName(entry, entries)
+		if name == "" {
+			continue
+		}
+		prompt := entry
+		registry.Register(commandregistry.Definition{
+			Name:           name,
+			Description:    cmp.Or(firstLine(entry.Description), entry.Title, "MCP prompt from "+entry.Server),
+			Args:           mcpPromptArgsUsage(entry),
+			Section:        HelpSectionAI,
+			RequiresPortal: true,
+			Handler: func(ce *commands.Event) {
+				client, meta, ok := requireClientMeta(ce)
+				if !ok {
+					return
+				}
+				runMCPPrompt(ce, client, meta, prompt, ce.Args, ce.RawArgs)
+			},
+		})
+	}
+	return registry
+}
+
+// handleMCPPromptCommand runs "/<prompt> [args]" messages as MCP prompt
+// commands. Anything else, including unknown slash commands, is left for the
+// normal chat flow.
+func (oc *AIClient) handleMCPPromptCommand(ctx context.Context, portal *bridgev2.Portal, evt *event.Event, body string) bool {
+	if !strings.HasPrefix(body, "/") || !oc.isMCPConfigured() {
+		return false
+	}
+	fields := strings.Fields(body[1:])
+	if len(fields) == 0 {
+		return false
+	}
+	entries, err := oc.mcpPromptEntries(ctx)
+	if err != nil || len(entries) == 0 {
+		return false
+	}
+	name := strings.ToLower(fields[0])
+	handler := mcpPromptCommands(entries).Get(name)
+	if handler == nil {
+		return false
+	}
+	ce := &commands.Event{
+		Bot:        oc.UserLogin.Bridge.Bot,
+		Bridge:     oc.UserLogin.Bridge,
+		Portal:     portal,
+		RoomID:     portal.MXID,
+		OrigRoomID: portal.MXID,
+		EventID:    evt.ID,
+		User:       oc.UserLogin.User,
+		Command:    name,
+		Args:       fields[1:],
+		RawArgs:    strings.TrimSpace(strings.TrimSpace(body[1:])[len(fields[0]):]),
+		Ctx:        ctx,
+		Log:        zerolog.Ctx(ctx),
+	}
+	handler.Run(ce)
+	return true
+}
@@ -0,0 +1,153 @@
+package connector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"maunium.net/go/mautrix/bridgev2/commands"
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+
+	"github.com/beeper/agentremote/pkg/connector/commandregistry"
+)
+
+const usageBreakdownLimit = 10
+
+var _ = registerAICommand(commandregistry.Definition{
+	Name:          "usage",
+	Description:   "Show token usage, estimated cost and budgets for this account",
+	Args:          "[today|month|all] [model|agent|room]",
+	Section:       HelpSectionAI,
+	RequiresLogin: true,
+	Handler:       fnUsage,
+})
+
+func fnUsage(ce *commands.Event) {
+	client := getAIClient(ce)
+	if client == nil {
+		markCommandFailure(ce, "Couldn't load AI settings. Try again.", event.MessageStatusGenericError)
+		ce.Reply("Couldn't load AI settings. Try again.")
+		return
+	}
+	scope := usageScope(client)
+	if scope == nil {
+		markCommandFailure(ce, "Usage tracking is unavailable.", event.MessageStatusUnsupported)
+		ce.Reply("Usage tracking is unavailable.")
+		return
+	}
+
+	period, groupBy := "month", "model"
+	for _, arg := range ce.Args {
+		switch strings.ToLower(strings.TrimSpace(arg)) {
+		case "today", "day":
+			period = "today"
+		case "month":
+			period = "month"
+		case "all":
+			period = "all"
+		case "model", "models":
+			groupBy = "model"
+		case "agent", "agents":
+			groupBy = "agent"
+		case "room", "rooms":
+			groupBy = "room"
+		default:
+			markCommandFailure(ce, "Unknown usage option: "+arg, event.MessageStatusUnsupported)
+			ce.Reply("Usage: `!ai usage [today|month|all] [model|agent|room]`")
+			return
+		}
+	}
+
+	_, loc := client.resolveUserTimezone()
+	dayStart, monthStart := usagePeriodStarts(time.Now(), loc)
+	since, label := monthStart, "This month"
+	switch period {
+	case "today":
+		since, label = dayStart, "Today"
+	case "all":
+		since, label = time.Time{}, "All time"
+	}
+
+	totals, err := scope.totalsSince(ce.Ctx, since, "")
+	var today []usageTotals
+	if err == nil && period != "today" {
+		today, err = scope.totalsSince(ce.Ctx, dayStart, "")
+	}
+	var groups []usageTotals
+	if err == nil {
+		groups, err = scope.totalsSince(ce.Ctx, since, groupBy)
+	}
+	var budgets []usageBudgetState
+	budgetCfg := client.usageBudgetConfig()
+	if err == nil {
+		budgets, err = client.usageBudgetStates(ce.Ctx, budgetCfg)
+	}
+	if err != nil {
+		markCommandFailure(ce, "Couldn't load usage: "+err.Error(), event.MessageStatusGenericError)
+		ce.Reply("Couldn't load usage: %s", err.Error())
+		return
+	}
+
+	var sb strings.Builder
+	if len(totals) == 0 || totals[0].Responses == 0 {
+		fmt.Fprintf(&sb, "%s: no usage recorded.", label)
+	} else {
+		fmt.Fprintf(&sb, "%s: %s", label, formatUsageTotals(totals[0]))
+		if len(today) > 0 && today[0].Responses > 0 {
+			fmt.Fprintf(&sb, "\nToday: %s", formatUsageTotals(today[0]))
+		}
+	}
+	if len(groups) > 0 && (len(groups) > 1 || groups[0].Key != "") {
+		fmt.Fprintf(&sb, "\n\nBy %s:", groupBy)
+		for i, group := range groups {
+			if i == usageBreakdownLimit {
+				fmt.Fprintf(&sb, "\n- …and %d more", len(groups)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "\n- %s — %s", client.usageGroupLabel(ce, groupBy, group.Key), formatUsageTotals(group))
+		}
+	}
+	if len(budgets) > 0 {
+		fmt.Fprintf(&sb, "\n\nBudgets (when used up: %s):", usageBudgetAction(budgetCfg))
+		for _, budget := range budgets {
+			fmt.Fprintf(&sb, "\n- %s: %s of %s", budget.Period, formatUSDMicros(budget.SpentMicros), formatUSDMicros(budget.LimitMicros))
+			if budget.exceeded() {
+				sb.WriteString(" (used up)")
+			}
+		}
+	}
+	ce.Reply("%s", sb.String())
+}
+
+func formatUsageTotals(t usageTotals) string {
+	parts := []string{fmt.Sprintf("%d responses", t.Responses)}
+	in := formatCompactTokens(t.PromptTokens) + " in"
+	if t.CachedTokens > 0 {
+		in += " (" + formatCompactTokens(t.CachedTokens) + " cached)"
+	}
+	parts = append(parts, in)
+	out := formatCompactTokens(t.CompletionTokens) + " out"
+	if t.ReasoningTokens > 0 {
+		out += " (" + formatCompactTokens(t.ReasoningTokens) + " reasoning)"
+	}
+	parts = append(parts, out)
+	if t.CostMicros > 0 {
+		parts = append(parts, "~"+formatUSDMicros(t.CostMicros))
+	} else {
+		parts = append(parts, "no price")
+	}
+	return strings.Join(parts, " · ")
+}
+
+func (oc *AIClient) usageGroupLabel(ce *commands.Event, groupBy, key string) string {
+	if key == "" {
+		return "(none)"
+	}
+	if groupBy == "room" {
+		if portal := oc.portalByRoomID(ce.Ctx, id.RoomID(key)); portal != nil && strings.TrimSpace(portal.Name) != "" {
+			return portal.Name
+		}
+	}
+	return key
+}
@@ -275,6 +275,30 @@ func extractAssistantTextFromCompletion(resp *openai.ChatCompletion) string {
 	return strings.TrimSpace(text)
 }
 
+// completeSummarizationRequest runs a one-shot summarization request, using the
+// native provider when the login doesn't speak the OpenAI API.
+func (oc *AIClient) completeSummarizationRequest(ctx context.Context, request openai.ChatCompletionNewParams) (string, error) {
+	if provider := oc.nativeProvider(); provider != nil {
+		params := GenerateParams{
+			Model:   request.Model,
+			Context: ChatMessagesToPromptContext(request.Messages),
+		}
+		if request.MaxCompletionTokens.Valid() {
+			params.MaxCompletionTokens = int(request.MaxCompletionTokens.Value)
+		}
+		resp, err := provider.Generate(ctx, params)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(resp.Content), nil
+	}
+	resp, err := oc.api.Chat.Completions.New(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	return extractAssistantTextFromCompletion(resp), nil
+}
+
 type generateSummaryParams struct {
 	Model            string
 	ReserveTokens    int
@@ -341,7 +365,7 @@ func (oc *AIClient) generateSummary(
 
 	var lastErr error
 	for attempt := 0; attempt < 3; attempt++ {
-		resp, err := oc.api.Chat.Completions.New(ctx, request)
+		summary, err := oc.completeSummarizationRequest(ctx, request)
 		if err != nil {
 			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 				return "", err
@@ -358,7 +382,6 @@ func (oc *AIClient) generateSummary(
 			}
 			continue
 		}
-		summary := extractAssistantTextFromCompletion(resp)
 		if summary == "" {
 			lastErr = errors.New("empty summary output")
 			continue
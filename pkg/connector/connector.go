@@ -43,6 +43,8 @@ type OpenAIConnector struct {
 
 	clientsMu sync.Mutex
 	clients   map[networkid.UserLoginID]bridgev2.NetworkAPI
+
+	webhooks webhookGuard
 }
 
 func (oc *OpenAIConnector) Init(bridge *bridgev2.Bridge) {
@@ -187,12 +189,15 @@ func (oc *OpenAIConnector) LoadUserLogin(ctx context.Context, login *bridgev2.Us
 
 // Package-level flow definitions (use Provider* constants as flow IDs)
 func (oc *OpenAIConnector) GetLoginFlows() []bridgev2.LoginFlow {
-	flows := make([]bridgev2.LoginFlow, 0, 3)
+	flows := make([]bridgev2.LoginFlow, 0, 6)
 	if !oc.hasManagedBeeperAuth() {
 		flows = append(flows, bridgev2.LoginFlow{ID: ProviderBeeper, Name: "Beeper Cloud"})
 	}
 	flows = append(flows,
 		bridgev2.LoginFlow{ID: ProviderMagicProxy, Name: "Magic Proxy"},
+		bridgev2.LoginFlow{ID: ProviderAnthropic, Name: "Anthropic"},
+		bridgev2.LoginFlow{ID: ProviderGemini, Name: "Google Gemini"},
+		bridgev2.LoginFlow{ID: ProviderLocal, Name: "Local models (Ollama, llama.cpp, vLLM)"},
 		bridgev2.LoginFlow{ID: FlowCustom, Name: "Manual"},
 	)
 	return flows
@@ -111,7 +111,8 @@ func hasContextLengthSignal(text string) bool {
 		strings.Contains(lower, "request too large") ||
 		strings.Contains(lower, "413 too large") ||
 		strings.Contains(lower, "request exceeds the maximum size") ||
-		strings.Contains(lower, "exceeds model context window")
+		strings.Contains(lower, "exceeds model context window") ||
+		strings.Contains(lower, "exceeds the maximum number of tokens allowed")
 }
 
 func safeErrorString(err error) (text string) {
@@ -270,6 +271,7 @@ func IsAuthError(err error) bool {
 		"no api key found",
 		"re-authenticate",
 		"oauth token refresh failed",
+		"api key not valid",
 	})
 }
 
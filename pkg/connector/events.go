@@ -100,22 +100,30 @@ var CommandDescriptionEventType = matrixevents.CommandDescriptionEventType
 
 // ModelInfo describes a single AI model's capabilities
 type ModelInfo struct {
-	ID                  string   `json:"id"`
-	Name                string   `json:"name"`
-	Provider            string   `json:"provider"`
-	API                 string   `json:"api,omitempty"`
-	Description         string   `json:"description,omitempty"`
-	SupportsVision      bool     `json:"supports_vision"`
-	SupportsToolCalling bool     `json:"supports_tool_calling"`
-	SupportsPDF         bool     `json:"supports_pdf,omitempty"`
-	SupportsReasoning   bool     `json:"supports_reasoning"`
-	SupportsWebSearch   bool     `json:"supports_web_search"`
-	SupportsImageGen    bool     `json:"supports_image_gen,omitempty"`
-	SupportsAudio       bool     `json:"supports_audio,omitempty"`
-	SupportsVideo       bool     `json:"supports_video,omitempty"`
-	ContextWindow       int      `json:"context_window,omitempty"`
-	MaxOutputTokens     int      `json:"max_output_tokens,omitempty"`
-	AvailableTools      []string `json:"available_tools,omitempty"`
+	ID                  string        `json:"id"`
+	Name                string        `json:"name"`
+	Provider            string        `json:"provider"`
+	API                 string        `json:"api,omitempty"`
+	Description         string        `json:"description,omitempty"`
+	SupportsVision      bool          `json:"supports_vision"`
+	SupportsToolCalling bool          `json:"supports_tool_calling"`
+	SupportsPDF         bool          `json:"supports_pdf,omitempty"`
+	SupportsReasoning   bool          `json:"supports_reasoning"`
+	SupportsWebSearch   bool          `json:"supports_web_search"`
+	SupportsImageGen    bool          `json:"supports_image_gen,omitempty"`
+	SupportsAudio       bool          `json:"supports_audio,omitempty"`
+	SupportsVideo       bool          `json:"supports_video,omitempty"`
+	ContextWindow       int           `json:"context_window,omitempty"`
+	MaxOutputTokens     int           `json:"max_output_tokens,omitempty"`
+	AvailableTools      []string      `json:"available_tools,omitempty"`
+	Pricing             *ModelPricing `json:"pricing,omitempty"`
+}
+
+// ModelPricing is the price of a model in USD per million tokens.
+type ModelPricing struct {
+	Input       float64 `json:"input" yaml:"input"`
+	Output      float64 `json:"output" yaml:"output"`
+	CachedInput float64 `json:"cached_input,omitempty" yaml:"cached_input"`
 }
 
 // AgentsEventContent configures active agents in a room
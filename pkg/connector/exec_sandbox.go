@@ -0,0 +1,297 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/beeper/agentremote/pkg/textfs"
+)
+
+const (
+	ExecSandboxBwrap     = "bwrap"
+	ExecSandboxAllowlist = "allowlist"
+	ExecSandboxNone      = "none"
+)
+
+const execDefaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// bwrapSystemPaths are bound read-only into the sandbox when they exist. The
+// rest of the host filesystem (bridge config, database, home directories)
+// stays invisible.
+var bwrapSystemPaths = []string{
+	"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64",
+	"/etc/alternatives", "/etc/ssl", "/etc/ca-certificates", "/etc/ld.so.cache",
+	"/etc/localtime", "/etc/passwd", "/etc/group", "/etc/nsswitch.conf",
+	"/etc/hosts", "/etc/resolv.conf",
+}
+
+var execEnvKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WithDefaults returns a copy of the config with unset values filled in.
+func (c *ExecToolsConfig) WithDefaults() *ExecToolsConfig {
+	out := ExecToolsConfig{}
+	if c != nil {
+		out = *c
+	}
+	out.Sandbox = strings.ToLower(strings.TrimSpace(out.Sandbox))
+	if out.Sandbox == "" {
+		out.Sandbox = ExecSandboxBwrap
+	}
+	if out.WorkspaceRoot == "" {
+		out.WorkspaceRoot = filepath.Join(os.TempDir(), "ai-bridge-exec")
+	}
+	if out.Shell == "" {
+		out.Shell = "/bin/sh"
+	}
+	if out.TimeoutSeconds <= 0 {
+		out.TimeoutSeconds = 60
+	}
+	if out.MaxTimeoutSeconds <= 0 {
+		out.MaxTimeoutSeconds = 1800
+	}
+	if out.MaxTimeoutSeconds < out.TimeoutSeconds {
+		out.MaxTimeoutSeconds = out.TimeoutSeconds
+	}
+	if out.MaxOutputBytes <= 0 {
+		out.MaxOutputBytes = textfs.DefaultMaxBytes
+	}
+	if out.MaxSessions <= 0 {
+		out.MaxSessions = 8
+	}
+	return &out
+}
+
+func (oc *AIClient) execConfig() *ExecToolsConfig {
+	if oc == nil || oc.connector == nil {
+		return (*ExecToolsConfig)(nil).WithDefaults()
+	}
+	return oc.connector.Config.Tools.Exec.WithDefaults()
+}
+
+// execAvailability reports whether exec and process can run with the current config.
+func (oc *AIClient) execAvailability() (bool, string) {
+	cfg := oc.execConfig()
+	if !cfg.Enabled {
+		return false, "exec disabled by config"
+	}
+	switch cfg.Sandbox {
+	case ExecSandboxBwrap:
+		if _, err := resolveBwrapPath(cfg); err != nil {
+			return false, err.Error()
+		}
+	case ExecSandboxAllowlist:
+		if len(cfg.AllowedCommands) == 0 {
+			return false, "exec allowlist is empty"
+		}
+	case ExecSandboxNone:
+	default:
+		return false, fmt.Sprintf("unknown exec sandbox %q", cfg.Sandbox)
+	}
+	return true, ""
+}
+
+func resolveBwrapPath(cfg *ExecToolsConfig) (string, error) {
+	name := strings.TrimSpace(cfg.BwrapPath)
+	if name == "" {
+		name = "bwrap"
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("bubblewrap not found (%s)", name)
+	}
+	return path, nil
+}
+
+// execAgentDir returns the working directory root of an agent. IDs are
+// sanitized so they can't point outside the workspace root.
+func execAgentDir(root, loginID, agentID string) string {
+	return filepath.Join(root, sanitizeExecPathSegment(loginID), sanitizeExecPathSegment(agentID))
+}
+
+func sanitizeExecPathSegment(value string) string {
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(value))
+	if strings.Trim(value, ".") == "" {
+		return "default"
+	}
+	return value
+}
+
+// resolveExecWorkdir resolves a workdir argument inside agentDir.
+func resolveExecWorkdir(agentDir, workdir string) (string, error) {
+	workdir = strings.TrimSpace(workdir)
+	if workdir == "" || workdir == "." {
+		return agentDir, nil
+	}
+	if filepath.IsAbs(workdir) {
+		rel, err := filepath.Rel(agentDir, filepath.Clean(workdir))
+		if err != nil {
+			return "", errors.New("workdir must be inside the agent workspace")
+		}
+		workdir = rel
+	}
+	resolved := filepath.Join(agentDir, workdir)
+	if resolved != agentDir && !strings.HasPrefix(resolved, agentDir+string(filepath.Separator)) {
+		return "", errors.New("workdir must be inside the agent workspace")
+	}
+	return resolved, nil
+}
+
+// execEnv builds the environment of a command. The bridge's own environment
+// (API keys, tokens) is never passed through.
+func execEnv(agentDir string, extra map[string]string) ([]string, error) {
+	env := []string{
+		"PATH=" + execDefaultPath,
+		"HOME=" + agentDir,
+		"LANG=C.UTF-8",
+		"TERM=dumb",
+	}
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !execEnvKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid environment variable name %q", key)
+		}
+		env = append(env, key+"="+extra[key])
+	}
+	return env, nil
+}
+
+// buildExecCommand prepares command for the configured sandbox. agentDir must exist.
+func buildExecCommand(ctx context.Context, cfg *ExecToolsConfig, agentDir, workdir, command string, env []string) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	switch cfg.Sandbox {
+	case ExecSandboxBwrap:
+		bwrap, err := resolveBwrapPath(cfg)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, bwrap, bwrapArgs(cfg, agentDir, workdir, command)...)
+	case ExecSandboxAllowlist:
+		argv, err := splitCommandLine(command)
+		if err != nil {
+			return nil, err
+		}
+		if !execCommandAllowed(cfg.AllowedCommands, argv[0]) {
+			return nil, fmt.Errorf("command %q is not in the exec allowlist", argv[0])
+		}
+		cmd = exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Dir = workdir
+	case ExecSandboxNone:
+		cmd = exec.CommandContext(ctx, cfg.Shell, "-c", command)
+		cmd.Dir = workdir
+	default:
+		return nil, fmt.Errorf("unknown exec sandbox %q", cfg.Sandbox)
+	}
+	cmd.Env = env
+	return cmd, nil
+}
+
+func bwrapArgs(cfg *ExecToolsConfig, agentDir, workdir, command string) []string {
+	args := []string{"--die-with-parent", "--new-session", "--unshare-all"}
+	if cfg.AllowNetwork {
+		args = append(args, "--share-net")
+	}
+	for _, path := range bwrapSystemPaths {
+		args = append(args, "--ro-bind-try", path, path)
+	}
+	args = append(args,
+		"--proc", "/proc",
+		"--dev", "/dev",
+		"--tmpfs", "/tmp",
+		"--bind", agentDir, agentDir,
+		"--chdir", workdir,
+		"--", cfg.Shell, "-c", command,
+	)
+	return args
+}
+
+func execCommandAllowed(allowed []string, program string) bool {
+	// Paths could point at a lookalike binary inside the workspace.
+	if strings.ContainsRune(program, '/') {
+		return false
+	}
+	for _, entry := range allowed {
+		if strings.TrimSpace(entry) == program {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCommandLine splits a command into arguments for the allowlist sandbox.
+// Quotes and backslash escapes are supported; anything that needs a shell
+// (pipes, redirects, substitutions, globs, chaining) is rejected.
+func splitCommandLine(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			case '$', '`':
+				return nil, fmt.Errorf("shell syntax %q is not allowed without a shell", r)
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case strings.ContainsRune("|&;<>()$`*?[]{}~\n\r", r):
+			return nil, fmt.Errorf("shell syntax %q is not allowed without a shell", r)
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape in command")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return args, nil
+}
@@ -0,0 +1,333 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// execOutputBufferBytes caps how much output is kept per session. Older output
+// is dropped once a command writes more than this.
+const execOutputBufferBytes = 1 << 20
+
+// execFinishedSessionTTL is how long finished sessions stay around for poll and log.
+const execFinishedSessionTTL = 30 * time.Minute
+
+const (
+	execStatusRunning   = "running"
+	execStatusCompleted = "completed"
+	execStatusFailed    = "failed"
+	execStatusTimedOut  = "timeout"
+	execStatusKilled    = "killed"
+)
+
+var (
+	errExecSessionNotFound = errors.New("exec session not found")
+	errExecSessionLimit    = errors.New("too many running exec sessions; kill or wait for one first")
+)
+
+// execSession is one command started by the exec tool.
+type execSession struct {
+	ID        string
+	AgentID   string
+	Command   string
+	Workdir   string
+	StartedAt time.Time
+
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// stdin has its own lock so a blocked write can't stall output capture.
+	stdinMu sync.Mutex
+	stdin   io.WriteCloser
+
+	mu         sync.Mutex
+	output     []byte
+	dropped    int // bytes dropped from the front of output
+	pollOffset int // absolute offset up to which poll has returned output
+	status     string
+	exitCode   int
+	errMsg     string
+	endedAt    time.Time
+}
+
+func (s *execSession) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.output = append(s.output, p...)
+	if over := len(s.output) - execOutputBufferBytes; over > 0 {
+		s.output = append(s.output[:0], s.output[over:]...)
+		s.dropped += over
+	}
+	return len(p), nil
+}
+
+// execSessionSnapshot is the state of a session at one point in time.
+type execSessionSnapshot struct {
+	ID         string `json:"sessionId"`
+	Command    string `json:"command"`
+	Status     string `json:"status"`
+	ExitCode   *int   `json:"exitCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	StartedAt  string `json:"startedAt"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+func (s *execSession) snapshot() execSessionSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := execSessionSnapshot{
+		ID:        s.ID,
+		Command:   s.Command,
+		Status:    s.status,
+		Error:     s.errMsg,
+		StartedAt: s.StartedAt.UTC().Format(time.RFC3339),
+	}
+	end := time.Now()
+	if s.status != execStatusRunning {
+		exitCode := s.exitCode
+		snap.ExitCode = &exitCode
+		end = s.endedAt
+	}
+	snap.DurationMs = end.Sub(s.StartedAt).Milliseconds()
+	return snap
+}
+
+// Output returns the buffered output and whether earlier output was dropped.
+func (s *execSession) Output() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return string(s.output), s.dropped > 0
+}
+
+// Poll returns the output written since the previous poll.
+func (s *execSession) Poll() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := max(s.pollOffset-s.dropped, 0)
+	out := string(s.output[start:])
+	s.pollOffset = s.dropped + len(s.output)
+	return out
+}
+
+func (s *execSession) running() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// WriteStdin sends data to the command, closing stdin afterwards if eof is set.
+func (s *execSession) WriteStdin(data string, eof bool) error {
+	if !s.running() {
+		return errors.New("exec session has exited")
+	}
+	s.stdinMu.Lock()
+	defer s.stdinMu.Unlock()
+	if s.stdin == nil {
+		return errors.New("stdin of exec session is closed")
+	}
+	if data != "" {
+		if _, err := io.WriteString(s.stdin, data); err != nil {
+			return err
+		}
+	}
+	if eof {
+		err := s.stdin.Close()
+		s.stdin = nil
+		return err
+	}
+	return nil
+}
+
+// Kill stops the command and waits for it to exit.
+func (s *execSession) Kill() {
+	s.mu.Lock()
+	if s.status == execStatusRunning {
+		s.status = execStatusKilled
+	}
+	s.mu.Unlock()
+	s.cancel()
+	<-s.done
+}
+
+func (s *execSession) wait(timeoutCtx context.Context) {
+	defer close(s.done)
+	err := s.cmd.Wait()
+	s.cancel()
+
+	s.stdinMu.Lock()
+	if s.stdin != nil {
+		_ = s.stdin.Close()
+		s.stdin = nil
+	}
+	s.stdinMu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endedAt = time.Now()
+	s.exitCode = -1
+	if s.cmd.ProcessState != nil {
+		s.exitCode = s.cmd.ProcessState.ExitCode()
+	}
+	switch {
+	case s.status == execStatusKilled:
+	case errors.Is(timeoutCtx.Err(), context.DeadlineExceeded):
+		s.status = execStatusTimedOut
+	case err == nil:
+		s.status = execStatusCompleted
+	default:
+		s.status = execStatusFailed
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			s.errMsg = err.Error()
+		}
+	}
+}
+
+// execSessionManager tracks the exec sessions of a login.
+type execSessionManager struct {
+	mu       sync.Mutex
+	sessions map[string]*execSession
+}
+
+type execStartParams struct {
+	AgentID     string
+	Command     string
+	Workdir     string
+	Timeout     time.Duration
+	MaxSessions int
+	// Build creates the command; it must not have been started.
+	Build func(ctx context.Context) (*exec.Cmd, error)
+}
+
+// Start launches a command as a session. The command runs under base rather
+// than the tool call context, so it can outlive the call in the background.
+func (m *execSessionManager) Start(base context.Context, params execStartParams) (*execSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked(time.Now())
+	running := 0
+	for _, session := range m.sessions {
+		if session.running() {
+			running++
+		}
+	}
+	if params.MaxSessions > 0 && running >= params.MaxSessions {
+		return nil, errExecSessionLimit
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(base, params.Timeout)
+	cmd, err := params.Build(timeoutCtx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	session := &execSession{
+		ID:        strings.SplitN(uuid.NewString(), "-", 2)[0],
+		AgentID:   params.AgentID,
+		Command:   params.Command,
+		Workdir:   params.Workdir,
+		StartedAt: time.Now(),
+		cmd:       cmd,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		status:    execStatusRunning,
+	}
+	cmd.Stdout = session
+	cmd.Stderr = session
+	// Give the command a moment to flush after it is killed, then stop waiting on
+	// pipes held open by orphaned children.
+	cmd.WaitDelay = 2 * time.Second
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	session.stdin = stdin
+	if err = cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	if m.sessions == nil {
+		m.sessions = make(map[string]*execSession)
+	}
+	m.sessions[session.ID] = session
+	go session.wait(timeoutCtx)
+	return session, nil
+}
+
+// Get returns a session of agentID.
+func (m *execSessionManager) Get(agentID, sessionID string) (*execSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session := m.sessions[strings.TrimSpace(sessionID)]
+	if session == nil || session.AgentID != agentID {
+		return nil, errExecSessionNotFound
+	}
+	return session, nil
+}
+
+// List returns the sessions of agentID, oldest first.
+func (m *execSessionManager) List(agentID string) []*execSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked(time.Now())
+	var out []*execSession
+	for _, session := range m.sessions {
+		if session.AgentID == agentID {
+			out = append(out, session)
+		}
+	}
+	slices.SortFunc(out, func(a, b *execSession) int { return a.StartedAt.Compare(b.StartedAt) })
+	return out
+}
+
+// Remove kills a session if it is still running and forgets it.
+func (m *execSessionManager) Remove(agentID, sessionID string) error {
+	session, err := m.Get(agentID, sessionID)
+	if err != nil {
+		return err
+	}
+	session.Kill()
+	m.mu.Lock()
+	delete(m.sessions, session.ID)
+	m.mu.Unlock()
+	return nil
+}
+
+// KillAll stops every session, e.g. when the login disconnects.
+func (m *execSessionManager) KillAll() {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = nil
+	m.mu.Unlock()
+	for _, session := range sessions {
+		session.Kill()
+	}
+}
+
+func (m *execSessionManager) pruneLocked(now time.Time) {
+	for id, session := range m.sessions {
+		if session.running() {
+			continue
+		}
+		session.mu.Lock()
+		expired := now.Sub(session.endedAt) > execFinishedSessionTTL
+		session.mu.Unlock()
+		if expired {
+			delete(m.sessions, id)
+		}
+	}
+}
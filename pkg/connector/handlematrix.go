@@ -116,11 +116,17 @@ func (oc *AIClient) HandleMatrixMessage(ctx context.Context, msg *bridgev2.Matri
 			rawBody = loc.Text
 		}
 	}
+	if oc.resolvePendingMCPElicitation(ctx, portal, msg.Event.Sender, rawBody) {
+		return &bridgev2.MatrixMessageResponse{Pending: false}, nil
+	}
 	rawBodyOriginal := rawBody
 	if traceFull && rawBodyOriginal != "" {
 		logCtx.Debug().Str("body", rawBodyOriginal).Msg("Inbound message body")
 	}
 	commandAuthorized := oc.isCommandAuthorizedSender(msg.Event.Sender)
+	if commandAuthorized && oc.handleMCPPromptCommand(ctx, portal, msg.Event, rawBody) {
+		return &bridgev2.MatrixMessageResponse{Pending: false}, nil
+	}
 
 	isGroup := oc.isGroupChat(ctx, portal)
 	roomName := ""
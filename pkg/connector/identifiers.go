@@ -51,6 +51,12 @@ func providerSlug(provider string) string {
 		return "openrouter"
 	case ProviderMagicProxy:
 		return "magic-proxy"
+	case ProviderAnthropic:
+		return "anthropic"
+	case ProviderGemini:
+		return "gemini"
+	case ProviderLocal:
+		return "local"
 	default:
 		return strings.TrimSpace(provider)
 	}
@@ -182,6 +182,21 @@ func providerMatches(info ModelInfo, provider string) bool {
 			return info.Provider == "openai"
 		}
 		return strings.HasPrefix(info.ID, "openai/")
+	case ProviderAnthropic:
+		if info.Provider != "" {
+			return info.Provider == ProviderAnthropic
+		}
+		return strings.HasPrefix(info.ID, "anthropic/")
+	case ProviderLocal:
+		if info.Provider != "" {
+			return info.Provider == ProviderLocal
+		}
+		return strings.HasPrefix(info.ID, "local/")
+	case ProviderGemini:
+		if info.Provider != "" {
+			return info.Provider == "google"
+		}
+		return strings.HasPrefix(info.ID, "google/")
 	default:
 		return true
 	}
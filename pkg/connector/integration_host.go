@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/param"
 	"github.com/rs/zerolog"
 	"maunium.net/go/mautrix/bridgev2"
 	"maunium.net/go/mautrix/bridgev2/database"
@@ -561,7 +562,20 @@ func (h *runtimeIntegrationHost) NewCompletion(ctx context.Context, model string
 	if h == nil || h.client == nil {
 		return nil, fmt.Errorf("missing client")
 	}
+	model, err := h.client.usageBudgetModel(ctx, model)
+	if err != nil {
+		return nil, err
+	}
 	params, _ := toolParams.([]openai.ChatCompletionToolUnionParam)
+	if provider := h.client.nativeProvider(); provider != nil {
+		promptContext := ChatMessagesToPromptContext(messages)
+		promptContext.Tools = chatToolParamsToDefinitions(params)
+		resp, err := provider.Generate(ctx, GenerateParams{Model: model, Context: promptContext})
+		if err != nil {
+			return nil, err
+		}
+		return completionResultFromGenerateResponse(resp), nil
+	}
 	req := openai.ChatCompletionNewParams{
 		Model:    model,
 		Messages: messages,
@@ -595,6 +609,36 @@ func (h *runtimeIntegrationHost) NewCompletion(ctx context.Context, model string
 	return result, nil
 }
 
+func completionResultFromGenerateResponse(resp *GenerateResponse) *integrationruntime.CompletionResult {
+	assistant := openai.ChatCompletionAssistantMessageParam{}
+	if resp.Content != "" {
+		assistant.Content.OfString = param.NewOpt(resp.Content)
+	}
+	result := &integrationruntime.CompletionResult{Done: len(resp.ToolCalls) == 0}
+	calls := make([]integrationruntime.CompletionToolCall, 0, len(resp.ToolCalls))
+	for _, tc := range resp.ToolCalls {
+		assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+			OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+				ID: tc.ID,
+				Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+					Name:      tc.Name,
+					Arguments: tc.Arguments,
+				},
+			},
+		})
+		calls = append(calls, integrationruntime.CompletionToolCall{
+			ID:       tc.ID,
+			Name:     strings.TrimSpace(tc.Name),
+			ArgsJSON: tc.Arguments,
+		})
+	}
+	if len(calls) > 0 {
+		result.ToolCalls = calls
+	}
+	result.AssistantMessage = openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant}
+	return result
+}
+
 // ---- Optional Host capability: ToolPolicyHelper ----
 
 func (h *runtimeIntegrationHost) IsToolEnabled(meta any, toolName string) bool {
@@ -686,7 +730,11 @@ func (h *runtimeIntegrationHost) WriteTextFile(ctx context.Context, portal any,
 			}
 		}
 	}
-	entry, e := store.Write(ctx, path, content)
+	author := textfs.Author{Kind: textfs.AuthorAgent}
+	if btc := GetBridgeToolContext(ctx); btc != nil {
+		author.ToolCallID = btc.ToolCallID
+	}
+	entry, e := store.WithAuthor(author).Write(ctx, path, content)
 	if e != nil {
 		return "", e
 	}
@@ -875,7 +923,8 @@ func (d *hostDispatch) SendAssistantMessage(ctx context.Context, portal any, bod
 	if p == nil {
 		return fmt.Errorf("missing portal")
 	}
-	return d.client.sendPlainAssistantMessageWithResult(ctx, p, body)
+	_, err := d.client.sendPlainAssistantMessageWithResult(ctx, p, body)
+	return err
 }
 
 type hostHeartbeat struct {
@@ -32,6 +32,8 @@ type Config struct {
 	Messages      *MessagesConfig                    `yaml:"messages"`
 	Commands      *CommandsConfig                    `yaml:"commands"`
 	Session       *SessionConfig                     `yaml:"session"`
+	Usage         *UsageConfig                       `yaml:"usage"`
+	Scheduler     *SchedulerConfig                   `yaml:"scheduler"`
 
 	// Global settings
 	DefaultSystemPrompt string        `yaml:"default_system_prompt"`
@@ -104,6 +106,21 @@ func (c *ToolApprovalsRuntimeConfig) WithDefaults() *ToolApprovalsRuntimeConfig
 	return c
 }
 
+// UsageConfig configures usage accounting and spending budgets.
+type UsageConfig struct {
+	// Pricing maps model IDs to prices, overriding the model manifest.
+	Pricing map[string]ModelPricing `yaml:"pricing"`
+	Budgets *UsageBudgetConfig      `yaml:"budgets"`
+}
+
+// UsageBudgetConfig limits the estimated spend of each login.
+type UsageBudgetConfig struct {
+	DailyUSD       float64 `yaml:"daily_usd"`
+	MonthlyUSD     float64 `yaml:"monthly_usd"`
+	Action         string  `yaml:"action"` // block | downgrade
+	DowngradeModel string  `yaml:"downgrade_model"`
+}
+
 // AgentsConfig configures agent defaults.
 type AgentsConfig struct {
 	Defaults *AgentDefaultsConfig `yaml:"defaults"`
@@ -220,6 +237,13 @@ type QueueConfig struct {
 }
 
 // SessionConfig configures session behavior.
+// SchedulerConfig selects how cron and heartbeat ticks are delivered.
+type SchedulerConfig struct {
+	// Backend is "auto" (default), "matrix" for homeserver delayed events or
+	// "local" for in-process timers.
+	Backend string `yaml:"backend"`
+}
+
 type SessionConfig struct {
 	Scope   string `yaml:"scope"`
 	MainKey string `yaml:"mainKey"`
@@ -232,6 +256,7 @@ type ToolProvidersConfig struct {
 	Media  *MediaToolsConfig `yaml:"media"`
 	MCP    *MCPToolsConfig   `yaml:"mcp"`
 	VFS    *VFSToolsConfig   `yaml:"vfs"`
+	Exec   *ExecToolsConfig  `yaml:"exec"`
 }
 
 // MCPToolsConfig configures generic MCP behavior.
@@ -250,6 +275,24 @@ type ApplyPatchToolsConfig struct {
 	AllowModels []string `yaml:"allow_models"`
 }
 
+// ExecToolsConfig configures the exec and process tools.
+type ExecToolsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Sandbox is bwrap (bubblewrap namespaces), allowlist (only AllowedCommands,
+	// no shell) or none.
+	Sandbox         string   `yaml:"sandbox"`
+	BwrapPath       string   `yaml:"bwrap_path"`
+	AllowNetwork    bool     `yaml:"allow_network"`
+	AllowedCommands []string `yaml:"allowed_commands"`
+	// WorkspaceRoot holds one working directory per login and agent.
+	WorkspaceRoot     string `yaml:"workspace_root"`
+	Shell             string `yaml:"shell"`
+	TimeoutSeconds    int    `yaml:"timeout_seconds"`
+	MaxTimeoutSeconds int    `yaml:"max_timeout_seconds"`
+	MaxOutputBytes    int    `yaml:"max_output_bytes"`
+	MaxSessions       int    `yaml:"max_sessions"`
+}
+
 // MediaUnderstandingScopeMatch defines match criteria for media understanding scope rules.
 type MediaUnderstandingScopeMatch struct {
 	Channel   string `yaml:"channel"`
@@ -352,7 +395,10 @@ type SearchConfig struct {
 	Provider  string   `yaml:"provider"`
 	Fallbacks []string `yaml:"fallbacks"`
 
-	Exa ProviderExaConfig `yaml:"exa"`
+	Exa        ProviderExaConfig        `yaml:"exa"`
+	Brave      ProviderBraveConfig      `yaml:"brave"`
+	Perplexity ProviderPerplexityConfig `yaml:"perplexity"`
+	SearXNG    ProviderSearXNGConfig    `yaml:"searxng"`
 }
 
 type FetchConfig struct {
@@ -375,11 +421,34 @@ type ProviderExaConfig struct {
 	Highlights        bool   `yaml:"highlights"`
 }
 
+type ProviderBraveConfig struct {
+	Enabled *bool  `yaml:"enabled"`
+	BaseURL string `yaml:"base_url"`
+	APIKey  string `yaml:"api_key"`
+}
+
+type ProviderPerplexityConfig struct {
+	Enabled *bool  `yaml:"enabled"`
+	BaseURL string `yaml:"base_url"`
+	APIKey  string `yaml:"api_key"`
+	Model   string `yaml:"model"`
+}
+
+type ProviderSearXNGConfig struct {
+	Enabled    *bool    `yaml:"enabled"`
+	BaseURL    string   `yaml:"base_url"`
+	APIKey     string   `yaml:"api_key"`
+	Categories []string `yaml:"categories"`
+	Engines    []string `yaml:"engines"`
+	Language   string   `yaml:"language"`
+	SafeSearch *int     `yaml:"safesearch"`
+}
+
 type ProviderDirectConfig struct {
 	Enabled      *bool  `yaml:"enabled"`
 	TimeoutSecs  int    `yaml:"timeout_seconds"`
 	UserAgent    string `yaml:"user_agent"`
-	Readability  bool   `yaml:"readability"`
+	Readability  *bool  `yaml:"readability"`
 	MaxChars     int    `yaml:"max_chars"`
 	MaxRedirects int    `yaml:"max_redirects"`
 	CacheTtlSecs int    `yaml:"cache_ttl_seconds"`
@@ -434,6 +503,9 @@ type ProvidersConfig struct {
 	Beeper     ProviderConfig `yaml:"beeper"`
 	OpenAI     ProviderConfig `yaml:"openai"`
 	OpenRouter ProviderConfig `yaml:"openrouter"`
+	Anthropic  ProviderConfig `yaml:"anthropic"`
+	Gemini     ProviderConfig `yaml:"gemini"`
+	Local      ProviderConfig `yaml:"local"`
 }
 
 // ModelsConfig configures model catalog seeding.
@@ -476,6 +548,15 @@ func upgradeConfig(helper configupgrade.Helper) {
 	helper.Copy(configupgrade.Str, "providers", "openrouter", "base_url")
 	helper.Copy(configupgrade.Str, "providers", "openrouter", "default_model")
 	helper.Copy(configupgrade.Str, "providers", "openrouter", "default_pdf_engine")
+	helper.Copy(configupgrade.Str, "providers", "anthropic", "api_key")
+	helper.Copy(configupgrade.Str, "providers", "anthropic", "base_url")
+	helper.Copy(configupgrade.Str, "providers", "anthropic", "default_model")
+	helper.Copy(configupgrade.Str, "providers", "gemini", "api_key")
+	helper.Copy(configupgrade.Str, "providers", "gemini", "base_url")
+	helper.Copy(configupgrade.Str, "providers", "gemini", "default_model")
+	helper.Copy(configupgrade.Str, "providers", "local", "api_key")
+	helper.Copy(configupgrade.Str, "providers", "local", "base_url")
+	helper.Copy(configupgrade.Str, "providers", "local", "default_model")
 
 	// Global settings
 	helper.Copy(configupgrade.Str, "default_system_prompt")
@@ -486,6 +567,16 @@ func upgradeConfig(helper configupgrade.Helper) {
 	// Tool approvals
 	helper.Copy(configupgrade.Map, "tool_approvals")
 
+	// Usage accounting
+	helper.Copy(configupgrade.Map, "usage", "pricing")
+	helper.Copy(configupgrade.Float, "usage", "budgets", "daily_usd")
+	helper.Copy(configupgrade.Float, "usage", "budgets", "monthly_usd")
+	helper.Copy(configupgrade.Str, "usage", "budgets", "action")
+	helper.Copy(configupgrade.Str, "usage", "budgets", "downgrade_model")
+
+	// Scheduler
+	helper.Copy(configupgrade.Str, "scheduler", "backend")
+
 	// Bridge-specific configuration
 	helper.Copy(configupgrade.Str, "bridge", "command_prefix")
 
@@ -588,6 +679,20 @@ func upgradeConfig(helper configupgrade.Helper) {
 	helper.Copy(configupgrade.Bool, "tools", "search", "exa", "include_text")
 	helper.Copy(configupgrade.Int, "tools", "search", "exa", "text_max_chars")
 	helper.Copy(configupgrade.Bool, "tools", "search", "exa", "highlights")
+	helper.Copy(configupgrade.Bool, "tools", "search", "brave", "enabled")
+	helper.Copy(configupgrade.Str, "tools", "search", "brave", "base_url")
+	helper.Copy(configupgrade.Str, "tools", "search", "brave", "api_key")
+	helper.Copy(configupgrade.Bool, "tools", "search", "perplexity", "enabled")
+	helper.Copy(configupgrade.Str, "tools", "search", "perplexity", "base_url")
+	helper.Copy(configupgrade.Str, "tools", "search", "perplexity", "api_key")
+	helper.Copy(configupgrade.Str, "tools", "search", "perplexity", "model")
+	helper.Copy(configupgrade.Bool, "tools", "search", "searxng", "enabled")
+	helper.Copy(configupgrade.Str, "tools", "search", "searxng", "base_url")
+	helper.Copy(configupgrade.Str, "tools", "search", "searxng", "api_key")
+	helper.Copy(configupgrade.List, "tools", "search", "searxng", "categories")
+	helper.Copy(configupgrade.List, "tools", "search", "searxng", "engines")
+	helper.Copy(configupgrade.Str, "tools", "search", "searxng", "language")
+	helper.Copy(configupgrade.Int, "tools", "search", "searxng", "safesearch")
 	helper.Copy(configupgrade.Str, "tools", "fetch", "provider")
 	helper.Copy(configupgrade.List, "tools", "fetch", "fallbacks")
 	helper.Copy(configupgrade.Bool, "tools", "fetch", "exa", "enabled")
@@ -603,6 +708,17 @@ func upgradeConfig(helper configupgrade.Helper) {
 	helper.Copy(configupgrade.Int, "tools", "fetch", "direct", "max_redirects")
 	helper.Copy(configupgrade.Int, "tools", "fetch", "direct", "cache_ttl_seconds")
 	helper.Copy(configupgrade.Bool, "tools", "mcp", "enable_stdio")
+	helper.Copy(configupgrade.Bool, "tools", "exec", "enabled")
+	helper.Copy(configupgrade.Str, "tools", "exec", "sandbox")
+	helper.Copy(configupgrade.Str, "tools", "exec", "bwrap_path")
+	helper.Copy(configupgrade.Bool, "tools", "exec", "allow_network")
+	helper.Copy(configupgrade.List, "tools", "exec", "allowed_commands")
+	helper.Copy(configupgrade.Str, "tools", "exec", "workspace_root")
+	helper.Copy(configupgrade.Str, "tools", "exec", "shell")
+	helper.Copy(configupgrade.Int, "tools", "exec", "timeout_seconds")
+	helper.Copy(configupgrade.Int, "tools", "exec", "max_timeout_seconds")
+	helper.Copy(configupgrade.Int, "tools", "exec", "max_output_bytes")
+	helper.Copy(configupgrade.Int, "tools", "exec", "max_sessions")
 
 	// Memory search configuration
 	helper.Copy(configupgrade.Bool, "memory_search", "enabled")
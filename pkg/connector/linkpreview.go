@@ -3,14 +3,12 @@ package connector
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"image"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -27,6 +25,7 @@ import (
 	"maunium.net/go/mautrix/id"
 
 	"github.com/beeper/agentremote/pkg/shared/citations"
+	"github.com/beeper/agentremote/pkg/shared/httputil"
 )
 
 type LinkPreviewConfig struct {
@@ -137,15 +136,10 @@ type LinkPreviewer struct {
 func NewLinkPreviewer(config LinkPreviewConfig) *LinkPreviewer {
 	return &LinkPreviewer{
 		config: config,
-		httpClient: &http.Client{
-			Timeout: config.FetchTimeout,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 5 {
-					return errors.New("too many redirects")
-				}
-				return nil
-			},
-		},
+		httpClient: httputil.NewSafeClient(httputil.SafeClientOptions{
+			Timeout:      config.FetchTimeout,
+			MaxRedirects: 5,
+		}),
 	}
 }
 
@@ -181,7 +175,7 @@ func ExtractURLs(text string, maxURLs int) []string {
 		if seen[cleaned] {
 			continue
 		}
-		if !isAllowedURL(cleaned) {
+		if httputil.ValidateURL(cleaned) != nil {
 			continue
 		}
 		seen[cleaned] = true
@@ -193,27 +187,6 @@ func ExtractURLs(text string, maxURLs int) []string {
 	return urls
 }
 
-func isAllowedURL(rawURL string) bool {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return false
-	}
-	if parsed.Scheme != "http" && parsed.Scheme != "https" {
-		return false
-	}
-	host := strings.ToLower(parsed.Hostname())
-	if host == "localhost" {
-		return false
-	}
-	ip := net.ParseIP(host)
-	if ip != nil {
-		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() {
-			return false
-		}
-	}
-	return true
-}
-
 // FetchPreview fetches and generates a link preview for a URL, including the image data.
 func (lp *LinkPreviewer) FetchPreview(ctx context.Context, urlStr string) (*PreviewWithImage, error) {
 	// Check cache first
@@ -12,6 +12,7 @@ import (
 	"maunium.net/go/mautrix/id"
 
 	"github.com/beeper/agentremote/pkg/shared/citations"
+	"github.com/beeper/agentremote/pkg/shared/httputil"
 )
 
 func TestPreviewCacheReturnsClones(t *testing.T) {
@@ -82,6 +83,33 @@ func newTestImageServer() *httptest.Server {
 	}))
 }
 
+// newLoopbackLinkPreviewer returns a previewer that can reach httptest servers,
+// which listen on loopback and are refused by the default client.
+func newLoopbackLinkPreviewer() *LinkPreviewer {
+	lp := NewLinkPreviewer(DefaultLinkPreviewConfig())
+	lp.httpClient = httputil.NewSafeClient(httputil.SafeClientOptions{MaxRedirects: 5, AllowPrivate: true})
+	return lp
+}
+
+func TestFetchPreviewRefusesLoopback(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><head><title>Internal</title></head></html>`)
+	}))
+	defer srv.Close()
+	globalPreviewCache = &previewCache{entries: make(map[string]*previewCacheEntry)}
+
+	lp := NewLinkPreviewer(DefaultLinkPreviewConfig())
+	if _, err := lp.FetchPreview(context.Background(), srv.URL); err == nil || hits != 0 {
+		t.Fatalf("expected loopback fetch to be refused, got err=%v hits=%d", err, hits)
+	}
+	if urls := ExtractURLs("see http://127.0.0.1:8080/admin and http://localhost/x", 5); len(urls) != 0 {
+		t.Fatalf("expected internal URLs to be skipped, got %v", urls)
+	}
+}
+
 func TestPreviewFromCitation(t *testing.T) {
 	imgServer := newTestImageServer()
 	defer imgServer.Close()
@@ -89,7 +117,7 @@ func TestPreviewFromCitation(t *testing.T) {
 	// Clear the global cache for a clean test.
 	globalPreviewCache = &previewCache{entries: make(map[string]*previewCacheEntry)}
 
-	lp := NewLinkPreviewer(DefaultLinkPreviewConfig())
+	lp := newLoopbackLinkPreviewer()
 	ctx := context.Background()
 
 	citation := citations.SourceCitation{
@@ -168,7 +196,7 @@ func TestFetchPreviewsWithCitations_PrefersCitation(t *testing.T) {
 
 	globalPreviewCache = &previewCache{entries: make(map[string]*previewCacheEntry)}
 
-	lp := NewLinkPreviewer(DefaultLinkPreviewConfig())
+	lp := newLoopbackLinkPreviewer()
 	ctx := context.Background()
 
 	citationURL := "https://example.com/exa-result"
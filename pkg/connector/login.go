@@ -15,12 +15,15 @@ import (
 	"github.com/beeper/agentremote/pkg/shared/stringutil"
 )
 
-// Provider constants - all use OpenAI SDK with different base URLs
+// Provider constants - most use OpenAI SDK with different base URLs
 const (
 	ProviderBeeper     = "beeper"      // Beeper's OpenRouter proxy
 	ProviderOpenAI     = "openai"      // Direct OpenAI API
 	ProviderOpenRouter = "openrouter"  // Direct OpenRouter API
 	ProviderMagicProxy = "magic_proxy" // Magic Proxy (OpenRouter-compatible)
+	ProviderAnthropic  = "anthropic"   // Direct Anthropic Messages API (native provider)
+	ProviderGemini     = "gemini"      // Direct Gemini generateContent API (native provider)
+	ProviderLocal      = "local"       // Self-hosted OpenAI-compatible server (Ollama, llama.cpp, vLLM)
 	FlowCustom         = "custom"      // Custom login flow (provider resolved during login)
 )
 
@@ -57,6 +60,12 @@ func normalizeProvider(provider string) string {
 		return ProviderOpenRouter
 	case ProviderMagicProxy:
 		return ProviderMagicProxy
+	case ProviderAnthropic:
+		return ProviderAnthropic
+	case ProviderGemini:
+		return ProviderGemini
+	case ProviderLocal:
+		return ProviderLocal
 	case FlowCustom:
 		return FlowCustom
 	default:
@@ -80,6 +89,21 @@ func (ol *OpenAILogin) Start(ctx context.Context) (*bridgev2.LoginStep, error) {
 		return ol.finishLogin(ctx, ProviderBeeper, apiKey, baseURL, nil)
 	case ProviderMagicProxy:
 		return nil, &ErrBaseURLRequired
+	case ProviderAnthropic:
+		apiKey := strings.TrimSpace(ol.Connector.Config.Providers.Anthropic.APIKey)
+		if apiKey == "" {
+			return nil, &ErrAPIKeyRequired
+		}
+		return ol.finishLogin(ctx, ProviderAnthropic, apiKey, "", nil)
+	case ProviderGemini:
+		apiKey := strings.TrimSpace(ol.Connector.Config.Providers.Gemini.APIKey)
+		if apiKey == "" {
+			return nil, &ErrAPIKeyRequired
+		}
+		return ol.finishLogin(ctx, ProviderGemini, apiKey, "", nil)
+	case ProviderLocal:
+		// Local servers usually run without credentials; the key is optional.
+		return ol.finishLogin(ctx, ProviderLocal, ol.Connector.Config.Providers.Local.APIKey, "", nil)
 	case FlowCustom:
 		provider, apiKey, serviceTokens, err := ol.resolveCustomLogin(nil)
 		if err != nil {
@@ -148,6 +172,24 @@ func (ol *OpenAILogin) SubmitUserInput(ctx context.Context, input map[string]str
 			event.Msg("Resolved magic proxy login URL")
 		}
 		return ol.finishLogin(ctx, ProviderMagicProxy, apiKey, baseURL, nil)
+	case ProviderAnthropic:
+		apiKey := strings.TrimSpace(ol.Connector.Config.Providers.Anthropic.APIKey)
+		if apiKey == "" {
+			apiKey = strings.TrimSpace(input["anthropic_api_key"])
+		}
+		if apiKey == "" {
+			return nil, &ErrAPIKeyRequired
+		}
+		return ol.finishLogin(ctx, ProviderAnthropic, apiKey, "", nil)
+	case ProviderGemini:
+		apiKey := strings.TrimSpace(ol.Connector.Config.Providers.Gemini.APIKey)
+		if apiKey == "" {
+			apiKey = strings.TrimSpace(input["gemini_api_key"])
+		}
+		if apiKey == "" {
+			return nil, &ErrAPIKeyRequired
+		}
+		return ol.finishLogin(ctx, ProviderGemini, apiKey, "", nil)
 	case FlowCustom:
 		provider, apiKey, serviceTokens, err := ol.resolveCustomLogin(input)
 		if err != nil {
@@ -187,6 +229,24 @@ func (ol *OpenAILogin) credentialsStep() *bridgev2.LoginStep {
 			ID:   "magic_proxy_link",
 			Name: "Magic Proxy link",
 		})
+	case ProviderAnthropic:
+		if !ol.configHasAnthropicKey() {
+			fields = append(fields, bridgev2.LoginInputDataField{
+				Type:        bridgev2.LoginInputFieldTypeToken,
+				ID:          "anthropic_api_key",
+				Name:        "Anthropic API Key",
+				Description: "Generate one at https://console.anthropic.com/settings/keys",
+			})
+		}
+	case ProviderGemini:
+		if !ol.configHasGeminiKey() {
+			fields = append(fields, bridgev2.LoginInputDataField{
+				Type:        bridgev2.LoginInputFieldTypeToken,
+				ID:          "gemini_api_key",
+				Name:        "Gemini API Key",
+				Description: "Generate one at https://aistudio.google.com/apikey",
+			})
+		}
 	case FlowCustom:
 		if !ol.configHasOpenRouterKey() {
 			fields = append(fields, bridgev2.LoginInputDataField{
@@ -549,6 +609,14 @@ func (ol *OpenAILogin) configHasOpenAIKey() bool {
 	return strings.TrimSpace(ol.Connector.Config.Providers.OpenAI.APIKey) != ""
 }
 
+func (ol *OpenAILogin) configHasAnthropicKey() bool {
+	return strings.TrimSpace(ol.Connector.Config.Providers.Anthropic.APIKey) != ""
+}
+
+func (ol *OpenAILogin) configHasGeminiKey() bool {
+	return strings.TrimSpace(ol.Connector.Config.Providers.Gemini.APIKey) != ""
+}
+
 func (ol *OpenAILogin) configHasExaKey() bool {
 	if ol.Connector.Config.Tools.Search != nil && strings.TrimSpace(ol.Connector.Config.Tools.Search.Exa.APIKey) != "" {
 		return true
@@ -570,6 +638,12 @@ func formatRemoteName(provider, apiKey string) string {
 		return fmt.Sprintf("OpenRouter (%s)", maskAPIKey(apiKey))
 	case ProviderMagicProxy:
 		return fmt.Sprintf("Magic Proxy (%s)", maskAPIKey(apiKey))
+	case ProviderAnthropic:
+		return fmt.Sprintf("Anthropic (%s)", maskAPIKey(apiKey))
+	case ProviderGemini:
+		return fmt.Sprintf("Gemini (%s)", maskAPIKey(apiKey))
+	case ProviderLocal:
+		return "Local models"
 	default:
 		return "AI Bridge"
 	}
@@ -2,6 +2,7 @@ package connector
 
 import (
 	"context"
+	"net/http"
 
 	"maunium.net/go/mautrix"
 	"maunium.net/go/mautrix/bridgev2"
@@ -30,6 +31,37 @@ func resolveSchedulerDelayedEventIntent(login *bridgev2.UserLogin) schedulerDela
 	return bot.Matrix
 }
 
+// delayedEventsFeature is the unstable flag of MSC4140 delayed events.
+var delayedEventsFeature = mautrix.UnstableFeature{UnstableFlag: "org.matrix.msc4140"}
+
+// homeserverSupportsDelayedEvents reports whether the homeserver advertises
+// delayed events. Hungryserv supports them without advertising the flag.
+func homeserverSupportsDelayedEvents(br *bridgev2.Bridge) bool {
+	if br == nil {
+		return false
+	}
+	matrixConnector, ok := br.Matrix.(*matrix.Connector)
+	if !ok || matrixConnector == nil || matrixConnector.SpecVersions == nil {
+		return false
+	}
+	versions := matrixConnector.SpecVersions
+	return versions.Supports(delayedEventsFeature) || versions.Supports(mautrix.BeeperFeatureHungry)
+}
+
+// registerPublicHandler adds an endpoint to the appservice HTTP server that
+// isn't behind the provisioning API's auth middleware.
+func registerPublicHandler(br *bridgev2.Bridge, pattern string, handler http.HandlerFunc) bool {
+	if br == nil {
+		return false
+	}
+	matrixConnector, ok := br.Matrix.(*matrix.Connector)
+	if !ok || matrixConnector == nil || matrixConnector.AS == nil || matrixConnector.AS.Router == nil {
+		return false
+	}
+	matrixConnector.AS.Router.HandleFunc(pattern, handler)
+	return true
+}
+
 func registerScheduleTickEventHandler(br *bridgev2.Bridge, handler func(context.Context, *event.Event)) bool {
 	if br == nil {
 		return false
@@ -88,13 +88,13 @@ func (oc *AIClient) mcpRequestTimeout() time.Duration {
 	return time.Duration(defaultMCPTimeoutSeconds) * time.Second
 }
 
-func (oc *AIClient) mcpHTTPClientForServer(server namedMCPServer) (*http.Client, error) {
+func (oc *AIClient) mcpHTTPClientForServer(server namedMCPServer, timeout time.Duration) (*http.Client, error) {
 	headerValue, err := mcpAuthorizationHeaderValue(server.Config.AuthType, server.Config.Token)
 	if err != nil {
 		return nil, err
 	}
 	client := &http.Client{
-		Timeout: oc.mcpRequestTimeout(),
+		Timeout: timeout,
 		Transport: &mcpAuthRoundTripper{
 			base:          http.DefaultTransport,
 			authorization: headerValue,
@@ -104,6 +104,13 @@ func (oc *AIClient) mcpHTTPClientForServer(server namedMCPServer) (*http.Client,
 }
 
 func (oc *AIClient) newMCPSession(ctx context.Context, server namedMCPServer) (*mcp.ClientSession, error) {
+	return oc.connectMCPSession(ctx, ctx, server, oc.mcpClientOptions(ctx, server.Name), oc.mcpRequestTimeout())
+}
+
+// connectMCPSession connects to server within ctx. Stdio servers are stopped
+// when lifetime ends; HTTP requests are bounded by httpTimeout (0 for none, so
+// the server's notification stream isn't cut off).
+func (oc *AIClient) connectMCPSession(ctx, lifetime context.Context, server namedMCPServer, opts *mcp.ClientOptions, httpTimeout time.Duration) (*mcp.ClientSession, error) {
 	if oc == nil {
 		return nil, errors.New("mcp requires bridge context")
 	}
@@ -118,7 +125,7 @@ func (oc *AIClient) newMCPSession(ctx context.Context, server namedMCPServer) (*
 	client := mcp.NewClient(&mcp.Implementation{
 		Name:    "ai-bridge",
 		Version: "1.0.0",
-	}, nil)
+	}, opts)
 
 	var (
 		session *mcp.ClientSession
@@ -126,10 +133,10 @@ func (oc *AIClient) newMCPSession(ctx context.Context, server namedMCPServer) (*
 	)
 	switch server.Config.Transport {
 	case mcpTransportStdio:
-		cmd := exec.CommandContext(ctx, server.Config.Command, server.Config.Args...)
+		cmd := exec.CommandContext(lifetime, server.Config.Command, server.Config.Args...)
 		session, err = client.Connect(ctx, &mcp.CommandTransport{Command: cmd}, nil)
 	case mcpTransportStreamableHTTP:
-		httpClient, clientErr := oc.mcpHTTPClientForServer(server)
+		httpClient, clientErr := oc.mcpHTTPClientForServer(server, httpTimeout)
 		if clientErr != nil {
 			return nil, clientErr
 		}
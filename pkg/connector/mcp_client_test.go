@@ -1,8 +1,12 @@
 package connector
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -114,3 +118,80 @@ func TestFormatMCPToolResultMultiContent(t *testing.T) {
 		t.Fatalf("expected content array with 2 items, got: %v", parsed)
 	}
 }
+
+func TestFormatMCPResourceText(t *testing.T) {
+	result := &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+		{URI: "docs://readme", MIMEType: "text/markdown", Text: "# Readme\n"},
+		{URI: "docs://logo", MIMEType: "image/png", Blob: []byte{1, 2, 3}},
+	}}
+	got := formatMCPResourceText(result)
+	want := "# Readme\n\n[binary content docs://logo: image/png, 3 bytes]"
+	if got != want {
+		t.Fatalf("unexpected resource text:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestInvalidateMCPResourceCache(t *testing.T) {
+	oc := &AIClient{
+		mcpResourcesFetchedAt: time.Now(),
+		mcpResourceContents: map[MCPResourceRef]mcpResourceContent{
+			{Server: "docs", URI: "docs://a"}: {text: "a", fetchedAt: time.Now()},
+			{Server: "docs", URI: "docs://b"}: {text: "b", fetchedAt: time.Now()},
+			{Server: "wiki", URI: "docs://a"}: {text: "wiki a", fetchedAt: time.Now()},
+		},
+	}
+
+	oc.invalidateMCPResourceCache("docs", "docs://a")
+	if _, ok := oc.mcpResourceContents[MCPResourceRef{Server: "docs", URI: "docs://a"}]; ok {
+		t.Fatal("expected updated resource to be evicted")
+	}
+	if len(oc.mcpResourceContents) != 2 || oc.mcpResourcesFetchedAt.IsZero() {
+		t.Fatal("expected other entries and the resource list to survive a single-resource update")
+	}
+
+	oc.invalidateMCPResourceCache("docs", "")
+	if len(oc.mcpResourceContents) != 1 || !oc.mcpResourcesFetchedAt.IsZero() {
+		t.Fatalf("expected list change to drop docs entries and the list, got %#v", oc.mcpResourceContents)
+	}
+}
+
+func TestWatchMCPResourceInvalidatesOnUpdate(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}, &mcp.ServerOptions{
+		SubscribeHandler:   func(context.Context, *mcp.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
+	})
+	server.AddResource(&mcp.Resource{URI: "docs://readme", Name: "readme"}, func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "readme"}}}, nil
+	})
+	httpServer := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	defer httpServer.Close()
+
+	oc := &AIClient{}
+	defer oc.closeMCPResourceWatches()
+	named := namedMCPServer{Name: "docs", Config: MCPServerConfig{Endpoint: httpServer.URL, AuthType: "none", Connected: true}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := oc.watchMCPResource(ctx, named, "docs://readme"); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	ref := MCPResourceRef{Server: "docs", URI: "docs://readme"}
+	oc.mcpToolsMu.Lock()
+	oc.mcpResourceContents = map[MCPResourceRef]mcpResourceContent{ref: {text: "stale", fetchedAt: time.Now()}}
+	oc.mcpToolsMu.Unlock()
+
+	if err := server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "docs://readme"}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	for {
+		oc.mcpToolsMu.Lock()
+		_, cached := oc.mcpResourceContents[ref]
+		oc.mcpToolsMu.Unlock()
+		if !cached {
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatal("expected the update notification to evict the cached resource")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
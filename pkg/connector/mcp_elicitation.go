@@ -0,0 +1,282 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/id"
+
+	"github.com/beeper/agentremote/pkg/shared/stringutil"
+)
+
+type mcpElicitationField struct {
+	Type        string   `json:"type"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Default     any      `json:"default,omitempty"`
+}
+
+// mcpElicitationSchema is the flat object schema MCP allows for form elicitation.
+type mcpElicitationSchema struct {
+	Properties map[string]mcpElicitationField `json:"properties"`
+	Required   []string                       `json:"required,omitempty"`
+}
+
+func (s mcpElicitationSchema) fieldNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+type pendingMCPElicitation struct {
+	server string
+	mode   string
+	schema mcpElicitationSchema
+	reply  chan *mcp.ElicitResult
+}
+
+func parseMCPElicitationSchema(raw any) (mcpElicitationSchema, error) {
+	var schema mcpElicitationSchema
+	if raw == nil {
+		return schema, nil
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return schema, err
+	}
+	if err := json.Unmarshal(encoded, &schema); err != nil {
+		return schema, fmt.Errorf("invalid elicitation schema: %w", err)
+	}
+	return schema, nil
+}
+
+// handleMCPElicitation posts the server's question in the room and waits for
+// the user's next message, which resolvePendingMCPElicitation routes back here.
+func (oc *AIClient) handleMCPElicitation(
+	ctx context.Context,
+	portal *bridgev2.Portal,
+	serverName string,
+	req *mcp.ElicitRequest,
+) (*mcp.ElicitResult, error) {
+	if portal == nil || portal.MXID == "" {
+		return nil, errors.New("MCP elicitation requires an active chat")
+	}
+	if req == nil || req.Params == nil {
+		return nil, errors.New("missing elicitation params")
+	}
+	params := req.Params
+	schema, err := parseMCPElicitationSchema(params.RequestedSchema)
+	if err != nil {
+		return nil, err
+	}
+	pending := &pendingMCPElicitation{
+		server: serverName,
+		mode:   strings.TrimSpace(params.Mode),
+		schema: schema,
+		reply:  make(chan *mcp.ElicitResult, 1),
+	}
+
+	oc.mcpElicitationsMu.Lock()
+	if _, busy := oc.mcpElicitations[portal.MXID]; busy {
+		oc.mcpElicitationsMu.Unlock()
+		return nil, errors.New("another MCP request is already waiting for input in this chat")
+	}
+	if oc.mcpElicitations == nil {
+		oc.mcpElicitations = make(map[id.RoomID]*pendingMCPElicitation)
+	}
+	oc.mcpElicitations[portal.MXID] = pending
+	oc.mcpElicitationsMu.Unlock()
+	defer func() {
+		oc.mcpElicitationsMu.Lock()
+		if oc.mcpElicitations[portal.MXID] == pending {
+			delete(oc.mcpElicitations, portal.MXID)
+		}
+		oc.mcpElicitationsMu.Unlock()
+	}()
+
+	oc.sendSystemNotice(ctx, portal, formatMCPElicitationPrompt(serverName, params, schema))
+
+	timer := time.NewTimer(time.Duration(oc.toolApprovalsTTLSeconds()) * time.Second)
+	defer timer.Stop()
+	select {
+	case result := <-pending.reply:
+		return result, nil
+	case <-ctx.Done():
+		return &mcp.ElicitResult{Action: "cancel"}, nil
+	case <-timer.C:
+		oc.sendSystemNotice(ctx, portal, fmt.Sprintf("MCP server '%s' stopped waiting for your answer.", serverName))
+		return &mcp.ElicitResult{Action: "cancel"}, nil
+	}
+}
+
+// resolvePendingMCPElicitation consumes an owner message as the answer to a
+// waiting elicitation. Returns false when nothing is waiting in the room.
+func (oc *AIClient) resolvePendingMCPElicitation(ctx context.Context, portal *bridgev2.Portal, sender id.UserID, body string) bool {
+	if oc == nil || portal == nil || oc.UserLogin == nil || sender != oc.UserLogin.UserMXID {
+		return false
+	}
+	oc.mcpElicitationsMu.Lock()
+	pending := oc.mcpElicitations[portal.MXID]
+	oc.mcpElicitationsMu.Unlock()
+	if pending == nil {
+		return false
+	}
+
+	result, err := parseMCPElicitationReply(pending.mode, pending.schema, body)
+	if err != nil {
+		oc.sendSystemNotice(ctx, portal, fmt.Sprintf("Couldn't use that answer: %s. Try again, or reply `cancel`.", err.Error()))
+		return true
+	}
+	select {
+	case pending.reply <- result:
+	default:
+	}
+	return true
+}
+
+func formatMCPElicitationPrompt(serverName string, params *mcp.ElicitParams, schema mcpElicitationSchema) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "MCP server '%s' asks: %s", serverName, strings.TrimSpace(params.Message))
+	if params.Mode == "url" {
+		fmt.Fprintf(&sb, "\n\nOpen %s, then reply `done`. Reply `decline` to refuse or `cancel` to dismiss.", params.URL)
+		return sb.String()
+	}
+	names := schema.fieldNames()
+	if len(names) > 0 {
+		sb.WriteString("\n")
+		for _, name := range names {
+			field := schema.Properties[name]
+			fmt.Fprintf(&sb, "\n- %s", name)
+			details := []string{field.Type}
+			if slices.Contains(schema.Required, name) {
+				details = append(details, "required")
+			}
+			if len(field.Enum) > 0 {
+				details = append(details, "one of: "+strings.Join(field.Enum, ", "))
+			}
+			fmt.Fprintf(&sb, " (%s)", strings.Join(details, ", "))
+			if label := firstLine(stringutil.FirstNonEmpty(strings.TrimSpace(field.Description), strings.TrimSpace(field.Title))); label != "" {
+				sb.WriteString(": " + label)
+			}
+		}
+	}
+	if len(names) > 1 {
+		sb.WriteString("\n\nReply with one `field: value` per line.")
+	} else if len(names) == 1 {
+		sb.WriteString("\n\nReply with the value.")
+	} else {
+		sb.WriteString("\n\nReply `ok` to confirm.")
+	}
+	sb.WriteString(" Reply `decline` to refuse or `cancel` to dismiss.")
+	return sb.String()
+}
+
+// parseMCPElicitationReply turns a chat reply into an elicitation result.
+// Single-field forms take the whole reply; larger forms take "field: value"
+// (or "field=value") lines. Values are coerced to the schema's primitive types.
+func parseMCPElicitationReply(mode string, schema mcpElicitationSchema, body string) (*mcp.ElicitResult, error) {
+	text := strings.TrimSpace(body)
+	switch strings.ToLower(text) {
+	case "decline":
+		return &mcp.ElicitResult{Action: "decline"}, nil
+	case "cancel":
+		return &mcp.ElicitResult{Action: "cancel"}, nil
+	}
+	if mode == "url" || len(schema.Properties) == 0 {
+		return &mcp.ElicitResult{Action: "accept"}, nil
+	}
+
+	names := schema.fieldNames()
+	rawValues := make(map[string]string)
+	if len(names) == 1 {
+		rawValues[names[0]] = text
+		if key, value, ok := cutMCPElicitationLine(text); ok && strings.EqualFold(key, names[0]) {
+			rawValues[names[0]] = value
+		}
+	} else {
+		for _, line := range strings.Split(text, "\n") {
+			key, value, ok := cutMCPElicitationLine(line)
+			if !ok {
+				continue
+			}
+			for _, name := range names {
+				if strings.EqualFold(key, name) {
+					rawValues[name] = value
+					break
+				}
+			}
+		}
+	}
+
+	content := make(map[string]any, len(rawValues))
+	for _, name := range names {
+		raw, ok := rawValues[name]
+		if !ok || raw == "" {
+			if slices.Contains(schema.Required, name) {
+				return nil, fmt.Errorf("missing %s", name)
+			}
+			continue
+		}
+		value, err := coerceMCPElicitationValue(schema.Properties[name], raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		content[name] = value
+	}
+	return &mcp.ElicitResult{Action: "accept", Content: content}, nil
+}
+
+func cutMCPElicitationLine(line string) (string, string, bool) {
+	sep := strings.IndexAny(line, ":=")
+	if sep <= 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(line[:sep]), strings.TrimSpace(line[sep+1:]), true
+}
+
+func coerceMCPElicitationValue(field mcpElicitationField, raw string) (any, error) {
+	if len(field.Enum) > 0 {
+		for _, option := range field.Enum {
+			if strings.EqualFold(option, raw) {
+				return option, nil
+			}
+		}
+		return nil, fmt.Errorf("expected one of %s", strings.Join(field.Enum, ", "))
+	}
+	switch field.Type {
+	case "integer":
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, errors.New("expected a whole number")
+		}
+		return value, nil
+	case "number":
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.New("expected a number")
+		}
+		return value, nil
+	case "boolean":
+		switch strings.ToLower(raw) {
+		case "true", "yes", "y", "1", "on":
+			return true, nil
+		case "false", "no", "n", "0", "off":
+			return false, nil
+		}
+		return nil, errors.New("expected yes or no")
+	default:
+		return raw, nil
+	}
+}
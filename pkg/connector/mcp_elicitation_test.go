@@ -0,0 +1,91 @@
+package connector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func testMCPElicitationSchema(t *testing.T) mcpElicitationSchema {
+	t.Helper()
+	schema, err := parseMCPElicitationSchema(map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":    map[string]any{"type": "string", "title": "Name"},
+			"age":     map[string]any{"type": "integer"},
+			"confirm": map[string]any{"type": "boolean"},
+			"color":   map[string]any{"type": "string", "enum": []string{"Red", "Blue"}},
+		},
+		"required": []string{"name"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected schema error: %v", err)
+	}
+	return schema
+}
+
+func TestParseMCPElicitationReplyFields(t *testing.T) {
+	schema := testMCPElicitationSchema(t)
+	result, err := parseMCPElicitationReply("", schema, "Name: Ada\nage=36\nconfirm: yes\ncolor: blue")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Action != "accept" {
+		t.Fatalf("expected accept, got %q", result.Action)
+	}
+	if result.Content["name"] != "Ada" || result.Content["age"] != 36 || result.Content["confirm"] != true || result.Content["color"] != "Blue" {
+		t.Fatalf("unexpected content: %#v", result.Content)
+	}
+
+	if _, err := parseMCPElicitationReply("", schema, "age: 36"); err == nil || !strings.Contains(err.Error(), "name") {
+		t.Fatalf("expected missing required field error, got %v", err)
+	}
+	if _, err := parseMCPElicitationReply("", schema, "name: Ada\nage: old"); err == nil || !strings.Contains(err.Error(), "age") {
+		t.Fatalf("expected coercion error, got %v", err)
+	}
+	if _, err := parseMCPElicitationReply("", schema, "name: Ada\ncolor: green"); err == nil {
+		t.Fatal("expected enum error")
+	}
+}
+
+func TestParseMCPElicitationReplyActions(t *testing.T) {
+	schema := testMCPElicitationSchema(t)
+	for body, want := range map[string]string{"decline": "decline", "Cancel": "cancel"} {
+		result, err := parseMCPElicitationReply("", schema, body)
+		if err != nil || result.Action != want {
+			t.Fatalf("%q: expected %s, got %#v (%v)", body, want, result, err)
+		}
+	}
+	result, err := parseMCPElicitationReply("url", mcpElicitationSchema{}, "done")
+	if err != nil || result.Action != "accept" {
+		t.Fatalf("expected url mode accept, got %#v (%v)", result, err)
+	}
+}
+
+func TestParseMCPElicitationReplySingleField(t *testing.T) {
+	schema, err := parseMCPElicitationSchema(map[string]any{
+		"properties": map[string]any{"token": map[string]any{"type": "string"}},
+		"required":   []string{"token"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected schema error: %v", err)
+	}
+	result, err := parseMCPElicitationReply("", schema, "  abc:123  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Content["token"] != "abc:123" {
+		t.Fatalf("expected whole reply as value, got %#v", result.Content)
+	}
+}
+
+func TestFormatMCPElicitationPrompt(t *testing.T) {
+	schema := testMCPElicitationSchema(t)
+	text := formatMCPElicitationPrompt("github", &mcp.ElicitParams{Message: "Who are you?"}, schema)
+	for _, want := range []string{"MCP server 'github' asks: Who are you?", "- name (string, required): Name", "one of: Red, Blue", "`field: value`"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in prompt:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package connector
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type mcpPromptEntry struct {
+	Server      string
+	Name        string
+	Title       string
+	Description string
+	Arguments   []*mcp.PromptArgument
+}
+
+func (oc *AIClient) invalidateMCPPromptCache() {
+	if oc == nil {
+		return
+	}
+	oc.mcpToolsMu.Lock()
+	oc.mcpPrompts = nil
+	oc.mcpPromptsFetchedAt = time.Time{}
+	oc.mcpToolsMu.Unlock()
+}
+
+func (oc *AIClient) fetchMCPPromptsForServer(ctx context.Context, server namedMCPServer) ([]mcpPromptEntry, error) {
+	session, err := oc.newMCPSession(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	if caps := mcpServerCapabilities(session); caps == nil || caps.Prompts == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]struct{})
+	var entries []mcpPromptEntry
+	for prompt, err := range session.Prompts(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list MCP prompts from %s: %w", server.Name, err)
+		}
+		if prompt == nil {
+			continue
+		}
+		name := strings.TrimSpace(prompt.Name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		entries = append(entries, mcpPromptEntry{
+			Server:      server.Name,
+			Name:        name,
+			Title:       strings.TrimSpace(prompt.Title),
+			Description: strings.TrimSpace(prompt.Description),
+			Arguments:   prompt.Arguments,
+		})
+	}
+	return entries, nil
+}
+
+func (oc *AIClient) mcpPromptEntries(ctx context.Context) ([]mcpPromptEntry, error) {
+	if !oc.isMCPConfigured() {
+		return nil, nil
+	}
+
+	oc.mcpToolsMu.Lock()
+	if time.Since(oc.mcpPromptsFetchedAt) < mcpToolCacheTTL {
+		cached := slices.Clone(oc.mcpPrompts)
+		oc.mcpToolsMu.Unlock()
+		return cached, nil
+	}
+	oc.mcpToolsMu.Unlock()
+
+	callCtx, cancel := oc.mcpListContext(ctx)
+	defer cancel()
+
+	var combined []mcpPromptEntry
+	var firstErr error
+	for _, server := range oc.activeMCPServers() {
+		entries, err := oc.fetchMCPPromptsForServer(callCtx, server)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			oc.loggerForContext(ctx).Debug().Err(err).Str("mcp_server", server.Name).Msg("Failed to discover MCP prompts from server")
+			continue
+		}
+		combined = append(combined, entries...)
+	}
+	slices.SortFunc(combined, func(a, b mcpPromptEntry) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Server, b.Server))
+	})
+
+	oc.mcpToolsMu.Lock()
+	oc.mcpPrompts = slices.Clone(combined)
+	oc.mcpPromptsFetchedAt = time.Now()
+	oc.mcpToolsMu.Unlock()
+
+	if len(combined) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return combined, nil
+}
+
+// findMCPPrompt looks a prompt up by name, accepting "server:name" when
+// several servers publish a prompt with the same name.
+func findMCPPrompt(entries []mcpPromptEntry, name string) (mcpPromptEntry, error) {
+	name = strings.TrimSpace(name)
+	var matches []mcpPromptEntry
+	for _, entry := range entries {
+		if entry.Name == name {
+			matches = append(matches, entry)
+		}
+	}
+	if len(matches) == 0 {
+		if serverName, promptName, ok := strings.Cut(name, ":"); ok {
+			serverName = normalizeMCPServerName(serverName)
+			for _, entry := range entries {
+				if entry.Server == serverName && entry.Name == promptName {
+					return entry, nil
+				}
+			}
+		}
+		return mcpPromptEntry{}, fmt.Errorf("unknown MCP prompt %q", name)
+	}
+	if len(matches) > 1 {
+		servers := make([]string, 0, len(matches))
+		for _, match := range matches {
+			servers = append(servers, match.Server+":"+match.Name)
+		}
+		return mcpPromptEntry{}, fmt.Errorf("prompt %q is published by several MCP servers; use one of %s", name, strings.Join(servers, ", "))
+	}
+	return matches[0], nil
+}
+
+// parseMCPPromptArgs reads key=value pairs. When the prompt takes a single
+// argument, free text without any "=" is passed as that argument.
+func parseMCPPromptArgs(prompt mcpPromptEntry, tokens []string, rawRest string) (map[string]string, error) {
+	args := make(map[string]string)
+	hasPairs := false
+	for _, token := range tokens {
+		key, value, ok := strings.Cut(token, "=")
+		if ok && strings.TrimSpace(key) != "" {
+			hasPairs = true
+			args[strings.TrimSpace(key)] = value
+		}
+	}
+	if !hasPairs && strings.TrimSpace(rawRest) != "" {
+		if len(prompt.Arguments) != 1 || prompt.Arguments[0] == nil {
+			return nil, errors.New("pass prompt arguments as key=value")
+		}
+		args[prompt.Arguments[0].Name] = strings.TrimSpace(rawRest)
+	}
+
+	var missing []string
+	for _, arg := range prompt.Arguments {
+		if arg == nil || !arg.Required {
+			continue
+		}
+		if strings.TrimSpace(args[arg.Name]) == "" {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required argument(s): %s", strings.Join(missing, ", "))
+	}
+	return args, nil
+}
+
+func (oc *AIClient) getMCPPrompt(ctx context.Context, prompt mcpPromptEntry, args map[string]string) (*mcp.GetPromptResult, error) {
+	server, ok := oc.mcpServerByName(prompt.Server)
+	if !ok {
+		return nil, fmt.Errorf("MCP server %q is not connected", prompt.Server)
+	}
+	session, err := oc.newMCPSession(ctx, server)
+	if err != nil {
+		if mcpCallLikelyAuthError(err) {
+			oc.notifyMCPAuthURL(ctx, server)
+		}
+		return nil, err
+	}
+	defer session.Close()
+
+	result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: prompt.Name, Arguments: args})
+	if err != nil {
+		return nil, fmt.Errorf("MCP prompt %s failed on %s: %w", prompt.Name, server.Name, err)
+	}
+	return result, nil
+}
+
+// renderMCPPromptMessages flattens a rendered prompt into a single user turn.
+// Multi-role prompts keep role labels so few-shot examples stay readable.
+func renderMCPPromptMessages(result *mcp.GetPromptResult) string {
+	if result == nil {
+		return ""
+	}
+	labelRoles := slices.ContainsFunc(result.Messages, func(msg *mcp.PromptMessage) bool {
+		return msg != nil && msg.Role != "user"
+	})
+	parts := make([]string, 0, len(result.Messages))
+	for _, msg := range result.Messages {
+		if msg == nil {
+			continue
+		}
+		text := strings.TrimSpace(mcpPromptContentText(msg.Content))
+		if text == "" {
+			continue
+		}
+		if labelRoles && msg.Role != "" {
+			text = strings.ToUpper(string(msg.Role[:1])) + string(msg.Role[1:]) + ": " + text
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func mcpPromptContentText(content mcp.Content) string {
+	switch v := content.(type) {
+	case *mcp.TextContent:
+		return v.Text
+	case *mcp.EmbeddedResource:
+		if v.Resource == nil {
+			return ""
+		}
+		if v.Resource.Text != "" {
+			return v.Resource.Text
+		}
+		return fmt.Sprintf("[resource %s]", v.Resource.URI)
+	case *mcp.ResourceLink:
+		return fmt.Sprintf("[resource %s]", v.URI)
+	case *mcp.ImageContent:
+		return "[image]"
+	case *mcp.AudioContent:
+		return "[audio]"
+	default:
+		return ""
+	}
+}
+
+func formatMCPPromptList(entries []mcpPromptEntry) string {
+	if len(entries) == 0 {
+		return "No MCP prompts available."
+	}
+	var sb strings.Builder
+	sb.WriteString("MCP prompts:")
+	for _, entry := range entries {
+		if command := mcpPromptCommandName(entry, entries); command != "" {
+			fmt.Fprintf(&sb, "\n- `/%s` (%s)", command, entry.Server)
+		} else {
+			fmt.Fprintf(&sb, "\n- `%s` (%s)", entry.Name, entry.Server)
+		}
+		if description := firstLine(entry.Description); description != "" {
+			sb.WriteString(" — " + description)
+		}
+		if usage := mcpPromptArgsUsage(entry); usage != "" {
+			sb.WriteString(" " + usage)
+		}
+	}
+	sb.WriteString("\n\nRun one with `/<name> [key=value...]`, or `!ai prompt <name> [key=value...]` for prompts without a command.")
+	return sb.String()
+}
+
+// mcpPromptArgsUsage describes a prompt's arguments as key=value pairs,
+// bracketing the optional ones.
+func mcpPromptArgsUsage(entry mcpPromptEntry) string {
+	parts := make([]string, 0, len(entry.Arguments))
+	for _, arg := range entry.Arguments {
+		if arg == nil {
+			continue
+		}
+		if arg.Required {
+			parts = append(parts, arg.Name+"=…")
+		} else {
+			parts = append(parts, "["+arg.Name+"=…]")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,101 @@
+package connector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseMCPPromptArgs(t *testing.T) {
+	prompt := mcpPromptEntry{
+		Name: "review",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "file", Required: true},
+			{Name: "focus"},
+		},
+	}
+	args, err := parseMCPPromptArgs(prompt, []string{"file=main.go", "focus=errors"}, "file=main.go focus=errors")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args["file"] != "main.go" || args["focus"] != "errors" {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	if _, err := parseMCPPromptArgs(prompt, []string{"focus=errors"}, "focus=errors"); err == nil || !strings.Contains(err.Error(), "file") {
+		t.Fatalf("expected missing required argument error, got %v", err)
+	}
+	if _, err := parseMCPPromptArgs(prompt, []string{"main.go"}, "main.go"); err == nil {
+		t.Fatal("expected error for free text with several arguments")
+	}
+}
+
+func TestParseMCPPromptArgsSingleArgumentFreeText(t *testing.T) {
+	prompt := mcpPromptEntry{Name: "summarize", Arguments: []*mcp.PromptArgument{{Name: "topic", Required: true}}}
+	args, err := parseMCPPromptArgs(prompt, []string{"release", "notes"}, "release notes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args["topic"] != "release notes" {
+		t.Fatalf("expected free text as topic, got %#v", args)
+	}
+}
+
+func TestFindMCPPrompt(t *testing.T) {
+	entries := []mcpPromptEntry{
+		{Server: "docs", Name: "summarize"},
+		{Server: "wiki", Name: "summarize"},
+		{Server: "wiki", Name: "lookup"},
+	}
+	if entry, err := findMCPPrompt(entries, "lookup"); err != nil || entry.Server != "wiki" {
+		t.Fatalf("expected wiki lookup, got %#v (%v)", entry, err)
+	}
+	if _, err := findMCPPrompt(entries, "summarize"); err == nil {
+		t.Fatal("expected ambiguity error")
+	}
+	if entry, err := findMCPPrompt(entries, "docs:summarize"); err != nil || entry.Server != "docs" {
+		t.Fatalf("expected docs summarize, got %#v (%v)", entry, err)
+	}
+	if _, err := findMCPPrompt(entries, "missing"); err == nil {
+		t.Fatal("expected unknown prompt error")
+	}
+}
+
+func TestRenderMCPPromptMessages(t *testing.T) {
+	single := &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+		{Role: "user", Content: &mcp.TextContent{Text: "Summarize the design doc."}},
+		{Role: "user", Content: &mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "docs://design", Text: "Design body"}}},
+	}}
+	if got := renderMCPPromptMessages(single); got != "Summarize the design doc.\n\nDesign body" {
+		t.Fatalf("unexpected user-only render: %q", got)
+	}
+
+	mixed := &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+		{Role: "user", Content: &mcp.TextContent{Text: "Hi"}},
+		{Role: "assistant", Content: &mcp.TextContent{Text: "Hello"}},
+	}}
+	if got := renderMCPPromptMessages(mixed); got != "User: Hi\n\nAssistant: Hello" {
+		t.Fatalf("unexpected mixed render: %q", got)
+	}
+}
+
+func TestMCPPromptCommands(t *testing.T) {
+	entries := []mcpPromptEntry{
+		{Server: "docs", Name: "Summarize"},
+		{Server: "wiki", Name: "summarize"},
+		{Server: "wiki", Name: "lookup", Description: "Look a page up\nwith details"},
+		{Server: "wiki", Name: "status"},
+		{Server: "wiki", Name: "two words"},
+	}
+	registry := mcpPromptCommands(entries)
+	if got := registry.Names(); strings.Join(got, ",") != "docs:summarize,lookup,wiki:summarize" {
+		t.Fatalf("unexpected prompt commands %v", got)
+	}
+	if handler := registry.Get("lookup"); handler == nil || handler.Help.Description != "Look a page up" {
+		t.Fatalf("expected lookup command with its description, got %#v", handler)
+	}
+	if !strings.Contains(formatMCPPromptList(entries), "`status` (wiki)") {
+		t.Fatal("expected prompts shadowed by AI commands to be listed without a slash command")
+	}
+}
@@ -0,0 +1,572 @@
+package connector
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"maunium.net/go/mautrix/bridgev2"
+)
+
+const (
+	mcpResourceContentTTL      = 5 * time.Minute
+	mcpResourceReadMaxChars    = 50_000
+	mcpResourceContextMaxChars = 16_000
+	mcpResourceContextTimeout  = 10 * time.Second
+	// mcpResourceWatchRetry spaces out reconnects to servers whose watch
+	// session failed, so an unreachable server doesn't slow down every turn.
+	mcpResourceWatchRetry = time.Minute
+)
+
+type mcpResourceEntry struct {
+	Server      string `json:"server"`
+	URI         string `json:"uri"`
+	Name        string `json:"name,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+type mcpResourceContent struct {
+	text      string
+	fetchedAt time.Time
+}
+
+// mcpClientOptions serves sampling and elicitation requests in the chat that
+// opened the session. Change notifications are handled by the longer-lived
+// watch sessions, see watchMCPResource.
+func (oc *AIClient) mcpClientOptions(ctx context.Context, serverName string) *mcp.ClientOptions {
+	// Server-initiated requests arrive on the SDK's own context, so remember
+	// the chat that opened the session.
+	var portal *bridgev2.Portal
+	if btc := GetBridgeToolContext(ctx); btc != nil {
+		portal = btc.Portal
+	}
+	return &mcp.ClientOptions{
+		CreateMessageHandler: func(hctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return oc.handleMCPSampling(hctx, portal, serverName, req)
+		},
+		ElicitationHandler: func(hctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return oc.handleMCPElicitation(hctx, portal, serverName, req)
+		},
+	}
+}
+
+// mcpResourceWatch is a long-lived session to one server that stays open to
+// receive change notifications for the resources rooms have attached.
+type mcpResourceWatch struct {
+	session      *mcp.ClientSession
+	canSubscribe bool
+	subscribed   map[string]struct{}
+	failedAt     time.Time
+}
+
+// mcpWatchClientOptions invalidates the discovery caches when a server reports
+// that its tools, prompts or resources changed.
+func (oc *AIClient) mcpWatchClientOptions(serverName string) *mcp.ClientOptions {
+	return &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			oc.invalidateMCPToolCache()
+		},
+		PromptListChangedHandler: func(context.Context, *mcp.PromptListChangedRequest) {
+			oc.invalidateMCPPromptCache()
+		},
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+			oc.invalidateMCPResourceCache(serverName, "")
+		},
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			uri := ""
+			if req != nil && req.Params != nil {
+				uri = req.Params.URI
+			}
+			oc.invalidateMCPResourceCache(serverName, uri)
+		},
+	}
+}
+
+// watchMCPResource subscribes to updates of uri over the server's watch
+// session, opening the session on first use. Servers without subscription
+// support still report list changes; their resource contents simply expire
+// after mcpResourceContentTTL.
+func (oc *AIClient) watchMCPResource(ctx context.Context, server namedMCPServer, uri string) error {
+	oc.mcpWatchesMu.Lock()
+	defer oc.mcpWatchesMu.Unlock()
+	watch := oc.mcpWatches[server.Name]
+	if watch == nil || watch.session == nil {
+		if watch != nil && time.Since(watch.failedAt) < mcpResourceWatchRetry {
+			return nil
+		}
+		if oc.mcpWatches == nil {
+			oc.mcpWatches = make(map[string]*mcpResourceWatch)
+		}
+		session, err := oc.connectMCPSession(ctx, oc.backgroundContext(ctx), server, oc.mcpWatchClientOptions(server.Name), 0)
+		if err != nil {
+			oc.mcpWatches[server.Name] = &mcpResourceWatch{failedAt: time.Now()}
+			return err
+		}
+		caps := mcpServerCapabilities(session)
+		watch = &mcpResourceWatch{
+			session:      session,
+			canSubscribe: caps != nil && caps.Resources != nil && caps.Resources.Subscribe,
+			subscribed:   make(map[string]struct{}),
+		}
+		oc.mcpWatches[server.Name] = watch
+		go oc.awaitMCPResourceWatch(server.Name, watch)
+	}
+	if !watch.canSubscribe {
+		return nil
+	}
+	if _, ok := watch.subscribed[uri]; ok {
+		return nil
+	}
+	if err := watch.session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+		return fmt.Errorf("failed to subscribe to %s on %s: %w", uri, server.Name, err)
+	}
+	watch.subscribed[uri] = struct{}{}
+	return nil
+}
+
+// awaitMCPResourceWatch forgets a watch session once it ends. Notifications may
+// have been missed, so the server's cached resource contents are dropped too.
+func (oc *AIClient) awaitMCPResourceWatch(serverName string, watch *mcpResourceWatch) {
+	_ = watch.session.Wait()
+	oc.mcpWatchesMu.Lock()
+	if oc.mcpWatches[serverName] == watch {
+		oc.mcpWatches[serverName] = &mcpResourceWatch{failedAt: time.Now()}
+	}
+	oc.mcpWatchesMu.Unlock()
+	oc.invalidateMCPResourceCache(serverName, "")
+}
+
+// closeMCPResourceWatches ends all watch sessions.
+func (oc *AIClient) closeMCPResourceWatches() {
+	if oc == nil {
+		return
+	}
+	oc.mcpWatchesMu.Lock()
+	watches := oc.mcpWatches
+	oc.mcpWatches = nil
+	oc.mcpWatchesMu.Unlock()
+	for _, watch := range watches {
+		if watch.session != nil {
+			_ = watch.session.Close()
+		}
+	}
+}
+
+// invalidateMCPResourceCache drops cached contents for one resource, or the
+// resource list and all cached contents of a server when uri is empty.
+func (oc *AIClient) invalidateMCPResourceCache(serverName, uri string) {
+	if oc == nil {
+		return
+	}
+	uri = strings.TrimSpace(uri)
+	oc.mcpToolsMu.Lock()
+	defer oc.mcpToolsMu.Unlock()
+	if uri == "" {
+		oc.mcpResourcesFetchedAt = time.Time{}
+	}
+	for ref := range oc.mcpResourceContents {
+		if ref.Server == serverName && (uri == "" || ref.URI == uri) {
+			delete(oc.mcpResourceContents, ref)
+		}
+	}
+}
+
+// invalidateMCPCaches drops all cached MCP discovery state, e.g. after the server list changes.
+func (oc *AIClient) invalidateMCPCaches() {
+	if oc == nil {
+		return
+	}
+	oc.closeMCPResourceWatches()
+	oc.invalidateMCPToolCache()
+	oc.invalidateMCPPromptCache()
+	oc.mcpToolsMu.Lock()
+	oc.mcpResources = nil
+	oc.mcpResourcesFetchedAt = time.Time{}
+	oc.mcpResourceContents = nil
+	oc.mcpToolsMu.Unlock()
+}
+
+// mcpListContext bounds discovery calls that don't already carry a deadline.
+func (oc *AIClient) mcpListContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, min(oc.mcpRequestTimeout(), 10*time.Second))
+}
+
+func mcpServerCapabilities(session *mcp.ClientSession) *mcp.ServerCapabilities {
+	if session == nil {
+		return nil
+	}
+	if result := session.InitializeResult(); result != nil {
+		return result.Capabilities
+	}
+	return nil
+}
+
+func (oc *AIClient) fetchMCPResourcesForServer(ctx context.Context, server namedMCPServer) ([]mcpResourceEntry, error) {
+	session, err := oc.newMCPSession(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	if caps := mcpServerCapabilities(session); caps == nil || caps.Resources == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]struct{})
+	var entries []mcpResourceEntry
+	for resource, err := range session.Resources(ctx, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list MCP resources from %s: %w", server.Name, err)
+		}
+		if resource == nil {
+			continue
+		}
+		uri := strings.TrimSpace(resource.URI)
+		if uri == "" {
+			continue
+		}
+		if _, ok := seen[uri]; ok {
+			continue
+		}
+		seen[uri] = struct{}{}
+		entries = append(entries, mcpResourceEntry{
+			Server:      server.Name,
+			URI:         uri,
+			Name:        strings.TrimSpace(resource.Name),
+			Title:       strings.TrimSpace(resource.Title),
+			Description: strings.TrimSpace(resource.Description),
+			MIMEType:    strings.TrimSpace(resource.MIMEType),
+			Size:        resource.Size,
+		})
+	}
+	return entries, nil
+}
+
+func (oc *AIClient) mcpResourceEntries(ctx context.Context) ([]mcpResourceEntry, error) {
+	if !oc.isMCPConfigured() {
+		return nil, nil
+	}
+
+	oc.mcpToolsMu.Lock()
+	if time.Since(oc.mcpResourcesFetchedAt) < mcpToolCacheTTL {
+		cached := slices.Clone(oc.mcpResources)
+		oc.mcpToolsMu.Unlock()
+		return cached, nil
+	}
+	oc.mcpToolsMu.Unlock()
+
+	callCtx, cancel := oc.mcpListContext(ctx)
+	defer cancel()
+
+	var combined []mcpResourceEntry
+	var firstErr error
+	for _, server := range oc.activeMCPServers() {
+		entries, err := oc.fetchMCPResourcesForServer(callCtx, server)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			oc.loggerForContext(ctx).Debug().Err(err).Str("mcp_server", server.Name).Msg("Failed to discover MCP resources from server")
+			continue
+		}
+		combined = append(combined, entries...)
+	}
+	slices.SortFunc(combined, func(a, b mcpResourceEntry) int {
+		return cmp.Or(cmp.Compare(a.Server, b.Server), cmp.Compare(a.URI, b.URI))
+	})
+
+	oc.mcpToolsMu.Lock()
+	oc.mcpResources = slices.Clone(combined)
+	oc.mcpResourcesFetchedAt = time.Now()
+	oc.mcpToolsMu.Unlock()
+
+	if len(combined) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return combined, nil
+}
+
+// resolveMCPResourceServer picks the server for a URI: the explicit server if
+// given, else the one that listed the URI, else the only connected server.
+func (oc *AIClient) resolveMCPResourceServer(ctx context.Context, serverName, uri string) (namedMCPServer, error) {
+	if strings.TrimSpace(serverName) != "" {
+		server, ok := oc.mcpServerByName(serverName)
+		if !ok {
+			return namedMCPServer{}, fmt.Errorf("MCP server %q is not connected", serverName)
+		}
+		return server, nil
+	}
+	entries, _ := oc.mcpResourceEntries(ctx)
+	var matches []string
+	for _, entry := range entries {
+		if entry.URI == uri {
+			matches = append(matches, entry.Server)
+		}
+	}
+	if len(matches) > 1 {
+		return namedMCPServer{}, fmt.Errorf("resource %s is served by several MCP servers (%s); pass server", uri, strings.Join(matches, ", "))
+	}
+	if len(matches) == 1 {
+		if server, ok := oc.mcpServerByName(matches[0]); ok {
+			return server, nil
+		}
+	}
+	servers := oc.activeMCPServers()
+	if len(servers) == 1 {
+		return servers[0], nil
+	}
+	return namedMCPServer{}, fmt.Errorf("unknown MCP resource %s; pass server", uri)
+}
+
+func (oc *AIClient) readMCPResource(ctx context.Context, server namedMCPServer, uri string) (*mcp.ReadResourceResult, error) {
+	session, err := oc.newMCPSession(ctx, server)
+	if err != nil {
+		if mcpCallLikelyAuthError(err) {
+			oc.notifyMCPAuthURL(ctx, server)
+		}
+		return nil, err
+	}
+	defer session.Close()
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		if mcpCallLikelyAuthError(err) {
+			oc.notifyMCPAuthURL(ctx, server)
+		}
+		return nil, fmt.Errorf("MCP resource read failed for %s on %s: %w", uri, server.Name, err)
+	}
+	return result, nil
+}
+
+// cachedMCPResourceText returns the resource text, reusing a recent read until
+// it expires or, for watched resources, the server reports that it changed.
+func (oc *AIClient) cachedMCPResourceText(ctx context.Context, server namedMCPServer, uri string) (string, error) {
+	ref := MCPResourceRef{Server: server.Name, URI: uri}
+	oc.mcpToolsMu.Lock()
+	if cached, ok := oc.mcpResourceContents[ref]; ok && time.Since(cached.fetchedAt) < mcpResourceContentTTL {
+		oc.mcpToolsMu.Unlock()
+		return cached.text, nil
+	}
+	oc.mcpToolsMu.Unlock()
+
+	result, err := oc.readMCPResource(ctx, server, uri)
+	if err != nil {
+		return "", err
+	}
+	text := formatMCPResourceText(result)
+
+	oc.mcpToolsMu.Lock()
+	if oc.mcpResourceContents == nil {
+		oc.mcpResourceContents = make(map[MCPResourceRef]mcpResourceContent)
+	}
+	oc.mcpResourceContents[ref] = mcpResourceContent{text: text, fetchedAt: time.Now()}
+	oc.mcpToolsMu.Unlock()
+	return text, nil
+}
+
+// formatMCPResourceText flattens resource contents to text; binary parts are
+// described rather than inlined.
+func formatMCPResourceText(result *mcp.ReadResourceResult) string {
+	if result == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(result.Contents))
+	for _, content := range result.Contents {
+		if content == nil {
+			continue
+		}
+		if text := strings.TrimSpace(content.Text); text != "" {
+			parts = append(parts, text)
+			continue
+		}
+		if len(content.Blob) > 0 {
+			mimeType := content.MIMEType
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+			parts = append(parts, fmt.Sprintf("[binary content %s: %s, %d bytes]", content.URI, mimeType, len(content.Blob)))
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func executeMCPResource(ctx context.Context, args map[string]any) (string, error) {
+	btc := GetBridgeToolContext(ctx)
+	if btc == nil || btc.Client == nil {
+		return "", errors.New("mcp_resource requires bridge context")
+	}
+	oc := btc.Client
+	if !oc.isMCPConfigured() {
+		return "", errors.New("MCP resources are not configured (add an MCP server with !ai mcp add/connect)")
+	}
+
+	action, _ := args["action"].(string)
+	uri, _ := args["uri"].(string)
+	serverName, _ := args["server"].(string)
+	action = strings.ToLower(strings.TrimSpace(action))
+	uri = strings.TrimSpace(uri)
+	serverName = normalizeMCPServerName(serverName)
+
+	callCtx, cancel := context.WithTimeout(ctx, oc.mcpRequestTimeout())
+	defer cancel()
+
+	switch action {
+	case "list":
+		entries, err := oc.mcpResourceEntries(callCtx)
+		if err != nil {
+			return "", err
+		}
+		if serverName != "" {
+			entries = slices.DeleteFunc(entries, func(entry mcpResourceEntry) bool {
+				return entry.Server != serverName
+			})
+		}
+		var attached []MCPResourceRef
+		if btc.Portal != nil {
+			if meta := portalMeta(btc.Portal); meta != nil {
+				attached = meta.MCPResources
+			}
+		}
+		return marshalMCPResourceResult(map[string]any{
+			"action":    "list",
+			"resources": entries,
+			"count":     len(entries),
+			"attached":  attached,
+		})
+	case "read", "attach", "detach":
+		if uri == "" {
+			return "", fmt.Errorf("uri is required for %s", action)
+		}
+	default:
+		return "", fmt.Errorf("unknown action %q (use list, read, attach or detach)", action)
+	}
+
+	if action == "detach" {
+		meta, err := mcpResourcePortalMeta(btc)
+		if err != nil {
+			return "", err
+		}
+		before := len(meta.MCPResources)
+		meta.MCPResources = slices.DeleteFunc(meta.MCPResources, func(ref MCPResourceRef) bool {
+			return ref.URI == uri && (serverName == "" || ref.Server == serverName)
+		})
+		if len(meta.MCPResources) != before {
+			oc.savePortalQuiet(ctx, btc.Portal, "mcp resource detach")
+		}
+		return marshalMCPResourceResult(map[string]any{
+			"action":   "detach",
+			"uri":      uri,
+			"detached": before - len(meta.MCPResources),
+		})
+	}
+
+	server, err := oc.resolveMCPResourceServer(callCtx, serverName, uri)
+	if err != nil {
+		return "", err
+	}
+	text, err := oc.cachedMCPResourceText(callCtx, server, uri)
+	if err != nil {
+		return "", err
+	}
+
+	if action == "attach" {
+		meta, err := mcpResourcePortalMeta(btc)
+		if err != nil {
+			return "", err
+		}
+		if err := oc.watchMCPResource(callCtx, server, uri); err != nil {
+			oc.loggerForContext(ctx).Debug().Err(err).Str("mcp_server", server.Name).Str("uri", uri).Msg("Failed to watch MCP resource")
+		}
+		ref := MCPResourceRef{Server: server.Name, URI: uri}
+		if !slices.Contains(meta.MCPResources, ref) {
+			meta.MCPResources = append(meta.MCPResources, ref)
+			oc.savePortalQuiet(ctx, btc.Portal, "mcp resource attach")
+		}
+		return marshalMCPResourceResult(map[string]any{
+			"action":   "attach",
+			"server":   server.Name,
+			"uri":      uri,
+			"attached": meta.MCPResources,
+		})
+	}
+
+	truncated := len([]rune(text)) > mcpResourceReadMaxChars
+	return marshalMCPResourceResult(map[string]any{
+		"action":    "read",
+		"server":    server.Name,
+		"uri":       uri,
+		"text":      truncateText(text, mcpResourceReadMaxChars),
+		"truncated": truncated,
+	})
+}
+
+func mcpResourcePortalMeta(btc *BridgeToolContext) (*PortalMetadata, error) {
+	if btc.Portal == nil {
+		return nil, errors.New("attaching MCP resources requires a room")
+	}
+	meta := portalMeta(btc.Portal)
+	if meta == nil {
+		return nil, errors.New("failed to get portal metadata")
+	}
+	return meta, nil
+}
+
+func marshalMCPResourceResult(payload map[string]any) (string, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode mcp_resource result: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// buildMCPResourceContextPrompt renders the room's attached MCP resources as a
+// system prompt section. Resources that can't be read are noted, not dropped,
+// so the model knows the context is incomplete.
+func (oc *AIClient) buildMCPResourceContextPrompt(ctx context.Context, meta *PortalMetadata) string {
+	if oc == nil || meta == nil || len(meta.MCPResources) == 0 || !oc.isMCPConfigured() {
+		return ""
+	}
+	callCtx, cancel := context.WithTimeout(ctx, mcpResourceContextTimeout)
+	defer cancel()
+
+	var sb strings.Builder
+	sb.WriteString("Attached MCP resources (reference material for this chat; use mcp_resource to detach):")
+	for _, ref := range meta.MCPResources {
+		fmt.Fprintf(&sb, "\n\n## %s (%s)\n", ref.URI, ref.Server)
+		server, ok := oc.mcpServerByName(ref.Server)
+		if !ok {
+			sb.WriteString("[unavailable: MCP server is not connected]")
+			continue
+		}
+		// Resubscribe after restarts and reconnects; no-op once watched.
+		if err := oc.watchMCPResource(callCtx, server, ref.URI); err != nil {
+			oc.loggerForContext(ctx).Debug().Err(err).Str("mcp_server", ref.Server).Str("uri", ref.URI).Msg("Failed to watch MCP resource")
+		}
+		text, err := oc.cachedMCPResourceText(callCtx, server, ref.URI)
+		if err != nil {
+			oc.loggerForContext(ctx).Debug().Err(err).Str("mcp_server", ref.Server).Str("uri", ref.URI).Msg("Failed to read attached MCP resource")
+			sb.WriteString("[unavailable: " + err.Error() + "]")
+			continue
+		}
+		if len([]rune(text)) > mcpResourceContextMaxChars {
+			text = truncateText(text, mcpResourceContextMaxChars) + "\n[truncated; use mcp_resource read for the full text]"
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
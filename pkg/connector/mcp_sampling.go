@@ -0,0 +1,197 @@
+package connector
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"maunium.net/go/mautrix/bridgev2"
+
+	"github.com/beeper/agentremote/pkg/bridgeadapter"
+	airuntime "github.com/beeper/agentremote/pkg/runtime"
+)
+
+const (
+	mcpSamplingRuleName     = "sampling"
+	mcpSamplingPreviewChars = 280
+)
+
+// handleMCPSampling serves sampling/createMessage with the room's current
+// provider and model, after the user approves the request.
+func (oc *AIClient) handleMCPSampling(
+	ctx context.Context,
+	portal *bridgev2.Portal,
+	serverName string,
+	req *mcp.CreateMessageRequest,
+) (*mcp.CreateMessageResult, error) {
+	if portal == nil {
+		return nil, errors.New("MCP sampling requires an active chat")
+	}
+	if req == nil || req.Params == nil || len(req.Params.Messages) == 0 {
+		return nil, errors.New("sampling request has no messages")
+	}
+	if oc.provider == nil {
+		return nil, errors.New("no AI provider available for sampling")
+	}
+	params := req.Params
+
+	if err := oc.approveMCPSampling(ctx, portal, serverName, params); err != nil {
+		return nil, err
+	}
+
+	meta := portalMeta(portal)
+	modelID := oc.effectiveModel(meta)
+	promptContext := PromptContext{
+		SystemPrompt: strings.TrimSpace(params.SystemPrompt),
+		Messages:     mcpSamplingPromptMessages(params.Messages),
+	}
+	resp, err := oc.provider.Generate(ctx, GenerateParams{
+		Model:               oc.modelIDForAPI(modelID),
+		Context:             promptContext,
+		Temperature:         params.Temperature,
+		MaxCompletionTokens: int(params.MaxTokens),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sampling failed: %w", err)
+	}
+
+	stopReason := "endTurn"
+	if resp.FinishReason == "length" || resp.FinishReason == "max_tokens" {
+		stopReason = "maxTokens"
+	}
+	return &mcp.CreateMessageResult{
+		Content:    &mcp.TextContent{Text: resp.Content},
+		Model:      modelID,
+		Role:       "assistant",
+		StopReason: stopReason,
+	}, nil
+}
+
+// approveMCPSampling asks the user to approve a sampling request unless tool
+// approvals are off, MCP approvals aren't required, or the server is always allowed.
+// Argument rules for the sampling tool name allow or deny it outright.
+func (oc *AIClient) approveMCPSampling(ctx context.Context, portal *bridgev2.Portal, serverName string, params *mcp.CreateMessageParams) error {
+	if rule := oc.toolArgumentRule(ToolApprovalKindMCP, serverName, mcpSamplingRuleName, nil); rule != nil {
+		if rule.denies() {
+			return errors.New("sampling request was denied by a tool approval rule")
+		}
+		return nil
+	}
+	runtimeDecision := airuntime.DecideToolApproval(airuntime.ToolPolicyInput{
+		ToolName:      mcpSamplingRuleName,
+		ToolKind:      "mcp",
+		RequireForMCP: oc.toolApprovalsRequireForMCP(),
+	})
+	needsApproval := oc.toolApprovalsRuntimeEnabled() &&
+		runtimeDecision.State == airuntime.ToolApprovalRequired &&
+		!oc.isMcpAlwaysAllowed(serverName, mcpSamplingRuleName)
+	if !needsApproval {
+		return nil
+	}
+
+	approvalID := NewCallID()
+	toolName := "mcp." + mcpSamplingRuleName
+	ttlSeconds := oc.toolApprovalsTTLSeconds()
+	if _, created := oc.registerToolApproval(ToolApprovalParams{
+		ApprovalID:   approvalID,
+		RoomID:       portal.MXID,
+		ToolCallID:   approvalID,
+		ToolName:     toolName,
+		ToolKind:     ToolApprovalKindMCP,
+		RuleToolName: mcpSamplingRuleName,
+		ServerLabel:  serverName,
+		TTL:          time.Duration(ttlSeconds) * time.Second,
+	}); !created {
+		return errors.New("failed to register sampling approval")
+	}
+	oc.approvalFlow.SendPrompt(ctx, portal, bridgeadapter.SendPromptParams{
+		ApprovalPromptMessageParams: bridgeadapter.ApprovalPromptMessageParams{
+			ApprovalID: approvalID,
+			ToolCallID: approvalID,
+			ToolName:   toolName,
+			Body:       buildMCPSamplingApprovalBody(serverName, params, toolName),
+			ExpiresAt:  bridgeadapter.ComputeApprovalExpiry(ttlSeconds),
+		},
+		RoomID:    portal.MXID,
+		OwnerMXID: oc.UserLogin.UserMXID,
+	})
+
+	resolution, _, ok := oc.waitToolApproval(ctx, approvalID)
+	if !ok {
+		return errors.New("sampling request was not approved in time")
+	}
+	if !approvalAllowed(resolution.Decision) {
+		return errors.New("sampling request was denied by the user")
+	}
+	return nil
+}
+
+func buildMCPSamplingApprovalBody(serverName string, params *mcp.CreateMessageParams, toolName string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "MCP server '%s' wants to run a model completion", serverName)
+	if params.MaxTokens > 0 {
+		fmt.Fprintf(&sb, " (up to %d tokens)", params.MaxTokens)
+	}
+	sb.WriteString(".")
+	if preview := mcpSamplingPreview(params.Messages); preview != "" {
+		sb.WriteString("\n> " + preview)
+	}
+	sb.WriteString("\n\n" + bridgeadapter.BuildApprovalPromptBody(toolName, nil))
+	return sb.String()
+}
+
+// mcpSamplingPreview shows the last user text so the approver knows what is being asked.
+func mcpSamplingPreview(messages []*mcp.SamplingMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg == nil || msg.Role != "user" {
+			continue
+		}
+		if text, ok := msg.Content.(*mcp.TextContent); ok {
+			preview := strings.Join(strings.Fields(text.Text), " ")
+			if len([]rune(preview)) > mcpSamplingPreviewChars {
+				preview = truncateText(preview, mcpSamplingPreviewChars) + "…"
+			}
+			return preview
+		}
+	}
+	return ""
+}
+
+func mcpSamplingPromptMessages(messages []*mcp.SamplingMessage) []PromptMessage {
+	out := make([]PromptMessage, 0, len(messages))
+	for _, msg := range messages {
+		if msg == nil {
+			continue
+		}
+		var block PromptBlock
+		switch content := msg.Content.(type) {
+		case *mcp.TextContent:
+			block = PromptBlock{Type: PromptBlockText, Text: content.Text}
+		case *mcp.ImageContent:
+			block = PromptBlock{
+				Type:     PromptBlockImage,
+				ImageB64: base64.StdEncoding.EncodeToString(content.Data),
+				MimeType: content.MIMEType,
+			}
+		case *mcp.AudioContent:
+			block = PromptBlock{
+				Type:        PromptBlockAudio,
+				AudioB64:    base64.StdEncoding.EncodeToString(content.Data),
+				AudioFormat: strings.TrimPrefix(content.MIMEType, "audio/"),
+			}
+		default:
+			continue
+		}
+		role := PromptRoleUser
+		if msg.Role == "assistant" {
+			role = PromptRoleAssistant
+		}
+		out = append(out, PromptMessage{Role: role, Blocks: []PromptBlock{block}})
+	}
+	return out
+}
@@ -0,0 +1,28 @@
+package connector
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestMCPSamplingPromptMessages(t *testing.T) {
+	messages := mcpSamplingPromptMessages([]*mcp.SamplingMessage{
+		{Role: "user", Content: &mcp.TextContent{Text: "hello"}},
+		{Role: "assistant", Content: &mcp.TextContent{Text: "hi"}},
+		{Role: "user", Content: &mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"}},
+		nil,
+	})
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+	if messages[1].Role != PromptRoleAssistant || messages[1].Blocks[0].Text != "hi" {
+		t.Fatalf("unexpected assistant message: %#v", messages[1])
+	}
+	if messages[2].Blocks[0].Type != PromptBlockImage || messages[2].Blocks[0].ImageB64 != "cG5n" {
+		t.Fatalf("unexpected image block: %#v", messages[2].Blocks[0])
+	}
+	if preview := mcpSamplingPreview([]*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: "  summarize\n this "}}}); preview != "summarize this" {
+		t.Fatalf("unexpected preview %q", preview)
+	}
+}
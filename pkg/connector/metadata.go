@@ -95,6 +95,11 @@ type ToolApprovalsConfig struct {
 	// Matching is done on normalized (trim + lowercase) tool name + action.
 	// Action "" means "any action".
 	BuiltinAlwaysAllow []BuiltinAlwaysAllowRule `json:"builtin_always_allow,omitempty"`
+
+	// ArgumentRules allow or deny tool calls based on their arguments.
+	// A matching deny rule blocks the call even when it would otherwise be
+	// always-allowed; a matching allow rule skips the approval prompt.
+	ArgumentRules []ToolArgumentRule `json:"argument_rules,omitempty"`
 }
 
 type MCPAlwaysAllowRule struct {
@@ -107,6 +112,21 @@ type BuiltinAlwaysAllowRule struct {
 	Action   string `json:"action,omitempty"`
 }
 
+// ToolArgumentRule matches tool calls by tool name and, optionally, one argument.
+// ToolName and ServerLabel are globs; Argument is a dotted path into the call's
+// arguments and Match selects how Pattern is compared against it. Allow regexes
+// must match the whole value; deny regexes match anywhere in it.
+type ToolArgumentRule struct {
+	ID          string `json:"id"`
+	Effect      string `json:"effect"`                 // allow|deny
+	ToolKind    string `json:"tool_kind,omitempty"`    // builtin|mcp, empty matches both
+	ServerLabel string `json:"server_label,omitempty"` // MCP only, empty matches any server
+	ToolName    string `json:"tool_name"`
+	Argument    string `json:"argument,omitempty"`
+	Match       string `json:"match,omitempty"` // glob|regex|prefix|domain; in globs "**" spans "/"
+	Pattern     string `json:"pattern,omitempty"`
+}
+
 // UserLoginMetadata is stored on each login row to keep per-user settings.
 type UserLoginMetadata struct {
 	Provider             string         `json:"provider,omitempty"` // Selected provider (beeper, openai, openrouter)
@@ -185,6 +205,8 @@ type PortalMetadata struct {
 	ModuleMeta           map[string]any `json:"module_meta,omitempty"`             // Generic per-module metadata (e.g., cron room markers, memory flush state)
 	SubagentParentRoomID string         `json:"subagent_parent_room_id,omitempty"` // Parent room ID for subagent sessions
 
+	MCPResources []MCPResourceRef `json:"mcp_resources,omitempty"` // MCP resources attached to the prompt context
+
 	// Runtime-only overrides (not persisted)
 	DisabledTools        []string        `json:"-"`
 	ResolvedTarget       *ResolvedTarget `json:"-"`
@@ -200,6 +222,12 @@ type PortalMetadata struct {
 
 }
 
+// MCPResourceRef identifies an MCP resource attached to a room's prompt context.
+type MCPResourceRef struct {
+	Server string `json:"server"`
+	URI    string `json:"uri"`
+}
+
 // SetModuleMeta sets a key in the ModuleMeta map, initializing the map if necessary.
 func (m *PortalMetadata) SetModuleMeta(key string, value any) {
 	if m == nil {
@@ -302,6 +330,9 @@ func clonePortalMetadata(src *PortalMetadata) *PortalMetadata {
 	if len(src.DisabledTools) > 0 {
 		clone.DisabledTools = slices.Clone(src.DisabledTools)
 	}
+	if len(src.MCPResources) > 0 {
+		clone.MCPResources = slices.Clone(src.MCPResources)
+	}
 	clone.ResolvedTarget = src.ResolvedTarget
 
 	if src.ModuleMeta != nil {
@@ -32,5 +32,9 @@ func (oc *AIClient) resolveModelAPI(meta *PortalMetadata) ModelAPI {
 			return api
 		}
 	}
+	// Local servers only reliably implement Chat Completions.
+	if loginMeta := loginMetadata(oc.UserLogin); loginMeta != nil && loginMeta.Provider == ProviderLocal {
+		return ModelAPIChatCompletions
+	}
 	return ModelAPIResponses
 }
@@ -89,6 +89,20 @@ func (oc *AIClient) implicitModelCatalogEntries(meta *UserLoginMetadata) []Model
 		return modelCatalogEntriesFromManifest(func(provider string) bool {
 			return provider == ProviderOpenAI
 		})
+	case ProviderAnthropic:
+		if strings.TrimSpace(oc.connector.resolveProviderAPIKey(meta)) == "" {
+			return nil
+		}
+		return modelCatalogEntriesFromManifest(func(provider string) bool {
+			return provider == ProviderAnthropic
+		})
+	case ProviderGemini:
+		if strings.TrimSpace(oc.connector.resolveProviderAPIKey(meta)) == "" {
+			return nil
+		}
+		return modelCatalogEntriesFromManifest(func(provider string) bool {
+			return provider == "google"
+		})
 	default:
 		return nil
 	}
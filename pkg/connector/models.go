@@ -11,6 +11,7 @@ type ModelBackend string
 const (
 	BackendOpenAI     ModelBackend = "openai"
 	BackendOpenRouter ModelBackend = "openrouter"
+	BackendLocal      ModelBackend = "local"
 )
 
 // Default models for each provider
@@ -19,6 +20,8 @@ const (
 	// OpenRouter-compatible backends (OpenRouter + Magic Proxy) should default to Opus.
 	DefaultModelOpenRouter = "anthropic/claude-opus-4.6"
 	DefaultModelBeeper     = "anthropic/claude-opus-4.6"
+	DefaultModelAnthropic  = "anthropic/claude-opus-4.6"
+	DefaultModelGemini     = "google/gemini-2.5-pro"
 )
 
 // ParseModelPrefix extracts the backend and actual model ID from a prefixed model
@@ -37,6 +40,8 @@ func ParseModelPrefix(modelID string) (backend ModelBackend, actualModel string)
 		return BackendOpenAI, rest
 	case "openrouter":
 		return BackendOpenRouter, rest // rest = "openai/gpt-5" (nested)
+	case "local":
+		return BackendLocal, rest // rest = "llama3.1:8b" (server-side model name)
 	default:
 		return "", modelID // Unknown prefix, return as-is
 	}
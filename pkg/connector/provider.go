@@ -75,6 +75,7 @@ type UsageInfo struct {
 	CompletionTokens int
 	TotalTokens      int
 	ReasoningTokens  int // For models with extended thinking
+	CachedTokens     int // Prompt tokens served from the provider's prompt cache
 }
 
 // Note: ModelInfo is defined in events.go and used for model metadata
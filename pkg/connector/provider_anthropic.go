@@ -0,0 +1,765 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	anthropicAPIVersion        = "2023-06-01"
+	anthropicBetaFeatures      = "prompt-caching-2024-07-31,pdfs-2024-09-25"
+	anthropicDefaultMaxTokens  = 8192
+	anthropicMinThinkingBudget = 1024
+	// anthropicMaxCachedThinking bounds how many tool-use turns keep their thinking blocks for replay.
+	anthropicMaxCachedThinking = 256
+)
+
+// anthropicThinkingBudgets maps reasoning effort levels to extended thinking token budgets.
+var anthropicThinkingBudgets = map[string]int{
+	"low":    2048,
+	"medium": 8192,
+	"high":   16384,
+}
+
+// AnthropicProvider implements AIProvider for the native Anthropic Messages API.
+type AnthropicProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	log        zerolog.Logger
+
+	// Extended thinking blocks must be replayed verbatim (with signatures) when a
+	// tool-use turn is continued. The canonical prompt model drops signatures, so
+	// they are remembered here keyed by the tool_use ID that followed them.
+	thinkingMu        sync.Mutex
+	thinkingByToolUse map[string][]anthropicContentBlock
+	thinkingOrder     []string
+}
+
+// NewAnthropicProvider creates a provider for the Anthropic Messages API.
+func NewAnthropicProvider(apiKey, baseURL string, log zerolog.Logger) (*AnthropicProvider, error) {
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return nil, errors.New("missing Anthropic API key")
+	}
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		baseURL = defaultAnthropicBaseURL
+	}
+	return &AnthropicProvider{
+		apiKey:            apiKey,
+		baseURL:           baseURL,
+		httpClient:        &http.Client{},
+		log:               log.With().Str("provider", "anthropic").Logger(),
+		thinkingByToolUse: make(map[string][]anthropicContentBlock),
+	}, nil
+}
+
+func (p *AnthropicProvider) Name() string {
+	return "anthropic"
+}
+
+type anthropicCacheControl struct {
+	Type string `json:"type"`
+}
+
+var anthropicEphemeralCache = &anthropicCacheControl{Type: "ephemeral"}
+
+type anthropicSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicContentBlock struct {
+	Type         string                  `json:"type"`
+	Text         string                  `json:"text,omitempty"`
+	Source       *anthropicSource        `json:"source,omitempty"`
+	Title        string                  `json:"title,omitempty"`
+	ID           string                  `json:"id,omitempty"`
+	Name         string                  `json:"name,omitempty"`
+	Input        json.RawMessage         `json:"input,omitempty"`
+	ToolUseID    string                  `json:"tool_use_id,omitempty"`
+	Content      []anthropicContentBlock `json:"content,omitempty"`
+	IsError      bool                    `json:"is_error,omitempty"`
+	Thinking     string                  `json:"thinking,omitempty"`
+	Signature    string                  `json:"signature,omitempty"`
+	Data         string                  `json:"data,omitempty"`
+	CacheControl *anthropicCacheControl  `json:"cache_control,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	InputSchema  map[string]any         `json:"input_schema"`
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+type anthropicThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens,omitempty"`
+}
+
+type anthropicMessagesRequest struct {
+	Model       string                  `json:"model"`
+	MaxTokens   int                     `json:"max_tokens"`
+	System      []anthropicContentBlock `json:"system,omitempty"`
+	Messages    []anthropicMessage      `json:"messages"`
+	Tools       []anthropicTool         `json:"tools,omitempty"`
+	Temperature *float64                `json:"temperature,omitempty"`
+	Thinking    *anthropicThinking      `json:"thinking,omitempty"`
+	Stream      bool                    `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+func (u anthropicUsage) toUsageInfo() UsageInfo {
+	prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return UsageInfo{
+		PromptTokens:     prompt,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      prompt + u.OutputTokens,
+		CachedTokens:     u.CacheReadInputTokens,
+	}
+}
+
+type anthropicMessagesResponse struct {
+	ID         string                  `json:"id"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+type anthropicErrorBody struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// anthropicStreamEvent is the union of all Messages API SSE payloads.
+type anthropicStreamEvent struct {
+	Type         string                     `json:"type"`
+	Index        int                        `json:"index"`
+	Message      *anthropicMessagesResponse `json:"message,omitempty"`
+	ContentBlock *anthropicContentBlock     `json:"content_block,omitempty"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		Thinking    string `json:"thinking"`
+		Signature   string `json:"signature"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *anthropicUsage `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// anthropicAPIModelID converts a catalog model ID (e.g. "anthropic/claude-opus-4.6")
+// into the identifier expected by the Messages API ("claude-opus-4-6").
+func anthropicAPIModelID(modelID string) string {
+	modelID = strings.TrimSpace(modelID)
+	modelID = strings.TrimPrefix(modelID, "anthropic/")
+	return strings.ReplaceAll(modelID, ".", "-")
+}
+
+func mapAnthropicStopReason(reason string) string {
+	switch reason {
+	case "", "end_turn", "stop_sequence", "pause_turn":
+		return "stop"
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	case "refusal":
+		return "content_filter"
+	default:
+		return reason
+	}
+}
+
+func (p *AnthropicProvider) buildRequest(params GenerateParams, stream bool) anthropicMessagesRequest {
+	req := anthropicMessagesRequest{
+		Model:     anthropicAPIModelID(params.Model),
+		MaxTokens: params.MaxCompletionTokens,
+		Stream:    stream,
+	}
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = anthropicDefaultMaxTokens
+	}
+
+	system := params.Context.SystemPrompt
+	appendPromptText(&system, params.Context.DeveloperPrompt)
+	if system != "" {
+		req.System = []anthropicContentBlock{{Type: "text", Text: system, CacheControl: anthropicEphemeralCache}}
+	}
+
+	req.Messages = p.promptMessagesToAnthropic(params.Context.Messages)
+	markAnthropicMessageCacheBreakpoint(req.Messages)
+
+	for _, tool := range params.Context.Tools {
+		schema := tool.Parameters
+		if schema != nil {
+			var stripped []string
+			schema, stripped = sanitizeToolSchemaWithReport(schema)
+			logSchemaSanitization(&p.log, tool.Name, stripped)
+		}
+		if schema == nil {
+			schema = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: schema,
+		})
+	}
+	req.Tools = dedupeAnthropicTools(req.Tools)
+	if len(req.Tools) > 0 {
+		req.Tools[len(req.Tools)-1].CacheControl = anthropicEphemeralCache
+	}
+
+	if budget, ok := anthropicThinkingBudgets[params.ReasoningEffort]; ok && p.canUseThinking(req.Messages) {
+		req.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: max(budget, anthropicMinThinkingBudget)}
+		// The thinking budget counts against max_tokens, so leave room for the visible answer.
+		if req.MaxTokens <= budget {
+			req.MaxTokens = budget + req.MaxTokens
+		}
+	} else if params.Temperature > 0 {
+		// Anthropic rejects custom temperatures while extended thinking is enabled.
+		temp := params.Temperature
+		req.Temperature = &temp
+	}
+	return req
+}
+
+// canUseThinking reports whether extended thinking can be enabled for this request.
+// A continued tool-use turn must start with the original signed thinking block; if
+// it is no longer available, thinking has to be disabled for the continuation.
+func (p *AnthropicProvider) canUseThinking(messages []anthropicMessage) bool {
+	if len(messages) == 0 {
+		return true
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if msg.Role != "assistant" {
+			continue
+		}
+		hasToolUse := false
+		for _, block := range msg.Content {
+			if block.Type == "tool_use" {
+				hasToolUse = true
+				break
+			}
+		}
+		if !hasToolUse {
+			return true
+		}
+		if i != len(messages)-2 {
+			return true
+		}
+		first := msg.Content[0].Type
+		return first == "thinking" || first == "redacted_thinking"
+	}
+	return true
+}
+
+func dedupeAnthropicTools(tools []anthropicTool) []anthropicTool {
+	if len(tools) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(tools))
+	out := make([]anthropicTool, 0, len(tools))
+	for _, tool := range tools {
+		if _, ok := seen[tool.Name]; ok {
+			continue
+		}
+		seen[tool.Name] = struct{}{}
+		out = append(out, tool)
+	}
+	return out
+}
+
+// markAnthropicMessageCacheBreakpoint marks the end of the conversation prefix so
+// follow-up turns can read the history from Anthropic's prompt cache.
+func markAnthropicMessageCacheBreakpoint(messages []anthropicMessage) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "user" || len(messages[i].Content) == 0 {
+			continue
+		}
+		last := len(messages[i].Content) - 1
+		switch messages[i].Content[last].Type {
+		case "thinking", "redacted_thinking":
+			return
+		}
+		messages[i].Content[last].CacheControl = anthropicEphemeralCache
+		return
+	}
+}
+
+func (p *AnthropicProvider) promptMessagesToAnthropic(messages []PromptMessage) []anthropicMessage {
+	var out []anthropicMessage
+	appendBlocks := func(role string, blocks []anthropicContentBlock) {
+		if len(blocks) == 0 {
+			return
+		}
+		// The Messages API requires alternating roles; merge consecutive turns.
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content = append(out[n-1].Content, blocks...)
+			return
+		}
+		out = append(out, anthropicMessage{Role: role, Content: blocks})
+	}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case PromptRoleUser:
+			appendBlocks("user", promptBlocksToAnthropic(msg.Blocks))
+		case PromptRoleAssistant:
+			appendBlocks("assistant", p.assistantBlocksToAnthropic(msg.Blocks))
+		case PromptRoleToolResult:
+			content := promptBlocksToAnthropic(msg.Blocks)
+			if len(content) == 0 {
+				content = []anthropicContentBlock{{Type: "text", Text: "(no output)"}}
+			}
+			appendBlocks("user", []anthropicContentBlock{{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   content,
+				IsError:   msg.IsError,
+			}})
+		}
+	}
+	return out
+}
+
+func (p *AnthropicProvider) assistantBlocksToAnthropic(blocks []PromptBlock) []anthropicContentBlock {
+	var out []anthropicContentBlock
+	for _, block := range blocks {
+		switch block.Type {
+		case PromptBlockText:
+			if strings.TrimSpace(block.Text) != "" {
+				out = append(out, anthropicContentBlock{Type: "text", Text: block.Text})
+			}
+		case PromptBlockToolCall:
+			if len(out) == 0 {
+				out = append(out, p.cachedThinking(block.ToolCallID)...)
+			}
+			out = append(out, anthropicContentBlock{
+				Type:  "tool_use",
+				ID:    block.ToolCallID,
+				Name:  block.ToolName,
+				Input: anthropicToolInput(block.ToolCallArguments),
+			})
+		}
+		// Unsigned thinking text cannot be replayed to the Messages API.
+	}
+	return out
+}
+
+func anthropicToolInput(arguments string) json.RawMessage {
+	trimmed := strings.TrimSpace(arguments)
+	if trimmed == "" || !json.Valid([]byte(trimmed)) || !strings.HasPrefix(trimmed, "{") {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(trimmed)
+}
+
+func promptBlocksToAnthropic(blocks []PromptBlock) []anthropicContentBlock {
+	var out []anthropicContentBlock
+	for _, block := range blocks {
+		switch block.Type {
+		case PromptBlockText:
+			if strings.TrimSpace(block.Text) != "" {
+				out = append(out, anthropicContentBlock{Type: "text", Text: block.Text})
+			}
+		case PromptBlockImage:
+			if source := anthropicMediaSource(block.ImageURL, block.ImageB64, block.MimeType); source != nil {
+				out = append(out, anthropicContentBlock{Type: "image", Source: source})
+			}
+		case PromptBlockFile:
+			source := anthropicMediaSource(block.FileURL, block.FileB64, block.MimeType)
+			if source == nil {
+				continue
+			}
+			if source.Type == "base64" && source.MediaType == "" {
+				source.MediaType = "application/pdf"
+			}
+			if source.Type == "base64" && source.MediaType != "application/pdf" {
+				out = append(out, anthropicContentBlock{
+					Type: "text",
+					Text: fmt.Sprintf("[Attached file %s (%s) is not supported by this model]", block.Filename, source.MediaType),
+				})
+				continue
+			}
+			out = append(out, anthropicContentBlock{Type: "document", Source: source, Title: block.Filename})
+		case PromptBlockAudio, PromptBlockVideo:
+			out = append(out, anthropicContentBlock{
+				Type: "text",
+				Text: fmt.Sprintf("[%s attachment omitted: not supported by this model]", block.Type),
+			})
+		}
+	}
+	return out
+}
+
+// anthropicMediaSource builds a base64 or URL source for an image or document block.
+func anthropicMediaSource(rawURL, b64, mimeType string) *anthropicSource {
+	media, ok := resolvePromptMedia(rawURL, b64, mimeType)
+	if !ok {
+		return nil
+	}
+	if media.URL != "" {
+		return &anthropicSource{Type: "url", URL: media.URL}
+	}
+	return &anthropicSource{Type: "base64", MediaType: media.MimeType, Data: media.Data}
+}
+
+func (p *AnthropicProvider) cachedThinking(toolUseID string) []anthropicContentBlock {
+	if toolUseID == "" {
+		return nil
+	}
+	p.thinkingMu.Lock()
+	defer p.thinkingMu.Unlock()
+	return p.thinkingByToolUse[toolUseID]
+}
+
+func (p *AnthropicProvider) rememberThinking(blocks []anthropicContentBlock) {
+	var thinking []anthropicContentBlock
+	var toolUseID string
+	for _, block := range blocks {
+		switch block.Type {
+		case "thinking", "redacted_thinking":
+			thinking = append(thinking, block)
+		case "tool_use":
+			if toolUseID == "" {
+				toolUseID = block.ID
+			}
+		}
+	}
+	if len(thinking) == 0 || toolUseID == "" {
+		return
+	}
+	p.thinkingMu.Lock()
+	defer p.thinkingMu.Unlock()
+	if _, exists := p.thinkingByToolUse[toolUseID]; !exists {
+		p.thinkingOrder = append(p.thinkingOrder, toolUseID)
+	}
+	p.thinkingByToolUse[toolUseID] = thinking
+	for len(p.thinkingOrder) > anthropicMaxCachedThinking {
+		delete(p.thinkingByToolUse, p.thinkingOrder[0])
+		p.thinkingOrder = p.thinkingOrder[1:]
+	}
+}
+
+func (p *AnthropicProvider) newRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode Anthropic request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+	req.Header.Set("anthropic-beta", anthropicBetaFeatures)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (p *AnthropicProvider) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	p.log.Debug().
+		Str("request_path", req.URL.Path).
+		Int("status_code", resp.StatusCode).
+		Str("upstream_request_id", resp.Header.Get("request-id")).
+		Int64("duration_ms", time.Since(start).Milliseconds()).
+		Msg("Provider HTTP response")
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, parseAnthropicError(resp)
+	}
+	return resp, nil
+}
+
+func parseAnthropicError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var body anthropicErrorBody
+	if err := json.Unmarshal(raw, &body); err == nil && body.Error.Message != "" {
+		return fmt.Errorf("anthropic API error: %s (%s): %s", resp.Status, body.Error.Type, body.Error.Message)
+	}
+	return fmt.Errorf("anthropic API error: %s: %s", resp.Status, strings.TrimSpace(string(raw)))
+}
+
+// GenerateStream generates a streaming response using the Messages API.
+func (p *AnthropicProvider) GenerateStream(ctx context.Context, params GenerateParams) (<-chan StreamEvent, error) {
+	req, err := p.newRequest(ctx, http.MethodPost, "/v1/messages", p.buildRequest(params, true))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StreamEvent, 100)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		p.consumeStream(resp.Body, events)
+	}()
+	return events, nil
+}
+
+func (p *AnthropicProvider) consumeStream(body io.Reader, events chan<- StreamEvent) {
+	var (
+		responseID string
+		stopReason string
+		usage      anthropicUsage
+		blocks     = map[int]*anthropicContentBlock{}
+		toolInputs = map[int]*strings.Builder{}
+		completed  []anthropicContentBlock
+	)
+
+	err := readServerSentEvents(body, func(data []byte) bool {
+		var evt anthropicStreamEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			p.log.Debug().Err(err).Msg("Skipping malformed Anthropic stream event")
+			return true
+		}
+		switch evt.Type {
+		case "message_start":
+			if evt.Message != nil {
+				responseID = evt.Message.ID
+				usage = evt.Message.Usage
+			}
+		case "content_block_start":
+			if evt.ContentBlock == nil {
+				return true
+			}
+			block := *evt.ContentBlock
+			blocks[evt.Index] = &block
+			if block.Type == "tool_use" {
+				toolInputs[evt.Index] = &strings.Builder{}
+			}
+			if block.Type == "text" && block.Text != "" {
+				events <- StreamEvent{Type: StreamEventDelta, Delta: block.Text}
+			}
+		case "content_block_delta":
+			block := blocks[evt.Index]
+			switch evt.Delta.Type {
+			case "text_delta":
+				if evt.Delta.Text != "" {
+					events <- StreamEvent{Type: StreamEventDelta, Delta: evt.Delta.Text}
+				}
+			case "thinking_delta":
+				if block != nil {
+					block.Thinking += evt.Delta.Thinking
+				}
+				if evt.Delta.Thinking != "" {
+					events <- StreamEvent{Type: StreamEventReasoning, ReasoningDelta: evt.Delta.Thinking}
+				}
+			case "signature_delta":
+				if block != nil {
+					block.Signature += evt.Delta.Signature
+				}
+			case "input_json_delta":
+				if input := toolInputs[evt.Index]; input != nil {
+					input.WriteString(evt.Delta.PartialJSON)
+				}
+			}
+		case "content_block_stop":
+			block := blocks[evt.Index]
+			if block == nil {
+				return true
+			}
+			if block.Type == "tool_use" {
+				args := "{}"
+				if input := toolInputs[evt.Index]; input != nil && strings.TrimSpace(input.String()) != "" {
+					args = input.String()
+				}
+				block.Input = json.RawMessage(args)
+				events <- StreamEvent{
+					Type: StreamEventToolCall,
+					ToolCall: &ToolCallResult{
+						ID:        block.ID,
+						Name:      block.Name,
+						Arguments: args,
+					},
+				}
+			}
+			completed = append(completed, *block)
+		case "message_delta":
+			if evt.Delta.StopReason != "" {
+				stopReason = evt.Delta.StopReason
+			}
+			if evt.Usage != nil {
+				usage.OutputTokens = evt.Usage.OutputTokens
+			}
+		case "message_stop":
+			p.rememberThinking(completed)
+			info := usage.toUsageInfo()
+			events <- StreamEvent{
+				Type:         StreamEventComplete,
+				FinishReason: mapAnthropicStopReason(stopReason),
+				ResponseID:   responseID,
+				Usage:        &info,
+			}
+			return false
+		case "error":
+			msg := "unknown error"
+			if evt.Error != nil {
+				msg = fmt.Sprintf("%s: %s", evt.Error.Type, evt.Error.Message)
+			}
+			events <- StreamEvent{Type: StreamEventError, Error: fmt.Errorf("anthropic API error: %s", msg)}
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		events <- StreamEvent{Type: StreamEventError, Error: err}
+	}
+}
+
+// Generate performs a non-streaming generation using the Messages API.
+func (p *AnthropicProvider) Generate(ctx context.Context, params GenerateParams) (*GenerateResponse, error) {
+	req, err := p.newRequest(ctx, http.MethodPost, "/v1/messages", p.buildRequest(params, false))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic generation failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result anthropicMessagesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Anthropic response: %w", err)
+	}
+	p.rememberThinking(result.Content)
+
+	var content strings.Builder
+	var toolCalls []ToolCallResult
+	for _, block := range result.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			args := "{}"
+			if len(block.Input) > 0 {
+				args = string(block.Input)
+			}
+			toolCalls = append(toolCalls, ToolCallResult{ID: block.ID, Name: block.Name, Arguments: args})
+		}
+	}
+
+	return &GenerateResponse{
+		Content:      content.String(),
+		FinishReason: mapAnthropicStopReason(result.StopReason),
+		ResponseID:   result.ID,
+		ToolCalls:    toolCalls,
+		Usage:        result.Usage.toUsageInfo(),
+	}, nil
+}
+
+type anthropicModelsPage struct {
+	Data []struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+}
+
+// ListModels returns the models available to this API key.
+func (p *AnthropicProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var models []ModelInfo
+	afterID := ""
+	for {
+		path := "/v1/models?limit=100"
+		if afterID != "" {
+			path += "&after_id=" + url.QueryEscape(afterID)
+		}
+		req, err := p.newRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.do(req)
+		if err != nil {
+			return nil, err
+		}
+		var page anthropicModelsPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Anthropic model list: %w", err)
+		}
+		for _, model := range page.Data {
+			models = append(models, anthropicModelInfo(model.ID, model.DisplayName))
+		}
+		if !page.HasMore || page.LastID == "" {
+			break
+		}
+		afterID = page.LastID
+	}
+	return models, nil
+}
+
+func anthropicModelInfo(apiID, displayName string) ModelInfo {
+	fullID := "anthropic/" + apiID
+	if displayName == "" {
+		displayName = fullID
+	}
+	legacy := strings.HasPrefix(apiID, "claude-2") || strings.HasPrefix(apiID, "claude-instant")
+	// Extended thinking is available from Claude 3.7 onwards.
+	reasoning := !legacy && (!strings.HasPrefix(apiID, "claude-3-") || strings.HasPrefix(apiID, "claude-3-7"))
+	return ModelInfo{
+		ID:                  fullID,
+		Name:                displayName,
+		Provider:            ProviderAnthropic,
+		SupportsVision:      !legacy,
+		SupportsToolCalling: !legacy,
+		SupportsPDF:         !legacy,
+		SupportsReasoning:   reasoning,
+		ContextWindow:       200000,
+	}
+}
@@ -0,0 +1,270 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func newAnthropicTestServer(t *testing.T, handler func(w http.ResponseWriter, body anthropicMessagesRequest)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("unexpected api key header: %q", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got != anthropicAPIVersion {
+			t.Errorf("unexpected anthropic-version header: %q", got)
+		}
+		var body anthropicMessagesRequest
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+		}
+		handler(w, body)
+	}))
+}
+
+func writeAnthropicSSE(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, evt := range events {
+		var typed struct {
+			Type string `json:"type"`
+		}
+		_ = json.Unmarshal([]byte(evt), &typed)
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typed.Type, evt)
+	}
+}
+
+func TestAnthropicProviderGenerateStreamTextThinkingAndTools(t *testing.T) {
+	var captured anthropicMessagesRequest
+	server := newAnthropicTestServer(t, func(w http.ResponseWriter, body anthropicMessagesRequest) {
+		captured = body
+		writeAnthropicSSE(w,
+			`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":10,"cache_read_input_tokens":90,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Let me check."}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+			`{"type":"ping"}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Hello"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"content_block_start","index":2,"content_block":{"type":"tool_use","id":"toolu_1","name":"web_search","input":{}}}`,
+			`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"{\"query\":"}}`,
+			`{"type":"content_block_delta","index":2,"delta":{"type":"input_json_delta","partial_json":"\"go\"}"}}`,
+			`{"type":"content_block_stop","index":2}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":25}}`,
+			`{"type":"message_stop"}`,
+		)
+	})
+	defer server.Close()
+
+	provider, err := NewAnthropicProvider("test-key", server.URL, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	events, err := provider.GenerateStream(context.Background(), GenerateParams{
+		Model:           "anthropic/claude-opus-4.6",
+		ReasoningEffort: "low",
+		Temperature:     0.5,
+		Context: PromptContext{
+			SystemPrompt: "Be brief.",
+			Messages: []PromptMessage{{
+				Role:   PromptRoleUser,
+				Blocks: []PromptBlock{{Type: PromptBlockText, Text: "hi"}},
+			}},
+			Tools: []ToolDefinition{{
+				Name:        "web_search",
+				Description: "Search the web",
+				Parameters:  map[string]any{"type": "object", "properties": map[string]any{"query": map[string]any{"type": "string"}}},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+
+	var text, reasoning strings.Builder
+	var toolCall *ToolCallResult
+	var complete *StreamEvent
+	for evt := range events {
+		switch evt.Type {
+		case StreamEventDelta:
+			text.WriteString(evt.Delta)
+		case StreamEventReasoning:
+			reasoning.WriteString(evt.ReasoningDelta)
+		case StreamEventToolCall:
+			toolCall = evt.ToolCall
+		case StreamEventComplete:
+			complete = &evt
+		case StreamEventError:
+			t.Fatalf("unexpected stream error: %v", evt.Error)
+		}
+	}
+
+	if text.String() != "Hello" {
+		t.Fatalf("unexpected text: %q", text.String())
+	}
+	if reasoning.String() != "Let me check." {
+		t.Fatalf("unexpected reasoning: %q", reasoning.String())
+	}
+	if toolCall == nil || toolCall.ID != "toolu_1" || toolCall.Name != "web_search" || toolCall.Arguments != `{"query":"go"}` {
+		t.Fatalf("unexpected tool call: %+v", toolCall)
+	}
+	if complete == nil || complete.FinishReason != "tool_calls" || complete.ResponseID != "msg_1" {
+		t.Fatalf("unexpected completion event: %+v", complete)
+	}
+	if complete.Usage == nil || complete.Usage.PromptTokens != 100 || complete.Usage.CompletionTokens != 25 || complete.Usage.CachedTokens != 90 {
+		t.Fatalf("unexpected usage: %+v", complete.Usage)
+	}
+
+	if captured.Model != "claude-opus-4-6" {
+		t.Fatalf("unexpected model: %q", captured.Model)
+	}
+	if !captured.Stream {
+		t.Fatalf("expected stream=true")
+	}
+	if captured.Thinking == nil || captured.Thinking.BudgetTokens != 2048 {
+		t.Fatalf("expected thinking budget 2048, got %+v", captured.Thinking)
+	}
+	if captured.MaxTokens <= captured.Thinking.BudgetTokens {
+		t.Fatalf("max_tokens %d must exceed thinking budget", captured.MaxTokens)
+	}
+	if captured.Temperature != nil {
+		t.Fatalf("temperature must be omitted with thinking enabled")
+	}
+	if len(captured.System) != 1 || captured.System[0].CacheControl == nil {
+		t.Fatalf("expected cached system prompt, got %+v", captured.System)
+	}
+	if len(captured.Tools) != 1 || captured.Tools[0].CacheControl == nil {
+		t.Fatalf("expected cached tool definition, got %+v", captured.Tools)
+	}
+
+	// The signed thinking block must be replayed before the tool_use on continuation.
+	req := provider.buildRequest(GenerateParams{
+		Model:           "anthropic/claude-opus-4.6",
+		ReasoningEffort: "low",
+		Context: PromptContext{Messages: []PromptMessage{
+			{Role: PromptRoleUser, Blocks: []PromptBlock{{Type: PromptBlockText, Text: "hi"}}},
+			{Role: PromptRoleAssistant, Blocks: []PromptBlock{{
+				Type:              PromptBlockToolCall,
+				ToolCallID:        "toolu_1",
+				ToolName:          "web_search",
+				ToolCallArguments: `{"query":"go"}`,
+			}}},
+			{Role: PromptRoleToolResult, ToolCallID: "toolu_1", Blocks: []PromptBlock{{Type: PromptBlockText, Text: "results"}}},
+		}},
+	}, true)
+	assistant := req.Messages[1].Content
+	if assistant[0].Type != "thinking" || assistant[0].Signature != "sig" || assistant[1].Type != "tool_use" {
+		t.Fatalf("expected replayed thinking before tool_use, got %+v", assistant)
+	}
+	if req.Thinking == nil {
+		t.Fatalf("expected thinking to stay enabled when the signed block is available")
+	}
+}
+
+func TestAnthropicProviderBuildRequestConvertsPromptContext(t *testing.T) {
+	provider, err := NewAnthropicProvider("test-key", "", zerolog.Nop())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	req := provider.buildRequest(GenerateParams{
+		Model:           "anthropic/claude-sonnet-4.5",
+		ReasoningEffort: "high",
+		Temperature:     0.3,
+		Context: PromptContext{Messages: []PromptMessage{
+			{Role: PromptRoleUser, Blocks: []PromptBlock{
+				{Type: PromptBlockText, Text: "look"},
+				{Type: PromptBlockImage, ImageURL: "data:image/png;base64,aGVsbG8="},
+				{Type: PromptBlockFile, FileURL: "data:application/pdf;base64,JVBERi0=", Filename: "doc.pdf"},
+			}},
+			{Role: PromptRoleUser, Blocks: []PromptBlock{{Type: PromptBlockImage, ImageURL: "https://example.com/cat.jpg"}}},
+			{Role: PromptRoleAssistant, Blocks: []PromptBlock{
+				{Type: PromptBlockThinking, Text: "unsigned"},
+				{Type: PromptBlockToolCall, ToolCallID: "toolu_x", ToolName: "read", ToolCallArguments: "not json"},
+			}},
+			{Role: PromptRoleToolResult, ToolCallID: "toolu_x", IsError: true},
+		}},
+	}, false)
+
+	if req.Model != "claude-sonnet-4-5" {
+		t.Fatalf("unexpected model: %q", req.Model)
+	}
+	if len(req.Messages) != 3 {
+		t.Fatalf("expected consecutive user turns to merge into 3 messages, got %d", len(req.Messages))
+	}
+	user := req.Messages[0].Content
+	if len(user) != 4 {
+		t.Fatalf("unexpected user blocks: %+v", user)
+	}
+	if user[1].Type != "image" || user[1].Source.Type != "base64" || user[1].Source.MediaType != "image/png" || user[1].Source.Data != "aGVsbG8=" {
+		t.Fatalf("unexpected image block: %+v", user[1].Source)
+	}
+	if user[2].Type != "document" || user[2].Source.MediaType != "application/pdf" || user[2].Title != "doc.pdf" {
+		t.Fatalf("unexpected document block: %+v", user[2])
+	}
+	if user[3].Type != "image" || user[3].Source.Type != "url" {
+		t.Fatalf("unexpected url image block: %+v", user[3])
+	}
+	assistant := req.Messages[1].Content
+	if len(assistant) != 1 || assistant[0].Type != "tool_use" || string(assistant[0].Input) != "{}" {
+		t.Fatalf("unexpected assistant blocks: %+v", assistant)
+	}
+	result := req.Messages[2].Content
+	if len(result) != 1 || result[0].Type != "tool_result" || !result[0].IsError || result[0].ToolUseID != "toolu_x" {
+		t.Fatalf("unexpected tool result: %+v", result)
+	}
+	if result[0].CacheControl == nil {
+		t.Fatalf("expected cache breakpoint on the last user block")
+	}
+	// The tool-use continuation has no signed thinking block, so thinking must be off.
+	if req.Thinking != nil {
+		t.Fatalf("expected thinking disabled without a replayable thinking block")
+	}
+	if req.Temperature == nil || *req.Temperature != 0.3 {
+		t.Fatalf("expected temperature to be forwarded when thinking is disabled")
+	}
+}
+
+func TestAnthropicProviderErrorsAndListModels(t *testing.T) {
+	server := newAnthropicTestServer(t, func(w http.ResponseWriter, body anthropicMessagesRequest) {
+		if body.Model != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[{"id":"claude-opus-4-6","display_name":"Claude Opus 4.6"},{"id":"claude-3-5-haiku-20241022","display_name":"Claude Haiku 3.5"}],"has_more":false}`))
+	})
+	defer server.Close()
+
+	provider, err := NewAnthropicProvider("test-key", server.URL, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "anthropic/claude-opus-4-6" || !models[0].SupportsReasoning || !models[0].SupportsPDF {
+		t.Fatalf("unexpected models: %+v", models)
+	}
+	if models[1].SupportsReasoning {
+		t.Fatalf("claude 3.5 should not advertise extended thinking")
+	}
+
+	_, err = provider.GenerateStream(context.Background(), GenerateParams{
+		Model:   "anthropic/claude-opus-4.6",
+		Context: PromptContext{Messages: []PromptMessage{{Role: PromptRoleUser, Blocks: []PromptBlock{{Type: PromptBlockText, Text: "hi"}}}}},
+	})
+	if err == nil || !IsAuthError(err) {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+}
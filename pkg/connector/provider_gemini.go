@@ -0,0 +1,719 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// geminiSkipThoughtSignature is accepted by the API in place of a real thought
+	// signature when replaying function calls whose signature was not retained.
+	geminiSkipThoughtSignature = "skip_thought_signature_validator"
+	// geminiMaxCachedSignatures bounds how many function calls keep their thought signature.
+	geminiMaxCachedSignatures = 512
+)
+
+// geminiThinkingBudgets maps reasoning effort levels to Gemini thinking budgets.
+var geminiThinkingBudgets = map[string]int{
+	"low":    1024,
+	"medium": 8192,
+	"high":   24576,
+}
+
+// GeminiProvider implements AIProvider for the native Gemini generateContent API.
+type GeminiProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	log        zerolog.Logger
+
+	// Gemini attaches opaque thought signatures to function call parts, which must
+	// be sent back with the call on the next turn. They are kept here by call ID.
+	signatureMu    sync.Mutex
+	signatures     map[string]string
+	signatureOrder []string
+}
+
+// NewGeminiProvider creates a provider for the Gemini generateContent API.
+func NewGeminiProvider(apiKey, baseURL string, log zerolog.Logger) (*GeminiProvider, error) {
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == "" {
+		return nil, errors.New("missing Gemini API key")
+	}
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		baseURL = defaultGoogleBaseURL
+	}
+	return &GeminiProvider{
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: &http.Client{},
+		log:        log.With().Str("provider", "gemini").Logger(),
+		signatures: make(map[string]string),
+	}, nil
+}
+
+func (p *GeminiProvider) Name() string {
+	return "gemini"
+}
+
+type geminiBlob struct {
+	MimeType string `json:"mimeType,omitempty"`
+	Data     string `json:"data,omitempty"`
+}
+
+type geminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+type geminiFunctionCall struct {
+	ID   string         `json:"id,omitempty"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	ThoughtSignature string                  `json:"thoughtSignature,omitempty"`
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FileData         *geminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiThinkingConfig struct {
+	IncludeThoughts bool `json:"includeThoughts"`
+	ThinkingBudget  *int `json:"thinkingBudget,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature     *float64              `json:"temperature,omitempty"`
+	MaxOutputTokens int                   `json:"maxOutputTokens,omitempty"`
+	ThinkingConfig  *geminiThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+type geminiGenerateRequest struct {
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent         `json:"contents"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiUsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+}
+
+func (u geminiUsageMetadata) toUsageInfo() UsageInfo {
+	completion := u.CandidatesTokenCount + u.ThoughtsTokenCount
+	total := u.TotalTokenCount
+	if total == 0 {
+		total = u.PromptTokenCount + completion
+	}
+	return UsageInfo{
+		PromptTokens:     u.PromptTokenCount,
+		CompletionTokens: completion,
+		TotalTokens:      total,
+		ReasoningTokens:  u.ThoughtsTokenCount,
+		CachedTokens:     u.CachedContentTokenCount,
+	}
+}
+
+type geminiGenerateResponse struct {
+	ResponseID string `json:"responseId"`
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback,omitempty"`
+	UsageMetadata *geminiUsageMetadata `json:"usageMetadata,omitempty"`
+}
+
+type geminiErrorBody struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+// geminiAPIModelID converts a catalog model ID (e.g. "google/gemini-2.5-pro")
+// into the bare model name used in Gemini endpoint paths.
+func geminiAPIModelID(modelID string) string {
+	modelID = strings.TrimSpace(modelID)
+	for _, prefix := range []string{"google/", "gemini/", "models/"} {
+		modelID = strings.TrimPrefix(modelID, prefix)
+	}
+	return modelID
+}
+
+func mapGeminiFinishReason(reason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	switch reason {
+	case "", "STOP", "FINISH_REASON_UNSPECIFIED":
+		return "stop"
+	case "MAX_TOKENS":
+		return "length"
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return "content_filter"
+	default:
+		return strings.ToLower(reason)
+	}
+}
+
+func (p *GeminiProvider) buildRequest(params GenerateParams) geminiGenerateRequest {
+	req := geminiGenerateRequest{
+		Contents: p.promptMessagesToGemini(params.Context.Messages),
+	}
+
+	system := params.Context.SystemPrompt
+	appendPromptText(&system, params.Context.DeveloperPrompt)
+	if system != "" {
+		req.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: system}}}
+	}
+
+	var decls []geminiFunctionDeclaration
+	seen := make(map[string]struct{}, len(params.Context.Tools))
+	for _, tool := range params.Context.Tools {
+		if _, ok := seen[tool.Name]; ok {
+			continue
+		}
+		seen[tool.Name] = struct{}{}
+		decl := geminiFunctionDeclaration{Name: tool.Name, Description: tool.Description}
+		if tool.Parameters != nil {
+			schema, stripped := sanitizeToolSchemaWithReport(tool.Parameters)
+			logSchemaSanitization(&p.log, tool.Name, stripped)
+			decl.Parameters = geminiSchema(schema)
+		}
+		decls = append(decls, decl)
+	}
+	if len(decls) > 0 {
+		req.Tools = []geminiTool{{FunctionDeclarations: decls}}
+	}
+
+	config := &geminiGenerationConfig{MaxOutputTokens: params.MaxCompletionTokens}
+	if params.Temperature > 0 {
+		temp := params.Temperature
+		config.Temperature = &temp
+	}
+	if budget, ok := geminiThinkingBudgets[params.ReasoningEffort]; ok {
+		config.ThinkingConfig = &geminiThinkingConfig{IncludeThoughts: true, ThinkingBudget: &budget}
+	}
+	if config.Temperature != nil || config.MaxOutputTokens > 0 || config.ThinkingConfig != nil {
+		req.GenerationConfig = config
+	}
+	return req
+}
+
+// geminiSchema adapts an already-sanitized JSON schema to the OpenAPI subset
+// accepted by Gemini function declarations.
+func geminiSchema(schema map[string]any) map[string]any {
+	cleaned, _ := geminiSchemaValue(schema).(map[string]any)
+	// Gemini rejects OBJECT parameters without properties; omit them entirely.
+	if props, ok := cleaned["properties"].(map[string]any); cleaned == nil || (cleaned["type"] == "object" && (!ok || len(props) == 0)) {
+		return nil
+	}
+	return cleaned
+}
+
+func geminiSchemaValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, entry := range v {
+			switch key {
+			case "type":
+				// Multi-type unions aren't supported; keep the first concrete type.
+				if types, ok := entry.([]any); ok {
+					for _, t := range types {
+						if s, ok := t.(string); ok && s != "null" {
+							out[key] = s
+							break
+						}
+					}
+					continue
+				}
+				out[key] = entry
+			case "properties":
+				props, ok := entry.(map[string]any)
+				if !ok {
+					continue
+				}
+				cleanedProps := make(map[string]any, len(props))
+				for name, prop := range props {
+					cleanedProps[name] = geminiSchemaValue(prop)
+				}
+				out[key] = cleanedProps
+			case "const", "allOf", "not", "if", "then", "else":
+				continue
+			default:
+				out[key] = geminiSchemaValue(entry)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, entry := range v {
+			out = append(out, geminiSchemaValue(entry))
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func (p *GeminiProvider) promptMessagesToGemini(messages []PromptMessage) []geminiContent {
+	var out []geminiContent
+	appendParts := func(role string, parts []geminiPart) {
+		if len(parts) == 0 {
+			return
+		}
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Parts = append(out[n-1].Parts, parts...)
+			return
+		}
+		out = append(out, geminiContent{Role: role, Parts: parts})
+	}
+
+	toolNames := make(map[string]string)
+	for _, msg := range messages {
+		switch msg.Role {
+		case PromptRoleUser:
+			appendParts("user", promptBlocksToGemini(msg.Blocks))
+		case PromptRoleAssistant:
+			var parts []geminiPart
+			signed := false
+			for _, block := range msg.Blocks {
+				switch block.Type {
+				case PromptBlockText:
+					if strings.TrimSpace(block.Text) != "" {
+						parts = append(parts, geminiPart{Text: block.Text})
+					}
+				case PromptBlockToolCall:
+					toolNames[block.ToolCallID] = block.ToolName
+					var args map[string]any
+					if err := json.Unmarshal([]byte(block.ToolCallArguments), &args); err != nil {
+						args = map[string]any{}
+					}
+					part := geminiPart{FunctionCall: &geminiFunctionCall{
+						ID:   geminiReplayCallID(block.ToolCallID),
+						Name: block.ToolName,
+						Args: args,
+					}}
+					// Only the first function call of a step carries the signature.
+					if !signed {
+						part.ThoughtSignature = p.signature(block.ToolCallID)
+						signed = true
+					}
+					parts = append(parts, part)
+				}
+			}
+			appendParts("model", parts)
+		case PromptRoleToolResult:
+			name := strings.TrimSpace(msg.ToolName)
+			if name == "" {
+				name = toolNames[msg.ToolCallID]
+			}
+			key := "result"
+			if msg.IsError {
+				key = "error"
+			}
+			appendParts("user", []geminiPart{{FunctionResponse: &geminiFunctionResponse{
+				ID:       geminiReplayCallID(msg.ToolCallID),
+				Name:     name,
+				Response: map[string]any{key: msg.Text()},
+			}}})
+		}
+	}
+	return out
+}
+
+// geminiReplayCallID drops locally generated call IDs, which Gemini never issued.
+func geminiReplayCallID(callID string) string {
+	if strings.HasPrefix(callID, geminiLocalCallIDPrefix) {
+		return ""
+	}
+	return callID
+}
+
+const geminiLocalCallIDPrefix = "gemini_call_"
+
+func promptBlocksToGemini(blocks []PromptBlock) []geminiPart {
+	var out []geminiPart
+	for _, block := range blocks {
+		switch block.Type {
+		case PromptBlockText:
+			if strings.TrimSpace(block.Text) != "" {
+				out = append(out, geminiPart{Text: block.Text})
+			}
+		case PromptBlockImage:
+			if part := geminiMediaPart(block.ImageURL, block.ImageB64, block.MimeType, "image/jpeg"); part != nil {
+				out = append(out, *part)
+			}
+		case PromptBlockFile:
+			if part := geminiMediaPart(block.FileURL, block.FileB64, block.MimeType, "application/pdf"); part != nil {
+				out = append(out, *part)
+			}
+		case PromptBlockAudio:
+			if part := geminiMediaPart("", block.AudioB64, "audio/"+strings.TrimSpace(block.AudioFormat), "audio/mpeg"); part != nil {
+				out = append(out, *part)
+			}
+		case PromptBlockVideo:
+			if part := geminiMediaPart(block.VideoURL, block.VideoB64, block.MimeType, "video/mp4"); part != nil {
+				out = append(out, *part)
+			}
+		}
+	}
+	return out
+}
+
+// geminiMediaPart builds an inline or file-reference part for an attachment.
+func geminiMediaPart(rawURL, b64, mimeType, fallbackMime string) *geminiPart {
+	media, ok := resolvePromptMedia(rawURL, b64, mimeType)
+	if !ok {
+		return nil
+	}
+	if media.MimeType == "" || strings.HasSuffix(media.MimeType, "/") {
+		media.MimeType = fallbackMime
+	}
+	if media.URL != "" {
+		return &geminiPart{FileData: &geminiFileData{MimeType: media.MimeType, FileURI: media.URL}}
+	}
+	return &geminiPart{InlineData: &geminiBlob{MimeType: media.MimeType, Data: media.Data}}
+}
+
+func (p *GeminiProvider) signature(callID string) string {
+	p.signatureMu.Lock()
+	defer p.signatureMu.Unlock()
+	if sig := p.signatures[callID]; sig != "" {
+		return sig
+	}
+	return geminiSkipThoughtSignature
+}
+
+func (p *GeminiProvider) rememberSignature(callID, signature string) {
+	if callID == "" || signature == "" {
+		return
+	}
+	p.signatureMu.Lock()
+	defer p.signatureMu.Unlock()
+	if _, exists := p.signatures[callID]; !exists {
+		p.signatureOrder = append(p.signatureOrder, callID)
+	}
+	p.signatures[callID] = signature
+	for len(p.signatureOrder) > geminiMaxCachedSignatures {
+		delete(p.signatures, p.signatureOrder[0])
+		p.signatureOrder = p.signatureOrder[1:]
+	}
+}
+
+// toolCallFromPart converts a function call part, remembering its thought signature.
+func (p *GeminiProvider) toolCallFromPart(part geminiPart) ToolCallResult {
+	call := part.FunctionCall
+	callID := strings.TrimSpace(call.ID)
+	if callID == "" {
+		callID = geminiLocalCallIDPrefix + NewCallID()
+	}
+	args := "{}"
+	if len(call.Args) > 0 {
+		if encoded, err := json.Marshal(call.Args); err == nil {
+			args = string(encoded)
+		}
+	}
+	p.rememberSignature(callID, part.ThoughtSignature)
+	return ToolCallResult{ID: callID, Name: call.Name, Arguments: args}
+}
+
+func (p *GeminiProvider) newRequest(ctx context.Context, method, path string, body any) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode Gemini request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Goog-Api-Key", p.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (p *GeminiProvider) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	p.log.Debug().
+		Str("request_path", req.URL.Path).
+		Int("status_code", resp.StatusCode).
+		Int64("duration_ms", time.Since(start).Milliseconds()).
+		Msg("Provider HTTP response")
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, parseGeminiError(resp)
+	}
+	return resp, nil
+}
+
+func parseGeminiError(resp *http.Response) error {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var body geminiErrorBody
+	if err := json.Unmarshal(raw, &body); err == nil && body.Error.Message != "" {
+		return fmt.Errorf("gemini API error: %s (%s): %s", resp.Status, body.Error.Status, body.Error.Message)
+	}
+	return fmt.Errorf("gemini API error: %s: %s", resp.Status, strings.TrimSpace(string(raw)))
+}
+
+// GenerateStream generates a streaming response using streamGenerateContent.
+func (p *GeminiProvider) GenerateStream(ctx context.Context, params GenerateParams) (<-chan StreamEvent, error) {
+	path := "/models/" + url.PathEscape(geminiAPIModelID(params.Model)) + ":streamGenerateContent?alt=sse"
+	req, err := p.newRequest(ctx, http.MethodPost, path, p.buildRequest(params))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan StreamEvent, 100)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		p.consumeStream(resp.Body, events)
+	}()
+	return events, nil
+}
+
+func (p *GeminiProvider) consumeStream(body io.Reader, events chan<- StreamEvent) {
+	var (
+		responseID   string
+		finishReason string
+		usage        *geminiUsageMetadata
+		hasToolCalls bool
+		blocked      string
+	)
+	err := readServerSentEvents(body, func(data []byte) bool {
+		var chunk geminiGenerateResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			p.log.Debug().Err(err).Msg("Skipping malformed Gemini stream chunk")
+			return true
+		}
+		if chunk.ResponseID != "" {
+			responseID = chunk.ResponseID
+		}
+		if chunk.UsageMetadata != nil {
+			usage = chunk.UsageMetadata
+		}
+		if chunk.PromptFeedback != nil && chunk.PromptFeedback.BlockReason != "" {
+			blocked = chunk.PromptFeedback.BlockReason
+		}
+		if len(chunk.Candidates) == 0 {
+			return true
+		}
+		candidate := chunk.Candidates[0]
+		if candidate.FinishReason != "" {
+			finishReason = candidate.FinishReason
+		}
+		for _, part := range candidate.Content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				call := p.toolCallFromPart(part)
+				hasToolCalls = true
+				events <- StreamEvent{Type: StreamEventToolCall, ToolCall: &call}
+			case part.Thought:
+				if part.Text != "" {
+					events <- StreamEvent{Type: StreamEventReasoning, ReasoningDelta: part.Text}
+				}
+			case part.Text != "":
+				events <- StreamEvent{Type: StreamEventDelta, Delta: part.Text}
+			}
+		}
+		return true
+	})
+	if err != nil {
+		events <- StreamEvent{Type: StreamEventError, Error: err}
+		return
+	}
+	if blocked != "" && finishReason == "" && !hasToolCalls {
+		events <- StreamEvent{Type: StreamEventError, Error: fmt.Errorf("gemini API error: prompt blocked (%s)", blocked)}
+		return
+	}
+	complete := StreamEvent{
+		Type:         StreamEventComplete,
+		FinishReason: mapGeminiFinishReason(finishReason, hasToolCalls),
+		ResponseID:   responseID,
+	}
+	if usage != nil {
+		info := usage.toUsageInfo()
+		complete.Usage = &info
+	}
+	events <- complete
+}
+
+// Generate performs a non-streaming generation using generateContent.
+func (p *GeminiProvider) Generate(ctx context.Context, params GenerateParams) (*GenerateResponse, error) {
+	path := "/models/" + url.PathEscape(geminiAPIModelID(params.Model)) + ":generateContent"
+	req, err := p.newRequest(ctx, http.MethodPost, path, p.buildRequest(params))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Gemini generation failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result geminiGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode Gemini response: %w", err)
+	}
+	if len(result.Candidates) == 0 {
+		if result.PromptFeedback != nil && result.PromptFeedback.BlockReason != "" {
+			return nil, fmt.Errorf("gemini API error: prompt blocked (%s)", result.PromptFeedback.BlockReason)
+		}
+		return nil, errors.New("gemini API returned no candidates")
+	}
+
+	candidate := result.Candidates[0]
+	var content strings.Builder
+	var toolCalls []ToolCallResult
+	for _, part := range candidate.Content.Parts {
+		switch {
+		case part.FunctionCall != nil:
+			toolCalls = append(toolCalls, p.toolCallFromPart(part))
+		case part.Thought:
+		default:
+			content.WriteString(part.Text)
+		}
+	}
+
+	out := &GenerateResponse{
+		Content:      content.String(),
+		FinishReason: mapGeminiFinishReason(candidate.FinishReason, len(toolCalls) > 0),
+		ResponseID:   result.ResponseID,
+		ToolCalls:    toolCalls,
+	}
+	if result.UsageMetadata != nil {
+		out.Usage = result.UsageMetadata.toUsageInfo()
+	}
+	return out, nil
+}
+
+type geminiModelsPage struct {
+	Models []struct {
+		Name                       string   `json:"name"`
+		DisplayName                string   `json:"displayName"`
+		Description                string   `json:"description"`
+		InputTokenLimit            int      `json:"inputTokenLimit"`
+		OutputTokenLimit           int      `json:"outputTokenLimit"`
+		SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+		Thinking                   bool     `json:"thinking"`
+	} `json:"models"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// ListModels returns the chat-capable models available to this API key.
+func (p *GeminiProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	var models []ModelInfo
+	pageToken := ""
+	for {
+		path := "/models?pageSize=1000"
+		if pageToken != "" {
+			path += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+		req, err := p.newRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.do(req)
+		if err != nil {
+			return nil, err
+		}
+		var page geminiModelsPage
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Gemini model list: %w", err)
+		}
+		for _, model := range page.Models {
+			if !slices.Contains(model.SupportedGenerationMethods, "generateContent") {
+				continue
+			}
+			id := strings.TrimPrefix(model.Name, "models/")
+			name := model.DisplayName
+			if name == "" {
+				name = id
+			}
+			isGemini := strings.HasPrefix(id, "gemini-")
+			models = append(models, ModelInfo{
+				ID:                  "google/" + id,
+				Name:                name,
+				Provider:            "google",
+				Description:         model.Description,
+				SupportsVision:      isGemini,
+				SupportsToolCalling: isGemini,
+				SupportsPDF:         isGemini,
+				SupportsAudio:       isGemini,
+				SupportsVideo:       isGemini,
+				SupportsReasoning:   model.Thinking,
+				ContextWindow:       model.InputTokenLimit,
+				MaxOutputTokens:     model.OutputTokenLimit,
+			})
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+	return models, nil
+}
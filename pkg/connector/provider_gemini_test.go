@@ -0,0 +1,239 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func newGeminiTestServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, body geminiGenerateRequest)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Goog-Api-Key"); got != "test-key" {
+			t.Errorf("unexpected api key header: %q", got)
+		}
+		var body geminiGenerateRequest
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+		}
+		handler(w, r, body)
+	}))
+}
+
+func TestGeminiProviderGenerateStreamThoughtsTextAndFunctionCalls(t *testing.T) {
+	var path string
+	server := newGeminiTestServer(t, func(w http.ResponseWriter, r *http.Request, _ geminiGenerateRequest) {
+		path = r.URL.Path + "?" + r.URL.RawQuery
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Thinking it over.","thought":true}]}}],"responseId":"resp_1"}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}]}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"web_search","args":{"query":"go"}},"thoughtSignature":"sig-1"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":5,"thoughtsTokenCount":7,"cachedContentTokenCount":4,"totalTokenCount":24}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+	})
+	defer server.Close()
+
+	provider, err := NewGeminiProvider("test-key", server.URL, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	events, err := provider.GenerateStream(context.Background(), GenerateParams{
+		Model:   "google/gemini-2.5-pro",
+		Context: PromptContext{Messages: []PromptMessage{{Role: PromptRoleUser, Blocks: []PromptBlock{{Type: PromptBlockText, Text: "hi"}}}}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var reasoning, text strings.Builder
+	var calls []ToolCallResult
+	var complete *StreamEvent
+	for evt := range events {
+		switch evt.Type {
+		case StreamEventReasoning:
+			reasoning.WriteString(evt.ReasoningDelta)
+		case StreamEventDelta:
+			text.WriteString(evt.Delta)
+		case StreamEventToolCall:
+			calls = append(calls, *evt.ToolCall)
+		case StreamEventComplete:
+			evt := evt
+			complete = &evt
+		case StreamEventError:
+			t.Fatalf("unexpected stream error: %v", evt.Error)
+		}
+	}
+
+	if path != "/models/gemini-2.5-pro:streamGenerateContent?alt=sse" {
+		t.Fatalf("unexpected request path: %s", path)
+	}
+	if reasoning.String() != "Thinking it over." || text.String() != "Hello" {
+		t.Fatalf("unexpected stream content: reasoning=%q text=%q", reasoning.String(), text.String())
+	}
+	if len(calls) != 1 || calls[0].Name != "web_search" || calls[0].Arguments != `{"query":"go"}` {
+		t.Fatalf("unexpected tool calls: %+v", calls)
+	}
+	if !strings.HasPrefix(calls[0].ID, geminiLocalCallIDPrefix) {
+		t.Fatalf("expected a local call ID, got %q", calls[0].ID)
+	}
+	if got := provider.signature(calls[0].ID); got != "sig-1" {
+		t.Fatalf("expected thought signature to be retained, got %q", got)
+	}
+	if complete == nil || complete.FinishReason != "tool_calls" || complete.ResponseID != "resp_1" {
+		t.Fatalf("unexpected completion event: %+v", complete)
+	}
+	want := UsageInfo{PromptTokens: 12, CompletionTokens: 12, TotalTokens: 24, ReasoningTokens: 7, CachedTokens: 4}
+	if complete.Usage == nil || *complete.Usage != want {
+		t.Fatalf("unexpected usage: %+v", complete.Usage)
+	}
+}
+
+func TestGeminiProviderBuildRequest(t *testing.T) {
+	provider, err := NewGeminiProvider("test-key", "", zerolog.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	provider.rememberSignature("call_1", "sig-1")
+
+	req := provider.buildRequest(GenerateParams{
+		Temperature:     0.5,
+		ReasoningEffort: "medium",
+		Context: PromptContext{
+			SystemPrompt:    "Be brief.",
+			DeveloperPrompt: "Use tools.",
+			Messages: []PromptMessage{
+				{Role: PromptRoleUser, Blocks: []PromptBlock{
+					{Type: PromptBlockText, Text: "What is this?"},
+					{Type: PromptBlockImage, ImageB64: "aGVsbG8=", MimeType: "image/png"},
+				}},
+				{Role: PromptRoleAssistant, Blocks: []PromptBlock{
+					{Type: PromptBlockToolCall, ToolCallID: "call_1", ToolName: "lookup", ToolCallArguments: `{"q":"x"}`},
+					{Type: PromptBlockToolCall, ToolCallID: geminiLocalCallIDPrefix + "2", ToolName: "lookup", ToolCallArguments: `{"q":"y"}`},
+				}},
+				{Role: PromptRoleToolResult, ToolCallID: "call_1", Blocks: []PromptBlock{{Type: PromptBlockText, Text: "found"}}},
+				{Role: PromptRoleToolResult, ToolCallID: geminiLocalCallIDPrefix + "2", IsError: true, Blocks: []PromptBlock{{Type: PromptBlockText, Text: "failed"}}},
+			},
+			Tools: []ToolDefinition{
+				{Name: "lookup", Description: "Look something up", Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"q":     map[string]any{"type": []any{"string", "null"}},
+						"limit": map[string]any{"type": "integer", "const": 3},
+					},
+				}},
+				{Name: "lookup"},
+				{Name: "noop", Parameters: map[string]any{"type": "object", "properties": map[string]any{}}},
+			},
+		},
+	})
+
+	if req.SystemInstruction == nil || req.SystemInstruction.Parts[0].Text != "Be brief.\n\nUse tools." {
+		t.Fatalf("unexpected system instruction: %+v", req.SystemInstruction)
+	}
+	if len(req.Contents) != 3 {
+		t.Fatalf("expected user, model and merged tool-result contents, got %d", len(req.Contents))
+	}
+	user := req.Contents[0]
+	if user.Role != "user" || len(user.Parts) != 2 || user.Parts[1].InlineData == nil || user.Parts[1].InlineData.MimeType != "image/png" {
+		t.Fatalf("unexpected user content: %+v", user)
+	}
+	model := req.Contents[1]
+	if model.Role != "model" || len(model.Parts) != 2 {
+		t.Fatalf("unexpected model content: %+v", model)
+	}
+	if model.Parts[0].ThoughtSignature != "sig-1" || model.Parts[1].ThoughtSignature != "" {
+		t.Fatalf("expected only the first call to carry a signature: %+v", model.Parts)
+	}
+	if model.Parts[0].FunctionCall.ID != "call_1" || model.Parts[1].FunctionCall.ID != "" {
+		t.Fatalf("unexpected replayed call IDs: %+v", model.Parts)
+	}
+	results := req.Contents[2]
+	if results.Role != "user" || len(results.Parts) != 2 {
+		t.Fatalf("unexpected tool results: %+v", results)
+	}
+	if resp := results.Parts[0].FunctionResponse; resp.Name != "lookup" || resp.Response["result"] != "found" {
+		t.Fatalf("unexpected function response: %+v", resp)
+	}
+	if resp := results.Parts[1].FunctionResponse; resp.Response["error"] != "failed" {
+		t.Fatalf("unexpected error function response: %+v", resp)
+	}
+
+	if len(req.Tools) != 1 || len(req.Tools[0].FunctionDeclarations) != 2 {
+		t.Fatalf("expected deduplicated function declarations, got %+v", req.Tools)
+	}
+	props := req.Tools[0].FunctionDeclarations[0].Parameters["properties"].(map[string]any)
+	if got := props["q"].(map[string]any)["type"]; got != "string" {
+		t.Fatalf("expected type union to collapse to string, got %v", got)
+	}
+	if _, ok := props["limit"].(map[string]any)["const"]; ok {
+		t.Fatal("expected const to be stripped from the schema")
+	}
+	if req.Tools[0].FunctionDeclarations[1].Parameters != nil {
+		t.Fatal("expected empty object parameters to be omitted")
+	}
+
+	config := req.GenerationConfig
+	if config == nil || config.Temperature == nil || *config.Temperature != 0.5 {
+		t.Fatalf("unexpected generation config: %+v", config)
+	}
+	if config.ThinkingConfig == nil || !config.ThinkingConfig.IncludeThoughts || *config.ThinkingConfig.ThinkingBudget != 8192 {
+		t.Fatalf("unexpected thinking config: %+v", config.ThinkingConfig)
+	}
+}
+
+func TestGeminiProviderErrorsAndListModels(t *testing.T) {
+	server := newGeminiTestServer(t, func(w http.ResponseWriter, r *http.Request, _ geminiGenerateRequest) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`))
+			return
+		}
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"models":[
+				{"name":"models/gemini-2.5-flash","displayName":"Gemini 2.5 Flash","inputTokenLimit":1048576,"outputTokenLimit":65536,"supportedGenerationMethods":["generateContent","countTokens"],"thinking":true},
+				{"name":"models/text-embedding-004","supportedGenerationMethods":["embedContent"]}
+			],"nextPageToken":"next"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"models":[{"name":"models/gemma-3-27b-it","supportedGenerationMethods":["generateContent"]}]}`))
+	})
+	defer server.Close()
+
+	provider, err := NewGeminiProvider("test-key", server.URL, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = provider.Generate(context.Background(), GenerateParams{Model: "google/gemini-2.5-flash"})
+	if err == nil || !IsAuthError(err) {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("expected 2 chat models, got %+v", models)
+	}
+	flash := models[0]
+	if flash.ID != "google/gemini-2.5-flash" || flash.Provider != "google" || !flash.SupportsReasoning || !flash.SupportsToolCalling {
+		t.Fatalf("unexpected model info: %+v", flash)
+	}
+	if flash.ContextWindow != 1048576 || flash.MaxOutputTokens != 65536 {
+		t.Fatalf("unexpected token limits: %+v", flash)
+	}
+	if models[1].ID != "google/gemma-3-27b-it" || models[1].SupportsToolCalling {
+		t.Fatalf("unexpected non-Gemini model info: %+v", models[1])
+	}
+}
@@ -0,0 +1,299 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// localPlaceholderAPIKey is sent when a local server needs no credentials;
+	// the OpenAI SDK always sets an Authorization header.
+	localPlaceholderAPIKey = "local"
+	// localMaxShowRequests bounds how many Ollama /api/show lookups a discovery makes.
+	localMaxShowRequests = 64
+)
+
+// LocalProvider implements AIProvider for self-hosted OpenAI-compatible servers
+// (Ollama, llama.cpp server, vLLM). Generation goes through the embedded OpenAI
+// SDK client using Chat Completions; model discovery reads the server's own
+// model listing instead of the bundled catalog.
+type LocalProvider struct {
+	*OpenAIProvider
+
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	log        zerolog.Logger
+}
+
+// NewLocalProvider creates a provider for a local OpenAI-compatible server.
+// baseURL is the OpenAI-compatible root, e.g. "http://127.0.0.1:11434/v1".
+func NewLocalProvider(apiKey, baseURL string, log zerolog.Logger) (*LocalProvider, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil, errors.New("missing local provider base URL")
+	}
+	apiKey = strings.TrimSpace(apiKey)
+	if apiKey == localPlaceholderAPIKey {
+		apiKey = ""
+	}
+	sdkKey := apiKey
+	if sdkKey == "" {
+		sdkKey = localPlaceholderAPIKey
+	}
+	inner, err := NewOpenAIProviderWithBaseURL(sdkKey, baseURL, log)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalProvider{
+		OpenAIProvider: inner,
+		apiKey:         apiKey,
+		baseURL:        baseURL,
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		log:            log.With().Str("provider", "local").Logger(),
+	}, nil
+}
+
+func (p *LocalProvider) Name() string {
+	return "local"
+}
+
+// serverRoot strips the OpenAI-compatible "/v1" suffix to reach native endpoints.
+func (p *LocalProvider) serverRoot() string {
+	return strings.TrimSuffix(p.baseURL, "/v1")
+}
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name    string `json:"name"`
+		Model   string `json:"model"`
+		Details struct {
+			Family   string   `json:"family"`
+			Families []string `json:"families"`
+		} `json:"details"`
+	} `json:"models"`
+}
+
+type ollamaShowResponse struct {
+	Capabilities []string       `json:"capabilities"`
+	ModelInfo    map[string]any `json:"model_info"`
+}
+
+type openAICompatModelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+		// vLLM reports the served context length here.
+		MaxModelLen int `json:"max_model_len"`
+		// llama.cpp server reports model metadata here.
+		Meta *struct {
+			NCtxTrain int `json:"n_ctx_train"`
+		} `json:"meta"`
+	} `json:"data"`
+	// Newer llama.cpp builds also return an Ollama-style model list with capabilities.
+	Models []struct {
+		Model        string   `json:"model"`
+		Capabilities []string `json:"capabilities"`
+	} `json:"models"`
+}
+
+// ListModels discovers the models served locally. Ollama's /api/tags is tried
+// first (with /api/show for capabilities); other servers fall back to /v1/models.
+func (p *LocalProvider) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	models, ollamaErr := p.listOllamaModels(ctx)
+	if ollamaErr == nil {
+		return models, nil
+	}
+	models, err := p.listOpenAICompatModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("local model discovery failed: %w (ollama: %v)", err, ollamaErr)
+	}
+	return models, nil
+}
+
+func (p *LocalProvider) listOllamaModels(ctx context.Context) ([]ModelInfo, error) {
+	var tags ollamaTagsResponse
+	if err := p.getJSON(ctx, http.MethodGet, p.serverRoot()+"/api/tags", nil, &tags); err != nil {
+		return nil, err
+	}
+	models := make([]ModelInfo, 0, len(tags.Models))
+	for i, tag := range tags.Models {
+		name := strings.TrimSpace(tag.Name)
+		if name == "" {
+			name = strings.TrimSpace(tag.Model)
+		}
+		if name == "" {
+			continue
+		}
+		info := localModelInfo(name)
+		// Older Ollama builds don't report capabilities; assume tool support and
+		// infer vision from projector families until /api/show says otherwise.
+		info.SupportsToolCalling = true
+		info.SupportsVision = slices.ContainsFunc(tag.Details.Families, func(family string) bool {
+			return family == "clip" || family == "mllama"
+		})
+		if i < localMaxShowRequests {
+			var show ollamaShowResponse
+			err := p.getJSON(ctx, http.MethodPost, p.serverRoot()+"/api/show", map[string]string{"model": name}, &show)
+			if err != nil {
+				p.log.Debug().Err(err).Str("model", name).Msg("Failed to read Ollama model details")
+			} else {
+				applyOllamaShow(&info, show)
+			}
+		}
+		models = append(models, info)
+	}
+	return models, nil
+}
+
+// applyOllamaShow fills capabilities and context length from an /api/show response.
+func applyOllamaShow(info *ModelInfo, show ollamaShowResponse) {
+	if len(show.Capabilities) > 0 {
+		info.SupportsToolCalling = false
+		info.SupportsVision = false
+		applyLocalCapabilities(info, show.Capabilities)
+	}
+	for key, value := range show.ModelInfo {
+		if !strings.HasSuffix(key, ".context_length") {
+			continue
+		}
+		if n, ok := value.(float64); ok && n > 0 {
+			info.ContextWindow = int(n)
+		}
+	}
+}
+
+func (p *LocalProvider) listOpenAICompatModels(ctx context.Context) ([]ModelInfo, error) {
+	var resp openAICompatModelsResponse
+	if err := p.getJSON(ctx, http.MethodGet, p.baseURL+"/models", nil, &resp); err != nil {
+		return nil, err
+	}
+	capabilities := make(map[string][]string, len(resp.Models))
+	for _, model := range resp.Models {
+		capabilities[model.Model] = model.Capabilities
+	}
+	models := make([]ModelInfo, 0, len(resp.Data))
+	for _, model := range resp.Data {
+		id := strings.TrimSpace(model.ID)
+		if id == "" {
+			continue
+		}
+		info := localModelInfo(id)
+		// OpenAI-compatible servers don't advertise tool support; assume it.
+		info.SupportsToolCalling = true
+		if caps := capabilities[id]; len(caps) > 0 {
+			applyLocalCapabilities(&info, caps)
+		}
+		switch {
+		case model.MaxModelLen > 0:
+			info.ContextWindow = model.MaxModelLen
+		case model.Meta != nil && model.Meta.NCtxTrain > 0:
+			info.ContextWindow = model.Meta.NCtxTrain
+		}
+		models = append(models, info)
+	}
+	return models, nil
+}
+
+func localModelInfo(name string) ModelInfo {
+	return ModelInfo{
+		ID:       AddModelPrefix(BackendLocal, name),
+		Name:     name,
+		Provider: ProviderLocal,
+		API:      string(ModelAPIChatCompletions),
+	}
+}
+
+// applyLocalCapabilities maps Ollama/llama.cpp capability names onto ModelInfo.
+func applyLocalCapabilities(info *ModelInfo, capabilities []string) {
+	for _, capability := range capabilities {
+		switch strings.ToLower(strings.TrimSpace(capability)) {
+		case "vision", "multimodal":
+			info.SupportsVision = true
+		case "tools":
+			info.SupportsToolCalling = true
+		case "thinking":
+			info.SupportsReasoning = true
+		case "audio":
+			info.SupportsAudio = true
+		}
+	}
+}
+
+func (p *LocalProvider) getJSON(ctx context.Context, method, url string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, resp.Status, strings.TrimSpace(string(raw)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", req.URL.Path, err)
+	}
+	return nil
+}
+
+// discoverLocalModels merges the models served by a local provider into the
+// catalog-derived list. Explicitly configured catalog entries win; if the server
+// can't be reached, previously discovered models are kept.
+func (oc *AIClient) discoverLocalModels(ctx context.Context, catalog []ModelInfo) []ModelInfo {
+	local, ok := oc.provider.(*LocalProvider)
+	if !ok {
+		return catalog
+	}
+	discoverCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	discovered, err := local.ListModels(discoverCtx)
+	if err != nil {
+		oc.loggerForContext(ctx).Warn().Err(err).Msg("Failed to discover local models")
+		if cache := loginMetadata(oc.UserLogin).ModelCache; cache != nil {
+			for _, model := range cache.Models {
+				if model.Provider == ProviderLocal {
+					discovered = append(discovered, model)
+				}
+			}
+		}
+	}
+	seen := make(map[string]struct{}, len(catalog))
+	for _, model := range catalog {
+		seen[model.ID] = struct{}{}
+	}
+	for _, model := range discovered {
+		if _, exists := seen[model.ID]; exists {
+			continue
+		}
+		seen[model.ID] = struct{}{}
+		catalog = append(catalog, model)
+	}
+	return catalog
+}
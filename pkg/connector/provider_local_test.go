@@ -0,0 +1,109 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLocalProviderListModelsOllama(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[
+				{"name":"llama3.2-vision:11b","details":{"families":["mllama"]}},
+				{"name":"qwen3:8b","details":{"families":["qwen3"]}},
+				{"name":"legacy:7b","details":{"families":["llama"]}}
+			]}`))
+		case "/api/show":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			switch body["model"] {
+			case "llama3.2-vision:11b":
+				_, _ = w.Write([]byte(`{"capabilities":["completion","vision"],"model_info":{"general.architecture":"mllama","mllama.context_length":131072}}`))
+			case "qwen3:8b":
+				_, _ = w.Write([]byte(`{"capabilities":["completion","tools","thinking"],"model_info":{"qwen3.context_length":40960}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewLocalProvider("", server.URL+"/v1", zerolog.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(models) != 3 {
+		t.Fatalf("expected 3 models, got %+v", models)
+	}
+
+	vision := models[0]
+	if vision.ID != "local/llama3.2-vision:11b" || vision.Provider != ProviderLocal || vision.API != string(ModelAPIChatCompletions) {
+		t.Fatalf("unexpected model identity: %+v", vision)
+	}
+	if !vision.SupportsVision || vision.SupportsToolCalling || vision.ContextWindow != 131072 {
+		t.Fatalf("unexpected vision model capabilities: %+v", vision)
+	}
+	qwen := models[1]
+	if qwen.SupportsVision || !qwen.SupportsToolCalling || !qwen.SupportsReasoning || qwen.ContextWindow != 40960 {
+		t.Fatalf("unexpected tool model capabilities: %+v", qwen)
+	}
+	legacy := models[2]
+	if !legacy.SupportsToolCalling || legacy.SupportsVision {
+		t.Fatalf("expected defaults when /api/show is unavailable: %+v", legacy)
+	}
+}
+
+func TestLocalProviderListModelsOpenAICompatible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected authorization header: %q", got)
+		}
+		switch r.URL.Path {
+		case "/v1/models":
+			_, _ = w.Write([]byte(`{
+				"data":[
+					{"id":"Qwen/Qwen2.5-7B-Instruct","max_model_len":32768},
+					{"id":"gemma-3-4b","meta":{"n_ctx_train":131072}}
+				],
+				"models":[{"model":"gemma-3-4b","capabilities":["completion","multimodal"]}]
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewLocalProvider("secret", server.URL+"/v1/", zerolog.Nop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	models, err := provider.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(models) != 2 {
+		t.Fatalf("expected 2 models, got %+v", models)
+	}
+	if models[0].ID != "local/Qwen/Qwen2.5-7B-Instruct" || models[0].ContextWindow != 32768 || !models[0].SupportsToolCalling {
+		t.Fatalf("unexpected vLLM model info: %+v", models[0])
+	}
+	if models[1].ContextWindow != 131072 || !models[1].SupportsVision {
+		t.Fatalf("unexpected llama.cpp model info: %+v", models[1])
+	}
+	if _, actual := ParseModelPrefix(models[0].ID); actual != "Qwen/Qwen2.5-7B-Instruct" {
+		t.Fatalf("expected API model name without local prefix, got %q", actual)
+	}
+}
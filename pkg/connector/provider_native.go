@@ -0,0 +1,83 @@
+package connector
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// promptMedia is an attachment payload resolved for native provider requests:
+// either inline base64 data or a remote HTTP(S) URL.
+type promptMedia struct {
+	MimeType string
+	Data     string
+	URL      string
+}
+
+// resolvePromptMedia resolves a data URL, an HTTP(S) URL, or a bare base64
+// payload from a prompt block into a promptMedia.
+func resolvePromptMedia(rawURL, b64, mimeType string) (promptMedia, bool) {
+	rawURL = strings.TrimSpace(rawURL)
+	b64 = strings.TrimSpace(b64)
+	mimeType = strings.TrimSpace(mimeType)
+	if b64 == "" && strings.HasPrefix(rawURL, "data:") {
+		b64 = rawURL
+	}
+	if b64 != "" {
+		if strings.HasPrefix(b64, "data:") {
+			header, data, ok := strings.Cut(strings.TrimPrefix(b64, "data:"), ",")
+			if !ok {
+				return promptMedia{}, false
+			}
+			if mediaType, _, _ := strings.Cut(header, ";"); mediaType != "" {
+				mimeType = mediaType
+			}
+			b64 = data
+		}
+		return promptMedia{MimeType: mimeType, Data: b64}, true
+	}
+	if strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") {
+		return promptMedia{MimeType: mimeType, URL: rawURL}, true
+	}
+	return promptMedia{}, false
+}
+
+// readServerSentEvents calls handle with the data payload of each SSE event until
+// the stream ends or handle returns false.
+func readServerSentEvents(body io.Reader, handle func(data []byte) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var data bytes.Buffer
+	flush := func() bool {
+		if data.Len() == 0 {
+			return true
+		}
+		payload := bytes.Clone(data.Bytes())
+		data.Reset()
+		if bytes.Equal(payload, []byte("[DONE]")) {
+			return false
+		}
+		return handle(payload)
+	}
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			if !flush() {
+				return nil
+			}
+			continue
+		}
+		if value, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.Write(bytes.TrimPrefix(value, []byte(" ")))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	flush()
+	return nil
+}
@@ -60,8 +60,21 @@ func (oc *OpenAIConnector) initProvisioning() {
 	r.HandleFunc("DELETE /v1/mcp/servers/{name}", api.handleDeleteMCPServer)
 	r.HandleFunc("POST /v1/mcp/servers/{name}/connect", api.handleConnectMCPServer)
 	r.HandleFunc("POST /v1/mcp/servers/{name}/disconnect", api.handleDisconnectMCPServer)
+	r.HandleFunc("GET /v1/tool-approvals/rules", api.handleListToolArgumentRules)
+	r.HandleFunc("POST /v1/tool-approvals/rules", api.handleCreateToolArgumentRule)
+	r.HandleFunc("DELETE /v1/tool-approvals/rules/{rule_id}", api.handleDeleteToolArgumentRule)
+	r.HandleFunc("GET /v1/webhooks", api.handleListWebhooks)
+	r.HandleFunc("POST /v1/webhooks", api.handleCreateWebhook)
+	r.HandleFunc("GET /v1/webhooks/{hook_id}", api.handleGetWebhook)
+	r.HandleFunc("PUT /v1/webhooks/{hook_id}", api.handleUpdateWebhook)
+	r.HandleFunc("DELETE /v1/webhooks/{hook_id}", api.handleDeleteWebhook)
+	r.HandleFunc("GET /v1/webhooks/{hook_id}/deliveries", api.handleListWebhookDeliveries)
+	r.HandleFunc("POST /v1/webhooks/{hook_id}/deliveries/{delivery_id}/replay", api.handleReplayWebhookDelivery)
+	if !registerPublicHandler(oc.br, "POST "+webhookReceivePath, oc.handleReceiveWebhook) {
+		oc.br.Log.Warn().Msg("Matrix connector has no appservice router, webhook deliveries can't be received")
+	}
 
-	oc.br.Log.Info().Msg("Registered provisioning API endpoints for AI profile, agents, and MCP")
+	oc.br.Log.Info().Msg("Registered provisioning API endpoints for AI profile, agents, MCP, tool approval rules, and webhooks")
 }
 
 // getLogin gets the preferred user login from the request.
@@ -594,7 +607,7 @@ func (api *ProvisioningAPI) handleCreateMCPServer(w http.ResponseWriter, r *http
 		mautrix.MUnknown.WithMessage("Couldn't save MCP server: %v.", err).Write(w)
 		return
 	}
-	client.invalidateMCPToolCache()
+	client.invalidateMCPCaches()
 	exhttp.WriteJSONResponse(w, http.StatusCreated, mcpServerResponseFromNamed(namedMCPServer{Name: name, Config: cfg, Source: "login"}))
 }
 
@@ -629,7 +642,7 @@ func (api *ProvisioningAPI) handleUpdateMCPServer(w http.ResponseWriter, r *http
 		mautrix.MUnknown.WithMessage("Couldn't save MCP server: %v.", err).Write(w)
 		return
 	}
-	client.invalidateMCPToolCache()
+	client.invalidateMCPCaches()
 	exhttp.WriteJSONResponse(w, http.StatusOK, mcpServerResponseFromNamed(namedMCPServer{Name: resolvedName, Config: cfg, Source: "login"}))
 }
 
@@ -655,7 +668,7 @@ func (api *ProvisioningAPI) handleDeleteMCPServer(w http.ResponseWriter, r *http
 		mautrix.MUnknown.WithMessage("Couldn't remove MCP server: %v.", err).Write(w)
 		return
 	}
-	client.invalidateMCPToolCache()
+	client.invalidateMCPCaches()
 	exhttp.WriteJSONResponse(w, http.StatusOK, map[string]any{"deleted": true})
 }
 
@@ -680,7 +693,7 @@ func connectMCPServer(ctx context.Context, client *AIClient, login *bridgev2.Use
 		if err = login.Save(ctx); err != nil {
 			return namedMCPServer{}, 0, err
 		}
-		client.invalidateMCPToolCache()
+		client.invalidateMCPCaches()
 		return namedMCPServer{Name: target.Name, Config: cfg, Source: "login"}, 0, errors.New("mcp server token is required")
 	}
 	cfg.Connected = true
@@ -691,14 +704,14 @@ func connectMCPServer(ctx context.Context, client *AIClient, login *bridgev2.Use
 		if err = login.Save(ctx); err != nil {
 			return namedMCPServer{}, 0, err
 		}
-		client.invalidateMCPToolCache()
+		client.invalidateMCPCaches()
 		return namedMCPServer{Name: target.Name, Config: cfg, Source: "login"}, 0, connectErr
 	}
 	setLoginMCPServer(loginMetadata(login), target.Name, cfg)
 	if err = login.Save(ctx); err != nil {
 		return namedMCPServer{}, 0, err
 	}
-	client.invalidateMCPToolCache()
+	client.invalidateMCPCaches()
 	return namedMCPServer{Name: target.Name, Config: cfg, Source: "login"}, count, nil
 }
 
@@ -751,6 +764,56 @@ func (api *ProvisioningAPI) handleDisconnectMCPServer(w http.ResponseWriter, r *
 		mautrix.MUnknown.WithMessage("Couldn't disconnect MCP server: %v.", err).Write(w)
 		return
 	}
-	client.invalidateMCPToolCache()
+	client.invalidateMCPCaches()
 	exhttp.WriteJSONResponse(w, http.StatusOK, mcpServerResponseFromNamed(namedMCPServer{Name: target.Name, Config: cfg, Source: "login"}))
 }
+
+func (api *ProvisioningAPI) handleListToolArgumentRules(w http.ResponseWriter, r *http.Request) {
+	_, client := api.getClient(w, r)
+	if client == nil {
+		return
+	}
+	rules := client.toolArgumentRules()
+	if rules == nil {
+		rules = []ToolArgumentRule{}
+	}
+	exhttp.WriteJSONResponse(w, http.StatusOK, map[string]any{"rules": rules})
+}
+
+func (api *ProvisioningAPI) handleCreateToolArgumentRule(w http.ResponseWriter, r *http.Request) {
+	_, client := api.getClient(w, r)
+	if client == nil {
+		return
+	}
+	var req ToolArgumentRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mautrix.MBadJSON.WithMessage("Invalid JSON: %v.", err).Write(w)
+		return
+	}
+	if _, err := normalizeToolArgumentRule(req); err != nil {
+		mautrix.MInvalidParam.WithMessage("%v.", err).Write(w)
+		return
+	}
+	rule, err := client.addToolArgumentRule(r.Context(), req)
+	if err != nil {
+		mautrix.MUnknown.WithMessage("Couldn't save rule: %v.", err).Write(w)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusCreated, rule)
+}
+
+func (api *ProvisioningAPI) handleDeleteToolArgumentRule(w http.ResponseWriter, r *http.Request) {
+	_, client := api.getClient(w, r)
+	if client == nil {
+		return
+	}
+	err := client.removeToolArgumentRule(r.Context(), r.PathValue("rule_id"))
+	if errors.Is(err, errToolArgumentRuleNotFound) {
+		mautrix.MNotFound.WithMessage("Rule not found.").Write(w)
+		return
+	} else if err != nil {
+		mautrix.MUnknown.WithMessage("Couldn't remove rule: %v.", err).Write(w)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusOK, map[string]any{"deleted": true})
+}
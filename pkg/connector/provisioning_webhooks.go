@@ -0,0 +1,375 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/util/exhttp"
+	"maunium.net/go/mautrix"
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/networkid"
+	"maunium.net/go/mautrix/id"
+)
+
+// webhookReceivePath is served on the appservice router next to the
+// provisioning API but outside its auth middleware: senders authenticate with
+// the hook's secret instead.
+const webhookReceivePath = "/_matrix/provision/v1/webhooks/{hook_id}/receive"
+
+type webhookUpsertRequest struct {
+	Name           *string `json:"name,omitempty"`
+	Secret         *string `json:"secret,omitempty"`
+	PromptTemplate *string `json:"prompt_template,omitempty"`
+	AgentID        *string `json:"agent_id,omitempty"`
+	RoomID         *string `json:"room_id,omitempty"`
+	WrapUntrusted  *bool   `json:"wrap_untrusted,omitempty"`
+	Enabled        *bool   `json:"enabled,omitempty"`
+}
+
+type webhookResponse struct {
+	Webhook
+	URL string `json:"url,omitempty"`
+}
+
+// webhookResponseFor hides the secret unless it was just set or generated.
+func (api *ProvisioningAPI) webhookResponseFor(hook Webhook, showSecret bool) webhookResponse {
+	if !showSecret {
+		hook.Secret = ""
+	}
+	return webhookResponse{Webhook: hook, URL: api.connector.webhookURL(hook.ID)}
+}
+
+// webhookURL returns the public receive URL of a hook, or "" when the bridge
+// has no public address.
+func (oc *OpenAIConnector) webhookURL(hookID string) string {
+	srv, ok := oc.br.Matrix.(bridgev2.MatrixConnectorWithServer)
+	if !ok || srv.GetPublicAddress() == "" {
+		return ""
+	}
+	return srv.GetPublicAddress() + strings.Replace(webhookReceivePath, "{hook_id}", hookID, 1)
+}
+
+func (api *ProvisioningAPI) getWebhookScope(w http.ResponseWriter, r *http.Request) (*AIClient, *schedulerDBScope) {
+	_, client := api.getClient(w, r)
+	if client == nil {
+		return nil, nil
+	}
+	scope := client.webhookDBScope()
+	if scope == nil {
+		mautrix.MUnknown.WithMessage("Webhook storage not available.").Write(w)
+		return nil, nil
+	}
+	return client, scope
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errWebhookNotFound):
+		mautrix.MNotFound.WithMessage("Webhook not found.").Write(w)
+	case errors.Is(err, errWebhookDeliveryNotFound):
+		mautrix.MNotFound.WithMessage("Webhook delivery not found.").Write(w)
+	default:
+		mautrix.MUnknown.WithMessage("Couldn't process webhook: %v.", err).Write(w)
+	}
+}
+
+// applyWebhookUpsert validates req and applies it to hook. It reports whether
+// the secret changed so it can be shown once.
+func applyWebhookUpsert(ctx context.Context, client *AIClient, hook *Webhook, req webhookUpsertRequest) (bool, error) {
+	if req.Name != nil {
+		hook.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.PromptTemplate != nil {
+		if _, err := parseWebhookTemplate(*req.PromptTemplate); err != nil {
+			return false, err
+		}
+		hook.PromptTemplate = *req.PromptTemplate
+	}
+	if req.AgentID != nil {
+		agentID := normalizeAgentID(*req.AgentID)
+		if agentID != "" {
+			if _, err := NewAgentStoreAdapter(client).GetAgentByID(ctx, agentID); err != nil {
+				return false, err
+			}
+		}
+		hook.AgentID = agentID
+	}
+	if req.RoomID != nil {
+		roomID := strings.TrimSpace(*req.RoomID)
+		if roomID != "" {
+			if client.webhookRoomPortal(ctx, id.RoomID(roomID)) == nil {
+				return false, errors.New("room not found")
+			}
+		}
+		hook.RoomID = roomID
+	}
+	if req.WrapUntrusted != nil {
+		hook.WrapUntrusted = *req.WrapUntrusted
+	}
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	secretChanged := false
+	if req.Secret != nil || hook.Secret == "" {
+		secret := ""
+		if req.Secret != nil {
+			secret = strings.TrimSpace(*req.Secret)
+		}
+		if secret == "" {
+			var err error
+			if secret, err = newWebhookSecret(); err != nil {
+				return false, err
+			}
+		}
+		hook.Secret = secret
+		secretChanged = true
+	}
+	return secretChanged, nil
+}
+
+// handleListWebhooks handles GET /v1/webhooks.
+func (api *ProvisioningAPI) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	_, scope := api.getWebhookScope(w, r)
+	if scope == nil {
+		return
+	}
+	hooks, err := listWebhooks(r.Context(), scope)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	resp := make([]webhookResponse, 0, len(hooks))
+	for _, hook := range hooks {
+		resp = append(resp, api.webhookResponseFor(hook, false))
+	}
+	exhttp.WriteJSONResponse(w, http.StatusOK, map[string]any{"webhooks": resp})
+}
+
+// handleCreateWebhook handles POST /v1/webhooks. A secret is generated when
+// none is given; it is only returned in this response.
+func (api *ProvisioningAPI) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	client, scope := api.getWebhookScope(w, r)
+	if scope == nil {
+		return
+	}
+	var req webhookUpsertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mautrix.MBadJSON.WithMessage("Invalid JSON: %v.", err).Write(w)
+		return
+	}
+	now := time.Now().UnixMilli()
+	hook := Webhook{ID: uuid.NewString(), WrapUntrusted: true, Enabled: true, CreatedAtMs: now, UpdatedAtMs: now}
+	if _, err := applyWebhookUpsert(r.Context(), client, &hook, req); err != nil {
+		mautrix.MInvalidParam.WithMessage("%v.", err).Write(w)
+		return
+	}
+	if err := saveWebhook(r.Context(), scope, hook); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusCreated, api.webhookResponseFor(hook, true))
+}
+
+// handleGetWebhook handles GET /v1/webhooks/{hook_id}.
+func (api *ProvisioningAPI) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	_, scope := api.getWebhookScope(w, r)
+	if scope == nil {
+		return
+	}
+	hook, err := getWebhook(r.Context(), scope, r.PathValue("hook_id"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusOK, api.webhookResponseFor(*hook, false))
+}
+
+// handleUpdateWebhook handles PUT /v1/webhooks/{hook_id}. Sending an empty
+// secret rotates it.
+func (api *ProvisioningAPI) handleUpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	client, scope := api.getWebhookScope(w, r)
+	if scope == nil {
+		return
+	}
+	hook, err := getWebhook(r.Context(), scope, r.PathValue("hook_id"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	var req webhookUpsertRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mautrix.MBadJSON.WithMessage("Invalid JSON: %v.", err).Write(w)
+		return
+	}
+	secretChanged, err := applyWebhookUpsert(r.Context(), client, hook, req)
+	if err != nil {
+		mautrix.MInvalidParam.WithMessage("%v.", err).Write(w)
+		return
+	}
+	hook.UpdatedAtMs = time.Now().UnixMilli()
+	if err = saveWebhook(r.Context(), scope, *hook); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusOK, api.webhookResponseFor(*hook, secretChanged))
+}
+
+// handleDeleteWebhook handles DELETE /v1/webhooks/{hook_id}.
+func (api *ProvisioningAPI) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	_, scope := api.getWebhookScope(w, r)
+	if scope == nil {
+		return
+	}
+	deleted, err := deleteWebhook(r.Context(), scope, strings.TrimSpace(r.PathValue("hook_id")))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	} else if !deleted {
+		writeWebhookError(w, errWebhookNotFound)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusOK, map[string]any{"deleted": true})
+}
+
+// handleListWebhookDeliveries handles GET /v1/webhooks/{hook_id}/deliveries.
+func (api *ProvisioningAPI) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	_, scope := api.getWebhookScope(w, r)
+	if scope == nil {
+		return
+	}
+	hook, err := getWebhook(r.Context(), scope, r.PathValue("hook_id"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	limit := 20
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 || limit > webhookDeliveryHistoryMax {
+			mautrix.MInvalidParam.WithMessage("limit must be between 1 and %d.", webhookDeliveryHistoryMax).Write(w)
+			return
+		}
+	}
+	deliveries, err := listWebhookDeliveries(r.Context(), scope, hook.ID, limit)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusOK, map[string]any{"deliveries": deliveries})
+}
+
+// handleReplayWebhookDelivery handles
+// POST /v1/webhooks/{hook_id}/deliveries/{delivery_id}/replay.
+func (api *ProvisioningAPI) handleReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	client, scope := api.getWebhookScope(w, r)
+	if scope == nil {
+		return
+	}
+	delivery, err := client.replayWebhookDelivery(r.Context(), r.PathValue("hook_id"), r.PathValue("delivery_id"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusAccepted, delivery)
+}
+
+// handleReceiveWebhook handles POST requests to webhookReceivePath from
+// external senders.
+func (oc *OpenAIConnector) handleReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	hookID := strings.TrimSpace(r.PathValue("hook_id"))
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBodyBytes))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			mautrix.MTooLarge.WithMessage("Webhook payload too large.").Write(w)
+		} else {
+			mautrix.MBadJSON.WithMessage("Couldn't read request body: %v.", err).Write(w)
+		}
+		return
+	}
+	db := oc.bridgeDB()
+	if db == nil {
+		mautrix.MUnknown.WithMessage("Webhook storage not available.").Write(w)
+		return
+	}
+	loginID, err := findWebhookLogin(ctx, db, string(oc.br.DB.BridgeID), hookID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	login := oc.br.GetCachedUserLoginByID(networkid.UserLoginID(loginID))
+	client, ok := loginClient(login)
+	if !ok {
+		mautrix.MNotFound.WithMessage("Webhook not found.").Write(w)
+		return
+	}
+	scope := client.webhookDBScope()
+	if scope == nil {
+		mautrix.MUnknown.WithMessage("Webhook storage not available.").Write(w)
+		return
+	}
+	hook, err := getWebhook(ctx, scope, hookID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	if !verifyWebhookSignature(hook.Secret, r.Header, body) {
+		oc.br.Log.Warn().Str("hook_id", hookID).Msg("Rejected webhook delivery with invalid signature")
+		mautrix.MForbidden.WithMessage("Invalid webhook signature.").Write(w)
+		return
+	}
+	// Limit after verifying, so unsigned requests can't use up a hook's quota.
+	if !oc.webhooks.allow(hook.ID, time.Now()) {
+		oc.br.Log.Warn().Str("hook_id", hookID).Msg("Rate limited webhook delivery")
+		w.Header().Set("Retry-After", "60")
+		mautrix.MLimitExceeded.WithMessage("Too many webhook deliveries, retry later.").Write(w)
+		return
+	}
+	guard := oc.webhooks.hook(hook.ID)
+	guard.deliverMu.Lock()
+	defer guard.deliverMu.Unlock()
+	externalID := webhookDeliveryExternalID(r.Header)
+	if externalID != "" {
+		previous, err := findWebhookDeliveryByExternalID(ctx, scope, hook.ID, externalID)
+		if err == nil {
+			exhttp.WriteJSONResponse(w, http.StatusOK, map[string]any{
+				"delivery_id": previous.ID,
+				"status":      previous.Status,
+				"duplicate":   true,
+			})
+			return
+		} else if !errors.Is(err, errWebhookDeliveryNotFound) {
+			writeWebhookError(w, err)
+			return
+		}
+	}
+	delivery, err := client.deliverWebhook(ctx, *hook, WebhookDelivery{
+		EventType:   webhookEventType(r.Header),
+		ContentType: r.Header.Get("Content-Type"),
+		Payload:     string(body),
+		ExternalID:  externalID,
+	})
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	exhttp.WriteJSONResponse(w, http.StatusAccepted, map[string]any{
+		"delivery_id": delivery.ID,
+		"status":      delivery.Status,
+	})
+}
+
+func loginClient(login *bridgev2.UserLogin) (*AIClient, bool) {
+	if login == nil {
+		return nil, false
+	}
+	client, ok := login.Client.(*AIClient)
+	return client, ok && client != nil
+}
@@ -445,9 +445,9 @@ func (oc *AIClient) sendPlainAssistantMessage(ctx context.Context, portal *bridg
 
 // sendPlainAssistantMessageWithResult is used by automated delivery paths where failures should be
 // observable by the caller (e.g. so a background runner doesn't get stuck on a blocked send forever).
-func (oc *AIClient) sendPlainAssistantMessageWithResult(ctx context.Context, portal *bridgev2.Portal, text string) error {
+func (oc *AIClient) sendPlainAssistantMessageWithResult(ctx context.Context, portal *bridgev2.Portal, text string) (id.EventID, error) {
 	if portal == nil || portal.MXID == "" {
-		return nil
+		return "", nil
 	}
 
 	rendered := format.RenderMarkdown(text, true, true)
@@ -468,12 +468,13 @@ func (oc *AIClient) sendPlainAssistantMessageWithResult(ctx context.Context, por
 		}},
 	}
 
-	if _, _, err := oc.sendViaPortal(ctx, portal, converted, ""); err != nil {
+	eventID, _, err := oc.sendViaPortal(ctx, portal, converted, "")
+	if err != nil {
 		oc.loggerForContext(ctx).Warn().Err(err).Stringer("room_id", portal.MXID).Msg("Failed to send plain assistant message")
-		return err
+		return "", err
 	}
 	oc.recordAgentActivity(ctx, portal, portalMeta(portal))
-	return nil
+	return eventID, nil
 }
 
 func buildSourceParts(cits []citations.SourceCitation, documents []citations.SourceDocument, previews []*event.BeeperLinkPreview) []map[string]any {
@@ -356,6 +356,10 @@ func (oc *AIClient) streamingResponseWithRetry(
 	meta *PortalMetadata,
 	promptContext PromptContext,
 ) {
+	meta, allowed := oc.applyUsageBudget(ctx, evt, portal, meta)
+	if !allowed {
+		return
+	}
 	prompt := oc.promptContextToDispatchMessages(ctx, portal, meta, promptContext)
 	responseFn, logLabel := oc.selectResponseFn(meta, promptContext)
 	success, err := oc.responseWithRetry(ctx, evt, portal, meta, prompt, responseFn, logLabel)
@@ -369,6 +373,9 @@ func (oc *AIClient) streamingResponseWithRetry(
 }
 
 func (oc *AIClient) selectResponseFn(meta *PortalMetadata, promptContext PromptContext) (responseFunc, string) {
+	if oc.nativeProvider() != nil {
+		return oc.streamProviderMessages, "provider_messages"
+	}
 	if hasUnsupportedResponsesPromptContext(promptContext) {
 		return oc.streamChatCompletions, "chat_completions"
 	}
@@ -24,8 +24,9 @@ const (
 )
 
 type schedulerRuntime struct {
-	client *AIClient
-	mu     sync.Mutex
+	client  *AIClient
+	mu      sync.Mutex
+	backend scheduleBackend
 }
 
 type scheduledCronStore struct {
@@ -77,6 +78,20 @@ func (s *schedulerRuntime) Start(ctx context.Context) {
 	}
 }
 
+// Stop drops pending local ticks. The backend is chosen again on the next
+// Start, which re-arms the ticks from the database.
+func (s *schedulerRuntime) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.backend != nil {
+		s.backend.Stop()
+		s.backend = nil
+	}
+}
+
 func (s *schedulerRuntime) HandleScheduleTick(ctx context.Context, evt *event.Event, portal *bridgev2.Portal) {
 	if s == nil || s.client == nil || evt == nil {
 		return
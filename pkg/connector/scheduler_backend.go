@@ -0,0 +1,192 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"maunium.net/go/mautrix"
+	"maunium.net/go/mautrix/event"
+	"maunium.net/go/mautrix/id"
+)
+
+const (
+	SchedulerBackendAuto   = "auto"
+	SchedulerBackendMatrix = "matrix"
+	SchedulerBackendLocal  = "local"
+)
+
+// localDelayIDPrefix marks delay IDs of the local backend so they are never
+// sent to the homeserver, and vice versa, after switching backends.
+const localDelayIDPrefix = "local_"
+
+// scheduleBackend delivers schedule ticks after a delay.
+type scheduleBackend interface {
+	Name() string
+	// Schedule arranges for content to be handled after delay and returns an ID
+	// for Exists and Cancel.
+	Schedule(ctx context.Context, roomID id.RoomID, content ScheduleTickContent, delay time.Duration) (string, error)
+	Exists(ctx context.Context, delayID string) (bool, error)
+	Cancel(ctx context.Context, delayID string) error
+	// Durable reports whether pending ticks survive a bridge restart. Missed runs
+	// are only caught up for backends that aren't.
+	Durable() bool
+	Stop()
+}
+
+// resolveScheduleBackend picks the backend from config. "auto" uses Matrix
+// delayed events when the homeserver advertises them and local timers otherwise.
+func (s *schedulerRuntime) resolveScheduleBackend() scheduleBackend {
+	mode := SchedulerBackendAuto
+	if cfg := s.client.connector.Config.Scheduler; cfg != nil && strings.TrimSpace(cfg.Backend) != "" {
+		mode = strings.ToLower(strings.TrimSpace(cfg.Backend))
+	}
+	switch mode {
+	case SchedulerBackendMatrix:
+		return &matrixScheduleBackend{scheduler: s}
+	case SchedulerBackendLocal:
+		return newLocalScheduleBackend(s.fireLocalTick)
+	case SchedulerBackendAuto:
+	default:
+		s.client.log.Warn().Str("backend", mode).Msg("Unknown scheduler backend, selecting automatically")
+	}
+	if s.client.UserLogin != nil && homeserverSupportsDelayedEvents(s.client.UserLogin.Bridge) {
+		return &matrixScheduleBackend{scheduler: s}
+	}
+	return newLocalScheduleBackend(s.fireLocalTick)
+}
+
+// backendLocked returns the active backend, choosing one on first use.
+func (s *schedulerRuntime) backendLocked() scheduleBackend {
+	if s.backend == nil {
+		s.backend = s.resolveScheduleBackend()
+		s.client.log.Info().Str("backend", s.backend.Name()).Msg("Selected scheduler backend")
+	}
+	return s.backend
+}
+
+// fireLocalTick handles a tick of the local backend like one that arrived as
+// a Matrix event.
+func (s *schedulerRuntime) fireLocalTick(roomID id.RoomID, content ScheduleTickContent) {
+	ctx := s.client.backgroundContext(context.Background())
+	if ctx.Err() != nil {
+		return
+	}
+	evt := &event.Event{Type: ScheduleTickEventType, RoomID: roomID}
+	s.HandleScheduleTickContent(ctx, content, evt, s.client.portalByRoomID(ctx, roomID))
+}
+
+// matrixScheduleBackend sends ticks as Matrix delayed events (MSC4140).
+type matrixScheduleBackend struct {
+	scheduler *schedulerRuntime
+}
+
+func (b *matrixScheduleBackend) Name() string { return SchedulerBackendMatrix }
+
+func (b *matrixScheduleBackend) Durable() bool { return true }
+
+func (b *matrixScheduleBackend) Stop() {}
+
+func (b *matrixScheduleBackend) Schedule(ctx context.Context, roomID id.RoomID, content ScheduleTickContent, delay time.Duration) (string, error) {
+	intent := b.scheduler.intentClient()
+	if intent == nil {
+		return "", errors.New("matrix intent not available")
+	}
+	resp, err := intent.SendMessageEvent(ctx, roomID, ScheduleTickEventType, content, mautrix.ReqSendEvent{UnstableDelay: delay})
+	if err != nil {
+		return "", err
+	}
+	return string(resp.UnstableDelayID), nil
+}
+
+func (b *matrixScheduleBackend) Exists(ctx context.Context, delayID string) (bool, error) {
+	intent := b.scheduler.intentClient()
+	if intent == nil || strings.TrimSpace(delayID) == "" || strings.HasPrefix(delayID, localDelayIDPrefix) {
+		return false, nil
+	}
+	resp, err := intent.DelayedEvents(ctx, &mautrix.ReqDelayedEvents{DelayID: id.DelayID(delayID)})
+	if err != nil {
+		return false, err
+	}
+	return resp != nil, nil
+}
+
+func (b *matrixScheduleBackend) Cancel(ctx context.Context, delayID string) error {
+	intent := b.scheduler.intentClient()
+	if intent == nil || strings.TrimSpace(delayID) == "" || strings.HasPrefix(delayID, localDelayIDPrefix) {
+		return nil
+	}
+	_, err := intent.UpdateDelayedEvent(ctx, &mautrix.ReqUpdateDelayedEvent{
+		DelayID: id.DelayID(delayID),
+		Action:  event.DelayActionCancel,
+	})
+	return err
+}
+
+// localScheduleBackend fires ticks from in-process timers. Timers are lost on
+// restart; the scheduler re-arms them from ai_cron_jobs and
+// ai_managed_heartbeats when it reconciles.
+type localScheduleBackend struct {
+	fire func(roomID id.RoomID, content ScheduleTickContent)
+
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	stopped bool
+}
+
+func newLocalScheduleBackend(fire func(roomID id.RoomID, content ScheduleTickContent)) *localScheduleBackend {
+	return &localScheduleBackend{fire: fire, timers: make(map[string]*time.Timer)}
+}
+
+func (b *localScheduleBackend) Name() string { return SchedulerBackendLocal }
+
+func (b *localScheduleBackend) Durable() bool { return false }
+
+func (b *localScheduleBackend) Schedule(_ context.Context, roomID id.RoomID, content ScheduleTickContent, delay time.Duration) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return "", errors.New("scheduler is stopped")
+	}
+	delayID := localDelayIDPrefix + uuid.NewString()
+	b.timers[delayID] = time.AfterFunc(delay, func() {
+		b.mu.Lock()
+		_, pending := b.timers[delayID]
+		delete(b.timers, delayID)
+		b.mu.Unlock()
+		if pending {
+			b.fire(roomID, content)
+		}
+	})
+	return delayID, nil
+}
+
+func (b *localScheduleBackend) Exists(_ context.Context, delayID string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.timers[delayID]
+	return ok, nil
+}
+
+func (b *localScheduleBackend) Cancel(_ context.Context, delayID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if timer, ok := b.timers[delayID]; ok {
+		timer.Stop()
+		delete(b.timers, delayID)
+	}
+	return nil
+}
+
+func (b *localScheduleBackend) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopped = true
+	for delayID, timer := range b.timers {
+		timer.Stop()
+		delete(b.timers, delayID)
+	}
+}
@@ -0,0 +1,117 @@
+package connector
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"maunium.net/go/mautrix/id"
+)
+
+func TestLocalScheduleBackendFiresAndCancels(t *testing.T) {
+	fired := make(chan ScheduleTickContent, 2)
+	backend := newLocalScheduleBackend(func(_ id.RoomID, content ScheduleTickContent) {
+		fired <- content
+	})
+	ctx := context.Background()
+
+	keep, err := backend.Schedule(ctx, "!room:example.com", ScheduleTickContent{RunKey: "keep"}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if !strings.HasPrefix(keep, localDelayIDPrefix) {
+		t.Fatalf("expected local delay ID, got %q", keep)
+	}
+	drop, err := backend.Schedule(ctx, "!room:example.com", ScheduleTickContent{RunKey: "drop"}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if exists, _ := backend.Exists(ctx, drop); !exists {
+		t.Fatal("expected scheduled tick to exist")
+	}
+	if err = backend.Cancel(ctx, drop); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+
+	select {
+	case content := <-fired:
+		if content.RunKey != "keep" {
+			t.Fatalf("expected the kept tick to fire, got %q", content.RunKey)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for tick")
+	}
+	select {
+	case content := <-fired:
+		t.Fatalf("expected cancelled tick not to fire, got %q", content.RunKey)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if exists, _ := backend.Exists(ctx, keep); exists {
+		t.Fatal("expected fired tick to be forgotten")
+	}
+
+	backend.Stop()
+	if _, err = backend.Schedule(ctx, "!room:example.com", ScheduleTickContent{}, time.Millisecond); err == nil {
+		t.Fatal("expected scheduling on a stopped backend to fail")
+	}
+}
+
+func TestResolveScheduleBackend(t *testing.T) {
+	for backend, want := range map[string]string{
+		"":        SchedulerBackendLocal, // auto without a homeserver that supports delayed events
+		"auto":    SchedulerBackendLocal,
+		"Matrix":  SchedulerBackendMatrix,
+		"local":   SchedulerBackendLocal,
+		"unknown": SchedulerBackendLocal,
+	} {
+		oc := &AIClient{log: zerolog.Nop(), connector: &OpenAIConnector{Config: Config{Scheduler: &SchedulerConfig{Backend: backend}}}}
+		s := newSchedulerRuntime(oc)
+		if got := s.resolveScheduleBackend().Name(); got != want {
+			t.Errorf("backend %q: expected %s, got %s", backend, want, got)
+		}
+	}
+}
+
+func TestCatchUpMissedCronRun(t *testing.T) {
+	oc := &AIClient{log: zerolog.Nop(), connector: &OpenAIConnector{}}
+	s := newSchedulerRuntime(oc)
+	local := newLocalScheduleBackend(func(id.RoomID, ScheduleTickContent) {})
+	defer local.Stop()
+	s.backend = local
+
+	nowMs := time.Now().UnixMilli()
+	missedAtMs := nowMs - time.Hour.Milliseconds()
+	record := &scheduledCronJob{Revision: 2}
+	record.Job.ID = "job"
+	record.Job.State.NextRunAtMs = &missedAtMs
+
+	if !s.catchUpMissedCronRunLocked(context.Background(), record, nowMs) {
+		t.Fatal("expected a missed run to be caught up")
+	}
+	wantKey := buildTickRunKey(2, "run", missedAtMs)
+	if record.PendingRunKey != wantKey || record.PendingDelayKind != "run" {
+		t.Fatalf("unexpected pending tick %q (%s)", record.PendingRunKey, record.PendingDelayKind)
+	}
+	if exists, _ := local.Exists(context.Background(), record.PendingDelayID); !exists {
+		t.Fatal("expected catch-up tick to be scheduled")
+	}
+
+	record.ProcessedRunKeys = []string{wantKey}
+	if s.catchUpMissedCronRunLocked(context.Background(), record, nowMs) {
+		t.Fatal("expected an already processed run not to be caught up again")
+	}
+	future := nowMs + time.Hour.Milliseconds()
+	record.ProcessedRunKeys = nil
+	record.Job.State.NextRunAtMs = &future
+	if s.catchUpMissedCronRunLocked(context.Background(), record, nowMs) {
+		t.Fatal("expected a future run not to be caught up")
+	}
+
+	s.backend = &matrixScheduleBackend{scheduler: s}
+	record.Job.State.NextRunAtMs = &missedAtMs
+	if s.catchUpMissedCronRunLocked(context.Background(), record, nowMs) {
+		t.Fatal("expected delayed events to deliver missed ticks themselves")
+	}
+}
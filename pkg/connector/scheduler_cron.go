@@ -23,7 +23,7 @@ func (s *schedulerRuntime) CronStatus(ctx context.Context) (bool, string, int, *
 
 	store, err := s.loadCronStoreLocked(ctx)
 	if err != nil {
-		return false, "sqlite:ai_cron_jobs", 0, nil, err
+		return false, s.backendLocked().Name(), 0, nil, err
 	}
 	var next *int64
 	for i := range store.Jobs {
@@ -36,7 +36,7 @@ func (s *schedulerRuntime) CronStatus(ctx context.Context) (bool, string, int, *
 			next = &val
 		}
 	}
-	return true, "sqlite:ai_cron_jobs", len(store.Jobs), next, nil
+	return true, s.backendLocked().Name(), len(store.Jobs), next, nil
 }
 
 func (s *schedulerRuntime) CronList(ctx context.Context, includeDisabled bool) ([]integrationcron.Job, error) {
@@ -273,7 +273,9 @@ func (s *schedulerRuntime) handleCronRun(ctx context.Context, tick ScheduleTickC
 	}
 	s.mu.Unlock()
 
-	status, errText, preview := s.executeCronJob(ctx, &record)
+	result := s.executeCronJob(ctx, &record)
+	finishedAt := time.Now().UnixMilli()
+	s.recordCronRun(ctx, record, tick, manual, nowMs, finishedAt, result)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -289,13 +291,13 @@ func (s *schedulerRuntime) handleCronRun(ctx context.Context, tick ScheduleTickC
 	if !record.Job.Enabled || tick.Revision != record.Revision || containsRunKey(record.ProcessedRunKeys, tick.RunKey) {
 		return nil
 	}
-	finishedAt := time.Now().UnixMilli()
+	durationMs := finishedAt - nowMs
 	record.Job.State.RunningAtMs = nil
 	record.Job.State.LastRunAtMs = &finishedAt
-	record.Job.State.LastStatus = status
-	record.Job.State.LastError = errText
-	record.Job.State.LastDurationMs = nil
-	record.LastOutputPreview = preview
+	record.Job.State.LastStatus = result.Status
+	record.Job.State.LastError = result.Error
+	record.Job.State.LastDurationMs = &durationMs
+	record.LastOutputPreview = truncateSchedulePreview(result.Output)
 	record.ProcessedRunKeys = appendRunKey(record.ProcessedRunKeys, tick.RunKey)
 	record.Job.UpdatedAtMs = finishedAt
 	if record.Job.DeleteAfterRun {
@@ -314,13 +316,30 @@ func (s *schedulerRuntime) handleCronRun(ctx context.Context, tick ScheduleTickC
 	return s.saveCronStoreLocked(ctx, store)
 }
 
-func (s *schedulerRuntime) executeCronJob(ctx context.Context, record *scheduledCronJob) (string, string, string) {
+// cronRunResult is the outcome of a single cron job execution.
+type cronRunResult struct {
+	Status           string
+	Error            string
+	Output           string
+	Model            string
+	DeliveredRoomID  string
+	DeliveredEventID string
+	PromptTokens     int64
+	CompletionTokens int64
+	ReasoningTokens  int64
+}
+
+func cronRunFailure(errText string) cronRunResult {
+	return cronRunResult{Status: "error", Error: errText}
+}
+
+func (s *schedulerRuntime) executeCronJob(ctx context.Context, record *scheduledCronJob) cronRunResult {
 	if s == nil || s.client == nil || record == nil {
-		return "error", "missing scheduler", ""
+		return cronRunFailure("missing scheduler")
 	}
 	portal := s.client.portalByRoomID(ctx, id.RoomID(record.RoomID))
 	if portal == nil || portal.MXID == "" {
-		return "error", "cron room not found", ""
+		return cronRunFailure("cron room not found")
 	}
 	meta := clonePortalMetadata(portalMeta(portal))
 	if meta == nil {
@@ -336,6 +355,7 @@ func (s *schedulerRuntime) executeCronJob(ctx context.Context, record *scheduled
 	if record.Job.Delivery != nil && record.Job.Delivery.Mode == integrationcron.DeliveryAnnounce {
 		meta.DisabledTools = appendMissingDisabledTool(meta.DisabledTools, "message")
 	}
+	result := cronRunResult{Model: s.client.effectiveModel(meta)}
 
 	timeoutSeconds := resolveScheduledCronTimeoutSeconds(s.client, record.Job.Payload.TimeoutSeconds)
 	runCtx, cancel := context.WithTimeout(s.client.backgroundContext(ctx), time.Duration(timeoutSeconds)*time.Second)
@@ -348,31 +368,45 @@ func (s *schedulerRuntime) executeCronJob(ctx context.Context, record *scheduled
 	}
 	lastID, lastTS := s.client.lastAssistantMessageInfo(runCtx, portal)
 	if _, _, err := s.client.dispatchInternalMessage(runCtx, portal, meta, message, defaultScheduleEventSource, false); err != nil {
-		return "error", err.Error(), ""
+		result.Status, result.Error = "error", err.Error()
+		return result
 	}
 
 	msg, found := s.client.waitForNewAssistantMessage(runCtx, portal, lastID, lastTS)
 	if !found || msg == nil {
-		return "error", "timed out waiting for cron response", ""
+		result.Status, result.Error = "error", "timed out waiting for cron response"
+		return result
 	}
 	body := ""
-	if meta := messageMeta(msg); meta != nil {
-		body = strings.TrimSpace(meta.Body)
+	if msgMeta := messageMeta(msg); msgMeta != nil {
+		body = strings.TrimSpace(msgMeta.Body)
+		if msgMeta.Model != "" {
+			result.Model = msgMeta.Model
+		}
+		result.PromptTokens = msgMeta.PromptTokens
+		result.CompletionTokens = msgMeta.CompletionTokens
+		result.ReasoningTokens = msgMeta.ReasoningTokens
 	}
 	if body == "" {
 		body = strings.TrimSpace(msg.MXID.String())
 	}
-	preview := truncateSchedulePreview(body)
+	result.Output = body
 	if record.Job.Delivery != nil && record.Job.Delivery.Mode == integrationcron.DeliveryAnnounce {
 		target := s.resolveCronDeliveryTarget(record.Job.AgentID, record.Job.Delivery)
 		if target.Portal == nil || strings.TrimSpace(target.RoomID) == "" {
-			return "skipped", "delivery target unavailable", preview
+			result.Status, result.Error = "skipped", "delivery target unavailable"
+			return result
 		}
-		if err := s.client.sendPlainAssistantMessageWithResult(runCtx, target.Portal.(*bridgev2.Portal), body); err != nil {
-			return "error", err.Error(), preview
+		eventID, err := s.client.sendPlainAssistantMessageWithResult(runCtx, target.Portal.(*bridgev2.Portal), body)
+		if err != nil {
+			result.Status, result.Error = "error", err.Error()
+			return result
 		}
+		result.DeliveredRoomID = strings.TrimSpace(target.RoomID)
+		result.DeliveredEventID = eventID.String()
 	}
-	return "success", "", preview
+	result.Status = "success"
+	return result
 }
 
 func (s *schedulerRuntime) resolveCronDeliveryTarget(agentID string, delivery *integrationcron.Delivery) integrationcron.DeliveryTarget {
@@ -436,6 +470,9 @@ func (s *schedulerRuntime) scheduleCronRecordLocked(ctx context.Context, record
 			record.Job.State.NextRunAtMs = due
 			return
 		}
+		if s.catchUpMissedCronRunLocked(ctx, record, nowMs) {
+			return
+		}
 	}
 	if record.PendingDelayID != "" {
 		_ = s.cancelPendingDelayLocked(ctx, record.PendingDelayID)
@@ -443,6 +480,38 @@ func (s *schedulerRuntime) scheduleCronRecordLocked(ctx context.Context, record
 	s.scheduleCronDueLocked(ctx, record, *due)
 }
 
+// catchUpMissedCronRunLocked runs a job once, right away, when its last due
+// time passed while the bridge was down. Backends whose ticks survive a
+// restart deliver the missed tick themselves. The run key is derived from the
+// missed due time, so repeated restarts can't run it twice.
+func (s *schedulerRuntime) catchUpMissedCronRunLocked(ctx context.Context, record *scheduledCronJob, nowMs int64) bool {
+	missedAtMs := record.Job.State.NextRunAtMs
+	if s.backendLocked().Durable() || missedAtMs == nil || *missedAtMs <= 0 || *missedAtMs > nowMs {
+		return false
+	}
+	runKey := buildTickRunKey(record.Revision, shortTickKind(scheduleTickKindCronRun), *missedAtMs)
+	if containsRunKey(record.ProcessedRunKeys, runKey) {
+		return false
+	}
+	delayID, err := s.scheduleTickLocked(ctx, id.RoomID(record.RoomID), ScheduleTickContent{
+		Kind:           scheduleTickKindCronRun,
+		EntityID:       record.Job.ID,
+		Revision:       record.Revision,
+		ScheduledForMs: *missedAtMs,
+		RunKey:         runKey,
+		Reason:         "catch-up",
+	}, scheduleImmediateDelay)
+	if err != nil {
+		s.client.log.Warn().Err(err).Str("job_id", record.Job.ID).Msg("Failed to schedule missed cron run")
+		return false
+	}
+	s.client.log.Info().Str("job_id", record.Job.ID).Int64("missed_at_ms", *missedAtMs).Msg("Catching up missed cron run")
+	record.PendingDelayID = delayID
+	record.PendingDelayKind = shortTickKind(scheduleTickKindCronRun)
+	record.PendingRunKey = runKey
+	return true
+}
+
 func (s *schedulerRuntime) scheduleCronDueLocked(ctx context.Context, record *scheduledCronJob, dueAtMs int64) {
 	if record == nil {
 		return
@@ -454,7 +523,7 @@ func (s *schedulerRuntime) scheduleCronDueLocked(ctx context.Context, record *sc
 		runAtMs = nowMs + int64(schedulePlannerHorizon/time.Millisecond)
 		kind = scheduleTickKindCronPlan
 	}
-	resp, err := s.scheduleTickLocked(ctx, id.RoomID(record.RoomID), ScheduleTickContent{
+	delayID, err := s.scheduleTickLocked(ctx, id.RoomID(record.RoomID), ScheduleTickContent{
 		Kind:           kind,
 		EntityID:       record.Job.ID,
 		Revision:       record.Revision,
@@ -469,7 +538,7 @@ func (s *schedulerRuntime) scheduleCronDueLocked(ctx context.Context, record *sc
 		return
 	}
 	record.Job.State.NextRunAtMs = &dueAtMs
-	record.PendingDelayID = string(resp.UnstableDelayID)
+	record.PendingDelayID = delayID
 	record.PendingDelayKind = shortTickKind(kind)
 	record.PendingRunKey = buildTickRunKey(record.Revision, shortTickKind(kind), runAtMs)
 }
@@ -0,0 +1,146 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	integrationcron "github.com/beeper/agentremote/pkg/integrations/cron"
+)
+
+const (
+	cronRunHistoryMaxPerJob = 50
+	cronRunHistoryMaxAge    = 30 * 24 * time.Hour
+)
+
+func (s *schedulerRuntime) CronRuns(ctx context.Context, jobID string, limit int) ([]integrationcron.JobRun, error) {
+	jobID = strings.TrimSpace(jobID)
+	s.mu.Lock()
+	store, err := s.loadCronStoreLocked(ctx)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if findScheduledCronJob(store.Jobs, jobID) < 0 {
+		return nil, fmt.Errorf("cron job not found: %s", jobID)
+	}
+	scope := s.schedulerDBScope()
+	if scope == nil {
+		return nil, nil
+	}
+	return listCronRuns(ctx, scope, jobID, limit)
+}
+
+// recordCronRun stores the outcome of a run in the job's run history and
+// applies the retention limits. Failures are logged, never returned, so that
+// bookkeeping can't fail the run itself.
+func (s *schedulerRuntime) recordCronRun(ctx context.Context, record scheduledCronJob, tick ScheduleTickContent, manual bool, startedAtMs, finishedAtMs int64, result cronRunResult) {
+	scope := s.schedulerDBScope()
+	if scope == nil {
+		return
+	}
+	trigger := "scheduled"
+	if manual {
+		trigger = "manual"
+	}
+	run := integrationcron.JobRun{
+		RunID:            uuid.NewString(),
+		JobID:            record.Job.ID,
+		Trigger:          trigger,
+		StartedAtMs:      startedAtMs,
+		FinishedAtMs:     finishedAtMs,
+		DurationMs:       finishedAtMs - startedAtMs,
+		Status:           result.Status,
+		Error:            result.Error,
+		RoomID:           record.RoomID,
+		DeliveredRoomID:  result.DeliveredRoomID,
+		DeliveredEventID: result.DeliveredEventID,
+		Model:            result.Model,
+		PromptTokens:     result.PromptTokens,
+		CompletionTokens: result.CompletionTokens,
+		ReasoningTokens:  result.ReasoningTokens,
+		Output:           result.Output,
+	}
+	log := s.client.log.With().Str("job_id", record.Job.ID).Logger()
+	if err := insertCronRun(ctx, scope, tick.RunKey, run); err != nil {
+		log.Warn().Err(err).Msg("Failed to record cron run")
+		return
+	}
+	cutoff := time.UnixMilli(finishedAtMs).Add(-cronRunHistoryMaxAge).UnixMilli()
+	if err := pruneCronRuns(ctx, scope, record.Job.ID, cronRunHistoryMaxPerJob, cutoff); err != nil {
+		log.Warn().Err(err).Msg("Failed to prune cron run history")
+	}
+}
+
+func insertCronRun(ctx context.Context, scope *schedulerDBScope, runKey string, run integrationcron.JobRun) error {
+	_, err := scope.db.Exec(ctx, `
+		INSERT INTO ai_cron_job_runs (
+			bridge_id, login_id, job_id, run_id, run_key, trigger_kind,
+			started_at_ms, finished_at_ms, duration_ms, status, error,
+			room_id, delivered_room_id, delivered_event_id, model_id,
+			prompt_tokens, completion_tokens, reasoning_tokens, output
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`,
+		scope.bridgeID, scope.loginID, run.JobID, run.RunID, runKey, run.Trigger,
+		run.StartedAtMs, run.FinishedAtMs, run.DurationMs, run.Status, run.Error,
+		run.RoomID, run.DeliveredRoomID, run.DeliveredEventID, run.Model,
+		run.PromptTokens, run.CompletionTokens, run.ReasoningTokens, run.Output,
+	)
+	return err
+}
+
+// listCronRuns returns the most recent runs of a job, newest first.
+func listCronRuns(ctx context.Context, scope *schedulerDBScope, jobID string, limit int) ([]integrationcron.JobRun, error) {
+	rows, err := scope.db.Query(ctx, `
+		SELECT
+			job_id, run_id, trigger_kind, started_at_ms, finished_at_ms, duration_ms, status, error,
+			room_id, delivered_room_id, delivered_event_id, model_id,
+			prompt_tokens, completion_tokens, reasoning_tokens, output
+		FROM ai_cron_job_runs
+		WHERE bridge_id=$1 AND login_id=$2 AND job_id=$3
+		ORDER BY started_at_ms DESC, run_id
+		LIMIT $4
+	`, scope.bridgeID, scope.loginID, jobID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []integrationcron.JobRun
+	for rows.Next() {
+		var run integrationcron.JobRun
+		if err := rows.Scan(
+			&run.JobID, &run.RunID, &run.Trigger, &run.StartedAtMs, &run.FinishedAtMs, &run.DurationMs, &run.Status, &run.Error,
+			&run.RoomID, &run.DeliveredRoomID, &run.DeliveredEventID, &run.Model,
+			&run.PromptTokens, &run.CompletionTokens, &run.ReasoningTokens, &run.Output,
+		); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// pruneCronRuns drops runs older than cutoffMs across all jobs of the login,
+// then trims the job's history to its newest maxPerJob runs.
+func pruneCronRuns(ctx context.Context, scope *schedulerDBScope, jobID string, maxPerJob int, cutoffMs int64) error {
+	if _, err := scope.db.Exec(ctx, `
+		DELETE FROM ai_cron_job_runs
+		WHERE bridge_id=$1 AND login_id=$2 AND started_at_ms<$3
+	`, scope.bridgeID, scope.loginID, cutoffMs); err != nil {
+		return err
+	}
+	_, err := scope.db.Exec(ctx, `
+		DELETE FROM ai_cron_job_runs
+		WHERE bridge_id=$1 AND login_id=$2 AND job_id=$3 AND run_id NOT IN (
+			SELECT run_id FROM ai_cron_job_runs
+			WHERE bridge_id=$1 AND login_id=$2 AND job_id=$3
+			ORDER BY started_at_ms DESC, run_id
+			LIMIT $4
+		)
+	`, scope.bridgeID, scope.loginID, jobID, maxPerJob)
+	return err
+}
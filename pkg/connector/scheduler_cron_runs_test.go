@@ -0,0 +1,87 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.mau.fi/util/dbutil"
+
+	"github.com/beeper/agentremote/pkg/aidb"
+	integrationcron "github.com/beeper/agentremote/pkg/integrations/cron"
+)
+
+func setupSchedulerDBScope(t *testing.T) *schedulerDBScope {
+	t.Helper()
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	raw.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = raw.Close() })
+	base, err := dbutil.NewWithDB(raw, "sqlite3")
+	if err != nil {
+		t.Fatalf("wrap db: %v", err)
+	}
+	db := aidb.NewChild(base, dbutil.NoopLogger)
+	if err := aidb.Upgrade(context.Background(), db, "ai_bridge", ""); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	return &schedulerDBScope{db: db, bridgeID: "bridge", loginID: "login"}
+}
+
+func TestCronRunHistory(t *testing.T) {
+	ctx := context.Background()
+	scope := setupSchedulerDBScope(t)
+	for i := range 5 {
+		run := integrationcron.JobRun{
+			RunID:        fmt.Sprintf("run-%d", i),
+			JobID:        "job-a",
+			Trigger:      "scheduled",
+			StartedAtMs:  int64(1000 * (i + 1)),
+			FinishedAtMs: int64(1000*(i+1) + 250),
+			DurationMs:   250,
+			Status:       "success",
+			PromptTokens: 10,
+			Output:       fmt.Sprintf("output %d", i),
+		}
+		if err := insertCronRun(ctx, scope, "rk", run); err != nil {
+			t.Fatalf("insert run: %v", err)
+		}
+	}
+	if err := insertCronRun(ctx, scope, "rk", integrationcron.JobRun{RunID: "other", JobID: "job-b", StartedAtMs: 1500}); err != nil {
+		t.Fatalf("insert other job run: %v", err)
+	}
+
+	runs, err := listCronRuns(ctx, scope, "job-a", 2)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 2 || runs[0].RunID != "run-4" || runs[1].RunID != "run-3" {
+		t.Fatalf("expected newest runs first, got %#v", runs)
+	}
+	if runs[0].Output != "output 4" || runs[0].DurationMs != 250 || runs[0].PromptTokens != 10 {
+		t.Fatalf("unexpected run fields: %#v", runs[0])
+	}
+
+	// Keep three runs per job and drop everything that started before 2000.
+	if err := pruneCronRuns(ctx, scope, "job-a", 3, 2000); err != nil {
+		t.Fatalf("prune runs: %v", err)
+	}
+	runs, err = listCronRuns(ctx, scope, "job-a", 10)
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	if len(runs) != 3 || runs[2].RunID != "run-2" {
+		t.Fatalf("expected runs 4..2 to remain, got %#v", runs)
+	}
+	other, err := listCronRuns(ctx, scope, "job-b", 10)
+	if err != nil {
+		t.Fatalf("list other runs: %v", err)
+	}
+	if len(other) != 0 {
+		t.Fatalf("expected expired run of other job to be pruned, got %#v", other)
+	}
+}
@@ -452,7 +452,7 @@ func nullableBoolValue(value *bool) any {
 }
 
 func deleteMissingCronRows(ctx context.Context, scope *schedulerDBScope, keep map[string]struct{}) error {
-	return deleteMissingScopedRows(ctx, scope, keep, "ai_cron_jobs", "job_id", "ai_cron_job_run_keys")
+	return deleteMissingScopedRows(ctx, scope, keep, "ai_cron_jobs", "job_id", "ai_cron_job_run_keys", "ai_cron_job_runs")
 }
 
 func deleteMissingHeartbeatRows(ctx context.Context, scope *schedulerDBScope, keep map[string]struct{}) error {
@@ -505,7 +505,7 @@ func replaceIndexedRunKeys(ctx context.Context, scope *schedulerDBScope, table,
 	return nil
 }
 
-func deleteMissingScopedRows(ctx context.Context, scope *schedulerDBScope, keep map[string]struct{}, entityTable, idColumn, runKeyTable string) error {
+func deleteMissingScopedRows(ctx context.Context, scope *schedulerDBScope, keep map[string]struct{}, entityTable, idColumn string, childTables ...string) error {
 	rows, err := scope.db.Query(ctx, fmt.Sprintf(
 		`SELECT %s FROM %s WHERE bridge_id=$1 AND login_id=$2`,
 		idColumn, entityTable,
@@ -535,11 +535,13 @@ func deleteMissingScopedRows(ctx context.Context, scope *schedulerDBScope, keep
 		), scope.bridgeID, scope.loginID, idValue); err != nil {
 			return err
 		}
-		if _, err := scope.db.Exec(ctx, fmt.Sprintf(
-			`DELETE FROM %s WHERE bridge_id=$1 AND login_id=$2 AND %s=$3`,
-			runKeyTable, idColumn,
-		), scope.bridgeID, scope.loginID, idValue); err != nil {
-			return err
+		for _, childTable := range childTables {
+			if _, err := scope.db.Exec(ctx, fmt.Sprintf(
+				`DELETE FROM %s WHERE bridge_id=$1 AND login_id=$2 AND %s=$3`,
+				childTable, idColumn,
+			), scope.bridgeID, scope.loginID, idValue); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
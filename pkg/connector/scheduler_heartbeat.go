@@ -68,7 +68,7 @@ func (s *schedulerRuntime) RequestHeartbeatNow(ctx context.Context, reason strin
 		}
 		runAtMs := nowMs + int64(scheduleImmediateDelay/time.Millisecond)
 		runKey := buildTickRunKey(state.Revision, "wake", runAtMs)
-		resp, err := s.scheduleTickLocked(ctx, id.RoomID(state.RoomID), ScheduleTickContent{
+		delayID, err := s.scheduleTickLocked(ctx, id.RoomID(state.RoomID), ScheduleTickContent{
 			Kind:           scheduleTickKindHeartbeatRun,
 			EntityID:       state.AgentID,
 			Revision:       state.Revision,
@@ -81,7 +81,7 @@ func (s *schedulerRuntime) RequestHeartbeatNow(ctx context.Context, reason strin
 			continue
 		}
 		state.NextRunAtMs = runAtMs
-		state.PendingDelayID = string(resp.UnstableDelayID)
+		state.PendingDelayID = delayID
 		state.PendingDelayKind = "wake"
 		state.PendingRunKey = runKey
 		changed = true
@@ -250,7 +250,7 @@ func (s *schedulerRuntime) scheduleHeartbeatStateLocked(ctx context.Context, sta
 		kind = scheduleTickKindHeartbeatPlan
 		runAtMs = nowMs + int64(schedulePlannerHorizon/time.Millisecond)
 	}
-	resp, err := s.scheduleTickLocked(ctx, id.RoomID(state.RoomID), ScheduleTickContent{
+	delayID, err := s.scheduleTickLocked(ctx, id.RoomID(state.RoomID), ScheduleTickContent{
 		Kind:           kind,
 		EntityID:       state.AgentID,
 		Revision:       state.Revision,
@@ -265,7 +265,7 @@ func (s *schedulerRuntime) scheduleHeartbeatStateLocked(ctx context.Context, sta
 		return
 	}
 	state.NextRunAtMs = nextRun
-	state.PendingDelayID = string(resp.UnstableDelayID)
+	state.PendingDelayID = delayID
 	state.PendingDelayKind = shortTickKind(kind)
 	state.PendingRunKey = buildTickRunKey(state.Revision, shortTickKind(kind), runAtMs)
 }
@@ -286,7 +286,7 @@ func (s *schedulerRuntime) scheduleHeartbeatRetryLocked(ctx context.Context, sta
 		_ = s.cancelPendingDelayLocked(ctx, state.PendingDelayID)
 	}
 	retryAtMs := nowMs + int64(scheduleHeartbeatCoalesce/time.Millisecond)
-	resp, err := s.scheduleTickLocked(ctx, id.RoomID(state.RoomID), ScheduleTickContent{
+	delayID, err := s.scheduleTickLocked(ctx, id.RoomID(state.RoomID), ScheduleTickContent{
 		Kind:           scheduleTickKindHeartbeatRun,
 		EntityID:       state.AgentID,
 		Revision:       state.Revision,
@@ -301,7 +301,7 @@ func (s *schedulerRuntime) scheduleHeartbeatRetryLocked(ctx context.Context, sta
 		return
 	}
 	state.NextRunAtMs = retryAtMs
-	state.PendingDelayID = string(resp.UnstableDelayID)
+	state.PendingDelayID = delayID
 	state.PendingDelayKind = "retry"
 	state.PendingRunKey = buildTickRunKey(state.Revision, "retry", retryAtMs)
 }
@@ -34,7 +34,7 @@ func (s *schedulerRuntime) ensureCronRoomLocked(ctx context.Context, record *sch
 	roomID, err := s.ensureScheduledRoomLocked(ctx, portalID, displayName, record.Job.AgentID, map[string]any{
 		"cron": map[string]any{
 			"is_internal_room": true,
-			"backend":          "hungry",
+			"backend":          s.backendLocked().Name(),
 			"job_id":           record.Job.ID,
 			"revision":         record.Revision,
 			"managed":          true,
@@ -56,7 +56,7 @@ func (s *schedulerRuntime) ensureHeartbeatRoomLocked(ctx context.Context, state
 	roomID, err := s.ensureScheduledRoomLocked(ctx, portalID, displayName, state.AgentID, map[string]any{
 		"heartbeat": map[string]any{
 			"is_internal_room": true,
-			"backend":          "hungry",
+			"backend":          s.backendLocked().Name(),
 			"agent_id":         state.AgentID,
 			"revision":         state.Revision,
 			"managed":          true,
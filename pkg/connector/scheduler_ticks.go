@@ -2,53 +2,34 @@ package connector
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 	"time"
 
-	"maunium.net/go/mautrix"
-	"maunium.net/go/mautrix/event"
 	"maunium.net/go/mautrix/id"
 )
 
-func (s *schedulerRuntime) scheduleTickLocked(ctx context.Context, roomID id.RoomID, content ScheduleTickContent, delay time.Duration) (*mautrix.RespSendEvent, error) {
-	intent := s.intentClient()
-	if intent == nil {
-		return nil, errors.New("matrix intent not available")
-	}
+// scheduleTickLocked schedules content through the active backend and
+// returns the delay ID of the pending tick.
+func (s *schedulerRuntime) scheduleTickLocked(ctx context.Context, roomID id.RoomID, content ScheduleTickContent, delay time.Duration) (string, error) {
 	if delay < scheduleImmediateDelay {
 		delay = scheduleImmediateDelay
 	}
-	resp, err := intent.SendMessageEvent(ctx, roomID, ScheduleTickEventType, content, mautrix.ReqSendEvent{UnstableDelay: delay})
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
+	return s.backendLocked().Schedule(ctx, roomID, content, delay)
 }
 
 func (s *schedulerRuntime) delayedEventExistsLocked(ctx context.Context, delayID string) (bool, error) {
-	intent := s.intentClient()
-	if intent == nil || strings.TrimSpace(delayID) == "" {
+	if strings.TrimSpace(delayID) == "" {
 		return false, nil
 	}
-	resp, err := intent.DelayedEvents(ctx, &mautrix.ReqDelayedEvents{DelayID: id.DelayID(delayID)})
-	if err != nil {
-		return false, err
-	}
-	return resp != nil, nil
+	return s.backendLocked().Exists(ctx, delayID)
 }
 
 func (s *schedulerRuntime) cancelPendingDelayLocked(ctx context.Context, delayID string) error {
-	intent := s.intentClient()
-	if intent == nil || strings.TrimSpace(delayID) == "" {
+	if strings.TrimSpace(delayID) == "" {
 		return nil
 	}
-	_, err := intent.UpdateDelayedEvent(ctx, &mautrix.ReqUpdateDelayedEvent{
-		DelayID: id.DelayID(delayID),
-		Action:  event.DelayActionCancel,
-	})
-	return err
+	return s.backendLocked().Cancel(ctx, delayID)
 }
 
 func (s *schedulerRuntime) intentClient() schedulerDelayedEventIntent {
@@ -2,9 +2,7 @@ package connector
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"sort"
 	"strings"
 	"time"
@@ -118,7 +116,9 @@ func (oc *AIClient) streamChatCompletions(
 				state.promptTokens = chunk.Usage.PromptTokens
 				state.completionTokens = chunk.Usage.CompletionTokens
 				state.reasoningTokens = chunk.Usage.CompletionTokensDetails.ReasoningTokens
+				state.cachedTokens = chunk.Usage.PromptTokensDetails.CachedTokens
 				state.totalTokens = chunk.Usage.TotalTokens
+				oc.recordStreamUsage(ctx, portal, meta, state)
 				oc.uiEmitter(state).EmitUIMessageMetadata(ctx, portal, oc.buildUIMessageMetadata(state, meta, true))
 			}
 
@@ -288,59 +288,7 @@ func (oc *AIClient) streamChatCompletions(
 				if typingSignals != nil {
 					typingSignals.SignalToolStart()
 				}
-				// Wrap context with bridge info for tools that need it (e.g., channel-edit, react)
-				toolCtx := WithBridgeToolContext(ctx, &BridgeToolContext{
-					Client:        oc,
-					Portal:        portal,
-					Meta:          meta,
-					SourceEventID: state.sourceEventID,
-					SenderID:      state.senderID,
-				})
-
-				result := ""
-				resultStatus := ResultStatusSuccess
-				if !oc.isToolEnabled(meta, toolName) {
-					result = fmt.Sprintf("Error: tool %s is not enabled", toolName)
-					resultStatus = ResultStatusError
-				} else {
-					// Tool approval gating for dangerous builtin tools.
-					var argsObj map[string]any
-					_ = json.Unmarshal([]byte(argsJSON), &argsObj)
-					if oc.isBuiltinToolDenied(ctx, portal, state, tool, toolName, argsObj) {
-						resultStatus = ResultStatusDenied
-						result = "Denied by user"
-					}
-
-					if resultStatus != ResultStatusDenied {
-						var err error
-						result, err = oc.executeBuiltinTool(toolCtx, portal, toolName, argsJSON)
-						if err != nil {
-							log.Warn().Err(err).Str("tool", toolName).Msg("Tool execution failed (Chat Completions)")
-							result = fmt.Sprintf("Error: %s", err.Error())
-							resultStatus = ResultStatusError
-						}
-					}
-
-					result, resultStatus = oc.processToolMediaResult(ctx, log, portal, state, argsJSON, result, resultStatus, " (Chat Completions)")
-				}
-
-				// Normalize input for storage
-				var inputMap any
-				if err := json.Unmarshal([]byte(argsJSON), &inputMap); err != nil {
-					inputMap = argsJSON
-					oc.uiEmitter(state).EmitUIToolInputError(ctx, portal, tool.callID, toolName, argsJSON, "Invalid JSON tool input", false, false)
-				}
-				oc.uiEmitter(state).EmitUIToolInputAvailable(ctx, portal, tool.callID, toolName, inputMap, false)
-
-				recordCompletedToolCall(ctx, oc, portal, state, tool, toolName, argsJSON, result, resultStatus)
-
-				if resultStatus == ResultStatusSuccess {
-					collectToolOutputCitations(state, toolName, result)
-					oc.uiEmitter(state).EmitUIToolOutputAvailable(ctx, portal, tool.callID, result, tool.toolType == ToolTypeProvider, false)
-				} else if resultStatus != ResultStatusDenied {
-					oc.uiEmitter(state).EmitUIToolOutputError(ctx, portal, tool.callID, result, tool.toolType == ToolTypeProvider)
-				}
-
+				result := oc.executeStreamingToolCall(ctx, log, portal, state, meta, tool, toolName, argsJSON, " (Chat Completions)")
 				toolResults = append(toolResults, chatToolResult{callID: tool.callID, output: result})
 			}
 		}
@@ -102,6 +102,77 @@ func (oc *AIClient) processToolMediaResult(
 	return result, resultStatus
 }
 
+// executeStreamingToolCall runs a model-requested tool call (with enablement and
+// approval gating), emits the tool UI events, records the call, and returns the
+// result text to send back to the model.
+func (oc *AIClient) executeStreamingToolCall(
+	ctx context.Context,
+	log zerolog.Logger,
+	portal *bridgev2.Portal,
+	state *streamingState,
+	meta *PortalMetadata,
+	tool *activeToolCall,
+	toolName string,
+	argsJSON string,
+	logSuffix string,
+) string {
+	// Wrap context with bridge info for tools that need it (e.g., channel-edit, react)
+	toolCtx := WithBridgeToolContext(ctx, &BridgeToolContext{
+		Client:        oc,
+		Portal:        portal,
+		Meta:          meta,
+		SourceEventID: state.sourceEventID,
+		SenderID:      state.senderID,
+		ToolCallID:    tool.callID,
+	})
+
+	result := ""
+	resultStatus := ResultStatusSuccess
+	if !oc.isToolEnabled(meta, toolName) {
+		result = fmt.Sprintf("Error: tool %s is not enabled", toolName)
+		resultStatus = ResultStatusError
+	} else {
+		// Tool approval gating for dangerous builtin tools.
+		var argsObj map[string]any
+		_ = json.Unmarshal([]byte(argsJSON), &argsObj)
+		if oc.isBuiltinToolDenied(ctx, portal, state, tool, toolName, argsObj) {
+			resultStatus = ResultStatusDenied
+			result = "Denied by user"
+		}
+
+		if resultStatus != ResultStatusDenied {
+			var err error
+			result, err = oc.executeBuiltinTool(toolCtx, portal, toolName, argsJSON)
+			if err != nil {
+				log.Warn().Err(err).Str("tool", toolName).Msg("Tool execution failed" + logSuffix)
+				result = fmt.Sprintf("Error: %s", err.Error())
+				resultStatus = ResultStatusError
+			}
+		}
+
+		result, resultStatus = oc.processToolMediaResult(ctx, log, portal, state, argsJSON, result, resultStatus, logSuffix)
+	}
+
+	// Normalize input for storage
+	var inputMap any
+	if err := json.Unmarshal([]byte(argsJSON), &inputMap); err != nil {
+		inputMap = argsJSON
+		oc.uiEmitter(state).EmitUIToolInputError(ctx, portal, tool.callID, toolName, argsJSON, "Invalid JSON tool input", false, false)
+	}
+	oc.uiEmitter(state).EmitUIToolInputAvailable(ctx, portal, tool.callID, toolName, inputMap, false)
+
+	recordCompletedToolCall(ctx, oc, portal, state, tool, toolName, argsJSON, result, resultStatus)
+
+	if resultStatus == ResultStatusSuccess {
+		collectToolOutputCitations(state, toolName, result)
+		oc.uiEmitter(state).EmitUIToolOutputAvailable(ctx, portal, tool.callID, result, tool.toolType == ToolTypeProvider, false)
+	} else if resultStatus != ResultStatusDenied {
+		oc.uiEmitter(state).EmitUIToolOutputError(ctx, portal, tool.callID, result, tool.toolType == ToolTypeProvider)
+	}
+
+	return result
+}
+
 func (oc *AIClient) ensureFunctionCallTool(
 	ctx context.Context,
 	portal *bridgev2.Portal,
@@ -221,6 +292,7 @@ func (oc *AIClient) handleFunctionCallArgumentsDone(
 				Meta:          meta,
 				SourceEventID: state.sourceEventID,
 				SenderID:      state.senderID,
+				ToolCallID:    tool.callID,
 			})
 			var err error
 			result, err = oc.executeBuiltinTool(toolCtx, portal, toolName, argsJSON)
@@ -228,11 +228,20 @@ func (oc *AIClient) gateMcpToolApproval(
 		CallID:        tool.callID,
 		RequireForMCP: oc.toolApprovalsRequireForMCP(),
 	})
+	rule := oc.mcpToolArgumentRule(serverLabel, mcpToolName, parsed.Arguments)
 	needsApproval := oc.toolApprovalsRuntimeEnabled() && runtimeDecision.State == airuntime.ToolApprovalRequired && !oc.isMcpAlwaysAllowed(serverLabel, mcpToolName)
-	if needsApproval && state.heartbeat != nil {
+	if needsApproval && (state.heartbeat != nil || rule != nil) {
 		needsApproval = false
 	}
-	if needsApproval {
+	if rule != nil && rule.denies() {
+		if err := oc.approvalFlow.Resolve(approvalID, bridgeadapter.ApprovalDecisionPayload{
+			ApprovalID: approvalID,
+			Approved:   false,
+			Reason:     "denied_by_rule",
+		}); err != nil {
+			oc.loggerForContext(ctx).Warn().Err(err).Str("approval_id", approvalID).Msg("Failed to deny MCP tool call")
+		}
+	} else if needsApproval {
 		if !state.ui.UIToolApprovalRequested[approvalID] {
 			state.ui.UIToolApprovalRequested[approvalID] = true
 			oc.emitUIToolApprovalRequest(ctx, portal, state, approvalID, tool.callID, tool.toolName, tool.eventID, oc.toolApprovalsTTLSeconds())
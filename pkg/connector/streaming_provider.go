@@ -0,0 +1,310 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/shared/constant"
+	"github.com/rs/zerolog"
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/event"
+
+	"github.com/beeper/agentremote/pkg/agents/tools"
+)
+
+// nativeProvider returns the login's provider when it speaks its own wire protocol
+// rather than the OpenAI SDK (e.g. Anthropic Messages). Such providers are driven
+// through the AIProvider interface instead of oc.api.
+func (oc *AIClient) nativeProvider() AIProvider {
+	if oc == nil || oc.provider == nil {
+		return nil
+	}
+	if _, ok := oc.provider.(interface{ Client() openai.Client }); ok {
+		return nil
+	}
+	return oc.provider
+}
+
+// toolDefinitionsForTurn collects the builtin, session and boss tools for a turn
+// in the provider-neutral ToolDefinition form.
+func (oc *AIClient) toolDefinitionsForTurn(ctx context.Context, meta *PortalMetadata) []ToolDefinition {
+	defs := oc.selectedBuiltinToolsForTurn(ctx, meta)
+	if !oc.getModelCapabilitiesForMeta(meta).SupportsToolCalling || resolveAgentID(meta) == "" {
+		return defs
+	}
+	agentTools := tools.SessionTools()
+	if hasBossAgent(meta) {
+		agentTools = tools.BossTools()
+	}
+	for _, tool := range agentTools {
+		if !oc.isToolEnabled(meta, tool.Name) {
+			continue
+		}
+		defs = append(defs, ToolDefinition{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  resolveToolSchema(tool.InputSchema, tool.Name, &oc.log),
+		})
+	}
+	return defs
+}
+
+// streamProviderMessages streams a turn through a native (non-OpenAI SDK) provider,
+// running the same tool loop as streamChatCompletions.
+func (oc *AIClient) streamProviderMessages(
+	ctx context.Context,
+	evt *event.Event,
+	portal *bridgev2.Portal,
+	meta *PortalMetadata,
+	messages []openai.ChatCompletionMessageParamUnion,
+) (bool, *ContextLengthError, error) {
+	portalID := ""
+	if portal != nil {
+		portalID = string(portal.ID)
+	}
+	provider := oc.nativeProvider()
+	if provider == nil {
+		return false, nil, &PreDeltaError{Err: errors.New("native provider not available")}
+	}
+	log := zerolog.Ctx(ctx).With().
+		Str("action", "stream_provider_messages").
+		Str("provider", provider.Name()).
+		Str("portal", portalID).
+		Logger()
+
+	prep, messages, typingCleanup := oc.prepareStreamingRun(ctx, log, evt, portal, meta, messages)
+	defer typingCleanup()
+	state := prep.State
+	typingSignals := prep.TypingSignals
+	touchTyping := prep.TouchTyping
+	isHeartbeat := prep.IsHeartbeat
+
+	currentMessages := messages
+	maxToolRounds := 10
+
+	oc.emitUIStart(ctx, portal, state, meta)
+
+	for round := 0; ; round++ {
+		promptContext := ChatMessagesToPromptContext(currentMessages)
+		promptContext.Tools = oc.toolDefinitionsForTurn(ctx, meta)
+		params := GenerateParams{
+			Model:               oc.effectiveModelForAPI(meta),
+			Context:             promptContext,
+			Temperature:         oc.effectiveTemperature(meta),
+			MaxCompletionTokens: oc.effectiveMaxTokens(meta),
+			ReasoningEffort:     oc.effectiveReasoningEffort(meta),
+		}
+
+		events, err := provider.GenerateStream(ctx, params)
+		if err != nil {
+			if cle := ParseContextLengthError(err); cle != nil {
+				return false, cle, nil
+			}
+			log.Warn().Err(err).Str("model", params.Model).Msg("Provider stream init failed")
+			return false, nil, &PreDeltaError{Err: err}
+		}
+
+		var toolCalls []*activeToolCall
+		var roundContent strings.Builder
+		var streamErr error
+		state.finishReason = ""
+
+		oc.uiEmitter(state).EmitUIStepStart(ctx, portal)
+
+		for streamEvt := range events {
+			oc.markMessageSendSuccess(ctx, portal, evt, state)
+			switch streamEvt.Type {
+			case StreamEventDelta:
+				touchTyping()
+				roundContent.WriteString(streamEvt.Delta)
+				if err := oc.handleResponseOutputTextDelta(
+					ctx, log, portal, state, meta, typingSignals, isHeartbeat, streamEvt.Delta,
+					"failed to send initial streaming message",
+					"Failed to send initial streaming message",
+				); err != nil {
+					go drainStreamEvents(events)
+					return false, nil, &PreDeltaError{Err: err}
+				}
+			case StreamEventReasoning:
+				touchTyping()
+				if err := oc.handleResponseReasoningTextDelta(
+					ctx, log, portal, state, meta, isHeartbeat, streamEvt.ReasoningDelta,
+					"failed to send initial streaming message",
+					"Failed to send initial streaming message",
+				); err != nil {
+					go drainStreamEvents(events)
+					return false, nil, &PreDeltaError{Err: err}
+				}
+			case StreamEventToolCall:
+				if streamEvt.ToolCall == nil {
+					continue
+				}
+				touchTyping()
+				if typingSignals != nil {
+					typingSignals.SignalToolStart()
+				}
+				callID := strings.TrimSpace(streamEvt.ToolCall.ID)
+				if callID == "" {
+					callID = NewCallID()
+				}
+				toolName := strings.TrimSpace(streamEvt.ToolCall.Name)
+				if toolName == "" {
+					toolName = "unknown_tool"
+				}
+				tool := &activeToolCall{
+					callID:      callID,
+					toolName:    toolName,
+					toolType:    ToolTypeFunction,
+					startedAtMs: time.Now().UnixMilli(),
+				}
+				tool.input.WriteString(streamEvt.ToolCall.Arguments)
+				tool.eventID = oc.sendToolCallEvent(ctx, portal, state, tool)
+				oc.uiEmitter(state).EmitUIToolInputDelta(ctx, portal, tool.callID, tool.toolName, streamEvt.ToolCall.Arguments, false)
+				toolCalls = append(toolCalls, tool)
+			case StreamEventComplete:
+				state.finishReason = streamEvt.FinishReason
+				if streamEvt.ResponseID != "" {
+					state.responseID = streamEvt.ResponseID
+				}
+				if usage := streamEvt.Usage; usage != nil {
+					state.promptTokens = int64(usage.PromptTokens)
+					state.completionTokens = int64(usage.CompletionTokens)
+					state.reasoningTokens = int64(usage.ReasoningTokens)
+					state.cachedTokens = int64(usage.CachedTokens)
+					state.totalTokens = int64(usage.TotalTokens)
+					oc.recordStreamUsage(ctx, portal, meta, state)
+					oc.uiEmitter(state).EmitUIMessageMetadata(ctx, portal, oc.buildUIMessageMetadata(state, meta, true))
+				}
+			case StreamEventError:
+				streamErr = streamEvt.Error
+			}
+		}
+
+		oc.uiEmitter(state).EmitUIStepFinish(ctx, portal)
+
+		if streamErr == nil && ctx.Err() != nil {
+			streamErr = ctx.Err()
+		}
+		if streamErr != nil {
+			if errors.Is(streamErr, context.Canceled) {
+				state.finishReason = "cancelled"
+				state.completedAtMs = time.Now().UnixMilli()
+				oc.uiEmitter(state).EmitUIAbort(ctx, portal, "cancelled")
+				oc.emitUIFinish(ctx, portal, state, meta)
+				oc.persistTerminalAssistantTurn(ctx, log, portal, state, meta)
+				return false, nil, streamFailureError(state, streamErr)
+			}
+			if cle := ParseContextLengthError(streamErr); cle != nil {
+				return false, cle, nil
+			}
+			log.Warn().Err(streamErr).Str("model", params.Model).Msg("Provider stream failed")
+			state.finishReason = "error"
+			state.completedAtMs = time.Now().UnixMilli()
+			oc.uiEmitter(state).EmitUIError(ctx, portal, streamErr.Error())
+			oc.emitUIFinish(ctx, portal, state, meta)
+			oc.persistTerminalAssistantTurn(ctx, log, portal, state, meta)
+			return false, nil, streamFailureError(state, streamErr)
+		}
+
+		toolCallParams := make([]openai.ChatCompletionMessageToolCallUnionParam, 0, len(toolCalls))
+		toolResults := make([]openai.ChatCompletionMessageParamUnion, 0, len(toolCalls))
+		for _, tool := range toolCalls {
+			argsJSON := normalizeToolArgsJSON(tool.input.String())
+			toolCallParams = append(toolCallParams, openai.ChatCompletionMessageToolCallUnionParam{
+				OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+					ID: tool.callID,
+					Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+						Name:      tool.toolName,
+						Arguments: argsJSON,
+					},
+					Type: constant.ValueOf[constant.Function](),
+				},
+			})
+			touchTyping()
+			if typingSignals != nil {
+				typingSignals.SignalToolStart()
+			}
+			result := oc.executeStreamingToolCall(ctx, log, portal, state, meta, tool, tool.toolName, argsJSON, " ("+provider.Name()+")")
+			toolResults = append(toolResults, openai.ToolMessage(result, tool.callID))
+		}
+
+		if shouldContinueChatToolLoop(state.finishReason, len(toolCallParams)) {
+			state.needsTextSeparator = true
+			if round >= maxToolRounds {
+				log.Warn().Int("rounds", round+1).Msg("Max tool call rounds reached; stopping provider continuation")
+				break
+			}
+			assistantMsg := openai.ChatCompletionAssistantMessageParam{
+				ToolCalls: toolCallParams,
+			}
+			if content := strings.TrimSpace(roundContent.String()); content != "" {
+				assistantMsg.Content.OfString = param.NewOpt(content)
+			}
+			currentMessages = append(currentMessages, openai.ChatCompletionMessageParamUnion{OfAssistant: &assistantMsg})
+			currentMessages = append(currentMessages, toolResults...)
+			for _, item := range oc.drainSteerQueue(state.roomID) {
+				if item.pending.Type != pendingTypeText {
+					continue
+				}
+				prompt := strings.TrimSpace(item.prompt)
+				if prompt == "" {
+					prompt = strings.TrimSpace(item.pending.MessageBody)
+				}
+				if prompt == "" {
+					continue
+				}
+				currentMessages = append(currentMessages, openai.UserMessage(prompt))
+			}
+			continue
+		}
+
+		break
+	}
+
+	state.completedAtMs = time.Now().UnixMilli()
+	if state.finishReason == "" {
+		state.finishReason = "stop"
+	}
+	oc.finalizeStreamingReplyAccumulator(state)
+	oc.emitUIFinish(ctx, portal, state, meta)
+	oc.persistTerminalAssistantTurn(ctx, log, portal, state, meta)
+
+	log.Info().
+		Str("turn_id", state.turnID).
+		Str("finish_reason", state.finishReason).
+		Int("content_length", state.accumulated.Len()).
+		Int("tool_calls", len(state.toolCalls)).
+		Msg("Provider streaming finished")
+
+	oc.maybeGenerateTitle(ctx, portal, state.accumulated.String())
+	oc.recordProviderSuccess(ctx)
+	return true, nil, nil
+}
+
+// drainStreamEvents consumes the rest of a provider stream so its producer goroutine can exit.
+func drainStreamEvents(events <-chan StreamEvent) {
+	for range events {
+	}
+}
+
+// chatToolParamsToDefinitions converts Chat Completions tool params back into
+// provider-neutral tool definitions (schemas only; Execute is left unset).
+func chatToolParamsToDefinitions(params []openai.ChatCompletionToolUnionParam) []ToolDefinition {
+	var defs []ToolDefinition
+	for _, tool := range params {
+		if tool.OfFunction == nil {
+			continue
+		}
+		fn := tool.OfFunction.Function
+		defs = append(defs, ToolDefinition{
+			Name:        fn.Name,
+			Description: fn.Description.Value,
+			Parameters:  map[string]any(fn.Parameters),
+		})
+	}
+	return defs
+}
@@ -216,7 +216,9 @@ func (oc *AIClient) processResponseStreamEvent(
 			state.promptTokens = streamEvent.Response.Usage.InputTokens
 			state.completionTokens = streamEvent.Response.Usage.OutputTokens
 			state.reasoningTokens = streamEvent.Response.Usage.OutputTokensDetails.ReasoningTokens
+			state.cachedTokens = streamEvent.Response.Usage.InputTokensDetails.CachedTokens
 			state.totalTokens = streamEvent.Response.Usage.TotalTokens
+			oc.recordStreamUsage(ctx, portal, meta, state)
 		}
 		if streamEvent.Response.Status == "completed" {
 			state.finishReason = "stop"
@@ -30,6 +30,7 @@ type streamingState struct {
 	promptTokens     int64
 	completionTokens int64
 	reasoningTokens  int64
+	cachedTokens     int64
 	totalTokens      int64
 
 	baseInput              responses.ResponseInputParam
@@ -144,6 +144,10 @@ func (oc *AIClient) runSubagentCompletion(
 	meta *PortalMetadata,
 	prompt []openai.ChatCompletionMessageParamUnion,
 ) (bool, error) {
+	meta, allowed := oc.applyUsageBudget(ctx, nil, portal, meta)
+	if !allowed {
+		return false, errUsageBudgetExceeded
+	}
 	responseFn, logLabel := oc.selectResponseFn(meta, ChatMessagesToPromptContext(prompt))
 	return oc.responseWithRetry(ctx, nil, portal, meta, prompt, responseFn, logLabel)
 }
@@ -99,5 +99,9 @@ func (oc *AIClient) buildAdditionalSystemPromptsCore(
 		out = append(out, openai.SystemMessage(ident))
 	}
 
+	if resources := oc.buildMCPResourceContextPrompt(ctx, meta); resources != "" {
+		out = append(out, openai.SystemMessage(resources))
+	}
+
 	return out
 }
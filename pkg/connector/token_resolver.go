@@ -16,6 +16,8 @@ const (
 const (
 	defaultOpenAIBaseURL     = "https://api.openai.com/v1"
 	defaultOpenRouterBaseURL = "https://openrouter.ai/api/v1"
+	defaultAnthropicBaseURL  = "https://api.anthropic.com"
+	defaultLocalBaseURL      = "http://127.0.0.1:11434/v1"
 )
 
 type ServiceConfig struct {
@@ -163,6 +165,30 @@ func (oc *OpenAIConnector) resolveOpenRouterBaseURL() string {
 	return strings.TrimRight(base, "/")
 }
 
+func (oc *OpenAIConnector) resolveAnthropicBaseURL() string {
+	base := strings.TrimSpace(oc.Config.Providers.Anthropic.BaseURL)
+	if base == "" {
+		base = defaultAnthropicBaseURL
+	}
+	return strings.TrimRight(base, "/")
+}
+
+func (oc *OpenAIConnector) resolveGeminiBaseURL() string {
+	base := strings.TrimSpace(oc.Config.Providers.Gemini.BaseURL)
+	if base == "" {
+		base = defaultGoogleBaseURL
+	}
+	return strings.TrimRight(base, "/")
+}
+
+func (oc *OpenAIConnector) resolveLocalBaseURL() string {
+	base := strings.TrimSpace(oc.Config.Providers.Local.BaseURL)
+	if base == "" {
+		base = defaultLocalBaseURL
+	}
+	return strings.TrimRight(base, "/")
+}
+
 func (oc *OpenAIConnector) resolveBeeperBaseURL(meta *UserLoginMetadata) string {
 	if meta != nil {
 		base := normalizeBeeperBaseURL(meta.BaseURL)
@@ -279,6 +305,24 @@ func (oc *OpenAIConnector) resolveProviderAPIKey(meta *UserLoginMetadata) string
 		if meta.ServiceTokens != nil {
 			return trimToken(meta.ServiceTokens.OpenAI)
 		}
+	case ProviderAnthropic:
+		if key := trimToken(oc.Config.Providers.Anthropic.APIKey); key != "" {
+			return key
+		}
+		return trimToken(meta.APIKey)
+	case ProviderGemini:
+		if key := trimToken(oc.Config.Providers.Gemini.APIKey); key != "" {
+			return key
+		}
+		return trimToken(meta.APIKey)
+	case ProviderLocal:
+		if key := trimToken(oc.Config.Providers.Local.APIKey); key != "" {
+			return key
+		}
+		if key := trimToken(meta.APIKey); key != "" {
+			return key
+		}
+		return localPlaceholderAPIKey
 	default:
 		return trimToken(meta.APIKey)
 	}
@@ -150,11 +150,26 @@ func (oc *AIClient) isBuiltinToolDenied(
 	if state == nil || tool == nil {
 		return true
 	}
-	required, action := oc.builtinToolApprovalRequirement(toolName, argsObj)
-	if required && oc.isBuiltinAlwaysAllowed(toolName, action) {
+	kind, serverLabel := oc.toolCallApprovalKind(ctx, toolName)
+	required, action, rule := oc.toolApprovalRequirement(kind, serverLabel, toolName, argsObj)
+	if rule != nil && rule.denies() {
+		oc.loggerForContext(ctx).Debug().
+			Str("tool_name", toolName).
+			Str("server_label", serverLabel).
+			Str("rule_id", rule.ID).
+			Msg("tool approval: builtin tool call denied by argument rule")
+		oc.uiEmitter(state).EmitUIToolOutputDenied(ctx, portal, tool.callID)
+		return true
+	}
+	if required && !isMandatoryApprovalTool(toolName) && oc.isBuiltinAlwaysAllowed(toolName, action) {
 		required = false
 	}
 	if required && state.heartbeat != nil {
+		// Nobody is around to approve heartbeat runs.
+		if isMandatoryApprovalTool(toolName) {
+			oc.uiEmitter(state).EmitUIToolOutputDenied(ctx, portal, tool.callID)
+			return true
+		}
 		required = false
 	}
 	input := airuntime.ToolPolicyInput{
@@ -0,0 +1,380 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ToolArgumentRuleAllow = "allow"
+	ToolArgumentRuleDeny  = "deny"
+
+	ToolArgumentMatchGlob   = "glob"
+	ToolArgumentMatchRegex  = "regex"
+	ToolArgumentMatchPrefix = "prefix"
+	ToolArgumentMatchDomain = "domain"
+)
+
+var errToolArgumentRuleNotFound = errors.New("rule not found")
+
+// normalizeToolArgumentRule validates rule and fills in defaults. The ID is
+// kept as-is; addToolArgumentRule assigns one.
+func normalizeToolArgumentRule(rule ToolArgumentRule) (ToolArgumentRule, error) {
+	rule.ID = strings.TrimSpace(rule.ID)
+	rule.Effect = normalizeApprovalToken(rule.Effect)
+	rule.ToolKind = normalizeApprovalToken(rule.ToolKind)
+	rule.ServerLabel = normalizeApprovalToken(rule.ServerLabel)
+	rule.ToolName = normalizeApprovalToken(rule.ToolName)
+	rule.Argument = strings.TrimSpace(rule.Argument)
+	rule.Match = normalizeApprovalToken(rule.Match)
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+
+	switch rule.Effect {
+	case ToolArgumentRuleAllow, ToolArgumentRuleDeny:
+	default:
+		return rule, fmt.Errorf("effect must be %s or %s", ToolArgumentRuleAllow, ToolArgumentRuleDeny)
+	}
+	switch ToolApprovalKind(rule.ToolKind) {
+	case "", ToolApprovalKindBuiltin:
+	case ToolApprovalKindMCP:
+		rule.ToolName = normalizeMcpRuleToolName(rule.ToolName)
+	default:
+		return rule, fmt.Errorf("tool kind must be %s or %s", ToolApprovalKindBuiltin, ToolApprovalKindMCP)
+	}
+	if rule.ServerLabel != "" && ToolApprovalKind(rule.ToolKind) != ToolApprovalKindMCP {
+		return rule, errors.New("server label is only valid for MCP rules")
+	}
+	if rule.ToolName == "" {
+		return rule, errors.New("tool name is required")
+	}
+	for _, glob := range []string{rule.ToolName, rule.ServerLabel} {
+		if _, err := path.Match(glob, ""); err != nil {
+			return rule, fmt.Errorf("invalid glob %q", glob)
+		}
+	}
+	if rule.Argument == "" {
+		if rule.Match != "" || rule.Pattern != "" {
+			return rule, errors.New("a pattern needs an argument to match against")
+		}
+		return rule, nil
+	}
+	if rule.Match == "" {
+		rule.Match = ToolArgumentMatchGlob
+	}
+	if rule.Pattern == "" {
+		return rule, errors.New("pattern is required")
+	}
+	switch rule.Match {
+	case ToolArgumentMatchGlob:
+		if _, err := path.Match(rule.Pattern, ""); err != nil {
+			return rule, fmt.Errorf("invalid glob %q", rule.Pattern)
+		}
+	case ToolArgumentMatchRegex:
+		if _, err := compileToolArgumentRegex(rule.Pattern); err != nil {
+			return rule, fmt.Errorf("invalid regex: %w", err)
+		}
+	case ToolArgumentMatchPrefix:
+		rule.Pattern = path.Clean(rule.Pattern)
+	case ToolArgumentMatchDomain:
+		rule.Pattern = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(rule.Pattern), "*"), ".")
+		if rule.Pattern == "" {
+			return rule, errors.New("domain is required")
+		}
+	default:
+		return rule, fmt.Errorf("match must be one of %s, %s, %s or %s",
+			ToolArgumentMatchGlob, ToolArgumentMatchRegex, ToolArgumentMatchPrefix, ToolArgumentMatchDomain)
+	}
+	return rule, nil
+}
+
+func (rule ToolArgumentRule) denies() bool {
+	return rule.Effect == ToolArgumentRuleDeny
+}
+
+// matches reports whether rule applies to a call. An allow rule only matches
+// when every value of an array argument matches, a deny rule when any does.
+func (rule ToolArgumentRule) matches(kind ToolApprovalKind, serverLabel, toolName string, args map[string]any) bool {
+	if rule.ToolKind != "" && ToolApprovalKind(rule.ToolKind) != kind {
+		return false
+	}
+	if kind == ToolApprovalKindMCP {
+		toolName = normalizeMcpRuleToolName(toolName)
+		if rule.ServerLabel != "" && !globMatches(rule.ServerLabel, normalizeApprovalToken(serverLabel)) {
+			return false
+		}
+	} else {
+		toolName = normalizeApprovalToken(toolName)
+	}
+	if !globMatches(rule.ToolName, toolName) {
+		return false
+	}
+	if rule.Argument == "" {
+		return true
+	}
+	values, ok := lookupToolArgument(args, rule.Argument)
+	if !ok || len(values) == 0 {
+		return false
+	}
+	if rule.denies() {
+		return slices.ContainsFunc(values, rule.matchesValue)
+	}
+	for _, value := range values {
+		if !rule.matchesValue(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (rule ToolArgumentRule) matchesValue(value string) bool {
+	switch rule.Match {
+	case ToolArgumentMatchRegex:
+		pattern := rule.Pattern
+		if !rule.denies() {
+			// An allow regex must match the whole value, or "https://example\.com/"
+			// would also allow URLs that merely contain it.
+			pattern = `^(?:` + pattern + `)$`
+		}
+		re, err := compileToolArgumentRegex(pattern)
+		return err == nil && re.MatchString(value)
+	case ToolArgumentMatchPrefix:
+		if value == "" {
+			return false
+		}
+		value = path.Clean(value)
+		return value == rule.Pattern || strings.HasPrefix(value, strings.TrimSuffix(rule.Pattern, "/")+"/")
+	case ToolArgumentMatchDomain:
+		host := argumentHost(value)
+		return host != "" && (host == rule.Pattern || strings.HasSuffix(host, "."+rule.Pattern))
+	default:
+		return globMatches(rule.Pattern, value)
+	}
+}
+
+// toolArgumentRegexCache holds compiled regex patterns. Rules are loaded from
+// login metadata, so the compiled form can't live on the rule itself.
+var toolArgumentRegexCache sync.Map
+
+func compileToolArgumentRegex(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := toolArgumentRegexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	toolArgumentRegexCache.Store(pattern, re)
+	return re, nil
+}
+
+// globMatches matches value against a path.Match pattern, where "*" stays
+// within one "/"-separated segment and a "**" segment matches any number of
+// segments except "..". Use the prefix and domain matchers for paths and
+// hosts that need normalizing first.
+func globMatches(pattern, value string) bool {
+	if pattern == "" || pattern == "*" || pattern == "**" {
+		return true
+	}
+	if !strings.Contains(pattern, "**") {
+		ok, _ := path.Match(pattern, value)
+		return ok
+	}
+	return globSegmentsMatch(strings.Split(pattern, "/"), strings.Split(value, "/"))
+}
+
+func globSegmentsMatch(pattern, value []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(value); i++ {
+				if globSegmentsMatch(pattern[1:], value[i:]) {
+					return true
+				}
+				if i < len(value) && value[i] == ".." {
+					return false
+				}
+			}
+			return false
+		}
+		if len(value) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], value[0]); !ok {
+			return false
+		}
+		pattern, value = pattern[1:], value[1:]
+	}
+	return len(value) == 0
+}
+
+// argumentHost extracts the lowercase host from a URL or bare hostname.
+func argumentHost(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "://") {
+		value = "//" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+}
+
+// lookupToolArgument resolves a dotted path in args to its scalar values.
+// Arrays of scalars yield one value per element.
+func lookupToolArgument(args map[string]any, argPath string) ([]string, bool) {
+	var current any = args
+	for _, key := range strings.Split(argPath, ".") {
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	if list, ok := current.([]any); ok {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			value, ok := scalarArgument(item)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, value)
+		}
+		return values, true
+	}
+	value, ok := scalarArgument(current)
+	if !ok {
+		return nil, false
+	}
+	return []string{value}, true
+}
+
+func scalarArgument(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// matchToolArgumentRules returns the first matching deny rule, or failing
+// that the first matching allow rule.
+func matchToolArgumentRules(rules []ToolArgumentRule, kind ToolApprovalKind, serverLabel, toolName string, args map[string]any) *ToolArgumentRule {
+	var allowed *ToolArgumentRule
+	for _, rule := range rules {
+		if !rule.matches(kind, serverLabel, toolName, args) {
+			continue
+		}
+		if rule.denies() {
+			return &rule
+		}
+		if allowed == nil {
+			allowed = &rule
+		}
+	}
+	return allowed
+}
+
+func (oc *AIClient) toolArgumentRule(kind ToolApprovalKind, serverLabel, toolName string, args map[string]any) *ToolArgumentRule {
+	if oc == nil || oc.UserLogin == nil {
+		return nil
+	}
+	cfg := loginMetadata(oc.UserLogin).ToolApprovals
+	if cfg == nil || len(cfg.ArgumentRules) == 0 {
+		return nil
+	}
+	return matchToolArgumentRules(cfg.ArgumentRules, kind, serverLabel, toolName, args)
+}
+
+// mcpToolArgumentRule evaluates the argument rules for an MCP approval request,
+// whose arguments arrive as a JSON string.
+func (oc *AIClient) mcpToolArgumentRule(serverLabel, toolName, rawArgs string) *ToolArgumentRule {
+	var args map[string]any
+	if strings.TrimSpace(rawArgs) != "" {
+		_ = json.Unmarshal([]byte(rawArgs), &args)
+	}
+	return oc.toolArgumentRule(ToolApprovalKindMCP, serverLabel, toolName, args)
+}
+
+func (oc *AIClient) addToolArgumentRule(ctx context.Context, rule ToolArgumentRule) (ToolArgumentRule, error) {
+	rule, err := normalizeToolArgumentRule(rule)
+	if err != nil {
+		return rule, err
+	}
+	meta := loginMetadata(oc.UserLogin)
+	if meta.ToolApprovals == nil {
+		meta.ToolApprovals = &ToolApprovalsConfig{}
+	}
+	if rule.ID == "" {
+		rule.ID = strings.SplitN(uuid.NewString(), "-", 2)[0]
+	}
+	if slices.ContainsFunc(meta.ToolApprovals.ArgumentRules, func(existing ToolArgumentRule) bool { return existing.ID == rule.ID }) {
+		return rule, fmt.Errorf("rule %s already exists", rule.ID)
+	}
+	meta.ToolApprovals.ArgumentRules = append(meta.ToolApprovals.ArgumentRules, rule)
+	return rule, oc.UserLogin.Save(ctx)
+}
+
+func (oc *AIClient) removeToolArgumentRule(ctx context.Context, ruleID string) error {
+	meta := loginMetadata(oc.UserLogin)
+	if meta.ToolApprovals == nil {
+		return errToolArgumentRuleNotFound
+	}
+	ruleID = strings.TrimSpace(ruleID)
+	before := len(meta.ToolApprovals.ArgumentRules)
+	meta.ToolApprovals.ArgumentRules = slices.DeleteFunc(meta.ToolApprovals.ArgumentRules, func(rule ToolArgumentRule) bool {
+		return rule.ID == ruleID
+	})
+	if len(meta.ToolApprovals.ArgumentRules) == before {
+		return errToolArgumentRuleNotFound
+	}
+	return oc.UserLogin.Save(ctx)
+}
+
+func (oc *AIClient) toolArgumentRules() []ToolArgumentRule {
+	if oc == nil || oc.UserLogin == nil {
+		return nil
+	}
+	cfg := loginMetadata(oc.UserLogin).ToolApprovals
+	if cfg == nil {
+		return nil
+	}
+	return slices.Clone(cfg.ArgumentRules)
+}
+
+func formatToolArgumentRule(rule ToolArgumentRule) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "`%s` %s ", rule.ID, rule.Effect)
+	if rule.ToolKind == string(ToolApprovalKindMCP) {
+		server := rule.ServerLabel
+		if server == "" {
+			server = "*"
+		}
+		fmt.Fprintf(&sb, "mcp:%s:%s", server, rule.ToolName)
+	} else {
+		sb.WriteString(rule.ToolName)
+	}
+	if rule.Argument != "" {
+		fmt.Fprintf(&sb, " when %s %s `%s`", rule.Argument, rule.Match, rule.Pattern)
+	}
+	return sb.String()
+}
@@ -0,0 +1,213 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/database"
+)
+
+func mustToolArgumentRule(t *testing.T, rule ToolArgumentRule) ToolArgumentRule {
+	t.Helper()
+	normalized, err := normalizeToolArgumentRule(rule)
+	if err != nil {
+		t.Fatalf("normalizeToolArgumentRule(%#v) returned error: %v", rule, err)
+	}
+	return normalized
+}
+
+func TestToolArgumentRuleMatchers(t *testing.T) {
+	cases := []struct {
+		name  string
+		rule  ToolArgumentRule
+		args  map[string]any
+		match bool
+	}{
+		{"domain exact", ToolArgumentRule{Match: "domain", Pattern: "example.com"}, map[string]any{"url": "https://example.com/a"}, true},
+		{"domain subdomain", ToolArgumentRule{Match: "domain", Pattern: "*.example.com"}, map[string]any{"url": "https://docs.Example.com/a"}, true},
+		{"domain lookalike", ToolArgumentRule{Match: "domain", Pattern: "example.com"}, map[string]any{"url": "https://badexample.com/"}, false},
+		{"domain userinfo", ToolArgumentRule{Match: "domain", Pattern: "example.com"}, map[string]any{"url": "https://example.com@evil.test/"}, false},
+		{"prefix inside", ToolArgumentRule{Match: "prefix", Pattern: "/srv/data/"}, map[string]any{"url": "/srv/data/a/b.txt"}, true},
+		{"prefix traversal", ToolArgumentRule{Match: "prefix", Pattern: "/srv/data"}, map[string]any{"url": "/srv/data/../secret"}, false},
+		{"prefix sibling", ToolArgumentRule{Match: "prefix", Pattern: "/srv/data"}, map[string]any{"url": "/srv/database"}, false},
+		{"glob", ToolArgumentRule{Pattern: "!*:example.com"}, map[string]any{"url": "!room:example.com"}, true},
+		{"glob star stays in segment", ToolArgumentRule{Pattern: "https://example.com/*"}, map[string]any{"url": "https://example.com/a/b"}, false},
+		{"glob double star", ToolArgumentRule{Pattern: "https://example.com/**"}, map[string]any{"url": "https://example.com/a/b"}, true},
+		{"glob double star middle", ToolArgumentRule{Pattern: "/srv/**/*.txt"}, map[string]any{"url": "/srv/a/b/c.txt"}, true},
+		{"glob double star empty", ToolArgumentRule{Pattern: "/srv/**/*.txt"}, map[string]any{"url": "/srv/c.txt"}, true},
+		{"glob double star traversal", ToolArgumentRule{Pattern: "/srv/**"}, map[string]any{"url": "/srv/../etc/passwd"}, false},
+		{"regex", ToolArgumentRule{Match: "regex", Pattern: `^https://(a|b)\.test/.*`}, map[string]any{"url": "https://b.test/x"}, true},
+		{"regex allow is anchored", ToolArgumentRule{Match: "regex", Pattern: `https://example\.com/.*`}, map[string]any{"url": "https://evil.test/?u=https://example.com/"}, false},
+		{"regex allow needs full match", ToolArgumentRule{Match: "regex", Pattern: `https://example\.com/`}, map[string]any{"url": "https://example.com/a"}, false},
+		{"missing argument", ToolArgumentRule{Pattern: "*"}, map[string]any{"other": "x"}, false},
+		{"array all match", ToolArgumentRule{Match: "domain", Pattern: "example.com"}, map[string]any{"url": []any{"https://example.com", "https://a.example.com"}}, true},
+		{"array partial match", ToolArgumentRule{Match: "domain", Pattern: "example.com"}, map[string]any{"url": []any{"https://example.com", "https://evil.test"}}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.rule.Effect, tc.rule.ToolName, tc.rule.Argument = ToolArgumentRuleAllow, "web_fetch", "url"
+			rule := mustToolArgumentRule(t, tc.rule)
+			if got := rule.matches(ToolApprovalKindBuiltin, "", "web_fetch", tc.args); got != tc.match {
+				t.Fatalf("expected match=%v, got %v", tc.match, got)
+			}
+		})
+	}
+}
+
+func TestToolArgumentRuleDenyMatchesAnyArrayValue(t *testing.T) {
+	rule := mustToolArgumentRule(t, ToolArgumentRule{Effect: "deny", ToolName: "web_fetch", Argument: "url", Match: "domain", Pattern: "evil.test"})
+	if !rule.matches(ToolApprovalKindBuiltin, "", "web_fetch", map[string]any{"url": []any{"https://ok.test", "https://evil.test"}}) {
+		t.Fatal("expected deny rule to match when any value matches")
+	}
+}
+
+func TestToolArgumentRuleDenyRegexMatchesAnywhere(t *testing.T) {
+	rule := mustToolArgumentRule(t, ToolArgumentRule{Effect: "deny", ToolName: "web_fetch", Argument: "url", Match: "regex", Pattern: `evil\.test`})
+	if !rule.matches(ToolApprovalKindBuiltin, "", "web_fetch", map[string]any{"url": "https://ok.test/?u=https://evil.test/"}) {
+		t.Fatal("expected deny regex to match anywhere in the value")
+	}
+}
+
+func TestNormalizeToolArgumentRuleRejectsInvalid(t *testing.T) {
+	for _, rule := range []ToolArgumentRule{
+		{Effect: "maybe", ToolName: "web_fetch"},
+		{Effect: "allow"},
+		{Effect: "allow", ToolName: "web_fetch", Argument: "url"},
+		{Effect: "allow", ToolName: "web_fetch", Argument: "url", Match: "regex", Pattern: "("},
+		{Effect: "allow", ToolName: "web_fetch", Argument: "url", Match: "fuzzy", Pattern: "x"},
+		{Effect: "allow", ToolName: "web_fetch", ServerLabel: "docs"},
+		{Effect: "allow", ToolName: "web_fetch", Pattern: "x"},
+	} {
+		if _, err := normalizeToolArgumentRule(rule); err == nil {
+			t.Fatalf("expected %#v to be rejected", rule)
+		}
+	}
+}
+
+func TestMatchToolArgumentRulesDenyWins(t *testing.T) {
+	rules := []ToolArgumentRule{
+		mustToolArgumentRule(t, ToolArgumentRule{ID: "a", Effect: "allow", ToolKind: "mcp", ToolName: "read_*"}),
+		mustToolArgumentRule(t, ToolArgumentRule{ID: "d", Effect: "deny", ToolKind: "mcp", ServerLabel: "files", ToolName: "read_file", Argument: "path", Match: "prefix", Pattern: "/etc"}),
+	}
+	args := map[string]any{"path": "/etc/passwd"}
+	if rule := matchToolArgumentRules(rules, ToolApprovalKindMCP, "Files", "mcp.read_file", args); rule == nil || rule.ID != "d" {
+		t.Fatalf("expected deny rule, got %#v", rule)
+	}
+	if rule := matchToolArgumentRules(rules, ToolApprovalKindMCP, "other", "read_file", args); rule == nil || rule.ID != "a" {
+		t.Fatalf("expected allow rule for other server, got %#v", rule)
+	}
+	if rule := matchToolArgumentRules(rules, ToolApprovalKindBuiltin, "", "read_file", args); rule != nil {
+		t.Fatalf("expected MCP rules to skip builtin tools, got %#v", rule)
+	}
+}
+
+func TestBuiltinToolApprovalRequirementUsesArgumentRules(t *testing.T) {
+	enabled := true
+	oc := &AIClient{
+		connector: &OpenAIConnector{Config: Config{ToolApprovals: &ToolApprovalsRuntimeConfig{Enabled: &enabled, RequireForTools: []string{"message"}}}},
+		UserLogin: &bridgev2.UserLogin{UserLogin: &database.UserLogin{Metadata: &UserLoginMetadata{ToolApprovals: &ToolApprovalsConfig{
+			ArgumentRules: []ToolArgumentRule{
+				mustToolArgumentRule(t, ToolArgumentRule{ID: "rooms", Effect: "allow", ToolName: "message", Argument: "target", Pattern: "!*:example.com"}),
+				mustToolArgumentRule(t, ToolArgumentRule{ID: "no-delete", Effect: "deny", ToolName: "message", Argument: "action", Pattern: "delete"}),
+			},
+		}}}},
+	}
+
+	required, _, rule := oc.builtinToolApprovalRequirement("message", map[string]any{"action": "send", "target": "!a:example.com"})
+	if required || rule == nil || rule.ID != "rooms" {
+		t.Fatalf("expected allow rule to skip approval, got required=%v rule=%#v", required, rule)
+	}
+	required, action, rule := oc.builtinToolApprovalRequirement("message", map[string]any{"action": "send", "target": "!a:other.com"})
+	if !required || action != "send" || rule != nil {
+		t.Fatalf("expected approval for other rooms, got required=%v action=%q rule=%#v", required, action, rule)
+	}
+	_, _, rule = oc.builtinToolApprovalRequirement("message", map[string]any{"action": "delete", "target": "!a:example.com"})
+	if rule == nil || !rule.denies() {
+		t.Fatalf("expected deny rule to win, got %#v", rule)
+	}
+}
+
+func TestParseToolArgumentRuleArgs(t *testing.T) {
+	rule, err := parseToolArgumentRuleArgs([]string{"allow", "web_fetch", "url", "domain", "example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.ToolKind != "builtin" || rule.Argument != "url" || rule.Match != "domain" || rule.Pattern != "example.com" {
+		t.Fatalf("unexpected rule %#v", rule)
+	}
+
+	rule, err = parseToolArgumentRuleArgs([]string{"deny", "mcp:files:read_file", "path", "regex", `^/etc/(passwd|shadow)$`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.ToolKind != "mcp" || rule.ServerLabel != "files" || rule.ToolName != "read_file" || rule.Match != "regex" {
+		t.Fatalf("unexpected MCP rule %#v", rule)
+	}
+
+	rule, err = parseToolArgumentRuleArgs([]string{"allow", "message", "target", "!room:example.com"})
+	if err != nil || rule.Match != "glob" || rule.Pattern != "!room:example.com" {
+		t.Fatalf("expected glob default, got %#v (%v)", rule, err)
+	}
+
+	if _, err = parseToolArgumentRuleArgs([]string{"allow", "web_fetch", "url"}); err == nil {
+		t.Fatal("expected missing pattern error")
+	}
+}
+
+func TestAllowRulesDontWaiveMandatoryApproval(t *testing.T) {
+	disabled := false
+	oc := &AIClient{
+		connector: &OpenAIConnector{Config: Config{ToolApprovals: &ToolApprovalsRuntimeConfig{Enabled: &disabled}}},
+		UserLogin: &bridgev2.UserLogin{UserLogin: &database.UserLogin{Metadata: &UserLoginMetadata{ToolApprovals: &ToolApprovalsConfig{
+			ArgumentRules: []ToolArgumentRule{
+				mustToolArgumentRule(t, ToolArgumentRule{ID: "exec", Effect: "allow", ToolName: "exec"}),
+				mustToolArgumentRule(t, ToolArgumentRule{ID: "any", Effect: "allow", ToolName: "*"}),
+				mustToolArgumentRule(t, ToolArgumentRule{ID: "no-rm", Effect: "deny", ToolName: "exec", Argument: "command", Pattern: "rm *"}),
+			},
+			BuiltinAlwaysAllow: []BuiltinAlwaysAllowRule{{ToolName: "exec"}, {ToolName: "process"}},
+		}}}},
+	}
+
+	required, action, rule := oc.builtinToolApprovalRequirement("exec", map[string]any{"command": "ls"})
+	if !required || action != "run" || rule != nil {
+		t.Fatalf("expected allow exec rule to still require approval, got required=%v action=%q rule=%#v", required, action, rule)
+	}
+	required, _, _ = oc.builtinToolApprovalRequirement("process", map[string]any{"action": "write", "sessionId": "a"})
+	if !required {
+		t.Fatal("expected process write to require approval despite allow rules")
+	}
+	_, _, rule = oc.builtinToolApprovalRequirement("exec", map[string]any{"command": "rm -rf build"})
+	if rule == nil || !rule.denies() {
+		t.Fatalf("expected deny rule to still apply to exec, got %#v", rule)
+	}
+}
+
+func TestBridgeMCPToolCallsUseMCPRules(t *testing.T) {
+	oc := testAIClientWithMCPServers(map[string]MCPServerConfig{
+		"files": {Endpoint: "https://files.example.com/mcp", AuthType: "none", Connected: true},
+	})
+	oc.mcpToolSet = map[string]struct{}{"read_file": {}}
+	oc.mcpToolServer = map[string]string{"read_file": "files"}
+	oc.mcpTools = []ToolDefinition{{Name: "read_file"}}
+	oc.mcpToolsFetchedAt = time.Now()
+	loginMetadata(oc.UserLogin).ToolApprovals = &ToolApprovalsConfig{ArgumentRules: []ToolArgumentRule{
+		mustToolArgumentRule(t, ToolArgumentRule{ID: "no-etc", Effect: "deny", ToolKind: "mcp", ServerLabel: "files", ToolName: "read_file", Argument: "path", Match: "prefix", Pattern: "/etc"}),
+	}}
+
+	kind, serverLabel := oc.toolCallApprovalKind(context.Background(), "read_file")
+	if kind != ToolApprovalKindMCP || serverLabel != "files" {
+		t.Fatalf("expected MCP tool of server files, got %q %q", kind, serverLabel)
+	}
+	_, _, rule := oc.toolApprovalRequirement(kind, serverLabel, "read_file", map[string]any{"path": "/etc/passwd"})
+	if rule == nil || rule.ID != "no-etc" {
+		t.Fatalf("expected deny rule to block the bridge-side MCP call, got %#v", rule)
+	}
+	if _, _, rule = oc.toolApprovalRequirement(kind, serverLabel, "read_file", map[string]any{"path": "/srv/a"}); rule != nil {
+		t.Fatalf("expected no rule for other paths, got %#v", rule)
+	}
+	if kind, _ = oc.toolCallApprovalKind(context.Background(), "write"); kind != ToolApprovalKindBuiltin {
+		t.Fatalf("expected tools not served over MCP to stay builtin, got %q", kind)
+	}
+}
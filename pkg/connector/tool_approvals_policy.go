@@ -1,16 +1,61 @@
 package connector
 
 import (
+	"context"
 	"strings"
 
 	"github.com/beeper/agentremote/pkg/shared/maputil"
 )
 
-func (oc *AIClient) builtinToolApprovalRequirement(toolName string, args map[string]any) (required bool, action string) {
-	if oc == nil || !oc.toolApprovalsRuntimeEnabled() {
-		return false, ""
+// builtinToolApprovalRequirement reports whether a builtin tool call needs
+// approval. rule is the argument rule that decided it, if any; a deny rule
+// means the call must not run at all, even with approvals turned off. Allow
+// rules can't waive the approval of mandatory tools.
+func (oc *AIClient) builtinToolApprovalRequirement(toolName string, args map[string]any) (required bool, action string, rule *ToolArgumentRule) {
+	return oc.toolApprovalRequirement(ToolApprovalKindBuiltin, "", toolName, args)
+}
+
+// toolCallApprovalKind reports how argument rules see a tool call the bridge
+// executes. Calls routed to a login-configured MCP server are MCP tools of
+// that server, so mcp:<server>:<tool> rules apply to them as they do to MCP
+// tools the provider runs.
+func (oc *AIClient) toolCallApprovalKind(ctx context.Context, toolName string) (ToolApprovalKind, string) {
+	if !oc.shouldUseMCPTool(ctx, toolName) {
+		return ToolApprovalKindBuiltin, ""
+	}
+	server, ok := oc.mcpServerForTool(ctx, toolName)
+	if !ok {
+		return ToolApprovalKindBuiltin, ""
+	}
+	return ToolApprovalKindMCP, server.Name
+}
+
+// toolApprovalRequirement is builtinToolApprovalRequirement for a call of any
+// kind; MCP calls are matched against rules by server label and tool name.
+func (oc *AIClient) toolApprovalRequirement(kind ToolApprovalKind, serverLabel, toolName string, args map[string]any) (required bool, action string, rule *ToolArgumentRule) {
+	if oc == nil {
+		return false, "", nil
 	}
 	toolName = strings.TrimSpace(toolName)
+	rule = oc.toolArgumentRule(kind, serverLabel, toolName, args)
+	if rule != nil && rule.denies() {
+		return false, "", rule
+	}
+	if isMandatoryApprovalTool(toolName) {
+		required, action = mandatoryToolApprovalRequirement(toolName, args)
+		return required, action, nil
+	}
+	if rule != nil {
+		return false, "", rule
+	}
+	required, action = oc.builtinToolApprovalDefault(toolName, args)
+	return required, action, nil
+}
+
+func (oc *AIClient) builtinToolApprovalDefault(toolName string, args map[string]any) (required bool, action string) {
+	if !oc.toolApprovalsRuntimeEnabled() {
+		return false, ""
+	}
 	if toolName == "" || !oc.toolApprovalsRequireForTool(toolName) {
 		return false, ""
 	}
@@ -33,7 +78,33 @@ func (oc *AIClient) builtinToolApprovalRequirement(toolName string, args map[str
 		switch toolName {
 		case ToolNameWrite, ToolNameEdit, ToolNameApplyPatch:
 			return true, "workspace"
+		case ToolNameFileHistory:
+			if normalizeApprovalToken(maputil.StringArg(args, "action")) != "restore" {
+				return false, ""
+			}
+			return true, "workspace"
 		}
 		return true, ""
 	}
 }
+
+// isMandatoryApprovalTool reports whether a tool needs approval even when
+// approvals are disabled, the tool isn't listed in requireForTools, or an
+// always-allow or argument allow rule matches it.
+func isMandatoryApprovalTool(toolName string) bool {
+	return toolName == ToolNameExec || toolName == ToolNameProcess
+}
+
+func mandatoryToolApprovalRequirement(toolName string, args map[string]any) (required bool, action string) {
+	switch toolName {
+	case ToolNameExec:
+		return true, "run"
+	case ToolNameProcess:
+		// Reading output and stopping commands are harmless; stdin can drive a shell.
+		if normalizeApprovalToken(maputil.StringArg(args, "action")) == "write" {
+			return true, "write"
+		}
+		return false, ""
+	}
+	return false, ""
+}
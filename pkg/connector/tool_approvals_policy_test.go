@@ -5,7 +5,7 @@ import "testing"
 func TestBuiltinToolApprovalRequirement_Write_DoesNotRequireApproval(t *testing.T) {
 	oc := &AIClient{connector: &OpenAIConnector{}}
 
-	required, action := oc.builtinToolApprovalRequirement("write", map[string]any{"path": "notes/a.txt"})
+	required, action, _ := oc.builtinToolApprovalRequirement("write", map[string]any{"path": "notes/a.txt"})
 	if required {
 		t.Fatalf("expected required=false for write")
 	}
@@ -17,7 +17,7 @@ func TestBuiltinToolApprovalRequirement_Write_DoesNotRequireApproval(t *testing.
 func TestBuiltinToolApprovalRequirement_Edit_DoesNotRequireApproval(t *testing.T) {
 	oc := &AIClient{connector: &OpenAIConnector{}}
 
-	required, action := oc.builtinToolApprovalRequirement("edit", map[string]any{"path": "notes/a.txt"})
+	required, action, _ := oc.builtinToolApprovalRequirement("edit", map[string]any{"path": "notes/a.txt"})
 	if required {
 		t.Fatalf("expected required=false for edit")
 	}
@@ -29,7 +29,7 @@ func TestBuiltinToolApprovalRequirement_Edit_DoesNotRequireApproval(t *testing.T
 func TestBuiltinToolApprovalRequirement_ApplyPatch_DoesNotRequireApproval(t *testing.T) {
 	oc := &AIClient{connector: &OpenAIConnector{}}
 
-	required, action := oc.builtinToolApprovalRequirement("apply_patch", map[string]any{
+	required, action, _ := oc.builtinToolApprovalRequirement("apply_patch", map[string]any{
 		"input": "*** Begin Patch\n*** End Patch",
 	})
 	if required {
@@ -43,7 +43,7 @@ func TestBuiltinToolApprovalRequirement_ApplyPatch_DoesNotRequireApproval(t *tes
 func TestBuiltinToolApprovalRequirement_MessageDesktopReadOnlyDoesNotRequireApproval(t *testing.T) {
 	oc := &AIClient{connector: &OpenAIConnector{}}
 
-	required, action := oc.builtinToolApprovalRequirement("message", map[string]any{"action": "desktop-search-chats"})
+	required, action, _ := oc.builtinToolApprovalRequirement("message", map[string]any{"action": "desktop-search-chats"})
 	if required {
 		t.Fatalf("expected required=false for desktop-search-chats")
 	}
@@ -55,7 +55,7 @@ func TestBuiltinToolApprovalRequirement_MessageDesktopReadOnlyDoesNotRequireAppr
 func TestBuiltinToolApprovalRequirement_MessageDesktopSendRequiresApproval(t *testing.T) {
 	oc := &AIClient{connector: &OpenAIConnector{}}
 
-	required, action := oc.builtinToolApprovalRequirement("message", map[string]any{"action": "send"})
+	required, action, _ := oc.builtinToolApprovalRequirement("message", map[string]any{"action": "send"})
 	if !required {
 		t.Fatalf("expected required=true for send")
 	}
@@ -67,7 +67,7 @@ func TestBuiltinToolApprovalRequirement_MessageDesktopSendRequiresApproval(t *te
 func TestBuiltinToolApprovalRequirement_MessageDesktopCreateChatRequiresApproval(t *testing.T) {
 	oc := &AIClient{connector: &OpenAIConnector{}}
 
-	required, action := oc.builtinToolApprovalRequirement("message", map[string]any{"action": "desktop-create-chat"})
+	required, action, _ := oc.builtinToolApprovalRequirement("message", map[string]any{"action": "desktop-create-chat"})
 	if !required {
 		t.Fatalf("expected required=true for desktop-create-chat")
 	}
@@ -75,3 +75,20 @@ func TestBuiltinToolApprovalRequirement_MessageDesktopCreateChatRequiresApproval
 		t.Fatalf("expected action=desktop-create-chat, got %q", action)
 	}
 }
+
+func TestBuiltinToolApprovalRequirement_ExecAlwaysRequiresApproval(t *testing.T) {
+	disabled := false
+	oc := &AIClient{connector: &OpenAIConnector{Config: Config{ToolApprovals: &ToolApprovalsRuntimeConfig{Enabled: &disabled}}}}
+
+	required, action, _ := oc.builtinToolApprovalRequirement("exec", map[string]any{"command": "ls"})
+	if !required || action != "run" {
+		t.Fatalf("expected exec to require approval with approvals disabled, got required=%v action=%q", required, action)
+	}
+	required, action, _ = oc.builtinToolApprovalRequirement("process", map[string]any{"action": "write", "sessionId": "a"})
+	if !required || action != "write" {
+		t.Fatalf("expected process write to require approval, got required=%v action=%q", required, action)
+	}
+	if required, _, _ = oc.builtinToolApprovalRequirement("process", map[string]any{"action": "poll", "sessionId": "a"}); required {
+		t.Fatalf("expected process poll not to require approval")
+	}
+}
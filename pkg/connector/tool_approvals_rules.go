@@ -134,7 +134,7 @@ func (oc *AIClient) persistAlwaysAllow(ctx context.Context, pending *pendingTool
 	case ToolApprovalKindBuiltin:
 		tn := normalizeApprovalToken(pending.RuleToolName)
 		act := normalizeApprovalToken(pending.Action)
-		if tn == "" {
+		if tn == "" || isMandatoryApprovalTool(tn) {
 			return nil
 		}
 		for _, rule := range meta.ToolApprovals.BuiltinAlwaysAllow {
@@ -64,12 +64,19 @@ func effectiveToolConfig[T any](
 func (oc *AIClient) isWebSearchConfigured(ctx context.Context) (bool, string) {
 	cfg := oc.effectiveSearchConfig(ctx)
 	// Mirrors pkg/search/router.go provider registration requirements.
-	if strings.TrimSpace(cfg.Exa.APIKey) != "" {
-		if stringutil.BoolPtrOr(cfg.Exa.Enabled, true) {
-			return true, ""
-		}
+	if strings.TrimSpace(cfg.Exa.APIKey) != "" && stringutil.BoolPtrOr(cfg.Exa.Enabled, true) {
+		return true, ""
+	}
+	if strings.TrimSpace(cfg.Brave.APIKey) != "" && stringutil.BoolPtrOr(cfg.Brave.Enabled, true) {
+		return true, ""
+	}
+	if strings.TrimSpace(cfg.Perplexity.APIKey) != "" && stringutil.BoolPtrOr(cfg.Perplexity.Enabled, true) {
+		return true, ""
+	}
+	if strings.TrimSpace(cfg.SearXNG.BaseURL) != "" && stringutil.BoolPtrOr(cfg.SearXNG.Enabled, true) {
+		return true, ""
 	}
-	return false, "Web search is not configured (missing Exa API key)"
+	return false, "Web search is not configured (missing Exa, Brave or Perplexity API key, or SearXNG base URL)"
 }
 
 func (oc *AIClient) isWebFetchConfigured(ctx context.Context) (bool, string) {
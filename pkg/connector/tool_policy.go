@@ -79,11 +79,19 @@ func (oc *AIClient) isToolAvailable(meta *PortalMetadata, toolName string) (bool
 			return false, source, reason
 		}
 	}
+	if toolName == ToolNameExec || toolName == ToolNameProcess {
+		if ok, reason := oc.execAvailability(); !ok {
+			return false, SourceGlobalDefault, reason
+		}
+	}
 	if toolName == ToolNameImage {
 		if model, _ := oc.resolveVisionModelForImage(context.Background(), meta); model == "" {
 			return false, SourceModelLimit, "No vision-capable model available"
 		}
 	}
+	if toolName == ToolNameMCPResource && !oc.isMCPConfigured() {
+		return false, SourceProviderLimit, "No MCP servers connected"
+	}
 	if oc.hasCachedMCPTool(toolName) {
 		if !oc.isMCPConfigured() {
 			return false, SourceProviderLimit, "MCP tool bridge is not configured"
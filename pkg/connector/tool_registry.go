@@ -23,10 +23,14 @@ func builtinToolExecutors() map[string]toolExecutor {
 		ToolNameApplyPatch:         executeApplyPatch,
 		ToolNameWrite:              executeWriteFile,
 		ToolNameEdit:               executeEditFile,
+		ToolNameFileHistory:        executeFileHistory,
+		ToolNameExec:               executeExec,
+		ToolNameProcess:            executeProcess,
 		ToolNameGravatarFetch:      executeGravatarFetch,
 		ToolNameGravatarSet:        executeGravatarSet,
 		ToolNameBeeperDocs:         executeBeeperDocs,
 		ToolNameBeeperSendFeedback: executeBeeperSendFeedback,
+		ToolNameMCPResource:        executeMCPResource,
 	}
 }
 
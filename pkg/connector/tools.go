@@ -24,6 +24,7 @@ import (
 	integrationruntime "github.com/beeper/agentremote/pkg/integrations/runtime"
 	runtimeparse "github.com/beeper/agentremote/pkg/runtime"
 	"github.com/beeper/agentremote/pkg/shared/calc"
+	"github.com/beeper/agentremote/pkg/shared/httputil"
 	"github.com/beeper/agentremote/pkg/shared/maputil"
 	"github.com/beeper/agentremote/pkg/shared/media"
 	"github.com/beeper/agentremote/pkg/shared/stringutil"
@@ -40,7 +41,7 @@ import (
 // ToolDefinition defines a tool that can be used by the AI.
 type ToolDefinition = integrationruntime.ToolDefinition
 
-var imageFetchHTTPClient = &http.Client{Timeout: 30 * time.Second}
+var imageFetchHTTPClient = httputil.NewSafeClient(httputil.SafeClientOptions{Timeout: 30 * time.Second, MaxRedirects: 5})
 var openRouterImageHTTPClient = &http.Client{Timeout: 120 * time.Second}
 var openAITTSHTTPClient = &http.Client{Timeout: 30 * time.Second}
 
@@ -58,6 +59,7 @@ type BridgeToolContext struct {
 	Meta          *PortalMetadata
 	SourceEventID id.EventID // The triggering message's event ID (for reactions/replies)
 	SenderID      string     // The triggering sender ID (owner-only tool gating)
+	ToolCallID    string     // The tool call being executed, if any (file revision attribution)
 }
 
 type bridgeToolContextKey struct{}
@@ -107,6 +109,10 @@ const (
 	ToolNameApplyPatch         = toolspec.ApplyPatchName
 	ToolNameWrite              = toolspec.WriteName
 	ToolNameEdit               = toolspec.EditName
+	ToolNameFileHistory        = toolspec.FileHistoryName
+	ToolNameExec               = toolspec.ExecName
+	ToolNameProcess            = toolspec.ProcessName
+	ToolNameMCPResource        = toolspec.MCPResourceName
 )
 
 const ImageResultPrefix = "IMAGE:"
@@ -1615,17 +1621,26 @@ func textFSStore(ctx context.Context) (*textfs.Store, error) {
 	if btc == nil {
 		return nil, errors.New("file tool requires bridge context")
 	}
-	meta := portalMeta(btc.Portal)
+	store, err := textFSStoreForPortal(btc.Client, btc.Portal)
+	if err != nil {
+		return nil, err
+	}
+	return store.WithAuthor(textfs.Author{Kind: textfs.AuthorAgent, ToolCallID: btc.ToolCallID}), nil
+}
+
+// textFSStoreForPortal returns the file store of the agent that owns portal.
+func textFSStoreForPortal(client *AIClient, portal *bridgev2.Portal) (*textfs.Store, error) {
+	meta := portalMeta(portal)
 	agentID := resolveAgentID(meta)
 	if agentID == "" {
 		agentID = "default"
 	}
-	db := btc.Client.bridgeDB()
+	db := client.bridgeDB()
 	if db == nil {
 		return nil, errors.New("file tool database unavailable")
 	}
-	bridgeID := string(btc.Client.UserLogin.Bridge.DB.BridgeID)
-	loginID := string(btc.Client.UserLogin.ID)
+	bridgeID := string(client.UserLogin.Bridge.DB.BridgeID)
+	loginID := string(client.UserLogin.ID)
 	return textfs.NewStore(db, bridgeID, loginID, agentID), nil
 }
 
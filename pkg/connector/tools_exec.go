@@ -0,0 +1,208 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/beeper/agentremote/pkg/shared/maputil"
+	"github.com/beeper/agentremote/pkg/textfs"
+)
+
+// execTarget resolves the client, agent and agent directory of a tool call.
+func execTarget(ctx context.Context) (*AIClient, string, string, error) {
+	btc := GetBridgeToolContext(ctx)
+	if btc == nil || btc.Client == nil || btc.Client.UserLogin == nil {
+		return nil, "", "", errors.New("exec requires bridge context")
+	}
+	oc := btc.Client
+	if ok, reason := oc.execAvailability(); !ok {
+		return nil, "", "", errors.New(reason)
+	}
+	agentID := resolveAgentID(btc.Meta)
+	if agentID == "" {
+		agentID = "default"
+	}
+	agentDir := execAgentDir(oc.execConfig().WorkspaceRoot, string(oc.UserLogin.ID), agentID)
+	return oc, agentID, agentDir, nil
+}
+
+func executeExec(ctx context.Context, args map[string]any) (string, error) {
+	oc, agentID, agentDir, err := execTarget(ctx)
+	if err != nil {
+		return "", err
+	}
+	cfg := oc.execConfig()
+	command := strings.TrimSpace(maputil.StringArg(args, "command"))
+	if command == "" {
+		return "", errors.New("missing or invalid 'command' argument")
+	}
+	if err = os.MkdirAll(agentDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create agent workspace: %w", err)
+	}
+	workdir, err := resolveExecWorkdir(agentDir, maputil.StringArg(args, "workdir"))
+	if err != nil {
+		return "", err
+	}
+	if info, statErr := os.Stat(workdir); statErr != nil || !info.IsDir() {
+		return "", fmt.Errorf("workdir %q does not exist", maputil.StringArg(args, "workdir"))
+	}
+	extraEnv := map[string]string{}
+	if raw, ok := args["env"].(map[string]any); ok {
+		for key, value := range raw {
+			if str, ok := value.(string); ok {
+				extraEnv[key] = str
+			}
+		}
+	}
+	env, err := execEnv(agentDir, extraEnv)
+	if err != nil {
+		return "", err
+	}
+
+	background, _ := args["background"].(bool)
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if background {
+		timeout = time.Duration(cfg.MaxTimeoutSeconds) * time.Second
+	}
+	if seconds, ok := maputil.IntArg(args, "timeout"); ok && seconds > 0 {
+		timeout = time.Duration(min(seconds, cfg.MaxTimeoutSeconds)) * time.Second
+	}
+	yieldMs, hasYield := maputil.IntArg(args, "yieldMs")
+
+	session, err := oc.execSessions.Start(oc.backgroundContext(ctx), execStartParams{
+		AgentID:     agentID,
+		Command:     command,
+		Workdir:     workdir,
+		Timeout:     timeout,
+		MaxSessions: cfg.MaxSessions,
+		Build: func(ctx context.Context) (*exec.Cmd, error) {
+			return buildExecCommand(ctx, cfg, agentDir, workdir, command, env)
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if background {
+		return jsonActionResult("exec", map[string]any{
+			"sessionId": session.ID,
+			"status":    execStatusRunning,
+			"message":   "Started in the background. Use process poll with this sessionId for output.",
+		})
+	}
+
+	var yield <-chan time.Time
+	if hasYield && yieldMs > 0 {
+		timer := time.NewTimer(time.Duration(yieldMs) * time.Millisecond)
+		defer timer.Stop()
+		yield = timer.C
+	}
+	select {
+	case <-session.done:
+	case <-yield:
+		return execSessionResult("exec", session, session.Poll(), cfg.MaxOutputBytes, map[string]any{
+			"message": "Still running in the background. Use process poll with this sessionId for more output.",
+		})
+	case <-ctx.Done():
+		// The turn was stopped; don't leave the command running unattended.
+		session.Kill()
+		return "", ctx.Err()
+	}
+	output, _ := session.Output()
+	return execSessionResult("exec", session, output, cfg.MaxOutputBytes, nil)
+}
+
+func executeProcess(ctx context.Context, args map[string]any) (string, error) {
+	oc, agentID, _, err := execTarget(ctx)
+	if err != nil {
+		return "", err
+	}
+	cfg := oc.execConfig()
+	action := normalizeApprovalToken(maputil.StringArg(args, "action"))
+	if action == "list" {
+		sessions := oc.execSessions.List(agentID)
+		snapshots := make([]execSessionSnapshot, 0, len(sessions))
+		for _, session := range sessions {
+			snapshots = append(snapshots, session.snapshot())
+		}
+		return jsonActionResult("list", map[string]any{"sessions": snapshots})
+	}
+
+	sessionID, ok := maputil.StringArgMulti(args, "sessionId", "session_id")
+	if !ok {
+		return "", errors.New("missing or invalid 'sessionId' argument")
+	}
+	switch action {
+	case "remove":
+		if err = oc.execSessions.Remove(agentID, sessionID); err != nil {
+			return "", err
+		}
+		return jsonActionResult("remove", map[string]any{"sessionId": sessionID, "removed": true})
+	case "poll", "log", "write", "kill":
+	default:
+		return "", fmt.Errorf("unknown action %q (use list, poll, log, write, kill or remove)", action)
+	}
+	session, err := oc.execSessions.Get(agentID, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	switch action {
+	case "poll":
+		return execSessionResult("poll", session, session.Poll(), cfg.MaxOutputBytes, nil)
+	case "log":
+		output, dropped := session.Output()
+		lines := strings.Split(output, "\n")
+		offset, _ := maputil.IntArg(args, "offset")
+		start := min(max(offset-1, 0), len(lines))
+		end := len(lines)
+		if limit, ok := maputil.IntArg(args, "limit"); ok && limit > 0 {
+			end = min(start+limit, end)
+		}
+		return execSessionResult("log", session, strings.Join(lines[start:end], "\n"), cfg.MaxOutputBytes, map[string]any{
+			"totalLines":    len(lines),
+			"earlierOutput": dropped,
+		})
+	case "write":
+		eof, _ := args["eof"].(bool)
+		if err = session.WriteStdin(maputil.StringArg(args, "data"), eof); err != nil {
+			return "", err
+		}
+		return jsonActionResult("write", map[string]any{"sessionId": session.ID, "eof": eof})
+	default: // kill
+		session.Kill()
+		return execSessionResult("kill", session, session.Poll(), cfg.MaxOutputBytes, nil)
+	}
+}
+
+// execSessionResult renders the state of a session with output truncated to
+// maxBytes.
+func execSessionResult(action string, session *execSession, output string, maxBytes int, extra map[string]any) (string, error) {
+	snap := session.snapshot()
+	trunc := textfs.TruncateHead(output, textfs.DefaultMaxLines, maxBytes)
+	fields := map[string]any{
+		"sessionId":  snap.ID,
+		"status":     snap.Status,
+		"durationMs": snap.DurationMs,
+		"output":     trunc.Content,
+	}
+	if snap.ExitCode != nil {
+		fields["exitCode"] = *snap.ExitCode
+	}
+	if snap.Error != "" {
+		fields["error"] = snap.Error
+	}
+	if trunc.Truncated {
+		fields["truncated"] = true
+		fields["totalBytes"] = trunc.TotalBytes
+		fields["note"] = fmt.Sprintf("Output truncated to %s; use process log with offset/limit to see the rest while the session exists.", textfs.FormatSize(trunc.OutputBytes))
+	}
+	for key, value := range extra {
+		fields[key] = value
+	}
+	return jsonActionResult(action, fields)
+}
@@ -0,0 +1,178 @@
+package connector
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	args, err := splitCommandLine(`git log --format="%h %s" -n 3 'a b' c\ d`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"git", "log", "--format=%h %s", "-n", "3", "a b", "c d"}
+	if !slices.Equal(args, want) {
+		t.Fatalf("expected %q, got %q", want, args)
+	}
+	for _, command := range []string{"ls | sh", "ls; rm -rf x", "echo $(id)", `echo "$HOME"`, "cat < /etc/passwd", "ls *", "echo 'open", ""} {
+		if _, err := splitCommandLine(command); err == nil {
+			t.Fatalf("expected %q to be rejected", command)
+		}
+	}
+}
+
+func TestExecCommandAllowed(t *testing.T) {
+	allowed := []string{"git", "ls"}
+	if !execCommandAllowed(allowed, "git") || execCommandAllowed(allowed, "rm") {
+		t.Fatal("unexpected allowlist result")
+	}
+	if execCommandAllowed(allowed, "./git") || execCommandAllowed(allowed, "/tmp/ls") {
+		t.Fatal("expected paths to be rejected")
+	}
+}
+
+func TestResolveExecWorkdir(t *testing.T) {
+	agentDir := filepath.Join(t.TempDir(), "login", "agent")
+	cases := map[string]string{
+		"":                               agentDir,
+		"src/app":                        filepath.Join(agentDir, "src", "app"),
+		filepath.Join(agentDir, "build"): filepath.Join(agentDir, "build"),
+	}
+	for input, want := range cases {
+		got, err := resolveExecWorkdir(agentDir, input)
+		if err != nil || got != want {
+			t.Fatalf("resolveExecWorkdir(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"..", "../other", "src/../../other", "/etc"} {
+		if _, err := resolveExecWorkdir(agentDir, input); err == nil {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+	if got := execAgentDir("/root", "login/../x", ".."); got != filepath.Join("/root", "login_.._x", "default") {
+		t.Fatalf("unexpected agent dir %q", got)
+	}
+}
+
+func TestExecEnvDoesNotLeakBridgeEnvironment(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "secret")
+	env, err := execEnv("/work", map[string]string{"FOO": "bar"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Contains(env, "HOME=/work") || !slices.Contains(env, "FOO=bar") {
+		t.Fatalf("unexpected env %q", env)
+	}
+	for _, entry := range env {
+		if strings.HasPrefix(entry, "OPENAI_API_KEY=") {
+			t.Fatal("bridge environment leaked into exec env")
+		}
+	}
+	if _, err = execEnv("/work", map[string]string{"BAD-NAME": "x"}); err == nil {
+		t.Fatal("expected invalid env name to be rejected")
+	}
+}
+
+func TestBwrapArgs(t *testing.T) {
+	cfg := (&ExecToolsConfig{}).WithDefaults()
+	args := bwrapArgs(cfg, "/work/agent", "/work/agent/src", "make test")
+	joined := strings.Join(args, " ")
+	for _, want := range []string{"--unshare-all", "--die-with-parent", "--bind /work/agent /work/agent", "--chdir /work/agent/src", "-- /bin/sh -c make test"} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %q in bwrap args %q", want, joined)
+		}
+	}
+	if slices.Contains(args, "--share-net") {
+		t.Fatal("expected network to be unshared by default")
+	}
+	cfg.AllowNetwork = true
+	if !slices.Contains(bwrapArgs(cfg, "/w", "/w", "true"), "--share-net") {
+		t.Fatal("expected --share-net when network is allowed")
+	}
+}
+
+func startTestExecSession(t *testing.T, m *execSessionManager, command string, timeout time.Duration) *execSession {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	cfg := (&ExecToolsConfig{Sandbox: ExecSandboxNone, Shell: "sh"}).WithDefaults()
+	env, _ := execEnv(dir, nil)
+	session, err := m.Start(context.Background(), execStartParams{
+		AgentID:     "agent",
+		Command:     command,
+		Timeout:     timeout,
+		MaxSessions: 2,
+		Build: func(ctx context.Context) (*exec.Cmd, error) {
+			return buildExecCommand(ctx, cfg, dir, dir, command, env)
+		},
+	})
+	if err != nil {
+		t.Fatalf("start %q: %v", command, err)
+	}
+	return session
+}
+
+func TestExecSessionLifecycle(t *testing.T) {
+	var m execSessionManager
+	defer m.KillAll()
+
+	done := startTestExecSession(t, &m, "echo hello; exit 3", time.Minute)
+	<-done.done
+	if snap := done.snapshot(); snap.Status != execStatusFailed || snap.ExitCode == nil || *snap.ExitCode != 3 {
+		t.Fatalf("unexpected snapshot %#v", snap)
+	}
+	if out := done.Poll(); out != "hello\n" {
+		t.Fatalf("unexpected output %q", out)
+	}
+	if out := done.Poll(); out != "" {
+		t.Fatalf("expected poll to return only new output, got %q", out)
+	}
+
+	cat := startTestExecSession(t, &m, "cat", time.Minute)
+	if err := cat.WriteStdin("ping\n", true); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	<-cat.done
+	if out, _ := cat.Output(); out != "ping\n" || cat.snapshot().Status != execStatusCompleted {
+		t.Fatalf("unexpected cat result %q (%s)", out, cat.snapshot().Status)
+	}
+
+	sleeper := startTestExecSession(t, &m, "sleep 30", time.Minute)
+	second := startTestExecSession(t, &m, "sleep 30", time.Minute)
+	if _, err := m.Start(context.Background(), execStartParams{AgentID: "agent", MaxSessions: 2}); err != errExecSessionLimit {
+		t.Fatalf("expected session limit error, got %v", err)
+	}
+	sleeper.Kill()
+	if snap := sleeper.snapshot(); snap.Status != execStatusKilled {
+		t.Fatalf("expected killed status, got %#v", snap)
+	}
+	if _, err := m.Get("other-agent", second.ID); err != errExecSessionNotFound {
+		t.Fatalf("expected sessions to be scoped to their agent, got %v", err)
+	}
+	if err := m.Remove("agent", second.ID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got := len(m.List("agent")); got != 3 {
+		t.Fatalf("expected 3 remaining sessions, got %d", got)
+	}
+}
+
+func TestExecSessionTimeout(t *testing.T) {
+	var m execSessionManager
+	session := startTestExecSession(t, &m, "sleep 30", 100*time.Millisecond)
+	select {
+	case <-session.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("command did not time out")
+	}
+	if status := session.snapshot().Status; status != execStatusTimedOut {
+		t.Fatalf("expected timeout status, got %q", status)
+	}
+}
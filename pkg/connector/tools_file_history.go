@@ -0,0 +1,131 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/beeper/agentremote/pkg/shared/maputil"
+	"github.com/beeper/agentremote/pkg/textfs"
+)
+
+type fileRevisionSummary struct {
+	Version    int    `json:"version"`
+	Author     string `json:"author"`
+	ToolCallID string `json:"toolCallId,omitempty"`
+	Bytes      int    `json:"bytes"`
+	CreatedAt  string `json:"createdAt"`
+	Current    bool   `json:"current,omitempty"`
+}
+
+func summarizeFileRevisions(revisions []textfs.Revision, current *textfs.FileEntry) []fileRevisionSummary {
+	out := make([]fileRevisionSummary, 0, len(revisions))
+	for i, rev := range revisions {
+		out = append(out, fileRevisionSummary{
+			Version:    rev.Version,
+			Author:     fileRevisionAuthor(rev.Author),
+			ToolCallID: rev.Author.ToolCallID,
+			Bytes:      len(rev.Content),
+			CreatedAt:  time.UnixMilli(rev.CreatedAt).UTC().Format(time.RFC3339),
+			Current:    i == 0 && current != nil && current.Hash == rev.Hash,
+		})
+	}
+	return out
+}
+
+func fileRevisionAuthor(author textfs.Author) string {
+	if author.Kind == "" {
+		return "unknown"
+	}
+	return author.Kind
+}
+
+func executeFileHistory(ctx context.Context, args map[string]any) (string, error) {
+	store, err := textFSStore(ctx)
+	if err != nil {
+		return "", err
+	}
+	action := normalizeApprovalToken(maputil.StringArg(args, "action"))
+	path, ok := maputil.StringArgMulti(args, "path", "file_path")
+	if !ok {
+		return "", errors.New("missing or invalid 'path' argument")
+	}
+	version, hasVersion := maputil.IntArg(args, "version")
+
+	callCtx, cancel := context.WithTimeout(ctx, textFSToolTimeout)
+	defer cancel()
+	switch action {
+	case "list":
+		revisions, err := store.History(callCtx, path)
+		if err != nil {
+			return "", err
+		}
+		current, _, err := store.Read(callCtx, path)
+		if err != nil {
+			return "", err
+		}
+		return jsonActionResult("list", map[string]any{
+			"path":     path,
+			"versions": summarizeFileRevisions(revisions, current),
+			"exists":   current != nil,
+		})
+	case "show", "restore":
+		if !hasVersion {
+			return "", fmt.Errorf("version is required for %s", action)
+		}
+	default:
+		return "", fmt.Errorf("unknown action %q (use list, show or restore)", action)
+	}
+
+	if action == "restore" {
+		entry, err := store.Revert(callCtx, path, version)
+		if err != nil {
+			return "", err
+		}
+		go func(path string) {
+			bg, cancel := context.WithTimeout(detachedBridgeToolContext(ctx), textFSPostWriteTimeout)
+			defer cancel()
+			notifyIntegrationFileChanged(bg, path)
+			maybeRefreshAgentIdentity(bg, path)
+		}(entry.Path)
+		return fmt.Sprintf("Restored %s to version %d.", entry.Path, version), nil
+	}
+
+	revisions, err := store.History(callCtx, path)
+	if err != nil {
+		return "", err
+	}
+	for _, rev := range revisions {
+		if rev.Version != version {
+			continue
+		}
+		trunc := textfs.TruncateHead(rev.Content, textfs.DefaultMaxLines, textfs.DefaultMaxBytes)
+		output := trunc.Content
+		if trunc.Truncated {
+			output += "\n\n[Version truncated; restore it and use read with offset/limit to see the rest]"
+		}
+		return output, nil
+	}
+	return "", textfs.ErrRevisionNotFound
+}
+
+func formatFileRevisions(path string, revisions []fileRevisionSummary) string {
+	if len(revisions) == 0 {
+		return fmt.Sprintf("No versions of `%s`.", path)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Versions of `%s` (newest first):", path)
+	for _, rev := range revisions {
+		fmt.Fprintf(&sb, "\n- v%d · %s · %s", rev.Version, rev.CreatedAt, rev.Author)
+		if rev.ToolCallID != "" {
+			fmt.Fprintf(&sb, " (%s)", rev.ToolCallID)
+		}
+		fmt.Fprintf(&sb, " · %d bytes", rev.Bytes)
+		if rev.Current {
+			sb.WriteString(" · current")
+		}
+	}
+	return sb.String()
+}
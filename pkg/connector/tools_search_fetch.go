@@ -67,6 +67,9 @@ func executeWebFetchWithProviders(ctx context.Context, args map[string]any) (str
 	var cfg *fetch.Config
 	if btc != nil && btc.Client != nil {
 		cfg = btc.Client.effectiveFetchConfig(ctx)
+		if btc.Client.UserLogin != nil {
+			req.CacheScope = string(btc.Client.UserLogin.ID)
+		}
 	}
 	resp, err := fetch.Fetch(ctx, req, cfg)
 	if err != nil {
@@ -80,6 +83,7 @@ func executeWebFetchWithProviders(ctx context.Context, args map[string]any) (str
 		"contentType":   resp.ContentType,
 		"extractMode":   resp.ExtractMode,
 		"extractor":     resp.Extractor,
+		"title":         resp.Title,
 		"truncated":     resp.Truncated,
 		"length":        resp.Length,
 		"rawLength":     resp.RawLength,
@@ -190,6 +194,14 @@ func applyLoginTokensToSearchConfig(cfg *search.Config, meta *UserLoginMetadata,
 	if cfg.Exa.BaseURL == "" {
 		cfg.Exa.BaseURL = services[serviceExa].BaseURL
 	}
+	if tokens := meta.ServiceTokens; tokens != nil {
+		if cfg.Brave.APIKey == "" {
+			cfg.Brave.APIKey = trimToken(tokens.Brave)
+		}
+		if cfg.Perplexity.APIKey == "" {
+			cfg.Perplexity.APIKey = trimToken(tokens.Perplexity)
+		}
+	}
 
 	if shouldApplyExaProxyDefaults(meta) {
 		applyExaProxyDefaults(cfg, meta, connector)
@@ -347,6 +359,26 @@ func mapSearchConfig(src *SearchConfig) *search.Config {
 			TextMaxCharacters: src.Exa.TextMaxCharacters,
 			Highlights:        src.Exa.Highlights,
 		},
+		Brave: search.BraveConfig{
+			Enabled: src.Brave.Enabled,
+			BaseURL: src.Brave.BaseURL,
+			APIKey:  src.Brave.APIKey,
+		},
+		Perplexity: search.PerplexityConfig{
+			Enabled: src.Perplexity.Enabled,
+			BaseURL: src.Perplexity.BaseURL,
+			APIKey:  src.Perplexity.APIKey,
+			Model:   src.Perplexity.Model,
+		},
+		SearXNG: search.SearXNGConfig{
+			Enabled:    src.SearXNG.Enabled,
+			BaseURL:    src.SearXNG.BaseURL,
+			APIKey:     src.SearXNG.APIKey,
+			Categories: src.SearXNG.Categories,
+			Engines:    src.SearXNG.Engines,
+			Language:   src.SearXNG.Language,
+			SafeSearch: src.SearXNG.SafeSearch,
+		},
 	}
 }
 
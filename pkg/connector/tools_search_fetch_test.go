@@ -82,3 +82,25 @@ func TestApplyLoginTokensToSearchConfig_DefaultExaEndpointDoesNotForceExa(t *tes
 		t.Fatalf("openrouter token must not be copied into exa api key")
 	}
 }
+
+func TestApplyLoginTokensToSearchConfig_UsesBraveAndPerplexityServiceTokens(t *testing.T) {
+	oc := &OpenAIConnector{}
+	meta := &UserLoginMetadata{
+		Provider: ProviderOpenAI,
+		APIKey:   "openai-token",
+		ServiceTokens: &ServiceTokens{
+			Brave:      " brave-token ",
+			Perplexity: "pplx-token",
+		},
+	}
+	cfg := &search.Config{Perplexity: search.PerplexityConfig{APIKey: "configured"}}
+
+	got := applyLoginTokensToSearchConfig(cfg, meta, oc)
+
+	if got.Brave.APIKey != "brave-token" {
+		t.Fatalf("unexpected brave API key: %q", got.Brave.APIKey)
+	}
+	if got.Perplexity.APIKey != "configured" {
+		t.Fatalf("configured perplexity key must win over login token, got %q", got.Perplexity.APIKey)
+	}
+}
@@ -0,0 +1,191 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/event"
+)
+
+const (
+	usageBudgetActionBlock     = "block"
+	usageBudgetActionDowngrade = "downgrade"
+)
+
+// usageBudgetState is one budget window and how much of it has been spent.
+type usageBudgetState struct {
+	Period      string // "daily" or "monthly"
+	Start       time.Time
+	SpentMicros int64
+	LimitMicros int64
+}
+
+func (s usageBudgetState) exceeded() bool {
+	return s.LimitMicros > 0 && s.SpentMicros >= s.LimitMicros
+}
+
+func (oc *AIClient) usageBudgetConfig() *UsageBudgetConfig {
+	if oc == nil || oc.connector == nil || oc.connector.Config.Usage == nil {
+		return nil
+	}
+	budgets := oc.connector.Config.Usage.Budgets
+	if budgets == nil || (budgets.DailyUSD <= 0 && budgets.MonthlyUSD <= 0) {
+		return nil
+	}
+	return budgets
+}
+
+func usageBudgetAction(cfg *UsageBudgetConfig) string {
+	if cfg != nil && strings.EqualFold(strings.TrimSpace(cfg.Action), usageBudgetActionDowngrade) && strings.TrimSpace(cfg.DowngradeModel) != "" {
+		return usageBudgetActionDowngrade
+	}
+	return usageBudgetActionBlock
+}
+
+// usagePeriodStarts returns the start of the current day and month in loc.
+func usagePeriodStarts(now time.Time, loc *time.Location) (time.Time, time.Time) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	return day, month
+}
+
+func usdToMicros(usd float64) int64 {
+	if usd <= 0 {
+		return 0
+	}
+	return int64(math.Round(usd * 1e6))
+}
+
+// usageBudgetStates reports spend against each configured budget of this login.
+func (oc *AIClient) usageBudgetStates(ctx context.Context, cfg *UsageBudgetConfig) ([]usageBudgetState, error) {
+	scope := usageScope(oc)
+	if cfg == nil || scope == nil {
+		return nil, nil
+	}
+	_, loc := oc.resolveUserTimezone()
+	dayStart, monthStart := usagePeriodStarts(time.Now(), loc)
+	var states []usageBudgetState
+	for _, budget := range []usageBudgetState{
+		{Period: "daily", Start: dayStart, LimitMicros: usdToMicros(cfg.DailyUSD)},
+		{Period: "monthly", Start: monthStart, LimitMicros: usdToMicros(cfg.MonthlyUSD)},
+	} {
+		if budget.LimitMicros <= 0 {
+			continue
+		}
+		spent, err := scope.costSince(ctx, budget.Start)
+		if err != nil {
+			return nil, err
+		}
+		budget.SpentMicros = spent
+		states = append(states, budget)
+	}
+	return states, nil
+}
+
+// errUsageBudgetExceeded is returned for model requests made while a budget
+// with the "block" action is used up.
+var errUsageBudgetExceeded = errors.New("usage budget exceeded")
+
+// exceededUsageBudget returns the first budget of this login that is used up,
+// or nil when requests may run. A ledger failure is logged and treated as not
+// exceeded so conversations don't stop because the ledger is unavailable.
+func (oc *AIClient) exceededUsageBudget(ctx context.Context, cfg *UsageBudgetConfig) *usageBudgetState {
+	states, err := oc.usageBudgetStates(ctx, cfg)
+	if err != nil {
+		oc.loggerForContext(ctx).Warn().Err(err).Msg("Failed to check usage budgets")
+		return nil
+	}
+	for i := range states {
+		if states[i].exceeded() {
+			return &states[i]
+		}
+	}
+	return nil
+}
+
+// usageBudgetModel enforces the login's budgets before a model request that
+// isn't part of a turn, such as integration completions. It returns the model
+// to call, which is the downgrade model once a "downgrade" budget is used up,
+// or errUsageBudgetExceeded when the request must not run. Side requests made
+// during a turn (compaction, tools, media understanding) are covered by the
+// turn's own check in applyUsageBudget.
+func (oc *AIClient) usageBudgetModel(ctx context.Context, model string) (string, error) {
+	cfg := oc.usageBudgetConfig()
+	if cfg == nil {
+		return model, nil
+	}
+	exceeded := oc.exceededUsageBudget(ctx, cfg)
+	if exceeded == nil {
+		return model, nil
+	}
+	if usageBudgetAction(cfg) == usageBudgetActionDowngrade {
+		return ResolveAlias(strings.TrimSpace(cfg.DowngradeModel)), nil
+	}
+	return "", fmt.Errorf("%w: %s budget spent %s of %s", errUsageBudgetExceeded, exceeded.Period, formatUSDMicros(exceeded.SpentMicros), formatUSDMicros(exceeded.LimitMicros))
+}
+
+// applyUsageBudget enforces the login's budgets before a turn runs. It returns
+// the metadata to run the turn with, which carries a model override when the
+// budget action is "downgrade", or false when the turn must not run.
+func (oc *AIClient) applyUsageBudget(ctx context.Context, evt *event.Event, portal *bridgev2.Portal, meta *PortalMetadata) (*PortalMetadata, bool) {
+	cfg := oc.usageBudgetConfig()
+	if cfg == nil {
+		return meta, true
+	}
+	exceeded := oc.exceededUsageBudget(ctx, cfg)
+	if exceeded == nil {
+		return meta, true
+	}
+
+	spent := fmt.Sprintf("The %s usage budget is used up (%s of %s).", exceeded.Period, formatUSDMicros(exceeded.SpentMicros), formatUSDMicros(exceeded.LimitMicros))
+	if usageBudgetAction(cfg) == usageBudgetActionDowngrade {
+		downgradeModel := ResolveAlias(strings.TrimSpace(cfg.DowngradeModel))
+		if oc.effectiveModel(meta) == downgradeModel {
+			return meta, true
+		}
+		downgraded := clonePortalMetadata(meta)
+		if downgraded == nil {
+			downgraded = &PortalMetadata{}
+		}
+		downgraded.RuntimeModelOverride = downgradeModel
+		if portal != nil && oc.claimUsageBudgetNotice(portal, *exceeded) {
+			oc.sendSystemNotice(ctx, portal, fmt.Sprintf("%s Switching to %s until it resets.", spent, downgradeModel))
+		}
+		return downgraded, true
+	}
+
+	oc.loggerForContext(ctx).Info().
+		Str("period", exceeded.Period).
+		Int64("spent_micros", exceeded.SpentMicros).
+		Int64("limit_micros", exceeded.LimitMicros).
+		Msg("Usage budget exceeded, not responding")
+	if portal != nil && evt != nil {
+		oc.sendSystemNotice(ctx, portal, spent+" Replies are paused until it resets. Check spending with `!ai usage`.")
+	}
+	return meta, false
+}
+
+// claimUsageBudgetNotice reports whether the downgrade notice for this room
+// and budget window hasn't been sent yet, and marks it sent.
+func (oc *AIClient) claimUsageBudgetNotice(portal *bridgev2.Portal, state usageBudgetState) bool {
+	key := fmt.Sprintf("%s|%s|%d", portal.MXID, state.Period, state.Start.Unix())
+	oc.usageBudgetMu.Lock()
+	defer oc.usageBudgetMu.Unlock()
+	if _, sent := oc.usageBudgetNotices[key]; sent {
+		return false
+	}
+	if oc.usageBudgetNotices == nil {
+		oc.usageBudgetNotices = make(map[string]struct{})
+	}
+	oc.usageBudgetNotices[key] = struct{}{}
+	return true
+}
@@ -0,0 +1,199 @@
+package connector
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mau.fi/util/dbutil"
+	"maunium.net/go/mautrix/bridgev2"
+)
+
+// usageRecord is one provider response in the usage ledger.
+type usageRecord struct {
+	AgentID          string
+	RoomID           string
+	ModelID          string
+	TurnID           string
+	PromptTokens     int64
+	CompletionTokens int64
+	ReasoningTokens  int64
+	CachedTokens     int64
+	CostMicros       int64
+	CreatedAtMs      int64
+}
+
+// usageTotals aggregates ledger rows. CostMicros is in millionths of a USD.
+type usageTotals struct {
+	Key              string
+	Responses        int64
+	PromptTokens     int64
+	CompletionTokens int64
+	ReasoningTokens  int64
+	CachedTokens     int64
+	CostMicros       int64
+}
+
+type usageLedgerScope struct {
+	db       *dbutil.Database
+	bridgeID string
+	loginID  string
+}
+
+func usageScope(client *AIClient) *usageLedgerScope {
+	db, bridgeID, loginID := loginDBContext(client)
+	if db == nil {
+		return nil
+	}
+	return &usageLedgerScope{db: db, bridgeID: bridgeID, loginID: loginID}
+}
+
+func (scope *usageLedgerScope) insert(ctx context.Context, rec usageRecord) error {
+	_, err := scope.db.Exec(ctx, `
+		INSERT INTO ai_usage_ledger (
+			bridge_id, login_id, agent_id, room_id, model_id, turn_id,
+			prompt_tokens, completion_tokens, reasoning_tokens, cached_tokens,
+			cost_micros, created_at_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`,
+		scope.bridgeID, scope.loginID, rec.AgentID, rec.RoomID, rec.ModelID, rec.TurnID,
+		rec.PromptTokens, rec.CompletionTokens, rec.ReasoningTokens, rec.CachedTokens,
+		rec.CostMicros, rec.CreatedAtMs,
+	)
+	return err
+}
+
+// usageGroupColumns are the ledger columns totals can be grouped by.
+var usageGroupColumns = map[string]string{
+	"model": "model_id",
+	"agent": "agent_id",
+	"room":  "room_id",
+}
+
+// totalsSince sums the ledger from since onwards, grouped by model, agent or
+// room ("" for a single overall total). Groups are sorted by cost, highest first.
+func (scope *usageLedgerScope) totalsSince(ctx context.Context, since time.Time, groupBy string) ([]usageTotals, error) {
+	column, groupClause := "''", ""
+	if groupBy != "" {
+		var ok bool
+		if column, ok = usageGroupColumns[groupBy]; !ok {
+			return nil, fmt.Errorf("unknown usage grouping %q", groupBy)
+		}
+		groupClause = " GROUP BY " + column
+	}
+	rows, err := scope.db.Query(ctx, `
+		SELECT `+column+`, COUNT(*),
+			COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0),
+			COALESCE(SUM(reasoning_tokens), 0), COALESCE(SUM(cached_tokens), 0),
+			COALESCE(SUM(cost_micros), 0)
+		FROM ai_usage_ledger
+		WHERE bridge_id=$1 AND login_id=$2 AND created_at_ms>=$3`+groupClause,
+		scope.bridgeID, scope.loginID, since.UnixMilli(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []usageTotals
+	for rows.Next() {
+		var t usageTotals
+		if err := rows.Scan(&t.Key, &t.Responses, &t.PromptTokens, &t.CompletionTokens, &t.ReasoningTokens, &t.CachedTokens, &t.CostMicros); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(out, func(a, b usageTotals) int {
+		return cmp.Or(cmp.Compare(b.CostMicros, a.CostMicros), cmp.Compare(b.PromptTokens+b.CompletionTokens, a.PromptTokens+a.CompletionTokens), cmp.Compare(a.Key, b.Key))
+	})
+	return out, nil
+}
+
+func (scope *usageLedgerScope) costSince(ctx context.Context, since time.Time) (int64, error) {
+	totals, err := scope.totalsSince(ctx, since, "")
+	if err != nil || len(totals) == 0 {
+		return 0, err
+	}
+	return totals[0].CostMicros, nil
+}
+
+// modelPricing returns the configured price for a model, falling back to the
+// model manifest. Returns nil when the price is unknown.
+func (oc *AIClient) modelPricing(modelID string) *ModelPricing {
+	modelID = strings.TrimSpace(modelID)
+	if modelID == "" {
+		return nil
+	}
+	if oc != nil && oc.connector != nil && oc.connector.Config.Usage != nil {
+		if pricing, ok := oc.connector.Config.Usage.Pricing[modelID]; ok {
+			return &pricing
+		}
+	}
+	if info, ok := ModelManifest.Models[modelID]; ok {
+		return info.Pricing
+	}
+	return nil
+}
+
+// usageCostMicros prices a response in millionths of a USD. Since prices are
+// per million tokens, tokens*price is already in micro-USD. Cached prompt
+// tokens use the cached input price when the model has one.
+func usageCostMicros(pricing *ModelPricing, promptTokens, completionTokens, cachedTokens int64) int64 {
+	if pricing == nil {
+		return 0
+	}
+	cachedTokens = min(max(cachedTokens, 0), promptTokens)
+	cachedPrice := pricing.CachedInput
+	if cachedPrice <= 0 {
+		cachedPrice = pricing.Input
+	}
+	cost := float64(promptTokens-cachedTokens)*pricing.Input +
+		float64(cachedTokens)*cachedPrice +
+		float64(completionTokens)*pricing.Output
+	return int64(math.Round(cost))
+}
+
+// recordStreamUsage writes the usage of the response that just completed to
+// the ledger. Each provider response (including tool continuations) is one row.
+func (oc *AIClient) recordStreamUsage(ctx context.Context, portal *bridgev2.Portal, meta *PortalMetadata, state *streamingState) {
+	if state == nil || (state.promptTokens <= 0 && state.completionTokens <= 0) {
+		return
+	}
+	scope := usageScope(oc)
+	if scope == nil {
+		return
+	}
+	modelID := oc.effectiveModel(meta)
+	rec := usageRecord{
+		AgentID:          state.agentID,
+		RoomID:           string(state.roomID),
+		ModelID:          modelID,
+		TurnID:           state.turnID,
+		PromptTokens:     state.promptTokens,
+		CompletionTokens: state.completionTokens,
+		ReasoningTokens:  state.reasoningTokens,
+		CachedTokens:     state.cachedTokens,
+		CostMicros:       usageCostMicros(oc.modelPricing(modelID), state.promptTokens, state.completionTokens, state.cachedTokens),
+		CreatedAtMs:      time.Now().UnixMilli(),
+	}
+	if rec.RoomID == "" && portal != nil {
+		rec.RoomID = string(portal.MXID)
+	}
+	if err := scope.insert(ctx, rec); err != nil {
+		oc.loggerForContext(ctx).Warn().Err(err).Str("model", modelID).Msg("Failed to record usage")
+	}
+}
+
+func formatUSDMicros(micros int64) string {
+	usd := float64(micros) / 1e6
+	if micros != 0 && usd < 0.01 {
+		return fmt.Sprintf("$%.4f", usd)
+	}
+	return fmt.Sprintf("$%.2f", usd)
+}
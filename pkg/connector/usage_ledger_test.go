@@ -0,0 +1,106 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.mau.fi/util/dbutil"
+
+	"github.com/beeper/agentremote/pkg/aidb"
+)
+
+func setupUsageLedgerScope(t *testing.T) *usageLedgerScope {
+	t.Helper()
+	raw, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	raw.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = raw.Close() })
+	base, err := dbutil.NewWithDB(raw, "sqlite3")
+	if err != nil {
+		t.Fatalf("wrap db: %v", err)
+	}
+	db := aidb.NewChild(base, dbutil.NoopLogger)
+	if err := aidb.Upgrade(context.Background(), db, "ai_bridge", ""); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	return &usageLedgerScope{db: db, bridgeID: "bridge", loginID: "login"}
+}
+
+func TestUsageCostMicros(t *testing.T) {
+	pricing := &ModelPricing{Input: 2, Output: 8, CachedInput: 0.5}
+	// 1000 uncached * $2/M + 1000 cached * $0.5/M + 500 out * $8/M = 2000 + 500 + 4000 micro-USD
+	if got := usageCostMicros(pricing, 2000, 500, 1000); got != 6500 {
+		t.Fatalf("expected 6500 micros, got %d", got)
+	}
+	if got := usageCostMicros(&ModelPricing{Input: 2, Output: 8}, 2000, 0, 1000); got != 4000 {
+		t.Fatalf("expected cached tokens at input price, got %d", got)
+	}
+	if got := usageCostMicros(nil, 2000, 500, 0); got != 0 {
+		t.Fatalf("expected no cost without pricing, got %d", got)
+	}
+}
+
+func TestUsageLedgerTotals(t *testing.T) {
+	ctx := context.Background()
+	scope := setupUsageLedgerScope(t)
+	now := time.Now()
+	records := []usageRecord{
+		{AgentID: "beeper", RoomID: "!a:x", ModelID: "openai/gpt-5", PromptTokens: 100, CompletionTokens: 10, CostMicros: 300, CreatedAtMs: now.UnixMilli()},
+		{AgentID: "beeper", RoomID: "!b:x", ModelID: "openai/gpt-5", PromptTokens: 200, CompletionTokens: 20, CachedTokens: 50, CostMicros: 700, CreatedAtMs: now.UnixMilli()},
+		{AgentID: "coder", RoomID: "!a:x", ModelID: "openai/gpt-5-mini", PromptTokens: 50, CompletionTokens: 5, CostMicros: 100, CreatedAtMs: now.UnixMilli()},
+		{AgentID: "beeper", RoomID: "!a:x", ModelID: "openai/gpt-5", PromptTokens: 999, CostMicros: 9999, CreatedAtMs: now.Add(-48 * time.Hour).UnixMilli()},
+	}
+	for _, rec := range records {
+		if err := scope.insert(ctx, rec); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+	}
+	other := &usageLedgerScope{db: scope.db, bridgeID: "bridge", loginID: "other"}
+	if err := other.insert(ctx, usageRecord{ModelID: "openai/gpt-5", PromptTokens: 1, CostMicros: 123456, CreatedAtMs: now.UnixMilli()}); err != nil {
+		t.Fatalf("insert other login: %v", err)
+	}
+
+	since := now.Add(-time.Hour)
+	cost, err := scope.costSince(ctx, since)
+	if err != nil {
+		t.Fatalf("costSince: %v", err)
+	}
+	if cost != 1100 {
+		t.Fatalf("expected 1100 micros since an hour ago, got %d", cost)
+	}
+
+	byModel, err := scope.totalsSince(ctx, since, "model")
+	if err != nil {
+		t.Fatalf("totals by model: %v", err)
+	}
+	if len(byModel) != 2 || byModel[0].Key != "openai/gpt-5" || byModel[0].Responses != 2 || byModel[0].PromptTokens != 300 || byModel[0].CachedTokens != 50 {
+		t.Fatalf("unexpected model totals: %#v", byModel)
+	}
+	byAgent, err := scope.totalsSince(ctx, time.Time{}, "agent")
+	if err != nil {
+		t.Fatalf("totals by agent: %v", err)
+	}
+	if len(byAgent) != 2 || byAgent[0].Key != "beeper" || byAgent[0].CostMicros != 10999 {
+		t.Fatalf("unexpected agent totals: %#v", byAgent)
+	}
+	if _, err := scope.totalsSince(ctx, since, "sender"); err == nil {
+		t.Fatal("expected error for unknown grouping")
+	}
+}
+
+func TestUsagePeriodStarts(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2026, 3, 31, 23, 30, 0, 0, time.UTC) // April 1st, 01:30 in loc
+	day, month := usagePeriodStarts(now, loc)
+	if !day.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected day start %v", day)
+	}
+	if !month.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected month start %v", month)
+	}
+}
@@ -0,0 +1,675 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/util/dbutil"
+	"go.mau.fi/util/exstrings"
+	"maunium.net/go/mautrix/bridgev2"
+	"maunium.net/go/mautrix/bridgev2/networkid"
+	"maunium.net/go/mautrix/id"
+
+	integrationcron "github.com/beeper/agentremote/pkg/integrations/cron"
+)
+
+const (
+	webhookMaxBodyBytes          = 1 << 20
+	webhookMaxPromptChars        = 16000
+	webhookDeliveryHistoryMax    = 100
+	webhookDeliveryHistoryMaxAge = 30 * 24 * time.Hour
+	webhookEventSource           = "webhook"
+	webhookMaxEventTypeChars     = 64
+	// Per-hook token bucket: bursts of webhookRateBurst deliveries, refilled
+	// at webhookRateBurst per webhookRateWindow.
+	webhookRateBurst  = 30
+	webhookRateWindow = time.Minute
+	// webhookUntrustedTag marks sender-controlled text in wrapped prompts.
+	webhookUntrustedTag = "untrusted-webhook-content"
+)
+
+// webhookSignatureHeaders carry a hex HMAC-SHA256 of the request body, with or
+// without a "sha256=" prefix, as sent by GitHub, Gitea/Forgejo and most other
+// senders.
+var webhookSignatureHeaders = []string{"X-Hub-Signature-256", "X-Gitea-Signature", "X-Webhook-Signature"}
+
+// webhookTokenHeaders carry the secret itself (GitLab doesn't sign payloads).
+var webhookTokenHeaders = []string{"X-Gitlab-Token"}
+
+// webhookEventHeaders name the event type of a delivery.
+var webhookEventHeaders = []string{"X-GitHub-Event", "X-Gitea-Event", "X-Gitlab-Event", "X-Event-Type"}
+
+// webhookDeliveryIDHeaders carry the sender's unique ID of a delivery, which
+// stays the same when the sender retries or the request is replayed.
+var webhookDeliveryIDHeaders = []string{"X-GitHub-Delivery", "X-Gitea-Delivery", "X-Gitlab-Event-UUID"}
+
+var (
+	errWebhookNotFound         = errors.New("webhook not found")
+	errWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+const defaultWebhookPromptTemplate = `Webhook "{{.Hook}}" received{{with .Event}} a {{.}} event{{end}}.
+
+{{.Body}}`
+
+// Webhook is an inbound HTTP endpoint whose deliveries are rendered into a
+// prompt and dispatched to an agent.
+type Webhook struct {
+	ID             string `json:"id"`
+	Name           string `json:"name,omitempty"`
+	Secret         string `json:"secret,omitempty"`
+	PromptTemplate string `json:"prompt_template,omitempty"`
+	AgentID        string `json:"agent_id,omitempty"`
+	RoomID         string `json:"room_id,omitempty"`
+	WrapUntrusted  bool   `json:"wrap_untrusted"`
+	Enabled        bool   `json:"enabled"`
+	CreatedAtMs    int64  `json:"created_at_ms"`
+	UpdatedAtMs    int64  `json:"updated_at_ms"`
+}
+
+// WebhookDelivery is a received (or replayed) webhook request and the outcome
+// of dispatching it.
+type WebhookDelivery struct {
+	ID           string `json:"id"`
+	HookID       string `json:"hook_id"`
+	ReplayOf     string `json:"replay_of,omitempty"`
+	ReceivedAtMs int64  `json:"received_at_ms"`
+	EventType    string `json:"event_type,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	Payload      string `json:"payload"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	RoomID       string `json:"room_id,omitempty"`
+	EventID      string `json:"event_id,omitempty"`
+	ExternalID   string `json:"external_id,omitempty"`
+}
+
+// webhookTemplateData is the dot of prompt templates.
+type webhookTemplateData struct {
+	Hook    string
+	HookID  string
+	Event   string
+	Payload any
+	Body    string
+}
+
+// webhookUntrustedString is a payload string that prints between untrusted
+// content markers. Its kind is still string, so template comparisons such as
+// {{if eq .Payload.action "completed"}} see the raw value.
+type webhookUntrustedString string
+
+func (s webhookUntrustedString) String() string {
+	return wrapWebhookUntrusted(string(s))
+}
+
+// wrapWebhookUntrusted encloses text in untrusted content markers. Markers
+// inside text are defused so the sender can't close the block early.
+func wrapWebhookUntrusted(text string) string {
+	text = strings.ReplaceAll(text, webhookUntrustedTag, "untrusted_webhook_content")
+	return "<" + webhookUntrustedTag + ">" + text + "</" + webhookUntrustedTag + ">"
+}
+
+// markWebhookPayloadUntrusted returns a copy of a decoded JSON payload whose
+// strings print as untrusted content.
+func markWebhookPayloadUntrusted(value any) any {
+	switch v := value.(type) {
+	case string:
+		return webhookUntrustedString(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = markWebhookPayloadUntrusted(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = markWebhookPayloadUntrusted(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func webhookTemplateFuncs(wrapUntrusted bool) template.FuncMap {
+	return template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil || !wrapUntrusted {
+				return string(data), err
+			}
+			return wrapWebhookUntrusted(string(data)), nil
+		},
+	}
+}
+
+func parseWebhookTemplate(text string) (*template.Template, error) {
+	return parseWebhookTemplateWith(text, false)
+}
+
+func parseWebhookTemplateWith(text string, wrapUntrusted bool) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = defaultWebhookPromptTemplate
+	}
+	return template.New("webhook").Funcs(webhookTemplateFuncs(wrapUntrusted)).Option("missingkey=zero").Parse(text)
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// verifyWebhookSignature accepts a request if any signature header matches
+// the HMAC of body or any token header equals the secret.
+func verifyWebhookSignature(secret string, header http.Header, body []byte) bool {
+	if secret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+	for _, name := range webhookSignatureHeaders {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+		sig, err := hex.DecodeString(strings.TrimPrefix(value, "sha256="))
+		if err == nil && hmac.Equal(sig, expected) {
+			return true
+		}
+	}
+	for _, name := range webhookTokenHeaders {
+		if value := header.Get(name); value != "" && exstrings.ConstantTimeEqual(value, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookEventType returns the delivery's event type. It is interpolated into
+// prompts outside the untrusted markers, so only short identifiers are kept.
+func webhookEventType(header http.Header) string {
+	for _, name := range webhookEventHeaders {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+		if len(value) > webhookMaxEventTypeChars || strings.ContainsFunc(value, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(" _.:/-", r))
+		}) {
+			return ""
+		}
+		return value
+	}
+	return ""
+}
+
+func webhookDeliveryExternalID(header http.Header) string {
+	for _, name := range webhookDeliveryIDHeaders {
+		if value := strings.TrimSpace(header.Get(name)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// webhookGuard rate limits deliveries per hook and serializes them, so a
+// retried delivery can't race its original past the duplicate check.
+type webhookGuard struct {
+	mu    sync.Mutex
+	hooks map[string]*webhookHookGuard
+}
+
+type webhookHookGuard struct {
+	deliverMu  sync.Mutex
+	tokens     float64
+	refilledAt time.Time
+}
+
+func (g *webhookGuard) hook(hookID string) *webhookHookGuard {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.hooks == nil {
+		g.hooks = make(map[string]*webhookHookGuard)
+	}
+	hook, ok := g.hooks[hookID]
+	if !ok {
+		hook = &webhookHookGuard{tokens: webhookRateBurst}
+		g.hooks[hookID] = hook
+	}
+	return hook
+}
+
+// allow takes a token from the hook's bucket, reporting false when it's empty.
+func (g *webhookGuard) allow(hookID string, now time.Time) bool {
+	hook := g.hook(hookID)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !hook.refilledAt.IsZero() {
+		refill := now.Sub(hook.refilledAt).Seconds() * webhookRateBurst / webhookRateWindow.Seconds()
+		hook.tokens = min(webhookRateBurst, hook.tokens+refill)
+	}
+	hook.refilledAt = now
+	if hook.tokens < 1 {
+		return false
+	}
+	hook.tokens--
+	return true
+}
+
+// renderWebhookPrompt builds the message dispatched to the agent for a
+// delivery. JSON payloads are decoded so templates can address fields, e.g.
+// {{.Payload.repository.full_name}}. When the hook wraps untrusted content,
+// only what the sender controls (.Body, .Payload values and json output) is
+// enclosed in markers; the template text stays trusted instructions.
+func renderWebhookPrompt(hook Webhook, delivery WebhookDelivery) (string, error) {
+	tmpl, err := parseWebhookTemplateWith(hook.PromptTemplate, hook.WrapUntrusted)
+	if err != nil {
+		return "", err
+	}
+	data := webhookTemplateData{
+		Hook:   webhookDisplayName(hook),
+		HookID: hook.ID,
+		Event:  delivery.EventType,
+		Body:   delivery.Payload,
+	}
+	var payload any
+	if err = json.Unmarshal([]byte(delivery.Payload), &payload); err == nil {
+		data.Payload = payload
+	}
+	if hook.WrapUntrusted {
+		data.Body = wrapWebhookUntrusted(delivery.Payload)
+		data.Payload = markWebhookPayloadUntrusted(data.Payload)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	rendered := strings.TrimSpace(buf.String())
+	if rendered == "" {
+		return "", errors.New("prompt template rendered an empty message")
+	}
+	if runes := []rune(rendered); len(runes) > webhookMaxPromptChars {
+		rendered = string(runes[:webhookMaxPromptChars]) + "..."
+		// Don't leave a block open past the cut.
+		if strings.Count(rendered, "<"+webhookUntrustedTag+">") > strings.Count(rendered, "</"+webhookUntrustedTag+">") {
+			rendered += "</" + webhookUntrustedTag + ">"
+		}
+	}
+	message := fmt.Sprintf("[webhook:%s %s]\n%s", hook.ID, webhookDisplayName(hook), rendered)
+	if hook.WrapUntrusted {
+		message = webhookUntrustedPreamble(webhookDisplayName(hook)) + "\n\n" + message
+	}
+	return message, nil
+}
+
+func webhookUntrustedPreamble(hookName string) string {
+	return integrationcron.UntrustedContentBoundary(fmt.Sprintf("Text between <%[1]s> and </%[1]s> comes from the sender of the %q webhook.", webhookUntrustedTag, hookName))
+}
+
+func webhookDisplayName(hook Webhook) string {
+	if name := strings.TrimSpace(hook.Name); name != "" {
+		return name
+	}
+	return "webhook"
+}
+
+func (oc *AIClient) webhookDBScope() *schedulerDBScope {
+	if oc == nil || oc.scheduler == nil {
+		return nil
+	}
+	return oc.scheduler.schedulerDBScope()
+}
+
+// deliverWebhook records a delivery and dispatches its prompt. The agent turn
+// runs in the background; the returned delivery only reflects whether the
+// prompt was handed to the room.
+func (oc *AIClient) deliverWebhook(ctx context.Context, hook Webhook, delivery WebhookDelivery) (WebhookDelivery, error) {
+	scope := oc.webhookDBScope()
+	if scope == nil {
+		return delivery, errors.New("webhook storage not available")
+	}
+	if delivery.ID == "" {
+		delivery.ID = uuid.NewString()
+	}
+	delivery.HookID = hook.ID
+	if delivery.ReceivedAtMs == 0 {
+		delivery.ReceivedAtMs = time.Now().UnixMilli()
+	}
+	delivery.Status, delivery.Error = "delivered", ""
+	if !hook.Enabled {
+		delivery.Status = "skipped"
+		delivery.Error = "webhook is disabled"
+	} else if err := oc.dispatchWebhookPrompt(ctx, hook, &delivery); err != nil {
+		delivery.Status, delivery.Error = "error", err.Error()
+	}
+	if err := insertWebhookDelivery(ctx, scope, delivery); err != nil {
+		return delivery, err
+	}
+	cutoff := time.UnixMilli(delivery.ReceivedAtMs).Add(-webhookDeliveryHistoryMaxAge).UnixMilli()
+	if err := pruneWebhookDeliveries(ctx, scope, hook.ID, webhookDeliveryHistoryMax, cutoff); err != nil {
+		oc.log.Warn().Err(err).Str("hook_id", hook.ID).Msg("Failed to prune webhook deliveries")
+	}
+	return delivery, nil
+}
+
+// replayWebhookDelivery dispatches a stored delivery again with the hook's
+// current template and target.
+func (oc *AIClient) replayWebhookDelivery(ctx context.Context, hookID, deliveryID string) (WebhookDelivery, error) {
+	scope := oc.webhookDBScope()
+	if scope == nil {
+		return WebhookDelivery{}, errors.New("webhook storage not available")
+	}
+	hook, err := getWebhook(ctx, scope, hookID)
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+	original, err := getWebhookDelivery(ctx, scope, hookID, deliveryID)
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+	return oc.deliverWebhook(ctx, *hook, WebhookDelivery{
+		ReplayOf:    original.ID,
+		EventType:   original.EventType,
+		ContentType: original.ContentType,
+		Payload:     original.Payload,
+	})
+}
+
+func (oc *AIClient) dispatchWebhookPrompt(ctx context.Context, hook Webhook, delivery *WebhookDelivery) error {
+	message, err := renderWebhookPrompt(hook, *delivery)
+	if err != nil {
+		return fmt.Errorf("render prompt: %w", err)
+	}
+	portal, err := oc.resolveWebhookPortal(ctx, hook)
+	if err != nil {
+		return err
+	}
+	meta := clonePortalMetadata(portalMeta(portal))
+	if meta == nil {
+		meta = &PortalMetadata{}
+	}
+	if portal.OtherUserID != "" {
+		meta.ResolvedTarget = resolveTargetFromGhostID(portal.OtherUserID)
+	}
+	delivery.RoomID = portal.MXID.String()
+	eventID, _, err := oc.dispatchInternalMessage(oc.backgroundContext(ctx), portal, meta, message, webhookEventSource, false)
+	if err != nil {
+		return err
+	}
+	delivery.EventID = eventID.String()
+	return nil
+}
+
+// resolveWebhookPortal returns the hook's configured room, or a dedicated room
+// with the hook's agent when none is set.
+func (oc *AIClient) resolveWebhookPortal(ctx context.Context, hook Webhook) (*bridgev2.Portal, error) {
+	if roomID := strings.TrimSpace(hook.RoomID); roomID != "" {
+		// Check again on delivery: the room may have changed hands since the
+		// hook was bound to it.
+		portal := oc.webhookRoomPortal(ctx, id.RoomID(roomID))
+		if portal == nil || portal.MXID == "" {
+			return nil, fmt.Errorf("webhook room not found: %s", roomID)
+		}
+		return portal, nil
+	}
+	if oc.scheduler == nil {
+		return nil, errors.New("scheduler not available")
+	}
+	agentID := normalizedCronAgentID(&hook.AgentID)
+	portalID := fmt.Sprintf("webhook:%s:%s", agentID, hook.ID)
+	portal, err := oc.scheduler.getOrCreateScheduledPortal(ctx, portalID, fmt.Sprintf("Webhook: %s", webhookDisplayName(hook)), func(meta *PortalMetadata) {
+		meta.SetModuleMeta("webhook", map[string]any{
+			"is_internal_room": true,
+			"hook_id":          hook.ID,
+			"managed":          true,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if portal.OtherUserID == "" {
+		portal.OtherUserID = oc.agentUserID(agentID)
+		if err = portal.Save(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return portal, nil
+}
+
+// webhookRoomPortal returns the portal of roomID if this login owns it. Hooks
+// may only post into the login's own rooms.
+func (oc *AIClient) webhookRoomPortal(ctx context.Context, roomID id.RoomID) *bridgev2.Portal {
+	portal := oc.portalByRoomID(ctx, roomID)
+	if !webhookCanPostTo(portal, oc.UserLogin.ID) {
+		return nil
+	}
+	return portal
+}
+
+func webhookCanPostTo(portal *bridgev2.Portal, loginID networkid.UserLoginID) bool {
+	return portal != nil && portal.Portal != nil && loginID != "" && portal.Receiver == loginID
+}
+
+// findWebhookLogin returns the login that owns a hook. Hook IDs are unique per
+// bridge so the public endpoint doesn't need to know the login.
+func findWebhookLogin(ctx context.Context, db *dbutil.Database, bridgeID, hookID string) (string, error) {
+	var loginID string
+	err := db.QueryRow(ctx, `
+		SELECT login_id FROM ai_webhooks WHERE bridge_id=$1 AND hook_id=$2
+	`, bridgeID, hookID).Scan(&loginID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errWebhookNotFound
+	}
+	return loginID, err
+}
+
+const webhookColumns = `hook_id, name, secret, prompt_template, agent_id, room_id, wrap_untrusted, enabled, created_at_ms, updated_at_ms`
+
+func scanWebhook(row dbutil.Scannable) (*Webhook, error) {
+	var hook Webhook
+	err := row.Scan(
+		&hook.ID, &hook.Name, &hook.Secret, &hook.PromptTemplate, &hook.AgentID, &hook.RoomID,
+		&hook.WrapUntrusted, &hook.Enabled, &hook.CreatedAtMs, &hook.UpdatedAtMs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func getWebhook(ctx context.Context, scope *schedulerDBScope, hookID string) (*Webhook, error) {
+	hook, err := scanWebhook(scope.db.QueryRow(ctx, `
+		SELECT `+webhookColumns+`
+		FROM ai_webhooks
+		WHERE bridge_id=$1 AND login_id=$2 AND hook_id=$3
+	`, scope.bridgeID, scope.loginID, strings.TrimSpace(hookID)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errWebhookNotFound
+	}
+	return hook, err
+}
+
+func listWebhooks(ctx context.Context, scope *schedulerDBScope) ([]Webhook, error) {
+	rows, err := scope.db.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM ai_webhooks
+		WHERE bridge_id=$1 AND login_id=$2
+		ORDER BY created_at_ms, hook_id
+	`, scope.bridgeID, scope.loginID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := []Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, *hook)
+	}
+	return hooks, rows.Err()
+}
+
+func saveWebhook(ctx context.Context, scope *schedulerDBScope, hook Webhook) error {
+	_, err := scope.db.Exec(ctx, `
+		INSERT INTO ai_webhooks (
+			bridge_id, login_id, hook_id, name, secret, prompt_template,
+			agent_id, room_id, wrap_untrusted, enabled, created_at_ms, updated_at_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (bridge_id, login_id, hook_id) DO UPDATE SET
+			name=excluded.name,
+			secret=excluded.secret,
+			prompt_template=excluded.prompt_template,
+			agent_id=excluded.agent_id,
+			room_id=excluded.room_id,
+			wrap_untrusted=excluded.wrap_untrusted,
+			enabled=excluded.enabled,
+			updated_at_ms=excluded.updated_at_ms
+	`,
+		scope.bridgeID, scope.loginID, hook.ID, hook.Name, hook.Secret, hook.PromptTemplate,
+		hook.AgentID, hook.RoomID, hook.WrapUntrusted, hook.Enabled, hook.CreatedAtMs, hook.UpdatedAtMs,
+	)
+	return err
+}
+
+// deleteWebhook removes a hook and its delivery history.
+func deleteWebhook(ctx context.Context, scope *schedulerDBScope, hookID string) (bool, error) {
+	var deleted bool
+	err := scope.db.DoTxn(ctx, nil, func(ctx context.Context) error {
+		res, err := scope.db.Exec(ctx, `
+			DELETE FROM ai_webhooks WHERE bridge_id=$1 AND login_id=$2 AND hook_id=$3
+		`, scope.bridgeID, scope.loginID, hookID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			deleted = true
+		}
+		_, err = scope.db.Exec(ctx, `
+			DELETE FROM ai_webhook_deliveries WHERE bridge_id=$1 AND login_id=$2 AND hook_id=$3
+		`, scope.bridgeID, scope.loginID, hookID)
+		return err
+	})
+	return deleted, err
+}
+
+func insertWebhookDelivery(ctx context.Context, scope *schedulerDBScope, delivery WebhookDelivery) error {
+	_, err := scope.db.Exec(ctx, `
+		INSERT INTO ai_webhook_deliveries (
+			bridge_id, login_id, hook_id, delivery_id, replay_of, received_at_ms,
+			event_type, content_type, payload, status, error, room_id, event_id, external_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`,
+		scope.bridgeID, scope.loginID, delivery.HookID, delivery.ID, delivery.ReplayOf, delivery.ReceivedAtMs,
+		delivery.EventType, delivery.ContentType, delivery.Payload, delivery.Status, delivery.Error, delivery.RoomID, delivery.EventID,
+		delivery.ExternalID,
+	)
+	return err
+}
+
+const webhookDeliveryColumns = `hook_id, delivery_id, replay_of, received_at_ms, event_type, content_type, payload, status, error, room_id, event_id, external_id`
+
+func scanWebhookDelivery(row dbutil.Scannable) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	err := row.Scan(
+		&delivery.HookID, &delivery.ID, &delivery.ReplayOf, &delivery.ReceivedAtMs, &delivery.EventType, &delivery.ContentType,
+		&delivery.Payload, &delivery.Status, &delivery.Error, &delivery.RoomID, &delivery.EventID, &delivery.ExternalID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func getWebhookDelivery(ctx context.Context, scope *schedulerDBScope, hookID, deliveryID string) (*WebhookDelivery, error) {
+	delivery, err := scanWebhookDelivery(scope.db.QueryRow(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM ai_webhook_deliveries
+		WHERE bridge_id=$1 AND login_id=$2 AND hook_id=$3 AND delivery_id=$4
+	`, scope.bridgeID, scope.loginID, hookID, deliveryID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errWebhookDeliveryNotFound
+	}
+	return delivery, err
+}
+
+// findWebhookDeliveryByExternalID returns the delivery the sender identified
+// as externalID, if it was received before.
+func findWebhookDeliveryByExternalID(ctx context.Context, scope *schedulerDBScope, hookID, externalID string) (*WebhookDelivery, error) {
+	delivery, err := scanWebhookDelivery(scope.db.QueryRow(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM ai_webhook_deliveries
+		WHERE bridge_id=$1 AND login_id=$2 AND hook_id=$3 AND external_id=$4
+	`, scope.bridgeID, scope.loginID, hookID, externalID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errWebhookDeliveryNotFound
+	}
+	return delivery, err
+}
+
+// listWebhookDeliveries returns the most recent deliveries of a hook, newest first.
+func listWebhookDeliveries(ctx context.Context, scope *schedulerDBScope, hookID string, limit int) ([]WebhookDelivery, error) {
+	rows, err := scope.db.Query(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM ai_webhook_deliveries
+		WHERE bridge_id=$1 AND login_id=$2 AND hook_id=$3
+		ORDER BY received_at_ms DESC, delivery_id
+		LIMIT $4
+	`, scope.bridgeID, scope.loginID, hookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// pruneWebhookDeliveries drops deliveries older than cutoffMs across all hooks
+// of the login, then trims the hook's history to its newest maxPerHook entries.
+func pruneWebhookDeliveries(ctx context.Context, scope *schedulerDBScope, hookID string, maxPerHook int, cutoffMs int64) error {
+	if _, err := scope.db.Exec(ctx, `
+		DELETE FROM ai_webhook_deliveries
+		WHERE bridge_id=$1 AND login_id=$2 AND received_at_ms<$3
+	`, scope.bridgeID, scope.loginID, cutoffMs); err != nil {
+		return err
+	}
+	_, err := scope.db.Exec(ctx, `
+		DELETE FROM ai_webhook_deliveries
+		WHERE bridge_id=$1 AND login_id=$2 AND hook_id=$3 AND delivery_id NOT IN (
+			SELECT delivery_id FROM ai_webhook_deliveries
+			WHERE bridge_id=$1 AND login_id=$2 AND hook_id=$3
+			ORDER BY received_at_ms DESC, delivery_id
+			LIMIT $4
+		)
+	`, scope.bridgeID, scope.loginID, hookID, maxPerHook)
+	return err
+}
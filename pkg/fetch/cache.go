@@ -0,0 +1,79 @@
+package fetch
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds the fetch cache; the oldest entry is evicted first.
+const maxCacheEntries = 100
+
+// cachedPage is an extracted page before it was truncated for a request.
+type cachedPage struct {
+	FinalURL    string
+	Status      int
+	ContentType string
+	Extractor   string
+	Title       string
+	Text        string
+	Warning     string
+	fetchedAt   time.Time
+}
+
+type pageCache struct {
+	mu      sync.Mutex
+	entries map[string]*cachedPage
+}
+
+// directCache is shared by all direct providers, which are created per fetch.
+// Entries are keyed by Request.CacheScope so they don't leak across logins.
+var directCache = &pageCache{}
+
+func pageCacheKey(req Request) string {
+	mode := strings.ToLower(strings.TrimSpace(req.ExtractMode))
+	if mode == "" {
+		mode = "markdown"
+	}
+	return req.CacheScope + "|" + mode + "|" + strings.TrimSpace(req.URL)
+}
+
+func (c *pageCache) get(key string, ttl time.Duration) *cachedPage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.entries[key]
+	if entry == nil {
+		return nil
+	}
+	if time.Since(entry.fetchedAt) > ttl {
+		delete(c.entries, key)
+		return nil
+	}
+	return entry
+}
+
+func (c *pageCache) put(key string, page *cachedPage, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*cachedPage)
+	}
+	c.entries[key] = page
+	if len(c.entries) <= maxCacheEntries {
+		return
+	}
+	var oldestKey string
+	var oldest time.Time
+	for k, entry := range c.entries {
+		if time.Since(entry.fetchedAt) > ttl {
+			delete(c.entries, k)
+			continue
+		}
+		if oldestKey == "" || entry.fetchedAt.Before(oldest) {
+			oldestKey, oldest = k, entry.fetchedAt
+		}
+	}
+	if len(c.entries) > maxCacheEntries {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -12,6 +12,8 @@ const (
 	ProviderDirect     = "direct"
 	DefaultTimeoutSecs = 30
 	DefaultMaxChars    = 50_000
+	// DefaultCacheTtlSecs is how long direct fetch results are reused.
+	DefaultCacheTtlSecs = 900
 )
 
 var DefaultFallbackOrder = []string{
@@ -36,11 +38,14 @@ type ExaConfig struct {
 	TextMaxCharacters int    `yaml:"text_max_chars"`
 }
 
+// DirectConfig configures fetching pages directly. Readability defaults to
+// true. CacheTtlSecs of zero uses DefaultCacheTtlSecs and a negative value
+// disables the cache.
 type DirectConfig struct {
 	Enabled      *bool  `yaml:"enabled"`
 	TimeoutSecs  int    `yaml:"timeout_seconds"`
 	UserAgent    string `yaml:"user_agent"`
-	Readability  bool   `yaml:"readability"`
+	Readability  *bool  `yaml:"readability"`
 	MaxChars     int    `yaml:"max_chars"`
 	MaxRedirects int    `yaml:"max_redirects"`
 	CacheTtlSecs int    `yaml:"cache_ttl_seconds"`
@@ -84,5 +89,8 @@ func (c DirectConfig) withDefaults() DirectConfig {
 	if c.MaxRedirects <= 0 {
 		c.MaxRedirects = 3
 	}
+	if c.CacheTtlSecs == 0 {
+		c.CacheTtlSecs = DefaultCacheTtlSecs
+	}
 	return c
 }
@@ -0,0 +1,629 @@
+package fetch
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlDocument is the readable content of an HTML page.
+type htmlDocument struct {
+	Title string
+	Text  string
+}
+
+var (
+	whitespaceRun    = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLinesRun    = regexp.MustCompile(`\n{3,}`)
+	unlikelyContent  = regexp.MustCompile(`(?i)ad-|advert|banner|breadcrumb|combx|comment|community|cookie|disqus|footer|footnote|masthead|menu|modal|newsletter|outbrain|pager|popup|promo|related|remark|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|taboola|tags|toolbar|widget`)
+	likelyContent    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveClassRE  = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|story|text|blog`)
+	negativeClassRE  = regexp.MustCompile(`(?i)hidden|banner|combx|comment|com-|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	codeLanguageAttr = regexp.MustCompile(`(?:^|\s)(?:language|lang)-([A-Za-z0-9_+-]+)`)
+)
+
+// skippedElements never contain readable content.
+var skippedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Svg: true, atom.Canvas: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Form: true, atom.Button: true, atom.Input: true, atom.Select: true, atom.Textarea: true,
+	atom.Head: true, atom.Dialog: true,
+}
+
+// chromeElements are page furniture dropped when extracting the main content.
+var chromeElements = map[atom.Atom]bool{
+	atom.Nav: true, atom.Aside: true, atom.Footer: true, atom.Header: true, atom.Menu: true,
+}
+
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Details: true, atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Fieldset: true, atom.Figcaption: true, atom.Figure: true, atom.Footer: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Header: true, atom.Hr: true, atom.Li: true, atom.Main: true, atom.Nav: true,
+	atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true, atom.Summary: true,
+	atom.Table: true, atom.Ul: true, atom.Body: true, atom.Html: true,
+}
+
+// extractHTMLDocument converts an HTML page to markdown, or plain text when
+// plain is set. With readability it keeps only the main content of the page;
+// otherwise the whole body is converted.
+func extractHTMLDocument(body string, pageURL string, readability, plain bool) (*htmlDocument, error) {
+	root, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	base, _ := url.Parse(pageURL)
+	if href := findBaseHref(root); href != "" && base != nil {
+		if resolved, err := base.Parse(href); err == nil {
+			base = resolved
+		}
+	}
+
+	doc := &htmlDocument{Title: documentTitle(root)}
+	content := findElement(root, atom.Body)
+	if content == nil {
+		content = root
+	}
+	if readability {
+		if main := findMainContent(content); main != nil {
+			content = main
+		}
+	}
+	conv := &markdownConverter{base: base, plain: plain, dropChrome: readability}
+	var sb strings.Builder
+	conv.blocks(&sb, content, "")
+	doc.Text = strings.TrimSpace(blankLinesRun.ReplaceAllString(sb.String(), "\n\n"))
+	return doc, nil
+}
+
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func findBaseHref(root *html.Node) string {
+	if base := findElement(root, atom.Base); base != nil {
+		return attr(base, "href")
+	}
+	return ""
+}
+
+func documentTitle(root *html.Node) string {
+	var ogTitle string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Meta && ogTitle == "" {
+			if prop := attr(n, "property"); prop == "og:title" {
+				ogTitle = strings.TrimSpace(attr(n, "content"))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	if title := findElement(root, atom.Title); title != nil {
+		if text := collapseWhitespace(textContent(title)); text != "" {
+			return text
+		}
+	}
+	return ogTitle
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && skippedElements[c.DataAtom] {
+			continue
+		}
+		sb.WriteString(textContent(c))
+	}
+	return sb.String()
+}
+
+func collapseWhitespace(s string) string {
+	return strings.TrimSpace(whitespaceRun.ReplaceAllString(s, " "))
+}
+
+func classAndID(n *html.Node) string {
+	return attr(n, "class") + " " + attr(n, "id")
+}
+
+// isUnlikelyCandidate reports whether n looks like page chrome (comments,
+// sidebars, share bars) rather than content.
+func isUnlikelyCandidate(n *html.Node) bool {
+	if chromeElements[n.DataAtom] {
+		return true
+	}
+	if attr(n, "role") == "navigation" || attr(n, "role") == "complementary" || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	if n.DataAtom == atom.Body || n.DataAtom == atom.Article || n.DataAtom == atom.Main {
+		return false
+	}
+	match := classAndID(n)
+	return unlikelyContent.MatchString(match) && !likelyContent.MatchString(match)
+}
+
+func classWeight(n *html.Node) float64 {
+	weight := 0.0
+	for _, value := range []string{attr(n, "class"), attr(n, "id")} {
+		if value == "" {
+			continue
+		}
+		if negativeClassRE.MatchString(value) {
+			weight -= 25
+		}
+		if positiveClassRE.MatchString(value) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+func linkDensity(n *html.Node) float64 {
+	total := len(collapseWhitespace(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	var walk func(*html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.ElementNode && c.DataAtom == atom.A {
+			links += len(collapseWhitespace(textContent(c)))
+			return
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return float64(links) / float64(total)
+}
+
+// findMainContent scores the parents of text paragraphs and returns the
+// best-scoring container, along the lines of Mozilla's Readability.
+func findMainContent(body *html.Node) *html.Node {
+	scores := map[*html.Node]float64{}
+	var order []*html.Node
+	initialize := func(n *html.Node) {
+		if _, ok := scores[n]; ok {
+			return
+		}
+		score := classWeight(n)
+		switch n.DataAtom {
+		case atom.Article:
+			score += 10
+		case atom.Div, atom.Main, atom.Section:
+			score += 5
+		case atom.Pre, atom.Td, atom.Blockquote:
+			score += 3
+		case atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+			score -= 3
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+			score -= 5
+		}
+		scores[n] = score
+		order = append(order, n)
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || skippedElements[c.DataAtom] || isUnlikelyCandidate(c) {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+				scoreParagraph(c, initialize, scores)
+			}
+			walk(c)
+		}
+	}
+	walk(body)
+
+	var (
+		best      *html.Node
+		bestScore float64
+	)
+	for _, n := range order {
+		score := scores[n] * (1 - linkDensity(n))
+		if best == nil || score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil || len(collapseWhitespace(textContent(best))) < 140 {
+		return nil
+	}
+	// A lone paragraph container inside a wrapper usually means the wrapper is
+	// the article; climb while the parent holds most of the same text.
+	for best.Parent != nil && best.Parent != body && best.Parent.Type == html.ElementNode {
+		parentScore, ok := scores[best.Parent]
+		if !ok || parentScore*(1-linkDensity(best.Parent)) < bestScore*0.75 {
+			break
+		}
+		best = best.Parent
+	}
+	return best
+}
+
+func scoreParagraph(p *html.Node, initialize func(*html.Node), scores map[*html.Node]float64) {
+	text := collapseWhitespace(textContent(p))
+	if len(text) < 25 {
+		return
+	}
+	score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+	ancestor := p.Parent
+	for level := 0; ancestor != nil && ancestor.Type == html.ElementNode && level < 3; level++ {
+		initialize(ancestor)
+		switch level {
+		case 0:
+			scores[ancestor] += score
+		case 1:
+			scores[ancestor] += score / 2
+		default:
+			scores[ancestor] += score / 6
+		}
+		ancestor = ancestor.Parent
+	}
+}
+
+// markdownConverter renders HTML nodes as markdown (or plain text).
+type markdownConverter struct {
+	base       *url.URL
+	plain      bool
+	dropChrome bool
+}
+
+func (c *markdownConverter) skip(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	if skippedElements[n.DataAtom] || attr(n, "hidden") != "" || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	return c.dropChrome && isUnlikelyCandidate(n)
+}
+
+func isBlock(n *html.Node) bool {
+	return n.Type == html.ElementNode && blockElements[n.DataAtom]
+}
+
+// blocks writes the block-level content of n. Inline runs between blocks
+// become paragraphs.
+func (c *markdownConverter) blocks(sb *strings.Builder, n *html.Node, indent string) {
+	var inline strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(inline.String()); text != "" {
+			c.writeBlock(sb, indent, text)
+		}
+		inline.Reset()
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if c.skip(child) {
+			continue
+		}
+		if !isBlock(child) {
+			c.inline(&inline, child)
+			continue
+		}
+		flush()
+		c.block(sb, child, indent)
+	}
+	flush()
+}
+
+func (c *markdownConverter) writeBlock(sb *strings.Builder, indent, text string) {
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(indent)
+		sb.WriteString(strings.TrimSpace(line))
+	}
+	sb.WriteString("\n\n")
+}
+
+func (c *markdownConverter) block(sb *strings.Builder, n *html.Node, indent string) {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := c.inlineText(n)
+		if text == "" {
+			return
+		}
+		if !c.plain {
+			text = strings.Repeat("#", int(n.Data[1]-'0')) + " " + text
+		}
+		c.writeBlock(sb, indent, text)
+	case atom.P, atom.Dt, atom.Dd, atom.Summary, atom.Figcaption, atom.Address:
+		if text := c.inlineText(n); text != "" {
+			if n.DataAtom == atom.Dt && !c.plain {
+				text = "**" + text + "**"
+			}
+			c.writeBlock(sb, indent, text)
+		}
+	case atom.Hr:
+		if !c.plain {
+			c.writeBlock(sb, indent, "---")
+		}
+	case atom.Pre:
+		c.pre(sb, n, indent)
+	case atom.Ul, atom.Ol:
+		c.list(sb, n, indent)
+		sb.WriteByte('\n')
+	case atom.Blockquote:
+		var inner strings.Builder
+		c.blocks(&inner, n, "")
+		text := strings.TrimSpace(inner.String())
+		if text == "" {
+			return
+		}
+		if c.plain {
+			c.writeBlock(sb, indent, text)
+			return
+		}
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		sb.WriteString(indent + strings.Join(lines, "\n"+indent) + "\n\n")
+	case atom.Table:
+		c.table(sb, n, indent)
+	default:
+		c.blocks(sb, n, indent)
+	}
+}
+
+func (c *markdownConverter) pre(sb *strings.Builder, n *html.Node, indent string) {
+	code := strings.Trim(textContent(n), "\n")
+	if strings.TrimSpace(code) == "" {
+		return
+	}
+	if c.plain {
+		sb.WriteString(code + "\n\n")
+		return
+	}
+	lang := ""
+	classes := attr(n, "class")
+	if inner := findElement(n, atom.Code); inner != nil {
+		classes += " " + attr(inner, "class")
+	}
+	if m := codeLanguageAttr.FindStringSubmatch(classes); m != nil {
+		lang = m[1]
+	}
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(sb, "%s%s%s\n%s\n%s%s\n\n", indent, fence, lang, code, indent, fence)
+}
+
+func (c *markdownConverter) list(sb *strings.Builder, n *html.Node, indent string) {
+	index := 1
+	if start := attr(n, "start"); start != "" {
+		fmt.Sscanf(start, "%d", &index)
+	}
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li || c.skip(li) {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", index)
+			index++
+		}
+		var text strings.Builder
+		var nested []*html.Node
+		for child := li.FirstChild; child != nil; child = child.NextSibling {
+			if c.skip(child) {
+				continue
+			}
+			if child.Type == html.ElementNode && (child.DataAtom == atom.Ul || child.DataAtom == atom.Ol) {
+				nested = append(nested, child)
+				continue
+			}
+			if isBlock(child) {
+				text.WriteString(" " + c.inlineText(child) + " ")
+				continue
+			}
+			c.inline(&text, child)
+		}
+		item := strings.TrimSpace(strings.ReplaceAll(collapseSpaces(text.String()), "\n", " "))
+		sb.WriteString(indent + marker + item + "\n")
+		for _, list := range nested {
+			c.list(sb, list, indent+strings.Repeat(" ", len(marker)))
+		}
+	}
+}
+
+func (c *markdownConverter) table(sb *strings.Builder, n *html.Node, indent string) {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Tr:
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						row = append(row, c.inlineText(cell))
+					}
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			case atom.Table:
+				// Nested tables are flattened into their cell text.
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return
+	}
+	if c.plain {
+		for _, row := range rows {
+			sb.WriteString(indent + strings.Join(row, "\t") + "\n")
+		}
+		sb.WriteByte('\n')
+		return
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	writeRow := func(row []string) {
+		cells := make([]string, width)
+		for i, cell := range row {
+			cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+		sb.WriteString(indent + "| " + strings.Join(cells, " | ") + " |\n")
+	}
+	writeRow(rows[0])
+	sep := make([]string, width)
+	for i := range sep {
+		sep[i] = "---"
+	}
+	writeRow(sep)
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	sb.WriteByte('\n')
+}
+
+// inlineText renders the inline content of n on one logical line.
+func (c *markdownConverter) inlineText(n *html.Node) string {
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if c.skip(child) {
+			continue
+		}
+		if isBlock(child) {
+			sb.WriteString(" " + c.inlineText(child) + " ")
+			continue
+		}
+		c.inline(&sb, child)
+	}
+	return strings.TrimSpace(collapseSpaces(sb.String()))
+}
+
+func (c *markdownConverter) inline(sb *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		sb.WriteString(whitespaceRun.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if c.skip(n) {
+		return
+	}
+	wrap := func(marker string) {
+		text := c.inlineText(n)
+		if text == "" {
+			return
+		}
+		if c.plain {
+			sb.WriteString(text)
+		} else {
+			sb.WriteString(marker + text + marker)
+		}
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		sb.WriteString("\n")
+	case atom.A:
+		text := c.inlineText(n)
+		href := c.resolve(attr(n, "href"))
+		switch {
+		case text == "":
+		case c.plain || href == "" || strings.HasPrefix(href, "#"):
+			sb.WriteString(text)
+		default:
+			sb.WriteString("[" + text + "](" + href + ")")
+		}
+	case atom.Strong, atom.B:
+		wrap("**")
+	case atom.Em, atom.I, atom.Cite:
+		wrap("_")
+	case atom.Del, atom.S, atom.Strike:
+		wrap("~~")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		code := collapseWhitespace(textContent(n))
+		if code == "" {
+			return
+		}
+		if c.plain {
+			sb.WriteString(code)
+		} else {
+			sb.WriteString("`" + code + "`")
+		}
+	case atom.Img:
+		alt := collapseWhitespace(attr(n, "alt"))
+		src := c.resolve(attr(n, "src"))
+		if c.plain || src == "" || strings.HasPrefix(src, "data:") {
+			sb.WriteString(alt)
+		} else {
+			sb.WriteString("![" + alt + "](" + src + ")")
+		}
+	default:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			c.inline(sb, child)
+		}
+	}
+}
+
+func (c *markdownConverter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(strings.ToLower(ref), "javascript:") {
+		return ""
+	}
+	if c.base == nil {
+		return ref
+	}
+	resolved, err := c.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return resolved.String()
+}
+
+// collapseSpaces squeezes runs of spaces while keeping line breaks from <br>.
+func collapseSpaces(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(strings.Join(strings.Fields(line), " "))
+	}
+	return strings.Join(lines, "\n")
+}
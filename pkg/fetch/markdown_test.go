@@ -0,0 +1,98 @@
+package fetch
+
+import (
+	"strings"
+	"testing"
+)
+
+const articleHTML = `<!doctype html>
+<html><head><title>Release notes</title><meta property="og:title" content="OG title"></head>
+<body>
+<nav class="site-nav"><a href="/">Home</a> <a href="/blog">Blog</a></nav>
+<div id="sidebar" class="sidebar"><p>Subscribe to our newsletter, follow us, like us, share us everywhere.</p></div>
+<article class="post-content">
+<h1>Version 2.0</h1>
+<p>This release brings a <strong>new parser</strong>, faster startup, and <em>much</em> better error messages for everyone.</p>
+<p>See the <a href="/docs/upgrade">upgrade guide</a> before updating, since some options were renamed, moved, or removed.</p>
+<h2>Changes</h2>
+<ul><li>Added <code>--fast</code><ul><li>Nested detail</li></ul></li><li>Removed legacy mode</li></ul>
+<ol start="3"><li>Third</li><li>Fourth</li></ol>
+<pre><code class="language-go">func main() {
+	fmt.Println("hi")
+}</code></pre>
+<blockquote><p>Quoted text, with commas, inside.</p></blockquote>
+<table><tr><th>Name</th><th>Value</th></tr><tr><td>a|b</td><td>1</td></tr></table>
+<p><img src="/img/chart.png" alt="Chart"></p>
+<script>var tracking = "do not include";</script>
+</article>
+<footer class="footer"><p>Copyright, all rights reserved, by the company, forever.</p></footer>
+</body></html>`
+
+func TestExtractHTMLDocumentMarkdown(t *testing.T) {
+	doc, err := extractHTMLDocument(articleHTML, "https://example.com/blog/v2", true, false)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if doc.Title != "Release notes" {
+		t.Fatalf("unexpected title %q", doc.Title)
+	}
+	for _, want := range []string{
+		"# Version 2.0",
+		"## Changes",
+		"a **new parser**, faster startup, and _much_ better",
+		"[upgrade guide](https://example.com/docs/upgrade)",
+		"- Added `--fast`\n  - Nested detail\n- Removed legacy mode",
+		"3. Third\n4. Fourth",
+		"```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```",
+		"> Quoted text, with commas, inside.",
+		"| Name | Value |\n| --- | --- |\n| a\\|b | 1 |",
+		"![Chart](https://example.com/img/chart.png)",
+	} {
+		if !strings.Contains(doc.Text, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, doc.Text)
+		}
+	}
+	for _, unwanted := range []string{"Home", "newsletter", "Copyright", "tracking"} {
+		if strings.Contains(doc.Text, unwanted) {
+			t.Errorf("expected %q to be dropped, got:\n%s", unwanted, doc.Text)
+		}
+	}
+}
+
+func TestExtractHTMLDocumentText(t *testing.T) {
+	doc, err := extractHTMLDocument(articleHTML, "https://example.com/blog/v2", true, true)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	for _, unwanted := range []string{"**", "](", "# ", "```"} {
+		if strings.Contains(doc.Text, unwanted) {
+			t.Errorf("expected no markdown syntax %q in text mode, got:\n%s", unwanted, doc.Text)
+		}
+	}
+	if !strings.Contains(doc.Text, "See the upgrade guide before updating") {
+		t.Fatalf("expected link text in text mode, got:\n%s", doc.Text)
+	}
+}
+
+func TestExtractHTMLDocumentWithoutReadabilityKeepsWholeBody(t *testing.T) {
+	doc, err := extractHTMLDocument(articleHTML, "https://example.com/blog/v2", false, false)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if !strings.Contains(doc.Text, "newsletter") || !strings.Contains(doc.Text, "Copyright") {
+		t.Fatalf("expected the whole body without readability, got:\n%s", doc.Text)
+	}
+	if strings.Contains(doc.Text, "tracking") {
+		t.Fatalf("expected scripts to be dropped, got:\n%s", doc.Text)
+	}
+}
+
+func TestExtractHTMLDocumentFallsBackToOGTitle(t *testing.T) {
+	doc, err := extractHTMLDocument(`<html><head><meta property="og:title" content="Shared title"></head><body><p>Hi</p></body></html>`, "", true, false)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if doc.Title != "Shared title" || doc.Text != "Hi" {
+		t.Fatalf("unexpected document %+v", doc)
+	}
+}
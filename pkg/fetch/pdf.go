@@ -0,0 +1,995 @@
+package fetch
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// pdfMaxDecodedBytes bounds how much a single stream may inflate to.
+const pdfMaxDecodedBytes = 64 << 20
+
+// pdfMaxDocumentDecodedBytes bounds how much all streams of a document may
+// inflate to together.
+const pdfMaxDocumentDecodedBytes = 128 << 20
+
+// pdfMaxOperations bounds the content stream tokens interpreted per document,
+// so forms that draw other forms many times can't blow up the work.
+const pdfMaxOperations = 2_000_000
+
+// pdfMaxCMapEntries bounds the ToUnicode mappings kept per document; real
+// fonts map a few thousand codes, while bfrange can expand to far more.
+const pdfMaxCMapEntries = 1 << 18
+
+// pdfMaxNesting bounds page tree depth, reference chains and form XObjects.
+const pdfMaxNesting = 32
+
+var (
+	errNotPDF      = errors.New("not a PDF document")
+	errPDFBudget   = errors.New("PDF exceeds processing limits")
+	pdfObjectStart = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+)
+
+// isPDF reports whether body looks like a PDF file.
+func isPDF(body []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(body[:min(len(body), 1024)], "\x00\t\r\n "), []byte("%PDF-"))
+}
+
+type (
+	pdfName    string
+	pdfKeyword string
+	pdfString  []byte
+	pdfDict    map[pdfName]any
+	pdfRef     struct{ Num, Gen int }
+	pdfStream  struct {
+		Dict pdfDict
+		Data []byte
+	}
+)
+
+// extractPDFText returns the text of every page of a PDF, pages separated by
+// blank lines. It understands the common subset of the format: classic and
+// compressed object streams, Flate-encoded content, the page tree, form
+// XObjects and ToUnicode CMaps. Encrypted documents and text drawn with other
+// filters or custom encodings come back empty or partial.
+func extractPDFText(data []byte) (text string, err error) {
+	if !isPDF(data) {
+		return "", errNotPDF
+	}
+	// The parser works on untrusted input; never let a bug in it take the
+	// bridge down.
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("malformed PDF: %v", r)
+		}
+	}()
+	doc := parsePDF(data)
+	if _, encrypted := doc.trailerKeys["Encrypt"]; encrypted {
+		return "", errors.New("encrypted PDFs are not supported")
+	}
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return "", errors.New("no pages found in PDF")
+	}
+	var out strings.Builder
+	for _, page := range pages {
+		w := &pdfTextWriter{}
+		doc.interpret(w, doc.pageContent(page.dict), page.resources, 0, map[*pdfStream]bool{})
+		text := w.String()
+		if text == "" {
+			continue
+		}
+		if out.Len() > 0 {
+			out.WriteString("\n\n")
+		}
+		out.WriteString(text)
+	}
+	if out.Len() == 0 && doc.overBudget() {
+		return "", errPDFBudget
+	}
+	return out.String(), nil
+}
+
+type pdfDocument struct {
+	objects     map[int]any
+	trailerKeys pdfDict
+
+	// decoded memoizes stream data so forms drawn repeatedly inflate once.
+	decoded      map[*pdfStream][]byte
+	decodedBytes int
+	operations   int
+	cmapEntries  int
+}
+
+func parsePDF(data []byte) *pdfDocument {
+	doc := &pdfDocument{objects: make(map[int]any), trailerKeys: pdfDict{}, decoded: make(map[*pdfStream][]byte)}
+	lastEnd := 0
+	for _, m := range pdfObjectStart.FindAllSubmatchIndex(data, -1) {
+		if m[0] < lastEnd {
+			continue // inside the previous object's stream
+		}
+		num, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		lex := &pdfLexer{data: data, pos: m[1]}
+		value, end := lex.readIndirect()
+		if value == nil {
+			continue
+		}
+		// Later definitions win, which matches incremental updates.
+		doc.objects[num] = value
+		lastEnd = end
+	}
+	for _, idx := range pdfTrailerIndexes(data) {
+		lex := &pdfLexer{data: data, pos: idx}
+		if dict, ok := lex.readValue().(pdfDict); ok {
+			maps.Copy(doc.trailerKeys, dict)
+		}
+	}
+	// Objects in object streams only fill gaps so direct definitions from
+	// incremental updates still win.
+	for _, obj := range doc.objectsInOrder() {
+		if stream, ok := obj.(*pdfStream); ok && stream.Dict["Type"] == pdfName("ObjStm") {
+			doc.loadObjectStream(stream)
+		}
+	}
+	for _, obj := range doc.objects {
+		// Cross-reference streams carry the trailer keys in PDF 1.5+.
+		if stream, ok := obj.(*pdfStream); ok && stream.Dict["Type"] == pdfName("XRef") {
+			for key, value := range stream.Dict {
+				if _, exists := doc.trailerKeys[key]; !exists {
+					doc.trailerKeys[key] = value
+				}
+			}
+		}
+	}
+	return doc
+}
+
+func pdfTrailerIndexes(data []byte) []int {
+	var out []int
+	for offset := 0; ; {
+		idx := bytes.Index(data[offset:], []byte("trailer"))
+		if idx < 0 {
+			return out
+		}
+		offset += idx + len("trailer")
+		out = append(out, offset)
+	}
+}
+
+func (d *pdfDocument) loadObjectStream(stream *pdfStream) {
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return
+	}
+	n, _ := d.resolve(stream.Dict["N"]).(float64)
+	first, _ := d.resolve(stream.Dict["First"]).(float64)
+	if n <= 0 || first <= 0 || int(first) > len(data) {
+		return
+	}
+	header := &pdfLexer{data: data[:int(first)]}
+	for range int(n) {
+		num, ok1 := header.readValue().(float64)
+		offset, ok2 := header.readValue().(float64)
+		if !ok1 || !ok2 {
+			return
+		}
+		pos := int(first) + int(offset)
+		if pos < 0 || pos >= len(data) {
+			continue
+		}
+		if _, exists := d.objects[int(num)]; exists {
+			continue
+		}
+		lex := &pdfLexer{data: data, pos: pos}
+		if value := lex.readValue(); value != nil {
+			d.objects[int(num)] = value
+		}
+	}
+}
+
+// objectsInOrder returns the objects sorted by object number.
+func (d *pdfDocument) objectsInOrder() []any {
+	keys := slices.Sorted(maps.Keys(d.objects))
+	out := make([]any, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, d.objects[key])
+	}
+	return out
+}
+
+func (d *pdfDocument) resolve(value any) any {
+	for range pdfMaxNesting {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			return value
+		}
+		value = d.objects[ref.Num]
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(value any) pdfDict {
+	switch v := d.resolve(value).(type) {
+	case pdfDict:
+		return v
+	case *pdfStream:
+		return v.Dict
+	}
+	return nil
+}
+
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages walks the page tree from the catalog, falling back to every page
+// object in file order when the tree is broken.
+func (d *pdfDocument) pages() []pdfPage {
+	var pages []pdfPage
+	visited := map[pdfRef]bool{}
+	var walk func(node any, resources pdfDict, depth int)
+	walk = func(node any, resources pdfDict, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		dict := d.dict(node)
+		if dict == nil || depth > pdfMaxNesting {
+			return
+		}
+		if res := d.dict(dict["Resources"]); res != nil {
+			resources = res
+		}
+		kids, isTree := d.resolve(dict["Kids"]).([]any)
+		if dict["Type"] == pdfName("Page") || !isTree {
+			if dict["Type"] == pdfName("Page") {
+				pages = append(pages, pdfPage{dict: dict, resources: resources})
+			}
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, resources, depth+1)
+		}
+	}
+	if root := d.dict(d.trailerKeys["Root"]); root != nil {
+		walk(root["Pages"], nil, 0)
+	}
+	if len(pages) == 0 {
+		for _, obj := range d.objects {
+			if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+				walk(dict["Pages"], nil, 0)
+				break
+			}
+		}
+	}
+	if len(pages) == 0 {
+		for _, obj := range d.objectsInOrder() {
+			if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+				pages = append(pages, pdfPage{dict: dict, resources: d.dict(dict["Resources"])})
+			}
+		}
+	}
+	return pages
+}
+
+func (d *pdfDocument) pageContent(page pdfDict) []byte {
+	var streams []any
+	switch contents := d.resolve(page["Contents"]).(type) {
+	case *pdfStream:
+		streams = append(streams, contents)
+	case []any:
+		streams = contents
+	}
+	// Content arrays may be split at any token boundary, so join them.
+	var buf bytes.Buffer
+	for _, item := range streams {
+		stream, ok := d.resolve(item).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := d.decodeStream(stream)
+		if err != nil {
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// overBudget reports whether the document used up its decode, operation or
+// CMap budget, after which nothing more is decoded or interpreted.
+func (d *pdfDocument) overBudget() bool {
+	return d.decodedBytes >= pdfMaxDocumentDecodedBytes || d.operations >= pdfMaxOperations || d.cmapEntries >= pdfMaxCMapEntries
+}
+
+// decodeStream decodes a stream once and charges it to the document budget.
+func (d *pdfDocument) decodeStream(stream *pdfStream) ([]byte, error) {
+	if data, ok := d.decoded[stream]; ok {
+		return data, nil
+	}
+	if d.overBudget() {
+		return nil, errPDFBudget
+	}
+	data, err := decodePDFStream(stream, min(pdfMaxDecodedBytes, pdfMaxDocumentDecodedBytes-d.decodedBytes))
+	if err != nil {
+		return nil, err
+	}
+	d.decodedBytes += len(data)
+	d.decoded[stream] = data
+	return data, nil
+}
+
+func decodePDFStream(stream *pdfStream, limit int) ([]byte, error) {
+	var filters []any
+	switch filter := stream.Dict["Filter"].(type) {
+	case pdfName:
+		filters = []any{filter}
+	case []any:
+		filters = filter
+	}
+	data := stream.Data
+	for _, filter := range filters {
+		switch filter {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			decoded, err := inflatePDF(data, limit)
+			if err != nil {
+				return nil, err
+			}
+			data = decoded
+		default:
+			return nil, fmt.Errorf("unsupported PDF filter %v", filter)
+		}
+	}
+	return data, nil
+}
+
+func inflatePDF(data []byte, limit int) ([]byte, error) {
+	var reader io.ReadCloser
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		reader = zr
+	} else {
+		reader = flate.NewReader(bytes.NewReader(data))
+	}
+	defer reader.Close()
+	decoded, err := io.ReadAll(io.LimitReader(reader, int64(limit)))
+	// Truncated streams are common; keep whatever inflated cleanly.
+	if err != nil && len(decoded) == 0 {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// pdfFont decodes the strings shown with one font.
+type pdfFont struct {
+	cmap    *pdfCMap
+	twoByte bool
+}
+
+func (d *pdfDocument) font(resources pdfDict, name pdfName, cache map[pdfName]*pdfFont) *pdfFont {
+	if font, ok := cache[name]; ok {
+		return font
+	}
+	font := &pdfFont{}
+	cache[name] = font
+	dict := d.dict(d.dict(resources["Font"])[name])
+	if dict == nil {
+		return font
+	}
+	font.twoByte = dict["Subtype"] == pdfName("Type0")
+	if stream, ok := d.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := d.decodeStream(stream); err == nil {
+			font.cmap = d.parseCMap(data)
+		}
+	}
+	return font
+}
+
+func (f *pdfFont) decode(s []byte) string {
+	var sb strings.Builder
+	if f.cmap != nil && len(f.cmap.mapping) > 0 {
+		width := f.cmap.width
+		if width == 0 {
+			width = 1
+			if f.twoByte {
+				width = 2
+			}
+		}
+		for i := 0; i+width <= len(s); i += width {
+			code := pdfCode(s[i : i+width])
+			if text, ok := f.cmap.mapping[code]; ok {
+				sb.WriteString(text)
+			} else if width == 1 {
+				writeLatin1(&sb, s[i])
+			}
+		}
+		return sb.String()
+	}
+	if f.twoByte {
+		// Glyph IDs without a ToUnicode map can't be turned into text.
+		return ""
+	}
+	for _, b := range s {
+		writeLatin1(&sb, b)
+	}
+	return sb.String()
+}
+
+// winAnsiExtras maps the printable WinAnsiEncoding bytes that differ from Latin-1.
+var winAnsiExtras = map[byte]rune{
+	0x80: '€', 0x85: '…', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”',
+	0x95: '•', 0x96: '–', 0x97: '—', 0x99: '™',
+}
+
+func writeLatin1(sb *strings.Builder, b byte) {
+	switch {
+	case b == '\t' || b == '\n' || b == '\r':
+		sb.WriteByte(' ')
+	case b < 0x20 || b == 0x7f:
+	case b < 0x80:
+		sb.WriteByte(b)
+	default:
+		if r, ok := winAnsiExtras[b]; ok {
+			sb.WriteRune(r)
+		} else if b >= 0xa0 {
+			sb.WriteRune(rune(b))
+		}
+	}
+}
+
+func pdfCode(b []byte) int {
+	code := 0
+	for _, c := range b {
+		code = code<<8 | int(c)
+	}
+	return code
+}
+
+// pdfCMap is the part of a ToUnicode CMap needed to decode text.
+type pdfCMap struct {
+	width   int
+	mapping map[int]string
+}
+
+// parseCMap reads a ToUnicode CMap. Every token and mapped code is charged to
+// the document budget, and parsing stops with what was read so far once the
+// budget is used up.
+func (d *pdfDocument) parseCMap(data []byte) *pdfCMap {
+	cmap := &pdfCMap{mapping: make(map[int]string)}
+	lex := &pdfLexer{data: data}
+	var operands []any
+	set := func(code int, text string) bool {
+		if d.overBudget() {
+			return false
+		}
+		d.operations++
+		d.cmapEntries++
+		cmap.mapping[code] = text
+		return true
+	}
+	for {
+		if d.operations++; d.overBudget() {
+			break
+		}
+		token := lex.readValue()
+		if token == nil {
+			break
+		}
+		keyword, ok := token.(pdfKeyword)
+		if !ok {
+			operands = append(operands, token)
+			continue
+		}
+		switch keyword {
+		case "endcodespacerange":
+			if len(operands) >= 1 && cmap.width == 0 {
+				if lo, ok := operands[0].(pdfString); ok && len(lo) > 0 && len(lo) <= 4 {
+					cmap.width = len(lo)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 && !set(pdfCode(src), decodeUTF16BE(dst)) {
+					break
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands) && !d.overBudget(); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 {
+					continue
+				}
+				start, end := pdfCode(lo), pdfCode(hi)
+				if end < start || end-start > 0xffff {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					units := utf16Units(dst)
+					if len(units) == 0 {
+						continue
+					}
+					for code := start; code <= end; code++ {
+						shifted := slices.Clone(units)
+						shifted[len(shifted)-1] += uint16(code - start)
+						if !set(code, string(utf16.Decode(shifted))) {
+							break
+						}
+					}
+				case []any:
+					for j, item := range dst {
+						if str, ok := item.(pdfString); ok && start+j <= end && !set(start+j, decodeUTF16BE(str)) {
+							break
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return cmap
+}
+
+func utf16Units(b []byte) []uint16 {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return units
+}
+
+func decodeUTF16BE(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+	return string(utf16.Decode(utf16Units(b)))
+}
+
+// pdfTextWriter accumulates text and turns positioning operators into spaces
+// and line breaks.
+type pdfTextWriter struct {
+	sb    strings.Builder
+	lineY float64
+	hasY  bool
+}
+
+func (w *pdfTextWriter) write(text string) {
+	w.sb.WriteString(text)
+}
+
+func (w *pdfTextWriter) space() {
+	s := w.sb.String()
+	if s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		w.sb.WriteByte(' ')
+	}
+}
+
+func (w *pdfTextWriter) newline() {
+	s := w.sb.String()
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		w.sb.WriteByte('\n')
+	}
+}
+
+// moveTo records a new line position, breaking the line when y changes.
+func (w *pdfTextWriter) moveTo(y float64) {
+	if w.hasY && math.Abs(y-w.lineY) > 1 {
+		w.newline()
+	} else {
+		w.space()
+	}
+	w.lineY, w.hasY = y, true
+}
+
+func (w *pdfTextWriter) String() string {
+	lines := strings.Split(w.sb.String(), "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// interpret runs the text operators of a content stream. active holds the
+// forms being drawn so a form that draws itself is skipped.
+func (d *pdfDocument) interpret(w *pdfTextWriter, content []byte, resources pdfDict, depth int, active map[*pdfStream]bool) {
+	if depth > pdfMaxNesting {
+		return
+	}
+	fonts := map[pdfName]*pdfFont{}
+	font := &pdfFont{}
+	lex := &pdfLexer{data: content}
+	var operands []any
+	number := func(i int) float64 {
+		if i < len(operands) {
+			if n, ok := operands[i].(float64); ok {
+				return n
+			}
+		}
+		return 0
+	}
+	show := func(value any) {
+		if str, ok := value.(pdfString); ok {
+			w.write(font.decode(str))
+		}
+	}
+	for {
+		if d.operations++; d.operations > pdfMaxOperations {
+			return
+		}
+		token := lex.readValue()
+		if token == nil {
+			return
+		}
+		op, ok := token.(pdfKeyword)
+		if !ok {
+			operands = append(operands, token)
+			continue
+		}
+		switch op {
+		case "BT":
+			w.space()
+		case "Tf":
+			if len(operands) >= 1 {
+				if name, ok := operands[0].(pdfName); ok {
+					font = d.font(resources, name, fonts)
+				}
+			}
+		case "Td", "TD":
+			if ty := number(1); ty != 0 {
+				w.newline()
+				w.lineY += ty
+			} else {
+				w.space()
+			}
+		case "Tm":
+			w.moveTo(number(5))
+		case "T*":
+			w.newline()
+		case "Tj":
+			if len(operands) >= 1 {
+				show(operands[len(operands)-1])
+			}
+		case "'", "\"":
+			w.newline()
+			if len(operands) >= 1 {
+				show(operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				items, _ := operands[len(operands)-1].([]any)
+				for _, item := range items {
+					if offset, ok := item.(float64); ok {
+						// Large negative kerning is how many generators draw spaces.
+						if offset < -200 {
+							w.space()
+						}
+						continue
+					}
+					show(item)
+				}
+			}
+		case "Do":
+			if len(operands) >= 1 {
+				name, _ := operands[0].(pdfName)
+				form, ok := d.resolve(d.dict(resources["XObject"])[name]).(*pdfStream)
+				if ok && form.Dict["Subtype"] == pdfName("Form") && !active[form] {
+					if data, err := d.decodeStream(form); err == nil {
+						formResources := d.dict(form.Dict["Resources"])
+						if formResources == nil {
+							formResources = resources
+						}
+						active[form] = true
+						d.interpret(w, data, formResources, depth+1, active)
+						delete(active, form)
+					}
+				}
+			}
+		case "ID":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// pdfLexer reads PDF tokens and values.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isPDFWhitespace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// readIndirect reads the body of an indirect object after "N G obj" and
+// returns it with the offset where it ended.
+func (l *pdfLexer) readIndirect() (any, int) {
+	var values []any
+	for {
+		token := l.readValue()
+		switch token {
+		case nil, pdfKeyword("endobj"):
+			values = foldPDFRefs(values)
+			if len(values) == 0 {
+				return nil, l.pos
+			}
+			return values[0], l.pos
+		case pdfKeyword("stream"):
+			values = foldPDFRefs(values)
+			dict, _ := lastOf(values).(pdfDict)
+			return &pdfStream{Dict: dict, Data: l.readStreamData(dict)}, l.pos
+		}
+		values = append(values, token)
+	}
+}
+
+func lastOf(values []any) any {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[len(values)-1]
+}
+
+func (l *pdfLexer) readStreamData(dict pdfDict) []byte {
+	if l.pos < len(l.data) && l.data[l.pos] == '\r' {
+		l.pos++
+	}
+	if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+		l.pos++
+	}
+	start := l.pos
+	if length, ok := dict["Length"].(float64); ok && length >= 0 {
+		end := start + int(length)
+		if end <= len(l.data) {
+			rest := bytes.TrimLeft(l.data[end:min(end+32, len(l.data))], "\r\n \t")
+			if bytes.HasPrefix(rest, []byte("endstream")) {
+				l.pos = end
+				return l.data[start:end]
+			}
+		}
+	}
+	// Length is missing, indirect or wrong; fall back to the endstream marker.
+	idx := bytes.Index(l.data[start:], []byte("endstream"))
+	if idx < 0 {
+		l.pos = len(l.data)
+		return l.data[start:]
+	}
+	l.pos = start + idx
+	return bytes.TrimRight(l.data[start:start+idx], "\r\n")
+}
+
+// skipInlineImage skips the binary data of an inline image after ID.
+func (l *pdfLexer) skipInlineImage() {
+	if l.pos < len(l.data) && isPDFWhitespace(l.data[l.pos]) {
+		l.pos++
+	}
+	for i := l.pos; i+2 <= len(l.data); i++ {
+		if l.data[i] == 'E' && l.data[i+1] == 'I' && (i == 0 || isPDFWhitespace(l.data[i-1])) &&
+			(i+2 == len(l.data) || isPDFWhitespace(l.data[i+2]) || isPDFDelimiter(l.data[i+2])) {
+			l.pos = i + 2
+			return
+		}
+	}
+	l.pos = len(l.data)
+}
+
+// readValue reads the next value. Arrays and dictionaries are read whole with
+// "N G R" references folded into pdfRef; operators and other bare words come
+// back as pdfKeyword. It returns nil at the end of input.
+func (l *pdfLexer) readValue() any {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil
+	}
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return l.readLiteralString()
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		items := l.readUntil(">>")
+		dict := pdfDict{}
+		for i := 0; i+1 < len(items); i += 2 {
+			if key, ok := items[i].(pdfName); ok {
+				dict[key] = items[i+1]
+			}
+		}
+		return dict
+	case c == '<':
+		return l.readHexString()
+	case c == '>' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '>':
+		l.pos += 2
+		return pdfKeyword(">>")
+	case c == '[':
+		l.pos++
+		return l.readUntil("]")
+	case c == ']' || c == '{' || c == '}' || c == ')' || c == '>':
+		l.pos++
+		return pdfKeyword(string(c))
+	case c == '/':
+		return l.readName()
+	}
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	word := string(l.data[start:l.pos])
+	switch word {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return pdfKeyword("null")
+	}
+	if n, err := strconv.ParseFloat(word, 64); err == nil && (word[0] == '-' || word[0] == '+' || word[0] == '.' || (word[0] >= '0' && word[0] <= '9')) {
+		return n
+	}
+	return pdfKeyword(word)
+}
+
+func (l *pdfLexer) readUntil(closer pdfKeyword) []any {
+	var items []any
+	for {
+		token := l.readValue()
+		if token == nil || token == closer {
+			return foldPDFRefs(items)
+		}
+		items = append(items, token)
+	}
+}
+
+func foldPDFRefs(items []any) []any {
+	out := items[:0]
+	for _, item := range items {
+		if item == pdfKeyword("R") && len(out) >= 2 {
+			num, ok1 := out[len(out)-2].(float64)
+			gen, ok2 := out[len(out)-1].(float64)
+			if ok1 && ok2 {
+				out = append(out[:len(out)-2], pdfRef{Num: int(num), Gen: int(gen)})
+				continue
+			}
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func (l *pdfLexer) readName() pdfName {
+	l.pos++ // '/'
+	var sb strings.Builder
+	for l.pos < len(l.data) && !isPDFWhitespace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		c := l.data[l.pos]
+		if c == '#' && l.pos+2 < len(l.data) {
+			if b, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				sb.WriteByte(byte(b))
+				l.pos += 3
+				continue
+			}
+		}
+		sb.WriteByte(c)
+		l.pos++
+	}
+	return pdfName(sb.String())
+}
+
+func (l *pdfLexer) readHexString() pdfString {
+	l.pos++ // '<'
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFWhitespace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // '>'
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		b, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			continue
+		}
+		out = append(out, byte(b))
+	}
+	return out
+}
+
+func (l *pdfLexer) readLiteralString() pdfString {
+	l.pos++ // '('
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			esc := l.data[l.pos]
+			l.pos++
+			switch esc {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				value := int(esc - '0')
+				for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+					value = value*8 + int(l.data[l.pos]-'0')
+					l.pos++
+				}
+				out = append(out, byte(value))
+			default:
+				out = append(out, esc)
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
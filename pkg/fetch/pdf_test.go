@@ -0,0 +1,216 @@
+package fetch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildTestPDF assembles a PDF from object bodies numbered from 1; object 1
+// must be the catalog. Streams are written as given.
+func buildTestPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, body := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func pdfStreamObject(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractPDFTextUncompressed(t *testing.T) {
+	content := "BT /F1 12 Tf 72 720 Td (Attention Is All You Need) Tj 0 -14 Td (Ashish Vaswani, Noam Shazeer) Tj ET\n" +
+		"BT /F1 10 Tf 72 680 Td [(Dominant) -300 (sequence) -300 (models)] TJ T* (are based on \\(recurrent\\) networks) Tj ET"
+	pdf := buildTestPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>",
+		pdfStreamObject("", []byte(content)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+
+	text, err := extractPDFText(pdf)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	want := "Attention Is All You Need\nAshish Vaswani, Noam Shazeer\nDominant sequence models\nare based on (recurrent) networks"
+	if text != want {
+		t.Fatalf("unexpected text:\n%q\nwant:\n%q", text, want)
+	}
+}
+
+func TestExtractPDFTextCompressedWithToUnicode(t *testing.T) {
+	cmap := "/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n" +
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+		"2 beginbfchar <0001> <0048> <0002> <00E9> endbfchar\n" +
+		"1 beginbfrange <0003> <0005> <006C> endbfrange\n" +
+		"endcmap CMapName currentdict /CMap defineresource pop end end"
+	page1 := "BT /F1 12 Tf 1 0 0 1 72 720 Tm <00010002000300030005> Tj ET"
+	page2 := "BT /F2 12 Tf 1 0 0 1 72 720 Tm (Second page) Tj 1 0 0 1 72 700 Tm (next line) Tj ET"
+	pdf := buildTestPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 7 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F2 9 0 R >> >> /Contents [6 0 R] >>",
+		pdfStreamObject("/Filter /FlateDecode", deflate(t, page1)),
+		pdfStreamObject("/Filter [/FlateDecode]", deflate(t, page2)),
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /Encoding /Identity-H /ToUnicode 8 0 R >>",
+		pdfStreamObject("/Filter /FlateDecode", deflate(t, cmap)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Times-Roman >>",
+	)
+
+	text, err := extractPDFText(pdf)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	// <0003>-<0005> map to l, m, n, so 0001 0002 0003 0003 0005 reads "Hélln".
+	want := "Hélln\n\nSecond page\nnext line"
+	if text != want {
+		t.Fatalf("unexpected text:\n%q\nwant:\n%q", text, want)
+	}
+}
+
+func TestExtractPDFTextObjectStreams(t *testing.T) {
+	// The page (object 5) only exists inside the object stream (object 3).
+	header := "5 0 "
+	objStm := header + "<< /Type /Page /Parent 2 0 R /Resources << >> /Contents 4 0 R >>"
+	pdf := buildTestPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [5 0 R] /Count 1 >>",
+		pdfStreamObject(fmt.Sprintf("/Type /ObjStm /N 1 /First %d /Filter /FlateDecode", len(header)), deflate(t, objStm)),
+		pdfStreamObject("", []byte("BT (From an object stream) Tj ET")),
+	)
+
+	text, err := extractPDFText(pdf)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if text != "From an object stream" {
+		t.Fatalf("unexpected text %q", text)
+	}
+}
+
+func TestExtractPDFTextRejectsNonPDF(t *testing.T) {
+	if _, err := extractPDFText([]byte("<html></html>")); err == nil {
+		t.Fatal("expected an error for non-PDF input")
+	}
+	encrypted := buildTestPDF("<< /Type /Catalog /Pages 2 0 R >>")
+	encrypted = bytes.Replace(encrypted, []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt 2 0 R"), 1)
+	if _, err := extractPDFText(encrypted); err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Fatalf("expected encrypted PDF error, got %v", err)
+	}
+}
+
+func TestExtractPDFTextSelfDrawingForm(t *testing.T) {
+	pdf := buildTestPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /X 5 0 R >> >> /Contents 4 0 R >>",
+		pdfStreamObject("", []byte("/X Do")),
+		pdfStreamObject("/Subtype /Form /Resources << /XObject << /X 5 0 R >> >>", []byte("BT (loop) Tj ET /X Do /X Do")),
+	)
+	text, err := extractPDFText(pdf)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if text != "loop" {
+		t.Fatalf("expected the form to be drawn once, got %q", text)
+	}
+}
+
+func TestExtractPDFTextBoundsNestedForms(t *testing.T) {
+	// Each form draws the next one twice, which would take 2^depth passes
+	// without the operation budget.
+	const depth = pdfMaxNesting
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /F 5 0 R >> >> /Contents 4 0 R >>",
+		pdfStreamObject("", []byte("/F Do")),
+	}
+	for i := range depth {
+		objects = append(objects, pdfStreamObject(
+			fmt.Sprintf("/Subtype /Form /Resources << /XObject << /F %d 0 R >> >> /Filter /FlateDecode", 6+i),
+			deflate(t, "BT (x) Tj ET /F Do /F Do"),
+		))
+	}
+	doc := parsePDF(buildTestPDF(objects...))
+	pages := doc.pages()
+	if len(pages) != 1 {
+		t.Fatalf("expected one page, got %d", len(pages))
+	}
+	doc.interpret(&pdfTextWriter{}, doc.pageContent(pages[0].dict), pages[0].resources, 0, map[*pdfStream]bool{})
+	if doc.operations <= pdfMaxOperations {
+		t.Fatalf("expected the operation budget to stop interpretation, used %d", doc.operations)
+	}
+	if len(doc.decoded) > depth+1 {
+		t.Fatalf("expected each form to be decoded once, decoded %d streams", len(doc.decoded))
+	}
+}
+
+func FuzzExtractPDFText(f *testing.F) {
+	f.Add(buildTestPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /X 5 0 R >> >> /Contents 4 0 R >>",
+		pdfStreamObject("", []byte("BT (a) Tj [(b) -300 (c)] TJ ET /X Do")),
+		pdfStreamObject("/Subtype /Form", []byte("BT <0001> Tj ET")),
+	))
+	f.Add([]byte("%PDF-1.4\n1 0 obj << /Type /Catalog /Pages 1 0 R >> endobj trailer << /Root 1 0 R >>"))
+	f.Add([]byte("%PDF-1.7\n1 0 obj << /Length 99 >>\nstream\n(unterminated"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = extractPDFText(data)
+	})
+}
+
+func TestExtractPDFTextBoundsCMapRanges(t *testing.T) {
+	// Each full-width bfrange expands to 65,536 mappings from a few bytes.
+	var cmap strings.Builder
+	cmap.WriteString("begincmap 1 begincodespacerange <0000> <ffff> endcodespacerange\n")
+	for range 20 {
+		cmap.WriteString("100 beginbfrange\n")
+		for range 100 {
+			cmap.WriteString("<0000> <ffff> <0041>\n")
+		}
+		cmap.WriteString("endbfrange\n")
+	}
+	cmap.WriteString("endcmap")
+
+	doc := parsePDF(buildTestPDF("<< /Type /Catalog >>"))
+	parsed := doc.parseCMap([]byte(cmap.String()))
+	if doc.cmapEntries > pdfMaxCMapEntries || len(parsed.mapping) > pdfMaxCMapEntries {
+		t.Fatalf("expected at most %d CMap entries, got %d (%d mapped)", pdfMaxCMapEntries, doc.cmapEntries, len(parsed.mapping))
+	}
+	if !doc.overBudget() {
+		t.Fatal("expected the CMap to use up the document budget")
+	}
+	if _, err := doc.decodeStream(&pdfStream{Dict: pdfDict{}}); err != errPDFBudget {
+		t.Fatalf("expected no further decoding after the budget is used up, got %v", err)
+	}
+}
@@ -3,20 +3,23 @@ package fetch
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/beeper/agentremote/pkg/shared/httputil"
 	"github.com/beeper/agentremote/pkg/shared/stringutil"
 )
 
+// directMaxResponseBytes caps how much of a response the direct provider will read.
+const directMaxResponseBytes = 10 << 20
+
 type directProvider struct {
-	cfg DirectConfig
+	cfg    DirectConfig
+	client *http.Client
 }
 
 func newDirectProvider(cfg *Config) Provider {
@@ -26,7 +29,14 @@ func newDirectProvider(cfg *Config) Provider {
 	if !stringutil.BoolPtrOr(cfg.Direct.Enabled, true) {
 		return nil
 	}
-	return &directProvider{cfg: cfg.Direct}
+	return &directProvider{
+		cfg: cfg.Direct,
+		client: httputil.NewSafeClient(httputil.SafeClientOptions{
+			Timeout:          time.Duration(cfg.Direct.TimeoutSecs) * time.Second,
+			MaxRedirects:     cfg.Direct.MaxRedirects,
+			MaxResponseBytes: directMaxResponseBytes,
+		}),
+	}
 }
 
 func (p *directProvider) Name() string {
@@ -34,11 +44,16 @@ func (p *directProvider) Name() string {
 }
 
 func (p *directProvider) Fetch(ctx context.Context, req Request) (*Response, error) {
-	if !isAllowedURL(req.URL) {
-		return nil, errors.New("url not allowed")
+	if err := httputil.ValidateURL(req.URL); err != nil {
+		return nil, fmt.Errorf("url not allowed: %w", err)
+	}
+	start := time.Now()
+	if ttl := p.cacheTTL(); ttl > 0 {
+		if page := directCache.get(pageCacheKey(req), ttl); page != nil {
+			return p.response(req, page, start, true), nil
+		}
 	}
 
-	client := &http.Client{Timeout: time.Duration(p.cfg.TimeoutSecs) * time.Second}
 	request, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
 	if err != nil {
 		return nil, err
@@ -46,8 +61,7 @@ func (p *directProvider) Fetch(ctx context.Context, req Request) (*Response, err
 	request.Header.Set("User-Agent", p.cfg.UserAgent)
 	request.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 
-	start := time.Now()
-	resp, err := client.Do(request)
+	resp, err := p.client.Do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -57,68 +71,120 @@ func (p *directProvider) Fetch(ctx context.Context, req Request) (*Response, err
 		return nil, fmt.Errorf("http %d: %s", resp.StatusCode, resp.Status)
 	}
 
-	maxChars := req.MaxChars
-	if maxChars <= 0 {
-		maxChars = p.cfg.MaxChars
-		if maxChars <= 0 {
-			maxChars = DefaultMaxChars
-		}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxChars*2)))
+	finalURL := req.URL
+	if resp.Request != nil && resp.Request.URL != nil {
+		finalURL = resp.Request.URL.String()
+	}
+	page, err := p.extract(body, normalizeContentType(resp.Header.Get("Content-Type")), finalURL, req.ExtractMode)
 	if err != nil {
 		return nil, err
 	}
+	page.FinalURL = finalURL
+	page.Status = resp.StatusCode
+	page.fetchedAt = time.Now()
+	if ttl := p.cacheTTL(); ttl > 0 {
+		directCache.put(pageCacheKey(req), page, ttl)
+	}
+	return p.response(req, page, start, false), nil
+}
+
+func (p *directProvider) cacheTTL() time.Duration {
+	return time.Duration(p.cfg.CacheTtlSecs) * time.Second
+}
 
-	contentType := normalizeContentType(resp.Header.Get("Content-Type"))
-	text := string(body)
-	extractor := "basic"
-	if strings.Contains(contentType, "text/html") {
-		if strings.EqualFold(req.ExtractMode, "text") {
-			text = extractTextFromHTML(text)
-			extractor = "basic-text"
+// extract turns a response body into text according to its content type.
+func (p *directProvider) extract(body []byte, contentType, finalURL, extractMode string) (*cachedPage, error) {
+	page := &cachedPage{ContentType: contentType, Extractor: "raw", Text: string(body)}
+	plain := strings.EqualFold(extractMode, "text")
+	switch {
+	case contentType == "application/pdf" || isPDF(body):
+		text, err := extractPDFText(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract PDF text: %w", err)
+		}
+		page.ContentType = "application/pdf"
+		page.Extractor = "pdf"
+		page.Text = text
+		if strings.TrimSpace(text) == "" {
+			page.Warning = "No text found in PDF; it may be scanned or use unsupported fonts."
+		}
+	case strings.Contains(contentType, "html"):
+		readability := stringutil.BoolPtrOr(p.cfg.Readability, true)
+		doc, err := extractHTMLDocument(string(body), finalURL, readability, plain)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		}
+		page.Extractor = "html"
+		if readability {
+			page.Extractor = "readability"
+		}
+		if plain {
+			page.Extractor += "-text"
 		} else {
-			text = extractTextFromHTML(text)
-			extractor = "basic-markdown"
+			page.Extractor += "-markdown"
 		}
-	} else if strings.Contains(contentType, "application/json") {
+		page.Title = doc.Title
+		page.Text = doc.Text
+	case strings.Contains(contentType, "json"):
 		var decoded any
 		if err := json.Unmarshal(body, &decoded); err == nil {
 			pretty, _ := json.MarshalIndent(decoded, "", "  ")
-			text = string(pretty)
-			extractor = "json"
+			page.Text = string(pretty)
+			page.Extractor = "json"
+		}
+	case strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "xml"):
+	default:
+		if !utf8.Valid(body) {
+			return nil, fmt.Errorf("unsupported content type %s", contentType)
 		}
 	}
+	return page, nil
+}
 
+// response builds the result for req from an extracted page, truncating the
+// text to the requested length.
+func (p *directProvider) response(req Request, page *cachedPage, start time.Time, cached bool) *Response {
+	maxChars := req.MaxChars
+	if maxChars <= 0 {
+		maxChars = p.cfg.MaxChars
+		if maxChars <= 0 {
+			maxChars = DefaultMaxChars
+		}
+	}
+	text := page.Text
 	truncated := false
 	rawLength := len(text)
-	if maxChars > 0 && len(text) > maxChars {
-		text = text[:maxChars] + "...[truncated]"
+	if len(text) > maxChars {
+		cut := maxChars
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "...[truncated]"
 		truncated = true
 	}
-	wrappedLength := len(text)
-
-	finalURL := req.URL
-	if resp.Request != nil && resp.Request.URL != nil {
-		finalURL = resp.Request.URL.String()
-	}
-
 	return &Response{
 		URL:           req.URL,
-		FinalURL:      finalURL,
-		Status:        resp.StatusCode,
-		ContentType:   contentType,
+		FinalURL:      page.FinalURL,
+		Status:        page.Status,
+		ContentType:   page.ContentType,
 		ExtractMode:   req.ExtractMode,
-		Extractor:     extractor,
+		Extractor:     page.Extractor,
+		Title:         page.Title,
 		Truncated:     truncated,
 		Length:        len(text),
 		RawLength:     rawLength,
-		WrappedLength: wrappedLength,
-		FetchedAt:     time.Now().UTC().Format(time.RFC3339),
+		WrappedLength: len(text),
+		FetchedAt:     page.fetchedAt.UTC().Format(time.RFC3339),
 		TookMs:        time.Since(start).Milliseconds(),
 		Text:          text,
+		Warning:       page.Warning,
+		Cached:        cached,
 		Provider:      ProviderDirect,
-	}, nil
+	}
 }
 
 func normalizeContentType(value string) string {
@@ -128,103 +194,3 @@ func normalizeContentType(value string) string {
 	parts := strings.Split(value, ";")
 	return strings.TrimSpace(parts[0])
 }
-
-var fetchBlockedCIDRs = []*net.IPNet{
-	mustParseCIDR("127.0.0.0/8"),
-	mustParseCIDR("10.0.0.0/8"),
-	mustParseCIDR("172.16.0.0/12"),
-	mustParseCIDR("192.168.0.0/16"),
-	mustParseCIDR("169.254.0.0/16"),
-	mustParseCIDR("::1/128"),
-}
-
-func mustParseCIDR(value string) *net.IPNet {
-	_, parsed, err := net.ParseCIDR(value)
-	if err != nil {
-		panic(fmt.Sprintf("invalid CIDR %q: %v", value, err))
-	}
-	return parsed
-}
-
-func isAllowedURL(rawURL string) bool {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return false
-	}
-	if parsed.Scheme != "http" && parsed.Scheme != "https" {
-		return false
-	}
-	host := strings.ToLower(parsed.Hostname())
-	if host == "localhost" {
-		return false
-	}
-	ip := net.ParseIP(host)
-	if ip != nil {
-		if ip4 := ip.To4(); ip4 != nil {
-			ip = ip4
-		}
-		for _, cidr := range fetchBlockedCIDRs {
-			if cidr.Contains(ip) {
-				return false
-			}
-		}
-	}
-	return true
-}
-
-func extractTextFromHTML(html string) string {
-	html = removeHTMLElement(html, "script")
-	html = removeHTMLElement(html, "style")
-	html = removeHTMLElement(html, "noscript")
-
-	var result strings.Builder
-	inTag := false
-	lastWasSpace := false
-	for _, r := range html {
-		if r == '<' {
-			inTag = true
-			continue
-		}
-		if r == '>' {
-			inTag = false
-			if !lastWasSpace {
-				result.WriteRune(' ')
-				lastWasSpace = true
-			}
-			continue
-		}
-		if inTag {
-			continue
-		}
-		if r == '\n' || r == '\r' || r == '\t' || r == ' ' {
-			if !lastWasSpace {
-				result.WriteRune(' ')
-				lastWasSpace = true
-			}
-			continue
-		}
-		result.WriteRune(r)
-		lastWasSpace = false
-	}
-	return strings.TrimSpace(result.String())
-}
-
-func removeHTMLElement(html, tag string) string {
-	lower := strings.ToLower(html)
-	openTag := "<" + tag
-	closeTag := "</" + tag + ">"
-	for {
-		start := strings.Index(lower, openTag)
-		if start == -1 {
-			break
-		}
-		end := strings.Index(lower[start:], closeTag)
-		if end == -1 {
-			break
-		}
-		end += start + len(closeTag)
-		html = html[:start] + html[end:]
-		lower = strings.ToLower(html)
-	}
-	return html
-}
@@ -0,0 +1,86 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirectProviderRefusesInternalAddresses(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	provider := newDirectProvider((&Config{}).WithDefaults())
+	for _, target := range []string{srv.URL, "http://169.254.169.254/latest/meta-data", "file:///etc/passwd"} {
+		if _, err := provider.Fetch(context.Background(), Request{URL: target}); err == nil {
+			t.Fatalf("expected %s to be refused", target)
+		}
+	}
+	if hits != 0 {
+		t.Fatalf("expected no requests to reach the internal server, got %d", hits)
+	}
+}
+
+func TestDirectProviderServesCachedPages(t *testing.T) {
+	const target = "https://cache-test.example/article"
+	directCache.put(pageCacheKey(Request{URL: target, CacheScope: "login-1"}), &cachedPage{
+		FinalURL:    target,
+		Status:      http.StatusOK,
+		ContentType: "text/html",
+		Extractor:   "readability-markdown",
+		Title:       "Cached article",
+		Text:        strings.Repeat("x", 100),
+		fetchedAt:   time.Now(),
+	}, time.Minute)
+
+	provider := newDirectProvider((&Config{}).WithDefaults())
+	resp, err := provider.Fetch(context.Background(), Request{URL: target, ExtractMode: "markdown", MaxChars: 10, CacheScope: "login-1"})
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if !resp.Cached || resp.Title != "Cached article" || resp.Extractor != "readability-markdown" {
+		t.Fatalf("expected cached response, got %+v", resp)
+	}
+	if !resp.Truncated || resp.RawLength != 100 || !strings.HasPrefix(resp.Text, strings.Repeat("x", 10)+"...") {
+		t.Fatalf("expected per-request truncation of cached text, got %+v", resp)
+	}
+	if directCache.get(pageCacheKey(Request{URL: target, ExtractMode: "text", CacheScope: "login-1"}), time.Minute) != nil {
+		t.Fatal("expected the cache to be keyed by extract mode")
+	}
+	if directCache.get(pageCacheKey(Request{URL: target, ExtractMode: "markdown", CacheScope: "login-2"}), time.Minute) != nil {
+		t.Fatal("expected the cache to be keyed by scope")
+	}
+}
+
+func TestDirectProviderExtractsByContentType(t *testing.T) {
+	provider := newDirectProvider((&Config{}).WithDefaults()).(*directProvider)
+	pdf := buildTestPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		pdfStreamObject("", []byte("BT (Paper abstract) Tj ET")),
+	)
+	page, err := provider.extract(pdf, "application/octet-stream", "https://example.com/paper", "markdown")
+	if err != nil {
+		t.Fatalf("extract pdf: %v", err)
+	}
+	if page.Extractor != "pdf" || page.ContentType != "application/pdf" || page.Text != "Paper abstract" {
+		t.Fatalf("unexpected pdf page %+v", page)
+	}
+
+	page, err = provider.extract([]byte(`{"a":1}`), "application/json", "https://example.com/api", "markdown")
+	if err != nil || page.Extractor != "json" || page.Text != "{\n  \"a\": 1\n}" {
+		t.Fatalf("unexpected json page %+v (err %v)", page, err)
+	}
+
+	if _, err = provider.extract([]byte{0xff, 0xfe, 0x00, 0x81}, "image/png", "https://example.com/a.png", "markdown"); err == nil {
+		t.Fatal("expected binary content to be rejected")
+	}
+}
@@ -5,6 +5,9 @@ type Request struct {
 	URL         string
 	ExtractMode string // "markdown" or "text"
 	MaxChars    int
+	// CacheScope partitions cached pages, e.g. by login, so a page fetched for
+	// one user is never served to another.
+	CacheScope string
 }
 
 // Response represents normalized fetch output.
@@ -15,6 +18,7 @@ type Response struct {
 	ContentType   string
 	ExtractMode   string
 	Extractor     string
+	Title         string
 	Truncated     bool
 	Length        int
 	RawLength     int
@@ -55,6 +55,62 @@ func formatCronJobListText(jobs []Job) string {
 	}
 	return strings.TrimRight(b.String(), "\n")
 }
+
+const cronRunOutputPreviewMax = 80
+
+func formatCronRunListText(jobID string, runs []JobRun) string {
+	if len(runs) == 0 {
+		return fmt.Sprintf("Cron runs for %s: (none)", cronShortID(jobID))
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Cron runs for %s (newest first):\n", cronShortID(jobID)))
+	for _, run := range runs {
+		b.WriteString(fmt.Sprintf("- %s %s %s status=%s duration=%s", cronShortID(run.RunID), formatUnixMs(run.StartedAtMs), run.Trigger, run.Status, formatDurationMs(run.DurationMs)))
+		if run.PromptTokens > 0 || run.CompletionTokens > 0 {
+			b.WriteString(fmt.Sprintf(" tokens=%d/%d", run.PromptTokens, run.CompletionTokens))
+		}
+		if errText := strings.TrimSpace(run.Error); errText != "" {
+			b.WriteString(" error=" + truncateContextText(normalizeContextText(errText), cronRunOutputPreviewMax))
+		} else if output := strings.TrimSpace(run.Output); output != "" {
+			b.WriteString(" output=" + truncateContextText(normalizeContextText(output), cronRunOutputPreviewMax))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Use `!ai cron runs <jobId> <runId>` for the full output.")
+	return b.String()
+}
+
+func formatCronRunText(run JobRun) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Run %s of job %s\n", run.RunID, run.JobID))
+	b.WriteString(fmt.Sprintf("- trigger: %s\n", run.Trigger))
+	b.WriteString(fmt.Sprintf("- started: %s\n", formatUnixMs(run.StartedAtMs)))
+	b.WriteString(fmt.Sprintf("- finished: %s (%s)\n", formatUnixMs(run.FinishedAtMs), formatDurationMs(run.DurationMs)))
+	b.WriteString(fmt.Sprintf("- status: %s\n", run.Status))
+	if errText := strings.TrimSpace(run.Error); errText != "" {
+		b.WriteString(fmt.Sprintf("- error: %s\n", errText))
+	}
+	if model := strings.TrimSpace(run.Model); model != "" {
+		b.WriteString(fmt.Sprintf("- model: %s\n", model))
+	}
+	if run.PromptTokens > 0 || run.CompletionTokens > 0 {
+		b.WriteString(fmt.Sprintf("- tokens: %d in, %d out", run.PromptTokens, run.CompletionTokens))
+		if run.ReasoningTokens > 0 {
+			b.WriteString(fmt.Sprintf(" (%d reasoning)", run.ReasoningTokens))
+		}
+		b.WriteString("\n")
+	}
+	if room := strings.TrimSpace(run.DeliveredRoomID); room != "" {
+		b.WriteString(fmt.Sprintf("- delivered: %s %s\n", room, strings.TrimSpace(run.DeliveredEventID)))
+	}
+	output := strings.TrimSpace(run.Output)
+	if output == "" {
+		output = "(no output)"
+	}
+	b.WriteString("\n" + output)
+	return b.String()
+}
+
 func formatCronSchedule(s Schedule) string {
 	switch strings.ToLower(strings.TrimSpace(s.Kind)) {
 	case "every":
@@ -3,6 +3,7 @@ package cron
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	iruntime "github.com/beeper/agentremote/pkg/integrations/runtime"
@@ -18,6 +19,7 @@ type cronSchedulerHost interface {
 	CronUpdate(ctx context.Context, jobID string, patch JobPatch) (Job, error)
 	CronRemove(ctx context.Context, jobID string) (bool, error)
 	CronRun(ctx context.Context, jobID string) (bool, string, error)
+	CronRuns(ctx context.Context, jobID string, limit int) ([]JobRun, error)
 }
 
 type Integration struct {
@@ -67,7 +69,7 @@ func (i *Integration) CommandDefinitions(_ context.Context, _ iruntime.CommandSc
 	return []iruntime.CommandDefinition{{
 		Name:           "cron",
 		Description:    "Inspect/manage scheduled jobs",
-		Args:           "[status|list|add|update|run|remove] ...",
+		Args:           "[status|list|add|update|run|runs|remove] ...",
 		RequiresPortal: true,
 		RequiresLogin:  true,
 	}}
@@ -217,8 +219,39 @@ func (i *Integration) executeCronCommand(ctx context.Context, call iruntime.Comm
 			reason = "not-run"
 		}
 		reply("Not run (%s).", reason)
+	case "runs", "history":
+		if len(call.Args) < 2 || strings.TrimSpace(call.Args[1]) == "" {
+			reply("Usage: `!ai cron runs <jobId> [limit|runId]`")
+			return nil
+		}
+		jobID := strings.TrimSpace(call.Args[1])
+		limit, runID := defaultRunsLimit, ""
+		if len(call.Args) > 2 {
+			arg := strings.TrimSpace(call.Args[2])
+			if n, err := strconv.Atoi(arg); err == nil {
+				limit = clampRunsLimit(n)
+			} else {
+				limit, runID = maxRunsLimit, arg
+			}
+		}
+		runs, err := scheduler.CronRuns(ctx, jobID, limit)
+		if err != nil {
+			reply("Cron runs failed: %s", err.Error())
+			return nil
+		}
+		if runID == "" {
+			reply("%s", formatCronRunListText(jobID, runs))
+			return nil
+		}
+		for _, run := range runs {
+			if strings.HasPrefix(run.RunID, runID) {
+				reply("%s", formatCronRunText(run))
+				return nil
+			}
+		}
+		reply("No such run.")
 	default:
-		reply("Usage:\n- `!ai cron status`\n- `!ai cron list [all]`\n- `!ai cron add <job-json>`\n- `!ai cron update <jobId> <patch-json>`\n- `!ai cron run <jobId>`\n- `!ai cron remove <jobId>`")
+		reply("Usage:\n- `!ai cron status`\n- `!ai cron list [all]`\n- `!ai cron add <job-json>`\n- `!ai cron update <jobId> <patch-json>`\n- `!ai cron run <jobId>`\n- `!ai cron runs <jobId> [limit|runId]`\n- `!ai cron remove <jobId>`")
 	}
 	return nil
 }
@@ -284,6 +317,9 @@ func (i *Integration) buildToolExecDeps(ctx context.Context, scope iruntime.Tool
 	deps.Run = func(jobID string) (bool, string, error) {
 		return scheduler.CronRun(ctx, jobID)
 	}
+	deps.Runs = func(jobID string, limit int) ([]JobRun, error) {
+		return scheduler.CronRuns(ctx, jobID, limit)
+	}
 	return deps
 }
 
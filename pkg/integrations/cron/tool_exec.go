@@ -8,6 +8,7 @@ import (
 	"time"
 
 	agenttools "github.com/beeper/agentremote/pkg/agents/tools"
+	"github.com/beeper/agentremote/pkg/textfs"
 )
 
 type ToolCreateContext struct {
@@ -28,6 +29,7 @@ type ToolExecDeps struct {
 	Update func(id string, patch JobPatch) (Job, error)
 	Remove func(id string) (bool, error)
 	Run    func(id string) (bool, string, error)
+	Runs   func(id string, limit int) ([]JobRun, error)
 
 	NowMs                func() int64
 	ResolveCreateContext func() ToolCreateContext
@@ -36,6 +38,13 @@ type ToolExecDeps struct {
 }
 
 const (
+	defaultRunsLimit = 10
+	maxRunsLimit     = 50
+	// runOutputMaxLines and runOutputMaxBytes cap each run's output in the
+	// runs action so a page of runs stays small.
+	runOutputMaxLines = 20
+	runOutputMaxBytes = 2000
+
 	reminderContextMessagesMax   = 10
 	reminderContextPerMessageMax = 220
 	reminderContextTotalMax      = 700
@@ -198,11 +207,66 @@ func ExecuteTool(ctx context.Context, args map[string]any, deps ToolExecDeps) (s
 			out["reason"] = reason
 		}
 		return agenttools.JSONResult(out).Text(), nil
+	case "runs":
+		if deps.Runs == nil {
+			return errorJSON("cron runs unavailable"), nil
+		}
+		jobID := readJobID(args)
+		if jobID == "" {
+			return errorJSON("jobId required"), nil
+		}
+		limit := clampRunsLimit(agenttools.ReadIntDefault(args, "limit", defaultRunsLimit))
+		offset := max(agenttools.ReadIntDefault(args, "offset", 0), 0)
+		// One extra run tells whether there is another page.
+		runs, err := deps.Runs(jobID, offset+limit+1)
+		if err != nil {
+			return errorJSON(err.Error()), nil
+		}
+		runs = runs[min(offset, len(runs)):]
+		hasMore := len(runs) > limit
+		runs = runs[:min(limit, len(runs))]
+		for i := range runs {
+			truncateRunOutput(&runs[i])
+		}
+		out := map[string]any{
+			"jobId":   jobID,
+			"runs":    runs,
+			"offset":  offset,
+			"hasMore": hasMore,
+		}
+		if hasMore {
+			out["nextOffset"] = offset + len(runs)
+		}
+		return agenttools.JSONResult(out).Text(), nil
 	default:
 		return errorJSON(fmt.Sprintf("unknown action: %s", action)), nil
 	}
 }
 
+// clampRunsLimit bounds how many runs a single runs request returns.
+func clampRunsLimit(limit int) int {
+	if limit <= 0 {
+		return defaultRunsLimit
+	}
+	return min(limit, maxRunsLimit)
+}
+
+// truncateRunOutput keeps the head of a run's output; the room the run
+// delivered to has the full reply.
+func truncateRunOutput(run *JobRun) {
+	tr := textfs.TruncateHead(run.Output, runOutputMaxLines, runOutputMaxBytes)
+	if !tr.Truncated {
+		return
+	}
+	if tr.FirstLineExceedsLimit {
+		// A rune is at most 4 bytes, so this stays within the byte budget.
+		run.Output = truncateContextText(run.Output, runOutputMaxBytes/4)
+	} else {
+		run.Output = tr.Content
+	}
+	run.OutputTruncated = true
+}
+
 func readJobID(args map[string]any) string {
 	if args == nil {
 		return ""
@@ -1,6 +1,12 @@
 package cron
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
 
 func TestInjectToolContextSetsAgentID(t *testing.T) {
 	job := JobCreate{
@@ -67,3 +73,61 @@ func TestInjectToolContextPreservesExplicitDeliveryTarget(t *testing.T) {
 		t.Fatalf("expected explicit delivery target to be preserved, got %q", job.Delivery.To)
 	}
 }
+
+func TestExecuteToolRuns(t *testing.T) {
+	var gotID string
+	var gotLimit int
+	deps := ToolExecDeps{
+		Runs: func(id string, limit int) ([]JobRun, error) {
+			gotID, gotLimit = id, limit
+			return []JobRun{{RunID: "run-1", JobID: id, Status: "error", Error: "boom"}}, nil
+		},
+	}
+	out, err := ExecuteTool(context.Background(), map[string]any{"action": "runs", "jobId": "job-1", "limit": 500}, deps)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotID != "job-1" || gotLimit != maxRunsLimit+1 {
+		t.Fatalf("expected clamped limit for job-1, got %q/%d", gotID, gotLimit)
+	}
+	if !strings.Contains(out, `"runId":"run-1"`) || !strings.Contains(out, `"error":"boom"`) {
+		t.Fatalf("unexpected runs output: %s", out)
+	}
+
+	deps.Runs = func(id string, limit int) ([]JobRun, error) {
+		runs := make([]JobRun, 0, limit)
+		for i := range min(limit, 5) {
+			runs = append(runs, JobRun{RunID: fmt.Sprintf("run-%d", i), Output: strings.Repeat("line\n", 100)})
+		}
+		return runs, nil
+	}
+	out, _ = ExecuteTool(context.Background(), map[string]any{"action": "runs", "jobId": "job-1", "limit": 2, "offset": 2}, deps)
+	var page struct {
+		Runs       []JobRun `json:"runs"`
+		HasMore    bool     `json:"hasMore"`
+		NextOffset int      `json:"nextOffset"`
+	}
+	if err = json.Unmarshal([]byte(out), &page); err != nil {
+		t.Fatalf("decode runs page: %v (%s)", err, out)
+	}
+	if len(page.Runs) != 2 || page.Runs[0].RunID != "run-2" || !page.HasMore || page.NextOffset != 4 {
+		t.Fatalf("unexpected runs page: %s", out)
+	}
+	if !page.Runs[0].OutputTruncated || strings.Count(page.Runs[0].Output, "\n") >= runOutputMaxLines {
+		t.Fatalf("expected truncated run output, got %q", page.Runs[0].Output)
+	}
+	long := JobRun{Output: strings.Repeat("x", 3*runOutputMaxBytes)}
+	truncateRunOutput(&long)
+	if !long.OutputTruncated || long.Output == "" || len(long.Output) > runOutputMaxBytes {
+		t.Fatalf("expected a long single line to be cut, got %d bytes", len(long.Output))
+	}
+	out, _ = ExecuteTool(context.Background(), map[string]any{"action": "runs", "jobId": "job-1", "limit": 2, "offset": 4}, deps)
+	if !strings.Contains(out, `"hasMore":false`) || strings.Contains(out, "nextOffset") {
+		t.Fatalf("expected last page, got %s", out)
+	}
+
+	out, _ = ExecuteTool(context.Background(), map[string]any{"action": "runs"}, deps)
+	if !strings.Contains(out, "jobId required") {
+		t.Fatalf("expected jobId error, got %s", out)
+	}
+}
@@ -72,6 +72,28 @@ type Job struct {
 	State          JobState  `json:"state"`
 }
 
+// JobRun is one recorded execution of a job.
+type JobRun struct {
+	RunID            string `json:"runId"`
+	JobID            string `json:"jobId"`
+	Trigger          string `json:"trigger"`
+	StartedAtMs      int64  `json:"startedAtMs"`
+	FinishedAtMs     int64  `json:"finishedAtMs"`
+	DurationMs       int64  `json:"durationMs"`
+	Status           string `json:"status"`
+	Error            string `json:"error,omitempty"`
+	RoomID           string `json:"roomId,omitempty"`
+	DeliveredRoomID  string `json:"deliveredRoomId,omitempty"`
+	DeliveredEventID string `json:"deliveredEventId,omitempty"`
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int64  `json:"promptTokens,omitempty"`
+	CompletionTokens int64  `json:"completionTokens,omitempty"`
+	ReasoningTokens  int64  `json:"reasoningTokens,omitempty"`
+	Output           string `json:"output,omitempty"`
+	// OutputTruncated is set when Output was cut short for a tool response.
+	OutputTruncated bool `json:"outputTruncated,omitempty"`
+}
+
 type JobCreate struct {
 	AgentID        *string   `json:"agentId,omitempty"`
 	Name           string    `json:"name,omitempty"`
@@ -19,7 +19,7 @@ func MergeSearchConfig(defaults *agents.MemorySearchConfig, overrides *agents.Me
 	fallback := pickString(o.fallback, d.fallback, "none")
 
 	hasRemoteConfig := d.hasRemote || o.hasRemote
-	includeRemote := hasRemoteConfig || provider == "openai" || provider == "gemini" || provider == "auto"
+	includeRemote := hasRemoteConfig || provider == "openai" || provider == "gemini" || provider == "local" || provider == "auto"
 
 	remote := RemoteConfig{}
 	if includeRemote {
@@ -41,6 +41,8 @@ func MergeSearchConfig(defaults *agents.MemorySearchConfig, overrides *agents.Me
 		modelDefault = DefaultGeminiEmbeddingModel
 	case "openai":
 		modelDefault = DefaultOpenAIEmbeddingModel
+	case "local":
+		modelDefault = DefaultLocalEmbeddingModel
 	}
 	model := pickString(o.model, d.model, modelDefault)
 
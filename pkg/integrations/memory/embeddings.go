@@ -0,0 +1,279 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	memorycore "github.com/beeper/agentremote/pkg/memory"
+)
+
+// vectorScanLimit bounds how many embedded chunks a vector search scores in-process.
+const vectorScanLimit = 5000
+
+const emptyEmbedding = "[]"
+
+func (m *MemorySearchManager) providerKey() string {
+	if m.embedder == nil {
+		return lexicalProviderKey
+	}
+	return m.embedder.Key()
+}
+
+// embedChunks returns the JSON-encoded embedding for each chunk, reusing cached
+// vectors by chunk hash. Chunks that can't be embedded get an empty vector so
+// keyword search keeps working when the provider is unavailable.
+func (m *MemorySearchManager) embedChunks(ctx context.Context, chunks []memorycore.Chunk) []string {
+	out := make([]string, len(chunks))
+	for i := range out {
+		out[i] = emptyEmbedding
+	}
+	if m.embedder == nil || len(chunks) == 0 {
+		return out
+	}
+
+	hashes := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		hashes = append(hashes, chunk.Hash)
+	}
+	vectors := m.loadCachedEmbeddings(ctx, hashes)
+
+	var missingTexts []string
+	var missingHashes []string
+	queued := make(map[string]struct{})
+	for _, chunk := range chunks {
+		if _, ok := vectors[chunk.Hash]; ok {
+			continue
+		}
+		if _, ok := queued[chunk.Hash]; ok {
+			continue
+		}
+		queued[chunk.Hash] = struct{}{}
+		missingTexts = append(missingTexts, chunk.Text)
+		missingHashes = append(missingHashes, chunk.Hash)
+	}
+	if len(missingTexts) > 0 {
+		embedded, err := m.embedder.EmbedBatch(ctx, missingTexts)
+		if err != nil {
+			m.log.Warn().Err(err).Int("chunks", len(missingTexts)).Msg("Memory embedding failed; indexing without vectors")
+		} else {
+			fresh := make(map[string][]float64, len(embedded))
+			for i, vector := range embedded {
+				if len(vector) == 0 {
+					continue
+				}
+				vectors[missingHashes[i]] = vector
+				fresh[missingHashes[i]] = vector
+			}
+			m.storeCachedEmbeddings(ctx, fresh)
+		}
+	}
+
+	for i, chunk := range chunks {
+		vector, ok := vectors[chunk.Hash]
+		if !ok {
+			continue
+		}
+		if encoded, err := json.Marshal(vector); err == nil {
+			out[i] = string(encoded)
+			m.vectorDims = len(vector)
+		}
+	}
+	return out
+}
+
+func (m *MemorySearchManager) loadCachedEmbeddings(ctx context.Context, hashes []string) map[string][]float64 {
+	out := make(map[string][]float64, len(hashes))
+	if !m.cfg.Cache.Enabled || len(hashes) == 0 {
+		return out
+	}
+	args := []any{m.bridgeID, m.loginID, m.agentID, m.embedder.ID(), m.embedder.Model(), m.embedder.Key()}
+	placeholders := make([]string, 0, len(hashes))
+	for i, hash := range hashes {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", 7+i))
+		args = append(args, hash)
+	}
+	rows, err := m.db.Query(ctx,
+		`SELECT hash, embedding FROM ai_memory_embedding_cache
+         WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND provider=$4 AND model=$5 AND provider_key=$6
+           AND hash IN (`+strings.Join(placeholders, ",")+`)`,
+		args...,
+	)
+	if err != nil {
+		m.log.Warn().Err(err).Msg("Memory embedding cache lookup failed")
+		return out
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hash, raw string
+		if err := rows.Scan(&hash, &raw); err != nil {
+			return out
+		}
+		if vector := decodeEmbedding(raw); len(vector) > 0 {
+			out[hash] = vector
+		}
+	}
+	return out
+}
+
+func (m *MemorySearchManager) storeCachedEmbeddings(ctx context.Context, vectors map[string][]float64) {
+	if !m.cfg.Cache.Enabled || len(vectors) == 0 {
+		return
+	}
+	now := time.Now().UnixMilli()
+	for hash, vector := range vectors {
+		encoded, err := json.Marshal(vector)
+		if err != nil {
+			continue
+		}
+		if _, err := m.db.Exec(ctx,
+			`INSERT INTO ai_memory_embedding_cache
+               (bridge_id, login_id, agent_id, provider, model, provider_key, hash, embedding, dims, updated_at)
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+             ON CONFLICT (bridge_id, login_id, agent_id, provider, model, provider_key, hash)
+             DO UPDATE SET embedding=excluded.embedding, dims=excluded.dims, updated_at=excluded.updated_at`,
+			m.bridgeID, m.loginID, m.agentID, m.embedder.ID(), m.embedder.Model(), m.embedder.Key(),
+			hash, string(encoded), len(vector), now,
+		); err != nil {
+			m.log.Warn().Err(err).Msg("Memory embedding cache write failed")
+			return
+		}
+	}
+	m.pruneEmbeddingCache(ctx)
+}
+
+// pruneEmbeddingCache drops the least recently written entries beyond Cache.MaxEntries.
+// Entries written in the same batch share a timestamp and are kept or dropped together.
+func (m *MemorySearchManager) pruneEmbeddingCache(ctx context.Context) {
+	if m.cfg.Cache.MaxEntries <= 0 {
+		return
+	}
+	if _, err := m.db.Exec(ctx,
+		`DELETE FROM ai_memory_embedding_cache
+         WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND updated_at < (
+           SELECT updated_at FROM ai_memory_embedding_cache
+           WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3
+           ORDER BY updated_at DESC
+           LIMIT 1 OFFSET $4
+         )`,
+		m.bridgeID, m.loginID, m.agentID, m.cfg.Cache.MaxEntries,
+	); err != nil {
+		m.log.Warn().Err(err).Msg("Memory embedding cache prune failed")
+	}
+}
+
+// searchVector embeds the query and ranks embedded chunks by cosine similarity.
+func (m *MemorySearchManager) searchVector(ctx context.Context, query string, limit int, sources []string, pathPrefix string, indexGen string) ([]memorycore.HybridVectorResult, error) {
+	if m.embedder == nil || limit <= 0 {
+		return nil, nil
+	}
+	queryVector, err := m.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(queryVector) == 0 {
+		return nil, nil
+	}
+
+	baseArgs := []any{m.bridgeID, m.loginID, m.agentID, m.status.Model, emptyEmbedding}
+	sourceSQL, sourceArgs := sourceFilterSQL(6, sources)
+	genSQL, genArgs := generationFilterSQL(6+len(sourceArgs), indexGen)
+	pathSQL, pathArgs := pathPrefixFilterSQL(6+len(sourceArgs)+len(genArgs), pathPrefix)
+	args := append(baseArgs, sourceArgs...)
+	args = append(args, genArgs...)
+	args = append(args, pathArgs...)
+	args = append(args, vectorScanLimit)
+
+	rows, err := m.db.Query(ctx,
+		`SELECT id, path, source, start_line, end_line, text, embedding
+         FROM ai_memory_chunks
+         WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND model=$4 AND embedding != $5`+sourceSQL+genSQL+pathSQL+`
+         ORDER BY updated_at DESC
+         LIMIT $`+fmt.Sprintf("%d", len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []memorycore.HybridVectorResult
+	for rows.Next() {
+		var id, path, source, text, raw string
+		var startLine, endLine int
+		if err := rows.Scan(&id, &path, &source, &startLine, &endLine, &text, &raw); err != nil {
+			return nil, err
+		}
+		score := memorycore.CosineSimilarity(queryVector, decodeEmbedding(raw))
+		if score <= 0 {
+			continue
+		}
+		results = append(results, memorycore.HybridVectorResult{
+			ID:          id,
+			Path:        path,
+			StartLine:   startLine,
+			EndLine:     endLine,
+			Source:      source,
+			Snippet:     truncateSnippet(text),
+			VectorScore: score,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(results, func(a, b memorycore.HybridVectorResult) int {
+		return cmp.Compare(b.VectorScore, a.VectorScore)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// rankVectorResults turns vector hits into search results, merging keyword hits
+// into a weighted hybrid score unless the search is semantic-only.
+func (m *MemorySearchManager) rankVectorResults(mode string, vector []memorycore.HybridVectorResult, keyword []memorycore.HybridKeywordResult) []memorycore.SearchResult {
+	if mode == "semantic" || !m.cfg.Query.Hybrid.Enabled {
+		out := make([]memorycore.SearchResult, 0, len(vector))
+		for _, entry := range vector {
+			out = append(out, memorycore.SearchResult{
+				Path:      entry.Path,
+				StartLine: entry.StartLine,
+				EndLine:   entry.EndLine,
+				Score:     entry.VectorScore,
+				Snippet:   entry.Snippet,
+				Source:    entry.Source,
+			})
+		}
+		return out
+	}
+	merged := memorycore.MergeHybridResults(vector, keyword, m.cfg.Query.Hybrid.VectorWeight, m.cfg.Query.Hybrid.TextWeight)
+	out := make([]memorycore.SearchResult, 0, len(merged))
+	for _, entry := range merged {
+		out = append(out, memorycore.SearchResult{
+			Path:      entry.Path,
+			StartLine: entry.StartLine,
+			EndLine:   entry.EndLine,
+			Score:     entry.Score,
+			Snippet:   entry.Snippet,
+			Source:    entry.Source,
+		})
+	}
+	return out
+}
+
+func decodeEmbedding(raw string) []float64 {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == emptyEmbedding {
+		return nil
+	}
+	var vector []float64
+	if err := json.Unmarshal([]byte(raw), &vector); err != nil {
+		return nil
+	}
+	return vector
+}
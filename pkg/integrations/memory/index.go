@@ -135,7 +135,7 @@ func (m *MemorySearchManager) needsFullReindex(ctx context.Context, force bool)
 	case nil:
 		if provider != m.status.Provider ||
 			model != m.status.Model ||
-			providerKey != lexicalProviderKey ||
+			providerKey != m.providerKey() ||
 			chunkTokens != m.cfg.Chunking.Tokens ||
 			chunkOverlap != m.cfg.Chunking.Overlap {
 			return true, nil
@@ -156,18 +156,22 @@ func (m *MemorySearchManager) needsFullReindex(ctx context.Context, force bool)
 }
 
 func (m *MemorySearchManager) updateMeta(ctx context.Context, generation string) error {
+	var vectorDims any
+	if m.vectorDims > 0 {
+		vectorDims = m.vectorDims
+	}
 	_, err := m.db.Exec(ctx,
 		`INSERT INTO ai_memory_meta
            (bridge_id, login_id, agent_id, provider, model, provider_key, chunk_tokens, chunk_overlap, vector_dims, index_generation, updated_at)
-         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULL, $9, $10)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
          ON CONFLICT (bridge_id, login_id, agent_id)
          DO UPDATE SET provider=excluded.provider, model=excluded.model, provider_key=excluded.provider_key,
            chunk_tokens=excluded.chunk_tokens, chunk_overlap=excluded.chunk_overlap,
-           vector_dims=NULL, index_generation=excluded.index_generation, updated_at=excluded.updated_at`,
+           vector_dims=excluded.vector_dims, index_generation=excluded.index_generation, updated_at=excluded.updated_at`,
 		m.bridgeID, m.loginID, m.agentID,
-		m.status.Provider, m.status.Model, lexicalProviderKey,
+		m.status.Provider, m.status.Model, m.providerKey(),
 		m.cfg.Chunking.Tokens, m.cfg.Chunking.Overlap,
-		generation, time.Now().UnixMilli(),
+		vectorDims, generation, time.Now().UnixMilli(),
 	)
 	return err
 }
@@ -369,15 +373,16 @@ func (m *MemorySearchManager) writeContent(ctx context.Context, pc *preparedCont
 	}
 	now := time.Now().UnixMilli()
 	newIDs := make([]string, 0, len(pc.Chunks))
-	for _, chunk := range pc.Chunks {
+	embeddings := m.embedChunks(ctx, pc.Chunks)
+	for i, chunk := range pc.Chunks {
 		chunkID := buildChunkID(pc.Generation)
 		newIDs = append(newIDs, chunkID)
 		_, err := m.db.Exec(ctx,
 			`INSERT INTO ai_memory_chunks
              (id, bridge_id, login_id, agent_id, path, source, start_line, end_line, hash, model, text, embedding, updated_at)
-             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, '[]', $12)`,
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
 			chunkID, m.bridgeID, m.loginID, m.agentID, pc.Path, pc.Source, chunk.StartLine, chunk.EndLine, chunk.Hash,
-			m.status.Model, chunk.Text, now,
+			m.status.Model, chunk.Text, embeddings[i], now,
 		)
 		if err != nil {
 			return err
@@ -45,6 +45,10 @@ func PurgeTablesBestEffort(ctx context.Context, db *dbutil.Database, bridgeID, l
 		`DELETE FROM ai_memory_files WHERE bridge_id=$1 AND login_id=$2`,
 		bridgeID, loginID,
 	)
+	bestEffortExec(ctx, db,
+		`DELETE FROM ai_memory_file_revisions WHERE bridge_id=$1 AND login_id=$2`,
+		bridgeID, loginID,
+	)
 	bestEffortExec(ctx, db,
 		`DELETE FROM ai_memory_meta WHERE bridge_id=$1 AND login_id=$2`,
 		bridgeID, loginID,
@@ -39,6 +39,8 @@ type MemorySearchManager struct {
 	agentID      string
 	cfg          *memorycore.ResolvedConfig
 	status       memorycore.ProviderStatus
+	embedder     memorycore.EmbeddingProvider
+	vectorDims   int
 	indexGen     string
 	ftsAvailable bool
 	ftsError     string
@@ -128,6 +130,22 @@ func GetMemorySearchManager(runtime Runtime, agentID string) (*MemorySearchManag
 		return existing, ""
 	}
 
+	log := runtime.Logger().With().Str("component", "memory").Logger()
+	status := memorycore.ProviderStatus{
+		Provider: "builtin",
+		Model:    "lexical",
+	}
+	embedder, fallback, err := memorycore.NewEmbeddingProvider(cfg)
+	if err != nil {
+		log.Warn().Err(err).Msg("Memory embeddings unavailable; using keyword search only")
+	} else if embedder != nil {
+		status = memorycore.ProviderStatus{
+			Provider: embedder.ID(),
+			Model:    embedder.Model(),
+			Fallback: fallback,
+		}
+	}
+
 	manager := &MemorySearchManager{
 		runtime:  runtime,
 		db:       db,
@@ -135,11 +153,9 @@ func GetMemorySearchManager(runtime Runtime, agentID string) (*MemorySearchManag
 		loginID:  loginID,
 		agentID:  agentID,
 		cfg:      cfg,
-		status: memorycore.ProviderStatus{
-			Provider: "builtin",
-			Model:    "lexical",
-		},
-		log: runtime.Logger().With().Str("component", "memory").Logger(),
+		status:   status,
+		embedder: embedder,
+		log:      log,
 	}
 	manager.startIntervalSync = sync.OnceFunc(func() {
 		interval := time.Duration(manager.cfg.Sync.IntervalMinutes) * time.Minute
@@ -207,8 +223,8 @@ func (m *MemorySearchManager) StatusDetails(ctx context.Context) (*MemorySearchS
 	if m == nil {
 		return nil, errors.New("memory search unavailable")
 	}
-	// Memory status reflects the current lexical-only runtime behavior.
-	// Keep the report truthful and avoid placeholder vector/embedding fields.
+	// Provider/model report the active embedder, or builtin/lexical when
+	// search is keyword-only.
 	statusCtx, cancel := context.WithTimeout(ctx, memoryStatusTimeout)
 	defer cancel()
 	start := time.Now()
@@ -381,13 +397,27 @@ func (m *MemorySearchManager) Search(ctx context.Context, query string, opts mem
 		return clampInjectedChars(filterAndLimit(results, minScore, maxResults), m.cfg.Query.MaxInjectedChars), nil
 	}
 
+	var vectorResults []memorycore.HybridVectorResult
+	if m.embedder != nil && mode != "keyword" {
+		results, err := m.searchVector(ctx, cleaned, candidates, sources, pathPrefix, indexGen)
+		if err != nil {
+			m.log.Warn().Err(err).Msg("Memory vector search failed; falling back to keyword search")
+		} else {
+			vectorResults = results
+		}
+	}
+
 	chunkResults := []memorycore.HybridKeywordResult{}
-	if m.ftsAvailable {
+	if m.ftsAvailable && (mode != "semantic" || len(vectorResults) == 0) {
 		results, err := m.searchKeyword(ctx, cleaned, candidates, sources, pathPrefix, indexGen)
 		if err == nil {
 			chunkResults = results
 		}
 	}
+	if len(vectorResults) > 0 {
+		results := m.rankVectorResults(mode, vectorResults, chunkResults)
+		return clampInjectedChars(filterAndLimit(results, minScore, maxResults), m.cfg.Query.MaxInjectedChars), nil
+	}
 	if len(chunkResults) == 0 && (mode == "semantic" || mode == "hybrid") {
 		results, err := m.searchKeywordScan(ctx, cleaned, candidates, sources, pathPrefix, indexGen)
 		if err == nil {
@@ -33,4 +33,5 @@ const (
 
 	DefaultOpenAIEmbeddingModel = memorycore.DefaultOpenAIEmbeddingModel
 	DefaultGeminiEmbeddingModel = memorycore.DefaultGeminiEmbeddingModel
+	DefaultLocalEmbeddingModel  = memorycore.DefaultLocalEmbeddingModel
 )
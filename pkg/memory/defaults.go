@@ -14,7 +14,10 @@ const (
 	DefaultHybridCandidateMultiple = 4
 	DefaultCacheEnabled            = true
 	DefaultMemorySource            = "memory"
+	DefaultOpenAIBaseURL           = "https://api.openai.com/v1"
 	DefaultOpenAIEmbeddingModel    = "text-embedding-3-small"
 	DefaultGeminiBaseURL           = "https://generativelanguage.googleapis.com/v1beta"
 	DefaultGeminiEmbeddingModel    = "gemini-embedding-001"
+	DefaultLocalEmbeddingBaseURL   = "http://127.0.0.1:11434/v1"
+	DefaultLocalEmbeddingModel     = "nomic-embed-text"
 )
@@ -0,0 +1,306 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beeper/agentremote/pkg/shared/httputil"
+	"github.com/beeper/agentremote/pkg/shared/stringutil"
+)
+
+const (
+	embeddingBatchSize      = 64
+	embeddingTimeoutSeconds = 60
+)
+
+// EmbeddingProvider turns text into vectors for semantic memory search.
+type EmbeddingProvider interface {
+	// ID is the provider name ("openai", "gemini" or "local").
+	ID() string
+	Model() string
+	// Key fingerprints the endpoint and model so cached vectors from a
+	// different configuration are never reused.
+	Key() string
+	EmbedQuery(ctx context.Context, text string) ([]float64, error)
+	EmbedBatch(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// NewEmbeddingProvider resolves the configured embedding provider. It returns a nil
+// provider (and no error) when search should stay lexical-only: vectors are disabled,
+// the provider is "none", or "auto" finds no credentials. If the primary provider
+// can't be created and a fallback is configured, the fallback is used and reported.
+func NewEmbeddingProvider(cfg *ResolvedConfig) (EmbeddingProvider, *FallbackStatus, error) {
+	if cfg == nil || !cfg.Store.Vector.Enabled {
+		return nil, nil, nil
+	}
+	provider := strings.ToLower(strings.TrimSpace(cfg.Provider))
+	if provider == "auto" {
+		provider = autoEmbeddingProvider(cfg.Remote)
+	}
+	if provider == "" || provider == "none" {
+		return nil, nil, nil
+	}
+	primary, err := newEmbeddingProvider(provider, cfg.Model, cfg.Remote)
+	if err == nil {
+		return primary, nil, nil
+	}
+	fallback := strings.ToLower(strings.TrimSpace(cfg.Fallback))
+	if fallback == "" || fallback == "none" || fallback == provider {
+		return nil, nil, err
+	}
+	// The configured model belongs to the primary provider; use the fallback's default.
+	secondary, fallbackErr := newEmbeddingProvider(fallback, "", cfg.Remote)
+	if fallbackErr != nil {
+		return nil, nil, fmt.Errorf("%w (fallback %s: %v)", err, fallback, fallbackErr)
+	}
+	return secondary, &FallbackStatus{From: provider, Reason: err.Error()}, nil
+}
+
+// autoEmbeddingProvider picks a provider from whatever credentials are available.
+func autoEmbeddingProvider(remote RemoteConfig) string {
+	if openAIEmbeddingKey(remote) != "" {
+		return "openai"
+	}
+	if geminiEmbeddingKey(remote) != "" {
+		return "gemini"
+	}
+	return ""
+}
+
+func newEmbeddingProvider(provider, model string, remote RemoteConfig) (EmbeddingProvider, error) {
+	model = strings.TrimSpace(model)
+	switch provider {
+	case "openai":
+		key := openAIEmbeddingKey(remote)
+		if key == "" {
+			return nil, errors.New("openai embeddings require an API key")
+		}
+		return &openAICompatEmbedder{
+			id:      "openai",
+			model:   stringutil.FirstNonEmpty(model, DefaultOpenAIEmbeddingModel),
+			baseURL: stringutil.FirstNonEmpty(stringutil.NormalizeBaseURL(remote.BaseURL), DefaultOpenAIBaseURL),
+			apiKey:  key,
+			headers: remote.Headers,
+			batch:   remote.Batch,
+		}, nil
+	case "local":
+		return &openAICompatEmbedder{
+			id:      "local",
+			model:   stringutil.FirstNonEmpty(model, DefaultLocalEmbeddingModel),
+			baseURL: stringutil.FirstNonEmpty(stringutil.NormalizeBaseURL(remote.BaseURL), DefaultLocalEmbeddingBaseURL),
+			apiKey:  strings.TrimSpace(remote.APIKey),
+			headers: remote.Headers,
+			batch:   remote.Batch,
+		}, nil
+	case "gemini":
+		key := geminiEmbeddingKey(remote)
+		if key == "" {
+			return nil, errors.New("gemini embeddings require an API key")
+		}
+		return &geminiEmbedder{
+			model:   strings.TrimPrefix(stringutil.FirstNonEmpty(model, DefaultGeminiEmbeddingModel), "models/"),
+			baseURL: stringutil.FirstNonEmpty(stringutil.NormalizeBaseURL(remote.BaseURL), DefaultGeminiBaseURL),
+			apiKey:  key,
+			headers: remote.Headers,
+			batch:   remote.Batch,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", provider)
+	}
+}
+
+func openAIEmbeddingKey(remote RemoteConfig) string {
+	return stringutil.FirstNonEmpty(strings.TrimSpace(remote.APIKey), strings.TrimSpace(os.Getenv("OPENAI_API_KEY")))
+}
+
+func geminiEmbeddingKey(remote RemoteConfig) string {
+	return stringutil.FirstNonEmpty(
+		strings.TrimSpace(remote.APIKey),
+		strings.TrimSpace(os.Getenv("GEMINI_API_KEY")),
+		strings.TrimSpace(os.Getenv("GOOGLE_API_KEY")),
+	)
+}
+
+func embeddingProviderKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// embedInBatches splits texts into request-sized batches. With batching enabled,
+// up to batch.Concurrency requests run in parallel within batch.TimeoutMinutes;
+// otherwise batches are sent one at a time.
+func embedInBatches(ctx context.Context, texts []string, batch BatchConfig, embed func(context.Context, []string) ([][]float64, error)) ([][]float64, error) {
+	concurrency := 1
+	if batch.Enabled {
+		concurrency = max(1, batch.Concurrency)
+		if batch.TimeoutMinutes > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(batch.TimeoutMinutes)*time.Minute)
+			defer cancel()
+		}
+	}
+	out := make([][]float64, len(texts))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			vectors, err := embed(ctx, texts[start:end])
+			if err == nil && len(vectors) != end-start {
+				err = fmt.Errorf("embedding response has %d vectors, expected %d", len(vectors), end-start)
+			}
+			if err != nil {
+				errOnce.Do(func() { firstErr = err })
+				return
+			}
+			copy(out[start:end], vectors)
+		}(start, end)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return out, nil
+}
+
+// openAICompatEmbedder calls an OpenAI-style /embeddings endpoint (OpenAI, Ollama,
+// llama.cpp server, vLLM).
+type openAICompatEmbedder struct {
+	id      string
+	model   string
+	baseURL string
+	apiKey  string
+	headers map[string]string
+	batch   BatchConfig
+}
+
+func (e *openAICompatEmbedder) ID() string    { return e.id }
+func (e *openAICompatEmbedder) Model() string { return e.model }
+func (e *openAICompatEmbedder) Key() string {
+	return embeddingProviderKey(e.id, e.baseURL, e.model)
+}
+
+func (e *openAICompatEmbedder) EmbedQuery(ctx context.Context, text string) ([]float64, error) {
+	vectors, err := e.embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 {
+		return nil, errors.New("embedding response is empty")
+	}
+	return vectors[0], nil
+}
+
+func (e *openAICompatEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	return embedInBatches(ctx, texts, e.batch, e.embed)
+}
+
+func (e *openAICompatEmbedder) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	auth := map[string]string{}
+	if e.apiKey != "" {
+		auth["Authorization"] = "Bearer " + e.apiKey
+	}
+	payload := map[string]any{"model": e.model, "input": texts}
+	data, _, err := httputil.PostJSON(ctx, e.baseURL+"/embeddings", httputil.MergeHeaders(auth, e.headers), payload, embeddingTimeoutSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("%s embeddings: %w", e.id, err)
+	}
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("%s embeddings: decoding response: %w", e.id, err)
+	}
+	out := make([][]float64, len(texts))
+	for i, item := range resp.Data {
+		idx := item.Index
+		if idx < 0 || idx >= len(out) {
+			idx = i
+		}
+		if idx < len(out) {
+			out[idx] = item.Embedding
+		}
+	}
+	return out, nil
+}
+
+// geminiEmbedder calls the Gemini batchEmbedContents endpoint.
+type geminiEmbedder struct {
+	model   string
+	baseURL string
+	apiKey  string
+	headers map[string]string
+	batch   BatchConfig
+}
+
+func (e *geminiEmbedder) ID() string    { return "gemini" }
+func (e *geminiEmbedder) Model() string { return e.model }
+func (e *geminiEmbedder) Key() string {
+	return embeddingProviderKey("gemini", e.baseURL, e.model)
+}
+
+func (e *geminiEmbedder) EmbedQuery(ctx context.Context, text string) ([]float64, error) {
+	vectors, err := e.embed(ctx, []string{text}, "RETRIEVAL_QUERY")
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 {
+		return nil, errors.New("embedding response is empty")
+	}
+	return vectors[0], nil
+}
+
+func (e *geminiEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	return embedInBatches(ctx, texts, e.batch, func(ctx context.Context, texts []string) ([][]float64, error) {
+		return e.embed(ctx, texts, "RETRIEVAL_DOCUMENT")
+	})
+}
+
+func (e *geminiEmbedder) embed(ctx context.Context, texts []string, taskType string) ([][]float64, error) {
+	modelName := "models/" + e.model
+	requests := make([]map[string]any, 0, len(texts))
+	for _, text := range texts {
+		requests = append(requests, map[string]any{
+			"model":    modelName,
+			"content":  map[string]any{"parts": []map[string]string{{"text": text}}},
+			"taskType": taskType,
+		})
+	}
+	endpoint := e.baseURL + "/models/" + url.PathEscape(e.model) + ":batchEmbedContents"
+	auth := map[string]string{"X-Goog-Api-Key": e.apiKey}
+	data, _, err := httputil.PostJSON(ctx, endpoint, httputil.MergeHeaders(auth, e.headers), map[string]any{"requests": requests}, embeddingTimeoutSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("gemini embeddings: %w", err)
+	}
+	var resp struct {
+		Embeddings []struct {
+			Values []float64 `json:"values"`
+		} `json:"embeddings"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("gemini embeddings: decoding response: %w", err)
+	}
+	out := make([][]float64, 0, len(resp.Embeddings))
+	for _, embedding := range resp.Embeddings {
+		out = append(out, embedding.Values)
+	}
+	return out, nil
+}
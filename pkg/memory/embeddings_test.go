@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAICompatEmbedderBatches(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected authorization header: %q", got)
+		}
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "nomic-embed-text" {
+			t.Errorf("unexpected model: %q", body.Model)
+		}
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		}
+		data := make([]item, 0, len(body.Input))
+		// Return items out of order to exercise index handling.
+		for i := len(body.Input) - 1; i >= 0; i-- {
+			data = append(data, item{Index: i, Embedding: []float64{float64(len(body.Input[i])), 1}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer server.Close()
+
+	provider, err := newEmbeddingProvider("local", "", RemoteConfig{BaseURL: server.URL + "/v1", APIKey: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.ID() != "local" || provider.Model() != DefaultLocalEmbeddingModel {
+		t.Fatalf("unexpected provider identity: %s %s", provider.ID(), provider.Model())
+	}
+
+	texts := make([]string, embeddingBatchSize+1)
+	for i := range texts {
+		texts[i] = string(make([]byte, i))
+	}
+	vectors, err := provider.EmbedBatch(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 batched requests, got %d", requests)
+	}
+	for i, vector := range vectors {
+		if len(vector) != 2 || vector[0] != float64(i) {
+			t.Fatalf("unexpected vector %d: %v", i, vector)
+		}
+	}
+}
+
+func TestGeminiEmbedderTaskTypes(t *testing.T) {
+	var taskTypes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/text-embedding-004:batchEmbedContents" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("X-Goog-Api-Key"); got != "gemini-key" {
+			t.Errorf("unexpected api key header: %q", got)
+		}
+		var body struct {
+			Requests []struct {
+				Model    string `json:"model"`
+				TaskType string `json:"taskType"`
+			} `json:"requests"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		embeddings := make([]map[string]any, 0, len(body.Requests))
+		for _, req := range body.Requests {
+			if req.Model != "models/text-embedding-004" {
+				t.Errorf("unexpected model: %q", req.Model)
+			}
+			taskTypes = append(taskTypes, req.TaskType)
+			embeddings = append(embeddings, map[string]any{"values": []float64{0.1, 0.2, 0.3}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+	}))
+	defer server.Close()
+
+	provider, err := newEmbeddingProvider("gemini", "models/text-embedding-004", RemoteConfig{BaseURL: server.URL, APIKey: "gemini-key"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, err := provider.EmbedQuery(context.Background(), "what did we decide?")
+	if err != nil || len(query) != 3 {
+		t.Fatalf("unexpected query embedding: %v %v", query, err)
+	}
+	docs, err := provider.EmbedBatch(context.Background(), []string{"one", "two"})
+	if err != nil || len(docs) != 2 {
+		t.Fatalf("unexpected document embeddings: %v %v", docs, err)
+	}
+	want := []string{"RETRIEVAL_QUERY", "RETRIEVAL_DOCUMENT", "RETRIEVAL_DOCUMENT"}
+	if len(taskTypes) != len(want) {
+		t.Fatalf("unexpected task types: %v", taskTypes)
+	}
+	for i := range want {
+		if taskTypes[i] != want[i] {
+			t.Fatalf("unexpected task types: %v", taskTypes)
+		}
+	}
+}
+
+func TestNewEmbeddingProviderSelection(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+
+	cfg := &ResolvedConfig{Provider: "auto", Store: StoreConfig{Vector: VectorConfig{Enabled: true}}}
+	provider, _, err := NewEmbeddingProvider(cfg)
+	if err != nil || provider != nil {
+		t.Fatalf("expected auto without credentials to stay lexical, got %v %v", provider, err)
+	}
+
+	t.Setenv("GEMINI_API_KEY", "gemini-key")
+	provider, _, err = NewEmbeddingProvider(cfg)
+	if err != nil || provider == nil || provider.ID() != "gemini" {
+		t.Fatalf("expected auto to pick gemini, got %v %v", provider, err)
+	}
+
+	cfg = &ResolvedConfig{Provider: "openai", Model: "text-embedding-3-large", Fallback: "local", Store: StoreConfig{Vector: VectorConfig{Enabled: true}}}
+	provider, fallback, err := NewEmbeddingProvider(cfg)
+	if err != nil || provider == nil || provider.ID() != "local" {
+		t.Fatalf("expected fallback to local, got %v %v", provider, err)
+	}
+	if fallback == nil || fallback.From != "openai" || provider.Model() != DefaultLocalEmbeddingModel {
+		t.Fatalf("unexpected fallback status: %+v model=%s", fallback, provider.Model())
+	}
+
+	cfg.Store.Vector.Enabled = false
+	if provider, _, _ := NewEmbeddingProvider(cfg); provider != nil {
+		t.Fatalf("expected disabled vectors to skip embeddings, got %v", provider)
+	}
+}
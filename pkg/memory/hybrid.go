@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"cmp"
 	"math"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -42,3 +44,100 @@ type HybridKeywordResult struct {
 	Snippet   string
 	TextScore float64
 }
+
+// HybridVectorResult holds a single vector search result with a cosine similarity score.
+type HybridVectorResult struct {
+	ID          string
+	Path        string
+	StartLine   int
+	EndLine     int
+	Source      string
+	Snippet     string
+	VectorScore float64
+}
+
+// HybridMergedResult is a search result scored by the weighted vector and text scores.
+type HybridMergedResult struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Source    string
+	Snippet   string
+	Score     float64
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or 0 when they
+// differ in length or either has zero magnitude.
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// MergeHybridResults combines vector and keyword results by chunk ID, scoring each
+// as vectorWeight*vectorScore + textWeight*textScore (a missing side counts as 0),
+// and returns them sorted by descending score.
+func MergeHybridResults(vector []HybridVectorResult, keyword []HybridKeywordResult, vectorWeight, textWeight float64) []HybridMergedResult {
+	type entry struct {
+		result      HybridMergedResult
+		vectorScore float64
+		textScore   float64
+	}
+	byID := make(map[string]*entry, len(vector)+len(keyword))
+	order := make([]string, 0, len(vector)+len(keyword))
+	for _, r := range vector {
+		if _, ok := byID[r.ID]; !ok {
+			order = append(order, r.ID)
+		}
+		byID[r.ID] = &entry{
+			result: HybridMergedResult{
+				Path:      r.Path,
+				StartLine: r.StartLine,
+				EndLine:   r.EndLine,
+				Source:    r.Source,
+				Snippet:   r.Snippet,
+			},
+			vectorScore: r.VectorScore,
+		}
+	}
+	for _, r := range keyword {
+		if existing, ok := byID[r.ID]; ok {
+			existing.textScore = r.TextScore
+			if r.Snippet != "" {
+				existing.result.Snippet = r.Snippet
+			}
+			continue
+		}
+		order = append(order, r.ID)
+		byID[r.ID] = &entry{
+			result: HybridMergedResult{
+				Path:      r.Path,
+				StartLine: r.StartLine,
+				EndLine:   r.EndLine,
+				Source:    r.Source,
+				Snippet:   r.Snippet,
+			},
+			textScore: r.TextScore,
+		}
+	}
+	out := make([]HybridMergedResult, 0, len(order))
+	for _, id := range order {
+		e := byID[id]
+		e.result.Score = vectorWeight*e.vectorScore + textWeight*e.textScore
+		out = append(out, e.result)
+	}
+	slices.SortStableFunc(out, func(a, b HybridMergedResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return out
+}
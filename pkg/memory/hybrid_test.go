@@ -40,3 +40,50 @@ func TestBuildFtsQuery(t *testing.T) {
 		t.Fatalf("expected empty query for punctuation-only input, got %q", got)
 	}
 }
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float64{1, 0}, []float64{1, 0}); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected identical vectors to score 1, got %v", got)
+	}
+	if got := CosineSimilarity([]float64{1, 0}, []float64{0, 1}); got != 0 {
+		t.Fatalf("expected orthogonal vectors to score 0, got %v", got)
+	}
+	if got := CosineSimilarity([]float64{1, 2}, []float64{1, 2, 3}); got != 0 {
+		t.Fatalf("expected mismatched dimensions to score 0, got %v", got)
+	}
+	if got := CosineSimilarity([]float64{0, 0}, []float64{1, 1}); got != 0 {
+		t.Fatalf("expected zero vector to score 0, got %v", got)
+	}
+}
+
+func TestMergeHybridResults(t *testing.T) {
+	vector := []HybridVectorResult{
+		{ID: "a", Path: "a.md", Snippet: "vector a", VectorScore: 0.9},
+		{ID: "b", Path: "b.md", Snippet: "vector b", VectorScore: 0.5},
+	}
+	keyword := []HybridKeywordResult{
+		{ID: "b", Path: "b.md", Snippet: "keyword b", TextScore: 1},
+		{ID: "c", Path: "c.md", Snippet: "keyword c", TextScore: 0.8},
+	}
+	merged := MergeHybridResults(vector, keyword, 0.7, 0.3)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 merged results, got %+v", merged)
+	}
+	wantPaths := []string{"a.md", "b.md", "c.md"}
+	wantScores := []float64{0.63, 0.65, 0.24}
+	byPath := map[string]HybridMergedResult{}
+	for _, r := range merged {
+		byPath[r.Path] = r
+	}
+	for i, path := range wantPaths {
+		if got := byPath[path].Score; math.Abs(got-wantScores[i]) > 1e-9 {
+			t.Fatalf("unexpected score for %s: %v", path, got)
+		}
+	}
+	if merged[0].Path != "b.md" || merged[1].Path != "a.md" || merged[2].Path != "c.md" {
+		t.Fatalf("unexpected order: %+v", merged)
+	}
+	if merged[0].Snippet != "keyword b" {
+		t.Fatalf("expected keyword snippet to win for shared chunk, got %q", merged[0].Snippet)
+	}
+}
@@ -9,14 +9,27 @@ import (
 
 const (
 	ProviderExa         = "exa"
+	ProviderBrave       = "brave"
+	ProviderPerplexity  = "perplexity"
+	ProviderSearXNG     = "searxng"
 	DefaultSearchCount  = 5
 	MaxSearchCount      = 10
 	DefaultTimeoutSecs  = 30
 	DefaultCacheTtlSecs = 900
 )
 
+const (
+	DefaultBraveBaseURL         = "https://api.search.brave.com/res/v1/web/search"
+	DefaultPerplexityBaseURL    = "https://api.perplexity.ai"
+	DefaultPerplexityOpenRouter = "https://openrouter.ai/api/v1"
+	DefaultPerplexityModel      = "perplexity/sonar-pro"
+)
+
 var DefaultFallbackOrder = []string{
 	ProviderExa,
+	ProviderBrave,
+	ProviderPerplexity,
+	ProviderSearXNG,
 }
 
 // Config controls search provider selection and credentials.
@@ -24,7 +37,10 @@ type Config struct {
 	Provider  string   `yaml:"provider"`
 	Fallbacks []string `yaml:"fallbacks"`
 
-	Exa ExaConfig `yaml:"exa"`
+	Exa        ExaConfig        `yaml:"exa"`
+	Brave      BraveConfig      `yaml:"brave"`
+	Perplexity PerplexityConfig `yaml:"perplexity"`
+	SearXNG    SearXNGConfig    `yaml:"searxng"`
 }
 
 type ExaConfig struct {
@@ -39,6 +55,36 @@ type ExaConfig struct {
 	Highlights        bool   `yaml:"highlights"`
 }
 
+type BraveConfig struct {
+	Enabled *bool  `yaml:"enabled"`
+	BaseURL string `yaml:"base_url"`
+	APIKey  string `yaml:"api_key"`
+}
+
+// PerplexityConfig configures Sonar search. The key may be a Perplexity key
+// ("pplx-...") or an OpenRouter key; when BaseURL is empty it is inferred from it.
+// From the environment only PERPLEXITY_API_KEY is read: an OpenRouter key meant
+// for chat must be set here explicitly to also pay for searches.
+type PerplexityConfig struct {
+	Enabled *bool  `yaml:"enabled"`
+	BaseURL string `yaml:"base_url"`
+	APIKey  string `yaml:"api_key"`
+	Model   string `yaml:"model"`
+}
+
+// SearXNGConfig configures a self-hosted SearXNG instance. The instance must have
+// the json format enabled in its settings.yml. APIKey is optional and only needed
+// when the instance sits behind an authenticating proxy.
+type SearXNGConfig struct {
+	Enabled    *bool    `yaml:"enabled"`
+	BaseURL    string   `yaml:"base_url"`
+	APIKey     string   `yaml:"api_key"`
+	Categories []string `yaml:"categories"`
+	Engines    []string `yaml:"engines"`
+	Language   string   `yaml:"language"`
+	SafeSearch *int     `yaml:"safesearch"`
+}
+
 func (c *Config) WithDefaults() *Config {
 	if c == nil {
 		c = &Config{}
@@ -50,6 +96,8 @@ func (c *Config) WithDefaults() *Config {
 		c.Fallbacks = slices.Clone(DefaultFallbackOrder)
 	}
 	c.Exa = c.Exa.withDefaults()
+	c.Brave = c.Brave.withDefaults()
+	c.Perplexity = c.Perplexity.withDefaults()
 	return c
 }
 
@@ -70,3 +118,17 @@ func (c ExaConfig) withDefaults() ExaConfig {
 	c.Highlights = true
 	return c
 }
+
+func (c BraveConfig) withDefaults() BraveConfig {
+	if c.BaseURL == "" {
+		c.BaseURL = DefaultBraveBaseURL
+	}
+	return c
+}
+
+func (c PerplexityConfig) withDefaults() PerplexityConfig {
+	if c.Model == "" {
+		c.Model = DefaultPerplexityModel
+	}
+	return c
+}
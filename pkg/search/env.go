@@ -19,6 +19,10 @@ func ConfigFromEnv() *Config {
 	}
 	cfg.Exa.APIKey = stringutil.EnvOr(cfg.Exa.APIKey, os.Getenv("EXA_API_KEY"))
 	cfg.Exa.BaseURL = stringutil.EnvOr(cfg.Exa.BaseURL, os.Getenv("EXA_BASE_URL"))
+	cfg.Brave.APIKey = stringutil.EnvOr(cfg.Brave.APIKey, os.Getenv("BRAVE_API_KEY"))
+	cfg.Perplexity.APIKey = stringutil.EnvOr(cfg.Perplexity.APIKey, os.Getenv("PERPLEXITY_API_KEY"))
+	cfg.SearXNG.BaseURL = stringutil.EnvOr(cfg.SearXNG.BaseURL, os.Getenv("SEARXNG_BASE_URL"))
+	cfg.SearXNG.APIKey = stringutil.EnvOr(cfg.SearXNG.APIKey, os.Getenv("SEARXNG_API_KEY"))
 
 	return cfg.WithDefaults()
 }
@@ -40,9 +44,30 @@ func ApplyEnvDefaults(cfg *Config) *Config {
 	if current.Exa.BaseURL == "" {
 		current.Exa.BaseURL = envCfg.Exa.BaseURL
 	}
+	if current.Brave.APIKey == "" {
+		current.Brave.APIKey = envCfg.Brave.APIKey
+	}
+	if current.Perplexity.APIKey == "" {
+		current.Perplexity.APIKey = envCfg.Perplexity.APIKey
+	}
+	if current.SearXNG.BaseURL == "" {
+		current.SearXNG.BaseURL = envCfg.SearXNG.BaseURL
+	}
+	if current.SearXNG.APIKey == "" {
+		current.SearXNG.APIKey = envCfg.SearXNG.APIKey
+	}
 
-	if !providerSet && strings.TrimSpace(current.Exa.APIKey) != "" {
-		current.Provider = ProviderExa
+	if !providerSet {
+		switch {
+		case strings.TrimSpace(current.Exa.APIKey) != "":
+			current.Provider = ProviderExa
+		case strings.TrimSpace(current.Brave.APIKey) != "":
+			current.Provider = ProviderBrave
+		case strings.TrimSpace(current.Perplexity.APIKey) != "":
+			current.Provider = ProviderPerplexity
+		case strings.TrimSpace(current.SearXNG.BaseURL) != "":
+			current.Provider = ProviderSearXNG
+		}
 	}
 
 	return current
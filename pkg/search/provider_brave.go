@@ -0,0 +1,146 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beeper/agentremote/pkg/shared/httputil"
+	"github.com/beeper/agentremote/pkg/shared/stringutil"
+)
+
+type braveProvider struct {
+	cfg BraveConfig
+}
+
+func (p *braveProvider) Name() string {
+	return ProviderBrave
+}
+
+func (p *braveProvider) Search(ctx context.Context, req Request) (*Response, error) {
+	endpoint := stringutil.NormalizeBaseURL(p.cfg.BaseURL)
+	if endpoint == "" {
+		return nil, errors.New("brave base_url is empty")
+	}
+
+	params := url.Values{}
+	params.Set("q", req.Query)
+	if req.Count > 0 {
+		params.Set("count", strconv.Itoa(req.Count))
+	}
+	if req.Country != "" {
+		params.Set("country", strings.ToUpper(req.Country))
+	}
+	if req.SearchLang != "" {
+		params.Set("search_lang", req.SearchLang)
+	}
+	if req.UILang != "" {
+		params.Set("ui_lang", req.UILang)
+	}
+	if freshness := normalizeBraveFreshness(req.Freshness); freshness != "" {
+		params.Set("freshness", freshness)
+	}
+
+	headers := map[string]string{"X-Subscription-Token": p.cfg.APIKey}
+	start := time.Now()
+	data, _, err := httputil.GetJSON(ctx, endpoint+"?"+params.Encode(), headers, DefaultTimeoutSecs)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Query struct {
+			Altered string `json:"altered"`
+		} `json:"query"`
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+				Age         string `json:"age"`
+				PageAge     string `json:"page_age"`
+				Profile     struct {
+					Name string `json:"name"`
+				} `json:"profile"`
+				MetaURL struct {
+					Hostname string `json:"hostname"`
+					Favicon  string `json:"favicon"`
+				} `json:"meta_url"`
+				Thumbnail struct {
+					Src string `json:"src"`
+				} `json:"thumbnail"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(resp.Web.Results))
+	for _, entry := range resp.Web.Results {
+		siteName := strings.TrimSpace(entry.Profile.Name)
+		if siteName == "" {
+			siteName = strings.TrimSpace(entry.MetaURL.Hostname)
+		}
+		if siteName == "" {
+			siteName = resolveSiteName(entry.URL)
+		}
+		results = append(results, Result{
+			Title:       cleanBraveText(entry.Title),
+			URL:         entry.URL,
+			Description: cleanBraveText(entry.Description),
+			Published:   stringutil.FirstNonEmpty(entry.PageAge, entry.Age),
+			SiteName:    siteName,
+			Image:       strings.TrimSpace(entry.Thumbnail.Src),
+			Favicon:     strings.TrimSpace(entry.MetaURL.Favicon),
+		})
+	}
+
+	out := &Response{
+		Query:     req.Query,
+		Provider:  ProviderBrave,
+		Count:     len(results),
+		TookMs:    time.Since(start).Milliseconds(),
+		Results:   results,
+		NoResults: len(results) == 0,
+	}
+	if altered := strings.TrimSpace(resp.Query.Altered); altered != "" {
+		out.Extras = map[string]any{"alteredQuery": altered}
+	}
+	return out, nil
+}
+
+// normalizeBraveFreshness accepts the Brave shorthands (pd, pw, pm, py) and
+// YYYY-MM-DDtoYYYY-MM-DD ranges; anything else is dropped.
+func normalizeBraveFreshness(raw string) string {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	switch value {
+	case "":
+		return ""
+	case "pd", "pw", "pm", "py":
+		return value
+	}
+	from, to, ok := strings.Cut(value, "to")
+	if !ok {
+		return ""
+	}
+	if _, err := time.Parse(time.DateOnly, from); err != nil {
+		return ""
+	}
+	if _, err := time.Parse(time.DateOnly, to); err != nil {
+		return ""
+	}
+	return value
+}
+
+// cleanBraveText strips the <strong> highlight tags and HTML entities Brave
+// embeds in titles and snippets.
+func cleanBraveText(text string) string {
+	text = html.UnescapeString(stringutil.StripMarkup(text))
+	return strings.Join(strings.Fields(text), " ")
+}
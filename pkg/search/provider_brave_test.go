@@ -0,0 +1,70 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBraveProviderSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Subscription-Token"); got != "brave-key" {
+			t.Fatalf("unexpected subscription token: %q", got)
+		}
+		query := r.URL.Query()
+		if query.Get("q") != "golang generics" || query.Get("count") != "3" || query.Get("country") != "DE" {
+			t.Fatalf("unexpected query params: %s", r.URL.RawQuery)
+		}
+		if query.Get("freshness") != "pw" {
+			t.Fatalf("expected freshness to be forwarded, got %q", query.Get("freshness"))
+		}
+		if query.Has("search_lang") {
+			t.Fatalf("expected empty search_lang to be omitted")
+		}
+		_, _ = w.Write([]byte(`{"web":{"results":[{
+			"title":"Tutorial: <strong>Generics</strong>",
+			"url":"https://go.dev/doc/tutorial/generics",
+			"description":"Getting started with <strong>generics</strong> &amp; type parameters.",
+			"page_age":"2024-01-02T00:00:00",
+			"profile":{"name":"Go"},
+			"meta_url":{"hostname":"go.dev","favicon":"https://imgs.search.brave.com/go.png"}
+		}]}}`))
+	}))
+	defer server.Close()
+
+	provider := &braveProvider{cfg: BraveConfig{BaseURL: server.URL, APIKey: "brave-key"}}
+	resp, err := provider.Search(context.Background(), Request{Query: "golang generics", Count: 3, Country: "de", Freshness: "PW"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Provider != ProviderBrave || resp.Count != 1 || resp.NoResults {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	result := resp.Results[0]
+	if result.Title != "Tutorial: Generics" {
+		t.Fatalf("expected highlight tags to be stripped, got %q", result.Title)
+	}
+	if result.Description != "Getting started with generics & type parameters." {
+		t.Fatalf("unexpected description: %q", result.Description)
+	}
+	if result.SiteName != "Go" || result.Published != "2024-01-02T00:00:00" || result.Favicon == "" {
+		t.Fatalf("unexpected result metadata: %+v", result)
+	}
+}
+
+func TestNormalizeBraveFreshness(t *testing.T) {
+	cases := map[string]string{
+		"":                       "",
+		"pd":                     "pd",
+		"PY":                     "py",
+		"2024-01-01to2024-02-01": "2024-01-01to2024-02-01",
+		"2024-01-01":             "",
+		"last week":              "",
+	}
+	for input, want := range cases {
+		if got := normalizeBraveFreshness(input); got != want {
+			t.Fatalf("normalizeBraveFreshness(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
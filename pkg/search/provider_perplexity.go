@@ -0,0 +1,167 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/beeper/agentremote/pkg/shared/citations"
+	"github.com/beeper/agentremote/pkg/shared/httputil"
+	"github.com/beeper/agentremote/pkg/shared/stringutil"
+)
+
+// perplexityProvider searches through Perplexity's Sonar chat completions API,
+// either directly or via OpenRouter. The synthesized answer becomes
+// Response.Answer and its citations become the results.
+type perplexityProvider struct {
+	cfg PerplexityConfig
+}
+
+func (p *perplexityProvider) Name() string {
+	return ProviderPerplexity
+}
+
+func (p *perplexityProvider) Search(ctx context.Context, req Request) (*Response, error) {
+	baseURL := resolvePerplexityBaseURL(p.cfg)
+	if baseURL == "" {
+		return nil, errors.New("perplexity base_url is empty")
+	}
+	direct := isDirectPerplexityBaseURL(baseURL)
+	model := strings.TrimSpace(p.cfg.Model)
+	if model == "" {
+		model = DefaultPerplexityModel
+	}
+	if direct {
+		// The Perplexity API expects bare model names ("sonar-pro").
+		model = strings.TrimPrefix(model, "perplexity/")
+	}
+
+	payload := map[string]any{
+		"model": model,
+		"messages": []map[string]string{
+			{"role": "user", "content": req.Query},
+		},
+	}
+	if direct {
+		if recency := freshnessPeriod(req.Freshness); recency != "" {
+			payload["search_recency_filter"] = recency
+		}
+	}
+
+	headers := map[string]string{"Authorization": "Bearer " + p.cfg.APIKey}
+	start := time.Now()
+	data, _, err := httputil.PostJSON(ctx, baseURL+"/chat/completions", headers, payload, DefaultTimeoutSecs)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content     string `json:"content"`
+				Annotations []struct {
+					Type        string `json:"type"`
+					URLCitation struct {
+						URL     string `json:"url"`
+						Title   string `json:"title"`
+						Content string `json:"content"`
+					} `json:"url_citation"`
+				} `json:"annotations"`
+			} `json:"message"`
+		} `json:"choices"`
+		Citations     []string `json:"citations"`
+		SearchResults []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Date    string `json:"date"`
+			Snippet string `json:"snippet"`
+		} `json:"search_results"`
+		Usage map[string]any `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	answer := ""
+	var sources []citations.SourceCitation
+	if len(resp.Choices) > 0 {
+		message := resp.Choices[0].Message
+		answer = strings.TrimSpace(message.Content)
+		for _, annotation := range message.Annotations {
+			if annotation.Type != "url_citation" {
+				continue
+			}
+			sources = citations.AppendUniqueCitation(sources, citations.SourceCitation{
+				URL:         annotation.URLCitation.URL,
+				Title:       strings.TrimSpace(annotation.URLCitation.Title),
+				Description: strings.TrimSpace(annotation.URLCitation.Content),
+			})
+		}
+	}
+	// search_results carry the richest metadata, so merge them before the bare URL list.
+	incoming := make([]citations.SourceCitation, 0, len(resp.SearchResults)+len(resp.Citations))
+	for _, entry := range resp.SearchResults {
+		incoming = append(incoming, citations.SourceCitation{
+			URL:         entry.URL,
+			Title:       strings.TrimSpace(entry.Title),
+			Description: strings.TrimSpace(entry.Snippet),
+			Published:   strings.TrimSpace(entry.Date),
+		})
+	}
+	for _, link := range resp.Citations {
+		incoming = append(incoming, citations.SourceCitation{URL: link})
+	}
+	sources = citations.MergeSourceCitations(sources, incoming)
+
+	results := make([]Result, 0, len(sources))
+	for _, source := range sources {
+		if req.Count > 0 && len(results) >= req.Count {
+			break
+		}
+		url := strings.TrimSpace(source.URL)
+		siteName := stringutil.FirstNonEmpty(source.SiteName, resolveSiteName(url))
+		results = append(results, Result{
+			Title:       stringutil.FirstNonEmpty(source.Title, siteName, url),
+			URL:         url,
+			Description: source.Description,
+			Published:   source.Published,
+			SiteName:    siteName,
+		})
+	}
+
+	out := &Response{
+		Query:     req.Query,
+		Provider:  ProviderPerplexity,
+		Count:     len(results),
+		TookMs:    time.Since(start).Milliseconds(),
+		Results:   results,
+		Answer:    answer,
+		NoResults: answer == "" && len(results) == 0,
+		Extras: map[string]any{
+			"model": stringutil.FirstNonEmpty(resp.Model, model),
+		},
+	}
+	if resp.Usage != nil {
+		out.Extras["usage"] = resp.Usage
+	}
+	return out, nil
+}
+
+// resolvePerplexityBaseURL uses the configured base URL, or infers the endpoint
+// from the key: Perplexity keys start with "pplx-", anything else goes through OpenRouter.
+func resolvePerplexityBaseURL(cfg PerplexityConfig) string {
+	if base := stringutil.NormalizeBaseURL(cfg.BaseURL); base != "" {
+		return base
+	}
+	if strings.HasPrefix(strings.TrimSpace(cfg.APIKey), "pplx-") {
+		return DefaultPerplexityBaseURL
+	}
+	return DefaultPerplexityOpenRouter
+}
+
+func isDirectPerplexityBaseURL(baseURL string) bool {
+	return strings.EqualFold(resolveSiteName(baseURL), "api.perplexity.ai")
+}
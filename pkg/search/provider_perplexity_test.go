@@ -0,0 +1,82 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPerplexityProviderSearchMapsAnswerAndCitations(t *testing.T) {
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-or-test" {
+			t.Fatalf("unexpected authorization header: %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{
+			"model":"perplexity/sonar-pro",
+			"choices":[{"message":{"content":"Go 1.18 added generics [1].","annotations":[
+				{"type":"url_citation","url_citation":{"url":"https://go.dev/blog/go1.18","title":"Go 1.18 is released"}}
+			]}}],
+			"citations":["https://go.dev/blog/go1.18","https://en.wikipedia.org/wiki/Go_(programming_language)"],
+			"search_results":[{"title":"Go 1.18 release","url":"https://go.dev/blog/go1.18","date":"2022-03-15","snippet":"Generics are here."}]
+		}`))
+	}))
+	defer server.Close()
+
+	provider := &perplexityProvider{cfg: PerplexityConfig{BaseURL: server.URL, APIKey: "sk-or-test", Model: DefaultPerplexityModel}}
+	resp, err := provider.Search(context.Background(), Request{Query: "when did go get generics", Count: 5, Freshness: "pw"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotBody["model"] != DefaultPerplexityModel {
+		t.Fatalf("expected OpenRouter model name, got %v", gotBody["model"])
+	}
+	if _, ok := gotBody["search_recency_filter"]; ok {
+		t.Fatalf("recency filter must only be sent to the Perplexity API")
+	}
+	if resp.Answer != "Go 1.18 added generics [1]." || resp.Provider != ProviderPerplexity {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("expected deduplicated citations, got %+v", resp.Results)
+	}
+	first := resp.Results[0]
+	if first.Title != "Go 1.18 is released" || first.Description != "Generics are here." || first.Published != "2022-03-15" || first.SiteName != "go.dev" {
+		t.Fatalf("unexpected merged citation: %+v", first)
+	}
+	if second := resp.Results[1]; second.Title != "en.wikipedia.org" {
+		t.Fatalf("expected bare citation to fall back to its host, got %+v", second)
+	}
+}
+
+func TestResolvePerplexityBaseURL(t *testing.T) {
+	if got := resolvePerplexityBaseURL(PerplexityConfig{APIKey: "pplx-abc"}); got != DefaultPerplexityBaseURL {
+		t.Fatalf("expected Perplexity API for pplx key, got %q", got)
+	}
+	if got := resolvePerplexityBaseURL(PerplexityConfig{APIKey: "sk-or-abc"}); got != DefaultPerplexityOpenRouter {
+		t.Fatalf("expected OpenRouter for other keys, got %q", got)
+	}
+	if got := resolvePerplexityBaseURL(PerplexityConfig{APIKey: "pplx-abc", BaseURL: "https://proxy.example/v1/"}); got != "https://proxy.example/v1" {
+		t.Fatalf("expected configured base URL to win, got %q", got)
+	}
+}
+
+func TestConfigFromEnvIgnoresOpenRouterKeyForPerplexity(t *testing.T) {
+	t.Setenv("PERPLEXITY_API_KEY", "")
+	t.Setenv("OPENROUTER_API_KEY", "sk-or-chat")
+	if cfg := ConfigFromEnv(); cfg.Perplexity.APIKey != "" {
+		t.Fatalf("expected OpenRouter key not to enable Perplexity, got %q", cfg.Perplexity.APIKey)
+	}
+	t.Setenv("PERPLEXITY_API_KEY", "pplx-test")
+	if cfg := ConfigFromEnv(); cfg.Perplexity.APIKey != "pplx-test" {
+		t.Fatalf("expected Perplexity key from env, got %q", cfg.Perplexity.APIKey)
+	}
+}
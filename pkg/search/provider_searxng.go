@@ -0,0 +1,186 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/beeper/agentremote/pkg/shared/httputil"
+	"github.com/beeper/agentremote/pkg/shared/stringutil"
+)
+
+type searxngProvider struct {
+	cfg SearXNGConfig
+}
+
+func (p *searxngProvider) Name() string {
+	return ProviderSearXNG
+}
+
+func (p *searxngProvider) Search(ctx context.Context, req Request) (*Response, error) {
+	endpoint := resolveEndpoint(strings.TrimSuffix(stringutil.NormalizeBaseURL(p.cfg.BaseURL), "/search"), "/search")
+	if endpoint == "" {
+		return nil, errors.New("searxng base_url is empty")
+	}
+
+	params := url.Values{}
+	params.Set("q", req.Query)
+	params.Set("format", "json")
+	if categories := joinSearXNGList(p.cfg.Categories); categories != "" {
+		params.Set("categories", categories)
+	}
+	if engines := joinSearXNGList(p.cfg.Engines); engines != "" {
+		params.Set("engines", engines)
+	}
+	if language := searxngLanguage(req, p.cfg.Language); language != "" {
+		params.Set("language", language)
+	}
+	if timeRange := freshnessPeriod(req.Freshness); timeRange != "" {
+		params.Set("time_range", timeRange)
+	}
+	if p.cfg.SafeSearch != nil {
+		params.Set("safesearch", strconv.Itoa(min(max(*p.cfg.SafeSearch, 0), 2)))
+	}
+
+	var headers map[string]string
+	if key := strings.TrimSpace(p.cfg.APIKey); key != "" {
+		headers = map[string]string{"Authorization": "Bearer " + key}
+	}
+	start := time.Now()
+	data, _, err := httputil.GetJSON(ctx, endpoint+"?"+params.Encode(), headers, DefaultTimeoutSecs)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Results []struct {
+			Title         string   `json:"title"`
+			URL           string   `json:"url"`
+			Content       string   `json:"content"`
+			Author        string   `json:"author"`
+			PublishedDate string   `json:"publishedDate"`
+			Thumbnail     string   `json:"thumbnail"`
+			ImgSrc        string   `json:"img_src"`
+			Engines       []string `json:"engines"`
+		} `json:"results"`
+		Answers   []json.RawMessage `json:"answers"`
+		Infoboxes []struct {
+			Infobox string `json:"infobox"`
+			Content string `json:"content"`
+		} `json:"infoboxes"`
+		Suggestions         []string `json:"suggestions"`
+		Corrections         []string `json:"corrections"`
+		UnresponsiveEngines [][]any  `json:"unresponsive_engines"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, min(len(resp.Results), req.Count))
+	for _, entry := range resp.Results {
+		if req.Count > 0 && len(results) >= req.Count {
+			break
+		}
+		if strings.TrimSpace(entry.URL) == "" {
+			continue
+		}
+		results = append(results, Result{
+			Title:       strings.TrimSpace(entry.Title),
+			URL:         entry.URL,
+			Description: strings.TrimSpace(entry.Content),
+			Published:   strings.TrimSpace(entry.PublishedDate),
+			SiteName:    resolveSiteName(entry.URL),
+			Author:      strings.TrimSpace(entry.Author),
+			Image:       stringutil.FirstNonEmpty(strings.TrimSpace(entry.Thumbnail), strings.TrimSpace(entry.ImgSrc)),
+		})
+	}
+
+	out := &Response{
+		Query:     req.Query,
+		Provider:  ProviderSearXNG,
+		Count:     len(results),
+		TookMs:    time.Since(start).Milliseconds(),
+		Results:   results,
+		Answer:    firstSearXNGAnswer(resp.Answers),
+		NoResults: len(results) == 0,
+	}
+	if len(resp.Infoboxes) > 0 {
+		out.Definition = strings.TrimSpace(resp.Infoboxes[0].Content)
+	}
+	extras := map[string]any{}
+	if len(resp.Suggestions) > 0 {
+		extras["suggestions"] = resp.Suggestions
+	}
+	if len(resp.Corrections) > 0 {
+		extras["corrections"] = resp.Corrections
+	}
+	if unresponsive := searxngUnresponsiveEngines(resp.UnresponsiveEngines); len(unresponsive) > 0 {
+		extras["unresponsiveEngines"] = unresponsive
+		if len(results) == 0 {
+			out.Warning = "Some SearXNG engines did not respond: " + strings.Join(unresponsive, ", ")
+		}
+	}
+	if len(extras) > 0 {
+		out.Extras = extras
+	}
+	return out, nil
+}
+
+// searxngLanguage builds a SearXNG language code from the request's search
+// language and country ("de" + "AT" -> "de-AT"), falling back to the configured default.
+func searxngLanguage(req Request, fallback string) string {
+	lang := strings.ToLower(strings.TrimSpace(req.SearchLang))
+	country := strings.ToUpper(strings.TrimSpace(req.Country))
+	if lang == "" {
+		return strings.TrimSpace(fallback)
+	}
+	if country != "" && country != "ALL" && !strings.Contains(lang, "-") {
+		return lang + "-" + country
+	}
+	return lang
+}
+
+func joinSearXNGList(values []string) string {
+	return strings.Join(stringutil.DedupeStrings(values), ",")
+}
+
+// firstSearXNGAnswer handles both answer shapes: plain strings (older
+// releases) and {"answer": "...", "url": "..."} objects.
+func firstSearXNGAnswer(answers []json.RawMessage) string {
+	for _, raw := range answers {
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil {
+			if text = strings.TrimSpace(text); text != "" {
+				return text
+			}
+			continue
+		}
+		var obj struct {
+			Answer string `json:"answer"`
+		}
+		if err := json.Unmarshal(raw, &obj); err == nil {
+			if text = strings.TrimSpace(obj.Answer); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// searxngUnresponsiveEngines extracts engine names from [name, reason] pairs.
+func searxngUnresponsiveEngines(entries [][]any) []string {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if len(entry) == 0 {
+			continue
+		}
+		if name, ok := entry[0].(string); ok && strings.TrimSpace(name) != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
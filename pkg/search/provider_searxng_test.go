@@ -0,0 +1,100 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearXNGProviderSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/searx/search" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Fatalf("expected no authorization header, got %q", got)
+		}
+		query := r.URL.Query()
+		want := map[string]string{
+			"q":          "matrix bridges",
+			"format":     "json",
+			"categories": "general,it",
+			"engines":    "duckduckgo",
+			"language":   "de-AT",
+			"time_range": "month",
+			"safesearch": "2",
+		}
+		for key, value := range want {
+			if got := query.Get(key); got != value {
+				t.Fatalf("expected %s=%q, got %q (%s)", key, value, got, r.URL.RawQuery)
+			}
+		}
+		_, _ = w.Write([]byte(`{
+			"results":[
+				{"title":"Bridges","url":"https://matrix.org/ecosystem/bridges/","content":"Connect to other networks.","publishedDate":"2024-05-01T00:00:00","thumbnail":"https://matrix.org/t.png"},
+				{"title":"No URL","url":""},
+				{"title":"Second","url":"https://example.com/2","content":"Two"},
+				{"title":"Third","url":"https://example.com/3","content":"Three"}
+			],
+			"answers":[{"answer":"Bridges connect Matrix to other chat networks.","url":"https://matrix.org"}],
+			"infoboxes":[{"infobox":"Matrix","content":"Matrix is an open standard."}],
+			"suggestions":["matrix bridge list"],
+			"unresponsive_engines":[["google","timeout"]]
+		}`))
+	}))
+	defer server.Close()
+
+	safeSearch := 5
+	provider := &searxngProvider{cfg: SearXNGConfig{
+		BaseURL:    server.URL + "/searx/search/",
+		Categories: []string{"general", " it ", "general"},
+		Engines:    []string{"duckduckgo"},
+		Language:   "en",
+		SafeSearch: &safeSearch,
+	}}
+	resp, err := provider.Search(context.Background(), Request{Query: "matrix bridges", Count: 2, SearchLang: "de", Country: "at", Freshness: "pm"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Provider != ProviderSearXNG || len(resp.Results) != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	first := resp.Results[0]
+	if first.SiteName != "matrix.org" || first.Description != "Connect to other networks." || first.Image != "https://matrix.org/t.png" {
+		t.Fatalf("unexpected first result: %+v", first)
+	}
+	if resp.Results[1].Title != "Second" {
+		t.Fatalf("expected results without URLs to be skipped, got %+v", resp.Results[1])
+	}
+	if resp.Answer != "Bridges connect Matrix to other chat networks." || resp.Definition != "Matrix is an open standard." {
+		t.Fatalf("unexpected answer/definition: %q / %q", resp.Answer, resp.Definition)
+	}
+	if engines, _ := resp.Extras["unresponsiveEngines"].([]string); len(engines) != 1 || engines[0] != "google" {
+		t.Fatalf("unexpected extras: %+v", resp.Extras)
+	}
+}
+
+func TestSearchFallsBackToSearXNG(t *testing.T) {
+	exa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer exa.Close()
+	searx := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[{"title":"ok","url":"https://example.com"}],"answers":["plain answer"]}`))
+	}))
+	defer searx.Close()
+
+	resp, err := Search(context.Background(), Request{Query: "test"}, &Config{
+		Provider:  ProviderExa,
+		Fallbacks: []string{ProviderSearXNG},
+		Exa:       ExaConfig{BaseURL: exa.URL, APIKey: "exa-key"},
+		SearXNG:   SearXNGConfig{BaseURL: searx.URL},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Provider != ProviderSearXNG || resp.Answer != "plain answer" || resp.Count != 1 {
+		t.Fatalf("unexpected fallback response: %+v", resp)
+	}
+}
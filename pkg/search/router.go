@@ -63,6 +63,24 @@ func normalizeRequest(req Request) Request {
 	return req
 }
 
+// freshnessPeriod maps the Brave-style freshness shorthands (pd, pw, pm, py) onto
+// the day/week/month/year periods other providers use. Date ranges have no
+// equivalent and map to "".
+func freshnessPeriod(freshness string) string {
+	switch strings.ToLower(strings.TrimSpace(freshness)) {
+	case "pd":
+		return "day"
+	case "pw":
+		return "week"
+	case "pm":
+		return "month"
+	case "py":
+		return "year"
+	default:
+		return ""
+	}
+}
+
 func buildOrder(cfg *Config) []string {
 	return stringutil.BuildProviderOrder(cfg.Provider, cfg.Fallbacks, DefaultFallbackOrder)
 }
@@ -75,6 +93,16 @@ func registerProviders(registry *Registry, cfg *Config) {
 	if p := newProviderIfEnabled(cfg.Exa.Enabled, cfg.Exa.APIKey, func() Provider { return &exaProvider{cfg: cfg.Exa} }); p != nil {
 		registry.Register(p)
 	}
+	if p := newProviderIfEnabled(cfg.Brave.Enabled, cfg.Brave.APIKey, func() Provider { return &braveProvider{cfg: cfg.Brave} }); p != nil {
+		registry.Register(p)
+	}
+	if p := newProviderIfEnabled(cfg.Perplexity.Enabled, cfg.Perplexity.APIKey, func() Provider { return &perplexityProvider{cfg: cfg.Perplexity} }); p != nil {
+		registry.Register(p)
+	}
+	// SearXNG needs no API key, only the instance URL.
+	if p := newProviderIfEnabled(cfg.SearXNG.Enabled, cfg.SearXNG.BaseURL, func() Provider { return &searxngProvider{cfg: cfg.SearXNG} }); p != nil {
+		registry.Register(p)
+	}
 }
 
 // newProviderIfEnabled returns a Provider when the feature flag is on and the
@@ -0,0 +1,276 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrBlockedAddress   = errors.New("destination address is not allowed")
+	ErrTooManyRedirects = errors.New("too many redirects")
+	ErrResponseTooLarge = errors.New("response body too large")
+)
+
+// blockedPrefixes are special-purpose ranges not covered by the netip helpers.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved and broadcast
+}
+
+var (
+	nat64Prefix  = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFourNet = netip.MustParsePrefix("2002::/16")
+)
+
+// IsBlockedAddr reports whether addr is loopback, private, link-local or
+// otherwise not a public unicast address. IPv6 forms that embed an IPv4
+// address are checked against the embedded address.
+func IsBlockedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if addr.Is6() {
+		raw := addr.As16()
+		switch {
+		case nat64Prefix.Contains(addr):
+			return IsBlockedAddr(netip.AddrFrom4([4]byte(raw[12:16])))
+		case sixToFourNet.Contains(addr):
+			return IsBlockedAddr(netip.AddrFrom4([4]byte(raw[2:6])))
+		}
+	}
+	return false
+}
+
+// ValidateURL checks that rawURL is an http(s) URL that doesn't obviously
+// point at an internal host. Hostnames are checked again after resolution
+// when a safe client dials them.
+func ValidateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	return validateURL(parsed, nil)
+}
+
+func validateURL(parsed *url.URL, allow *hostAllowlist) error {
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", parsed.Scheme)
+	}
+	host := normalizeHost(parsed.Hostname())
+	if host == "" {
+		return errors.New("URL has no host")
+	}
+	if allow.allowsHost(host) {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && IsBlockedAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// SafeClientOptions configures NewSafeClient.
+type SafeClientOptions struct {
+	Timeout time.Duration
+	// MaxRedirects is how many redirects are followed. Zero disables redirects.
+	MaxRedirects int
+	// MaxResponseBytes makes reads fail with ErrResponseTooLarge once a body
+	// exceeds it. Zero means unlimited.
+	MaxResponseBytes int64
+	// AllowedHosts exempts trusted internal destinations, such as a
+	// self-hosted server on the LAN, from the address checks. Entries are
+	// hostnames, matched exactly, or IP addresses and CIDR prefixes like
+	// "10.0.0.0/8". Everything else is still checked.
+	AllowedHosts []string
+	// AllowPrivate turns off the address checks. Only for tests.
+	AllowPrivate bool
+}
+
+// hostAllowlist is SafeClientOptions.AllowedHosts split into hostnames and
+// address prefixes. A nil allowlist allows nothing.
+type hostAllowlist struct {
+	hosts    map[string]bool
+	prefixes []netip.Prefix
+}
+
+func newHostAllowlist(entries []string) *hostAllowlist {
+	allow := &hostAllowlist{hosts: make(map[string]bool)}
+	for _, entry := range entries {
+		entry = normalizeHost(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			allow.prefixes = append(allow.prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			addr = addr.Unmap()
+			allow.prefixes = append(allow.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		} else if entry != "" {
+			allow.hosts[entry] = true
+		}
+	}
+	if len(allow.hosts) == 0 && len(allow.prefixes) == 0 {
+		return nil
+	}
+	return allow
+}
+
+func normalizeHost(host string) string {
+	host = strings.TrimSpace(strings.ToLower(host))
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.TrimSuffix(host, ".")
+}
+
+func (a *hostAllowlist) allowsHost(host string) bool {
+	if a == nil {
+		return false
+	}
+	host = normalizeHost(host)
+	if a.hosts[host] {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && a.allowsAddr(addr)
+}
+
+func (a *hostAllowlist) allowsAddr(addr netip.Addr) bool {
+	if a == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range a.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSafeClient returns an HTTP client for fetching untrusted URLs. Every
+// connection is checked against IsBlockedAddr after DNS resolution, so
+// hostnames that resolve to internal addresses and DNS rebinding are caught,
+// and each redirect hop is validated again. Environment proxies are ignored
+// since they would hide the real destination.
+func NewSafeClient(opts SafeClientOptions) *http.Client {
+	transport := sharedSafeTransport()
+	if opts.AllowPrivate {
+		transport = newSafeTransport(false, nil)
+	} else if allow := newHostAllowlist(opts.AllowedHosts); allow != nil {
+		transport = newSafeTransport(true, allow)
+	}
+	var roundTripper http.RoundTripper = transport
+	if opts.MaxResponseBytes > 0 {
+		roundTripper = &limitedTransport{base: transport, max: opts.MaxResponseBytes}
+	}
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: roundTripper,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return checkSafeRedirect(req, via, opts)
+		},
+	}
+}
+
+// sharedSafeTransport is reused by all checked clients so their idle
+// connections are pooled instead of leaking one pool per client.
+var sharedSafeTransport = sync.OnceValue(func() *http.Transport {
+	return newSafeTransport(true, nil)
+})
+
+// newSafeTransport dials through the address checks when checkAddrs is set.
+// Hosts on allow are dialed without them: allowed hostnames are matched
+// before resolution, allowed prefixes against the resolved address.
+func newSafeTransport(checkAddrs bool, allow *hostAllowlist) *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	dial := dialer.DialContext
+	if checkAddrs {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+			}
+			if IsBlockedAddr(addrPort.Addr()) && !allow.allowsAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, addrPort.Addr())
+			}
+			return nil
+		}
+		if allow != nil && len(allow.hosts) > 0 {
+			trusted := &net.Dialer{Timeout: dialer.Timeout, KeepAlive: dialer.KeepAlive}
+			dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+				if host, _, err := net.SplitHostPort(address); err == nil && allow.hosts[normalizeHost(host)] {
+					return trusted.DialContext(ctx, network, address)
+				}
+				return dialer.DialContext(ctx, network, address)
+			}
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dial
+	return transport
+}
+
+func checkSafeRedirect(req *http.Request, via []*http.Request, opts SafeClientOptions) error {
+	if len(via) > opts.MaxRedirects {
+		return fmt.Errorf("%w (max %d)", ErrTooManyRedirects, opts.MaxRedirects)
+	}
+	if opts.AllowPrivate {
+		return nil
+	}
+	return validateURL(req.URL, newHostAllowlist(opts.AllowedHosts))
+}
+
+type limitedTransport struct {
+	base http.RoundTripper
+	max  int64
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: t.max, max: t.max}
+	return resp, nil
+}
+
+// limitedBody reads up to max bytes and fails instead of silently truncating.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	max       int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		return n, fmt.Errorf("%w (max %d bytes)", ErrResponseTooLarge, b.max)
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,151 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestIsBlockedAddr(t *testing.T) {
+	blocked := []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "255.255.255.255", "224.0.0.1",
+		"::1", "::", "fe80::1", "fd00::1", "::ffff:127.0.0.1", "64:ff9b::a9fe:a9fe", "2002:7f00:1::",
+	}
+	for _, raw := range blocked {
+		if !IsBlockedAddr(netip.MustParseAddr(raw)) {
+			t.Errorf("expected %s to be blocked", raw)
+		}
+	}
+	for _, raw := range []string{"93.184.216.34", "1.1.1.1", "2606:4700:4700::1111", "64:ff9b::808:808"} {
+		if IsBlockedAddr(netip.MustParseAddr(raw)) {
+			t.Errorf("expected %s to be allowed", raw)
+		}
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	for _, raw := range []string{"https://example.com/a", "http://93.184.216.34:8080/"} {
+		if err := ValidateURL(raw); err != nil {
+			t.Errorf("expected %s to be valid, got %v", raw, err)
+		}
+	}
+	for _, raw := range []string{"ftp://example.com", "file:///etc/passwd", "http://localhost/", "http://app.localhost/", "http://[::1]/", "http://10.0.0.1/", "http:///path"} {
+		if err := ValidateURL(raw); err == nil {
+			t.Errorf("expected %s to be rejected", raw)
+		}
+	}
+}
+
+func TestSafeClientRefusesLoopbackAtDialTime(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits++ }))
+	defer srv.Close()
+
+	// A hostname that resolves to loopback gets past URL validation but not the dialer.
+	target := strings.Replace(srv.URL, "127.0.0.1", "localtest.invalid", 1)
+	transport := newSafeTransport(true, nil)
+	transport.DialContext = wrapResolve(transport.DialContext, "localtest.invalid", "127.0.0.1")
+	client := &http.Client{Transport: transport}
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, target, nil)
+	_, err := client.Do(req)
+	if !errors.Is(err, ErrBlockedAddress) || hits != 0 {
+		t.Fatalf("expected blocked address error, got %v (hits=%d)", err, hits)
+	}
+}
+
+func TestSafeClientAllowedHosts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	byName := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	for _, tc := range []struct {
+		allowed []string
+		target  string
+		ok      bool
+	}{
+		{nil, srv.URL, false},
+		{[]string{"10.0.0.0/8"}, srv.URL, false},
+		{[]string{"127.0.0.0/8"}, srv.URL, true},
+		{[]string{"127.0.0.1"}, srv.URL, true},
+		{[]string{"LocalHost."}, byName, true},
+		{[]string{"localhost"}, srv.URL, false},
+	} {
+		client := NewSafeClient(SafeClientOptions{AllowedHosts: tc.allowed})
+		resp, err := client.Get(tc.target)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if tc.ok && err != nil {
+			t.Errorf("%v: expected %s to be allowed, got %v", tc.allowed, tc.target, err)
+		} else if !tc.ok && !errors.Is(err, ErrBlockedAddress) {
+			t.Errorf("%v: expected %s to be blocked, got %v", tc.allowed, tc.target, err)
+		}
+	}
+
+	opts := SafeClientOptions{MaxRedirects: 1, AllowedHosts: []string{"192.168.1.0/24", "nas.lan"}}
+	for raw, ok := range map[string]bool{"http://192.168.1.20/": true, "http://nas.lan/": true, "http://192.168.2.1/": false, "http://localhost/": false} {
+		u, _ := url.Parse(raw)
+		if err := checkSafeRedirect(&http.Request{URL: u}, nil, opts); (err == nil) != ok {
+			t.Errorf("redirect to %s: expected allowed=%v, got %v", raw, ok, err)
+		}
+	}
+}
+
+// wrapResolve maps host to ip before dialing, standing in for a DNS answer.
+func wrapResolve(dial func(context.Context, string, string) (net.Conn, error), host, ip string) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dial(ctx, network, strings.Replace(address, host, ip, 1))
+	}
+}
+
+func TestCheckSafeRedirect(t *testing.T) {
+	opts := SafeClientOptions{MaxRedirects: 2}
+	hop := func(raw string) *http.Request {
+		u, _ := url.Parse(raw)
+		return &http.Request{URL: u}
+	}
+	via := []*http.Request{hop("https://example.com/")}
+	if err := checkSafeRedirect(hop("https://example.org/"), via, opts); err != nil {
+		t.Fatalf("expected public redirect to be allowed, got %v", err)
+	}
+	if err := checkSafeRedirect(hop("http://169.254.169.254/latest/meta-data"), via, opts); !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("expected redirect into link-local space to be blocked, got %v", err)
+	}
+	via = append(via, hop("https://example.org/"), hop("https://example.net/"))
+	if err := checkSafeRedirect(hop("https://example.com/final"), via, opts); !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("expected redirect limit error, got %v", err)
+	}
+}
+
+func TestSafeClientEnforcesRedirectLimitAndBodySize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/loop" {
+			http.Redirect(w, r, "/loop", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer srv.Close()
+
+	client := NewSafeClient(SafeClientOptions{MaxRedirects: 3, MaxResponseBytes: 50, AllowPrivate: true})
+	if _, err := client.Get(srv.URL + "/loop"); !errors.Is(err, ErrTooManyRedirects) {
+		t.Fatalf("expected redirect limit error, got %v", err)
+	}
+	resp, err := client.Get(srv.URL + "/big")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if !errors.Is(err, ErrResponseTooLarge) || len(data) != 50 {
+		t.Fatalf("expected body to stop at 50 bytes with an error, got %d bytes (%v)", len(data), err)
+	}
+}
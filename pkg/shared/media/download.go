@@ -6,23 +6,62 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/beeper/agentremote/pkg/shared/httputil"
 	"github.com/beeper/agentremote/pkg/shared/stringutil"
 )
 
+// downloadClient refuses internal destinations since download URLs can come
+// from model output and message content.
+var downloadClient = newDownloadClient()
+
+// downloadClients caches DownloadClientFor results by allowlist so each
+// trusted host keeps a single connection pool.
+var downloadClients sync.Map
+
+func newDownloadClient(allowedHosts ...string) *http.Client {
+	return httputil.NewSafeClient(httputil.SafeClientOptions{
+		Timeout:      60 * time.Second,
+		MaxRedirects: 5,
+		AllowedHosts: allowedHosts,
+	})
+}
+
+// DownloadClientFor returns a client with the same limits as DownloadURL that
+// may also reach allowedHosts (hostnames, IPs or CIDR prefixes). Bridges use
+// it for files served by their own configured server, which is often on
+// localhost or the LAN.
+func DownloadClientFor(allowedHosts ...string) *http.Client {
+	if len(allowedHosts) == 0 {
+		return downloadClient
+	}
+	key := strings.Join(allowedHosts, "\n")
+	if client, ok := downloadClients.Load(key); ok {
+		return client.(*http.Client)
+	}
+	client, _ := downloadClients.LoadOrStore(key, newDownloadClient(allowedHosts...))
+	return client.(*http.Client)
+}
+
 // DownloadURL fetches a file from rawURL over HTTP(S) and returns the bytes,
 // resolved MIME type, and any error. When maxBytes > 0 the download is
 // rejected if the server-advertised Content-Length or the actual body exceeds
 // that limit. fallbackMime is used when the server does not return a usable
 // Content-Type header.
 func DownloadURL(ctx context.Context, rawURL, fallbackMime string, maxBytes int64) ([]byte, string, error) {
+	return DownloadURLWithClient(ctx, downloadClient, rawURL, fallbackMime, maxBytes)
+}
+
+// DownloadURLWithClient is DownloadURL using client, e.g. one from
+// DownloadClientFor that may reach a trusted internal host.
+func DownloadURLWithClient(ctx context.Context, client *http.Client, rawURL, fallbackMime string, maxBytes int64) ([]byte, string, error) {
 	rawURL = strings.TrimSpace(rawURL)
 	if rawURL == "" {
 		return nil, "", fmt.Errorf("missing download URL")
 	}
 
-	client := &http.Client{Timeout: 60 * time.Second}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
 		return nil, "", err
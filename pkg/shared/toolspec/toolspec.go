@@ -10,13 +10,13 @@ const (
 	WebSearchDescription = "Search the web using the best available provider (OpenRouter web search when configured). Supports region-specific and localized search via country and language parameters. Returns titles, URLs, and snippets for fast research."
 
 	WebFetchName        = "web_fetch"
-	WebFetchDescription = "Fetch and extract readable content from a URL (HTML \u2192 markdown/text). Use for lightweight page access without browser automation."
+	WebFetchDescription = "Fetch and extract readable content from a URL (HTML \u2192 markdown/text, PDF \u2192 text). Use for lightweight page access without browser automation."
 
 	MessageName        = "message"
 	MessageDescription = "Send messages and channel actions. Supports actions: send, delete, react, poll, pin, threads, focus, and more."
 
 	CronName        = "cron"
-	CronDescription = "Manage scheduler-backed jobs that run in hidden background rooms.\n\nACTIONS:\n- status: Check scheduler status\n- list: List jobs (use includeDisabled:true to include disabled)\n- add: Create job (requires job object, see schema below)\n- update: Modify job (requires jobId + patch object)\n- remove: Delete job (requires jobId)\n- run: Trigger job immediately (requires jobId)\n\nJOB SCHEMA (for add action):\n{\n  \"name\": \"string (optional)\",\n  \"schedule\": { ... },\n  \"payload\": { ... },\n  \"delivery\": { ... },\n  \"enabled\": true | false\n}\n\nSCHEDULE TYPES (schedule.kind):\n- \"at\": One-shot at absolute time\n  { \"kind\": \"at\", \"at\": \"<ISO-8601 timestamp>\" }\n- \"every\": Recurring interval\n  { \"kind\": \"every\", \"everyMs\": <interval-ms>, \"anchorMs\": <optional-start-ms> }\n- \"cron\": Cron expression\n  { \"kind\": \"cron\", \"expr\": \"<cron-expression>\", \"tz\": \"<optional-timezone>\" }\n\nPAYLOAD:\n- \"agentTurn\": Run the agent inside a hidden background room\n  { \"kind\": \"agentTurn\", \"message\": \"<prompt>\", \"model\": \"<optional>\", \"thinking\": \"<optional>\", \"timeoutSeconds\": <optional> }\n\nDELIVERY:\n  { \"mode\": \"none|announce\", \"to\": \"<!room-id:server>\", \"bestEffort\": <optional-bool> }\n  - delivery.to: Matrix room ID (e.g. !abcdef:server.com). Omit to use the last active room or default chat.\n\nUse contextMessages (0-10) to add recent chat context to the scheduled payload."
+	CronDescription = "Manage scheduler-backed jobs that run in hidden background rooms.\n\nACTIONS:\n- status: Check scheduler status\n- list: List jobs (use includeDisabled:true to include disabled)\n- add: Create job (requires job object, see schema below)\n- update: Modify job (requires jobId + patch object)\n- remove: Delete job (requires jobId)\n- run: Trigger job immediately (requires jobId)\n- runs: Show recent runs of a job with status, timing, token usage and output (requires jobId, optional limit)\n\nJOB SCHEMA (for add action):\n{\n  \"name\": \"string (optional)\",\n  \"schedule\": { ... },\n  \"payload\": { ... },\n  \"delivery\": { ... },\n  \"enabled\": true | false\n}\n\nSCHEDULE TYPES (schedule.kind):\n- \"at\": One-shot at absolute time\n  { \"kind\": \"at\", \"at\": \"<ISO-8601 timestamp>\" }\n- \"every\": Recurring interval\n  { \"kind\": \"every\", \"everyMs\": <interval-ms>, \"anchorMs\": <optional-start-ms> }\n- \"cron\": Cron expression\n  { \"kind\": \"cron\", \"expr\": \"<cron-expression>\", \"tz\": \"<optional-timezone>\" }\n\nPAYLOAD:\n- \"agentTurn\": Run the agent inside a hidden background room\n  { \"kind\": \"agentTurn\", \"message\": \"<prompt>\", \"model\": \"<optional>\", \"thinking\": \"<optional>\", \"timeoutSeconds\": <optional> }\n\nDELIVERY:\n  { \"mode\": \"none|announce\", \"to\": \"<!room-id:server>\", \"bestEffort\": <optional-bool> }\n  - delivery.to: Matrix room ID (e.g. !abcdef:server.com). Omit to use the last active room or default chat.\n\nUse contextMessages (0-10) to add recent chat context to the scheduled payload."
 
 	SessionStatusName        = "session_status"
 	SessionStatusDescription = "Show a /status-equivalent session status card (usage + time + cost when available). Use for model-use questions (📊 session_status). Optional: set per-session model override (model=default resets overrides)."
@@ -45,6 +45,14 @@ const (
 	EditName         = "edit"
 	EditDescription  = "Replace exact text in file. Must match exactly including whitespace. Fails if text appears multiple times or not found."
 
+	FileHistoryName        = "file_history"
+	FileHistoryDescription = "List earlier versions of a file, show one, or restore it. Actions: list (versions of path, newest first), show (content of a version), restore (write a version back as the newest). Use to undo bad writes or edits."
+
+	ExecName           = "exec"
+	ExecDescription    = "Run a shell command in the agent's working directory. Returns exit code and output. Set background (or yieldMs) for long-running commands and follow up with the process tool."
+	ProcessName        = "process"
+	ProcessDescription = "Manage background exec sessions. Actions: list (sessions of this agent), poll (new output and status), log (buffered output with offset/limit lines), write (send data to stdin, eof closes it), kill (stop the command), remove (kill and forget the session)."
+
 	GravatarFetchName        = "gravatar_fetch"
 	GravatarFetchDescription = "Fetch a Gravatar profile for an email address. You must provide an email address."
 	GravatarSetName          = "gravatar_set"
@@ -55,6 +63,9 @@ const (
 
 	BeeperSendFeedbackName        = "beeper_send_feedback"
 	BeeperSendFeedbackDescription = "Submit feedback or bug reports to Beeper. Use when the user wants to report a problem, request a feature, or send feedback about their Beeper experience."
+
+	MCPResourceName        = "mcp_resource"
+	MCPResourceDescription = "Browse and read resources (docs, files, records) published by connected MCP servers. Actions: list (available resources), read (fetch a resource by uri), attach (keep a resource in this chat's context), detach (remove it again)."
 )
 
 // CalculatorSchema returns the JSON schema for the calculator tool.
@@ -170,6 +181,99 @@ func EditSchema() map[string]any {
 	}
 }
 
+// FileHistorySchema returns the JSON schema for the file_history tool.
+func FileHistorySchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "show", "restore"},
+				"description": "Action to perform: list, show, restore.",
+			},
+			"path": map[string]any{
+				"type":        "string",
+				"description": "Path of the file (relative or absolute)",
+			},
+			"version": map[string]any{
+				"type":        "number",
+				"description": "Version number from list (required for show and restore).",
+			},
+		},
+		"required": []string{"action", "path"},
+	}
+}
+
+// ExecSchema returns the JSON schema for the exec tool.
+func ExecSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"command": map[string]any{
+				"type":        "string",
+				"description": "Shell command to run",
+			},
+			"workdir": map[string]any{
+				"type":        "string",
+				"description": "Working directory relative to the agent's workspace (default: workspace root)",
+			},
+			"env": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"description":          "Extra environment variables",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Timeout in seconds (capped by the bridge config)",
+			},
+			"yieldMs": map[string]any{
+				"type":        "number",
+				"description": "Wait this many milliseconds, then leave the command running as a background session",
+			},
+			"background": map[string]any{
+				"type":        "boolean",
+				"description": "Start as a background session and return immediately",
+			},
+		},
+		"required": []string{"command"},
+	}
+}
+
+// ProcessSchema returns the JSON schema for the process tool.
+func ProcessSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "poll", "log", "write", "kill", "remove"},
+				"description": "Action to perform: list, poll, log, write, kill, remove.",
+			},
+			"sessionId": map[string]any{
+				"type":        "string",
+				"description": "Session ID returned by exec (required for everything but list)",
+			},
+			"data": map[string]any{
+				"type":        "string",
+				"description": "Data to write to stdin (write)",
+			},
+			"eof": map[string]any{
+				"type":        "boolean",
+				"description": "Close stdin after writing (write)",
+			},
+			"offset": map[string]any{
+				"type":        "number",
+				"description": "Line to start from, 1-indexed (log)",
+			},
+			"limit": map[string]any{
+				"type":        "number",
+				"description": "Maximum number of lines to return (log)",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
 // GravatarFetchSchema returns the JSON schema for the Gravatar fetch tool.
 func GravatarFetchSchema() map[string]any {
 	return map[string]any{
@@ -418,8 +522,8 @@ func CronSchema() map[string]any {
 		"properties": map[string]any{
 			"action": map[string]any{
 				"type":        "string",
-				"enum":        []string{"status", "list", "add", "update", "remove", "run"},
-				"description": "Action to perform: status, list, add, update, remove, run.",
+				"enum":        []string{"status", "list", "add", "update", "remove", "run", "runs"},
+				"description": "Action to perform: status, list, add, update, remove, run, runs.",
 			},
 			"includeDisabled": map[string]any{
 				"type":        "boolean",
@@ -439,6 +543,17 @@ func CronSchema() map[string]any {
 				"additionalProperties": true,
 				"description":          "Patch object for update.",
 			},
+			"limit": map[string]any{
+				"type":        "number",
+				"minimum":     1,
+				"maximum":     50,
+				"description": "For runs: maximum number of runs to return, newest first (default 10).",
+			},
+			"offset": map[string]any{
+				"type":        "number",
+				"minimum":     0,
+				"description": "For runs: number of newer runs to skip, from nextOffset of the previous page. Each run's output is truncated.",
+			},
 			"contextMessages": map[string]any{
 				"type":        "number",
 				"minimum":     0,
@@ -685,3 +800,26 @@ func BeeperSendFeedbackSchema() map[string]any {
 		"required": []string{"text"},
 	}
 }
+
+// MCPResourceSchema returns the JSON schema for the mcp_resource tool.
+func MCPResourceSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{
+				"type":        "string",
+				"enum":        []string{"list", "read", "attach", "detach"},
+				"description": "Action to perform: list, read, attach, detach.",
+			},
+			"uri": map[string]any{
+				"type":        "string",
+				"description": "Resource URI (required for read, attach, detach).",
+			},
+			"server": map[string]any{
+				"type":        "string",
+				"description": "Optional MCP server name; narrows list and disambiguates URIs served by several servers.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
@@ -0,0 +1,152 @@
+package textfs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// MaxRevisionsPerFile caps how many versions are kept for each path. Older
+// versions are dropped as new ones are written.
+const MaxRevisionsPerFile = 50
+
+const (
+	AuthorAgent  = "agent"
+	AuthorUser   = "user"
+	AuthorSystem = "system"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Author identifies who wrote a revision. ToolCallID is set when an agent
+// wrote the file through a tool call.
+type Author struct {
+	Kind       string
+	ToolCallID string
+}
+
+// Revision is one stored version of a file. Version numbers increase per path.
+// An empty author means the content predates revision tracking.
+type Revision struct {
+	Path      string
+	Version   int
+	Content   string
+	Hash      string
+	Author    Author
+	CreatedAt int64
+}
+
+// snapshotCurrent stores the current content of path as its first revision if
+// it has none yet, so files written before revisions existed can be restored.
+func (s *Store) snapshotCurrent(ctx context.Context, path string) error {
+	var count int
+	err := s.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM ai_memory_file_revisions
+         WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND path=$4`,
+		s.bridgeID, s.loginID, s.agentID, path,
+	).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = s.db.Exec(ctx,
+		`INSERT INTO ai_memory_file_revisions
+           (bridge_id, login_id, agent_id, path, version, content, hash, author, tool_call_id, created_at)
+         SELECT bridge_id, login_id, agent_id, path, 1, content, hash, '', '', updated_at
+         FROM ai_memory_files
+         WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND path=$4`,
+		s.bridgeID, s.loginID, s.agentID, path,
+	)
+	return err
+}
+
+// addRevision records content as the newest version of path unless it is
+// identical to the current newest version.
+func (s *Store) addRevision(ctx context.Context, path, content, hash string, createdAt int64) error {
+	var (
+		latest     int
+		latestHash sql.NullString
+	)
+	err := s.db.QueryRow(ctx,
+		`SELECT version, hash FROM ai_memory_file_revisions
+         WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND path=$4
+         ORDER BY version DESC LIMIT 1`,
+		s.bridgeID, s.loginID, s.agentID, path,
+	).Scan(&latest, &latestHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if latestHash.Valid && latestHash.String == hash {
+		return nil
+	}
+	version := latest + 1
+	_, err = s.db.Exec(ctx,
+		`INSERT INTO ai_memory_file_revisions
+           (bridge_id, login_id, agent_id, path, version, content, hash, author, tool_call_id, created_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		s.bridgeID, s.loginID, s.agentID, path, version, content, hash, s.author.Kind, s.author.ToolCallID, createdAt,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(ctx,
+		`DELETE FROM ai_memory_file_revisions
+         WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND path=$4 AND version <= $5`,
+		s.bridgeID, s.loginID, s.agentID, path, version-MaxRevisionsPerFile,
+	)
+	return err
+}
+
+// History returns the stored versions of a file, newest first.
+func (s *Store) History(ctx context.Context, relPath string) ([]Revision, error) {
+	path, err := NormalizePath(relPath)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(ctx,
+		`SELECT path, version, content, hash, author, tool_call_id, created_at
+         FROM ai_memory_file_revisions
+         WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND path=$4
+         ORDER BY version DESC`,
+		s.bridgeID, s.loginID, s.agentID, path,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []Revision
+	for rows.Next() {
+		var rev Revision
+		if err := rows.Scan(&rev.Path, &rev.Version, &rev.Content, &rev.Hash, &rev.Author.Kind, &rev.Author.ToolCallID, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		// Files that haven't been written since revisions were added still have
+		// their current content as a version.
+		entry, found, err := s.Read(ctx, path)
+		if err != nil || !found {
+			return nil, err
+		}
+		revisions = append(revisions, Revision{Path: entry.Path, Version: 1, Content: entry.Content, Hash: entry.Hash, CreatedAt: entry.UpdatedAt})
+	}
+	return revisions, nil
+}
+
+// Revert writes the content of an earlier version back as the newest version.
+func (s *Store) Revert(ctx context.Context, relPath string, version int) (*FileEntry, error) {
+	revisions, err := s.History(ctx, relPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, rev := range revisions {
+		if rev.Version == version {
+			return s.Write(ctx, rev.Path, rev.Content)
+		}
+	}
+	return nil, ErrRevisionNotFound
+}
@@ -0,0 +1,104 @@
+package textfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStoreHistoryAndRevert(t *testing.T) {
+	ctx := context.Background()
+	db := setupTextfsDB(t)
+	store := NewStore(db, "bridge", "login", "agent")
+	agent := store.WithAuthor(Author{Kind: AuthorAgent, ToolCallID: "call_1"})
+
+	if _, err := store.Write(ctx, "SOUL.md", "v1"); err != nil {
+		t.Fatalf("write v1: %v", err)
+	}
+	if _, err := agent.Write(ctx, "SOUL.md", "v2"); err != nil {
+		t.Fatalf("write v2: %v", err)
+	}
+	// Rewriting identical content doesn't add a version.
+	if _, err := agent.Write(ctx, "SOUL.md", "v2"); err != nil {
+		t.Fatalf("rewrite v2: %v", err)
+	}
+
+	history, err := store.History(ctx, "SOUL.md")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(history) != 2 || history[0].Version != 2 || history[0].Content != "v2" || history[1].Content != "v1" {
+		t.Fatalf("unexpected history %#v", history)
+	}
+	if history[0].Author != (Author{Kind: AuthorAgent, ToolCallID: "call_1"}) {
+		t.Fatalf("unexpected author %#v", history[0].Author)
+	}
+
+	entry, err := store.WithAuthor(Author{Kind: AuthorUser}).Revert(ctx, "SOUL.md", 1)
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if entry.Content != "v1" {
+		t.Fatalf("unexpected reverted content %q", entry.Content)
+	}
+	history, err = store.History(ctx, "SOUL.md")
+	if err != nil || len(history) != 3 || history[0].Author.Kind != AuthorUser || history[0].Content != "v1" {
+		t.Fatalf("expected revert to add a user version, got %#v (%v)", history, err)
+	}
+
+	if _, err = store.Revert(ctx, "SOUL.md", 42); !errors.Is(err, ErrRevisionNotFound) {
+		t.Fatalf("expected ErrRevisionNotFound, got %v", err)
+	}
+}
+
+func TestStoreHistorySnapshotsUntrackedContent(t *testing.T) {
+	ctx := context.Background()
+	db := setupTextfsDB(t)
+	store := NewStore(db, "bridge", "login", "agent")
+
+	// Simulate a file written before revisions were tracked.
+	if _, err := db.Exec(ctx,
+		`INSERT INTO ai_memory_files (bridge_id, login_id, agent_id, path, source, content, hash, updated_at)
+         VALUES ('bridge', 'login', 'agent', 'MEMORY.md', 'memory', 'old', $1, 1)`, hashContent("old"),
+	); err != nil {
+		t.Fatalf("seed file: %v", err)
+	}
+	history, err := store.History(ctx, "MEMORY.md")
+	if err != nil || len(history) != 1 || history[0].Content != "old" {
+		t.Fatalf("expected current content as only version, got %#v (%v)", history, err)
+	}
+
+	if _, err = store.Write(ctx, "MEMORY.md", "new"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err = store.Delete(ctx, "MEMORY.md"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	history, err = store.History(ctx, "MEMORY.md")
+	if err != nil || len(history) != 2 || history[1].Content != "old" || history[1].Author.Kind != "" {
+		t.Fatalf("expected untracked content to be kept, got %#v (%v)", history, err)
+	}
+	if entry, err := store.Revert(ctx, "MEMORY.md", 1); err != nil || entry.Content != "old" {
+		t.Fatalf("expected deleted file to be restorable, got %#v (%v)", entry, err)
+	}
+}
+
+func TestStoreRevisionsArePruned(t *testing.T) {
+	ctx := context.Background()
+	db := setupTextfsDB(t)
+	store := NewStore(db, "bridge", "login", "agent")
+
+	for i := range MaxRevisionsPerFile + 5 {
+		if _, err := store.Write(ctx, "notes/log.md", fmt.Sprintf("entry %d", i)); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+	history, err := store.History(ctx, "notes/log.md")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(history) != MaxRevisionsPerFile || history[len(history)-1].Version != 6 {
+		t.Fatalf("expected %d newest versions, got %d (oldest v%d)", MaxRevisionsPerFile, len(history), history[len(history)-1].Version)
+	}
+}
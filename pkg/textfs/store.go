@@ -15,6 +15,7 @@ type Store struct {
 	bridgeID string
 	loginID  string
 	agentID  string
+	author   Author
 }
 
 type FileEntry struct {
@@ -34,6 +35,14 @@ func NewStore(db *dbutil.Database, bridgeID, loginID, agentID string) *Store {
 	}
 }
 
+// WithAuthor returns a copy of the store that attributes the revisions it
+// writes to author.
+func (s *Store) WithAuthor(author Author) *Store {
+	clone := *s
+	clone.author = author
+	return &clone
+}
+
 func (s *Store) Read(ctx context.Context, relPath string) (*FileEntry, bool, error) {
 	path, err := NormalizePath(relPath)
 	if err != nil {
@@ -63,14 +72,23 @@ func (s *Store) Write(ctx context.Context, relPath, content string) (*FileEntry,
 	hash := hashContent(content)
 	updatedAt := time.Now().UnixMilli()
 	source := ClassifySource(path)
-	_, err = s.db.Exec(ctx,
-		`INSERT INTO ai_memory_files
-           (bridge_id, login_id, agent_id, path, source, content, hash, updated_at)
-         VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-         ON CONFLICT (bridge_id, login_id, agent_id, path)
-         DO UPDATE SET source=excluded.source, content=excluded.content, hash=excluded.hash, updated_at=excluded.updated_at`,
-		s.bridgeID, s.loginID, s.agentID, path, source, content, hash, updatedAt,
-	)
+	err = s.db.DoTxn(ctx, nil, func(ctx context.Context) error {
+		if err := s.snapshotCurrent(ctx, path); err != nil {
+			return err
+		}
+		_, err := s.db.Exec(ctx,
+			`INSERT INTO ai_memory_files
+               (bridge_id, login_id, agent_id, path, source, content, hash, updated_at)
+             VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+             ON CONFLICT (bridge_id, login_id, agent_id, path)
+             DO UPDATE SET source=excluded.source, content=excluded.content, hash=excluded.hash, updated_at=excluded.updated_at`,
+			s.bridgeID, s.loginID, s.agentID, path, source, content, hash, updatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return s.addRevision(ctx, path, content, hash, updatedAt)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +129,11 @@ func (s *Store) WriteIfMissing(ctx context.Context, relPath, content string) (bo
 	if err != nil {
 		return false, nil
 	}
+	if rows > 0 {
+		if err = s.addRevision(ctx, path, content, hash, updatedAt); err != nil {
+			return true, err
+		}
+	}
 	return rows > 0, nil
 }
 
@@ -119,11 +142,17 @@ func (s *Store) Delete(ctx context.Context, relPath string) error {
 	if err != nil {
 		return err
 	}
-	_, err = s.db.Exec(ctx,
-		`DELETE FROM ai_memory_files WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND path=$4`,
-		s.bridgeID, s.loginID, s.agentID, path,
-	)
-	return err
+	return s.db.DoTxn(ctx, nil, func(ctx context.Context) error {
+		// Keep the deleted content restorable through Revert.
+		if err := s.snapshotCurrent(ctx, path); err != nil {
+			return err
+		}
+		_, err := s.db.Exec(ctx,
+			`DELETE FROM ai_memory_files WHERE bridge_id=$1 AND login_id=$2 AND agent_id=$3 AND path=$4`,
+			s.bridgeID, s.loginID, s.agentID, path,
+		)
+		return err
+	})
 }
 
 func (s *Store) List(ctx context.Context) ([]FileEntry, error) {
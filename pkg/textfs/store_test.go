@@ -15,6 +15,7 @@ func setupTextfsDB(t *testing.T) *dbutil.Database {
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
+	raw.SetMaxOpenConns(1)
 	db, err := dbutil.NewWithDB(raw, "sqlite3")
 	if err != nil {
 		t.Fatalf("wrap db: %v", err)
@@ -32,6 +33,19 @@ func setupTextfsDB(t *testing.T) *dbutil.Database {
 			updated_at INTEGER NOT NULL,
 			PRIMARY KEY (bridge_id, login_id, agent_id, path)
 		);
+		CREATE TABLE IF NOT EXISTS ai_memory_file_revisions (
+			bridge_id TEXT NOT NULL,
+			login_id TEXT NOT NULL,
+			agent_id TEXT NOT NULL,
+			path TEXT NOT NULL,
+			version INTEGER NOT NULL,
+			content TEXT NOT NULL,
+			hash TEXT NOT NULL,
+			author TEXT NOT NULL DEFAULT '',
+			tool_call_id TEXT NOT NULL DEFAULT '',
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (bridge_id, login_id, agent_id, path, version)
+		);
 	`)
 	if err != nil {
 		t.Fatalf("create table: %v", err)